@@ -0,0 +1,99 @@
+// Command migrate applies, reverts and reports the versioned schema migrations.
+//
+// Usage:
+//
+//	migrate up [N]     apply all pending migrations, or only the next N
+//	migrate down [N]   revert the most recently applied migration, or the last N ("all" reverts everything)
+//	migrate status     list every migration and whether it has been applied
+//
+// The database is selected with the same DB_* environment variables as the server.
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/joho/godotenv"
+	"github.com/zayyadi/finance-tracker/internal/database"
+	"github.com/zayyadi/finance-tracker/migrations"
+)
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: migrate up [N] | down [N|all] | status")
+	os.Exit(2)
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+	_ = godotenv.Load() // .env is optional
+
+	if err := database.ConnectDB(); err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer database.CloseDB()
+
+	migrator, err := database.NewMigrator(database.GetDB(), migrations.FS)
+	if err != nil {
+		log.Fatalf("Failed to load migrations: %v", err)
+	}
+
+	switch os.Args[1] {
+	case "up":
+		steps := parseSteps(os.Args[2:], 0)
+		applied, err := migrator.Up(steps)
+		for _, m := range applied {
+			fmt.Printf("applied  %06d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+		if len(applied) == 0 {
+			fmt.Println("no pending migrations")
+		}
+	case "down":
+		steps := parseSteps(os.Args[2:], 1)
+		reverted, err := migrator.Down(steps)
+		for _, m := range reverted {
+			fmt.Printf("reverted %06d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			log.Fatalf("Rollback failed: %v", err)
+		}
+		if len(reverted) == 0 {
+			fmt.Println("no applied migrations")
+		}
+	case "status":
+		statuses, err := migrator.Status()
+		if err != nil {
+			log.Fatalf("Failed to read migration status: %v", err)
+		}
+		for _, s := range statuses {
+			if s.Applied {
+				fmt.Printf("applied  %06d_%s (%s)\n", s.Version, s.Name, s.AppliedAt.Format("2006-01-02 15:04:05"))
+			} else {
+				fmt.Printf("pending  %06d_%s\n", s.Version, s.Name)
+			}
+		}
+	default:
+		usage()
+	}
+}
+
+// parseSteps reads the optional step count argument. "all" (or 0) means no limit.
+func parseSteps(args []string, defaultSteps int) int {
+	if len(args) == 0 {
+		return defaultSteps
+	}
+	if args[0] == "all" {
+		return 0
+	}
+	steps, err := strconv.Atoi(args[0])
+	if err != nil || steps <= 0 {
+		usage()
+	}
+	return steps
+}
@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"strconv"
 	"time" // Added for cron.WithLocation(time.UTC)
 
 	"github.com/gin-contrib/cors" // Import CORS middleware
@@ -13,8 +14,10 @@ import (
 	"github.com/robfig/cron/v3" // Added for cron jobs
 	"github.com/zayyadi/finance-tracker/internal/database"
 	"github.com/zayyadi/finance-tracker/internal/handlers"
-	"github.com/zayyadi/finance-tracker/internal/models" // Added for direct AutoMigrate
+	"github.com/zayyadi/finance-tracker/internal/middleware"
 	"github.com/zayyadi/finance-tracker/internal/services"
+	"github.com/zayyadi/finance-tracker/internal/storage"
+	"github.com/zayyadi/finance-tracker/migrations"
 )
 
 func main() {
@@ -37,6 +40,11 @@ func main() {
 	// os.Setenv("JWT_SECRET_KEY", "your-super-secret-jwt-key-that-is-very-long-and-random")
 	// os.Setenv("OPENROUTER_API_KEY", "YOUR_DUMMY_OPENROUTER_API_KEY_FOR_TESTING")
 
+	jwtSecret := os.Getenv("JWT_SECRET_KEY")
+	if jwtSecret == "" {
+		log.Fatalf("JWT_SECRET_KEY environment variable is not set or is empty")
+	}
+
 	if err := database.ConnectDB(); err != nil {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
@@ -45,24 +53,31 @@ func main() {
 		log.Fatalf("Failed to get GORM DB instance after connecting")
 	}
 
-	// Perform Auto-Migration directly here
-	log.Println("Starting GORM auto-migration...")
-	err = db.AutoMigrate(
-		&models.User{}, // Ensure User table is created first if others depend on it
-		&models.Income{},
-		&models.Expense{},
-		&models.Savings{},
-		&models.Debt{},
-		&models.FinancialSummary{},
-	)
+	// Apply pending schema migrations. Set DB_AUTO_MIGRATE=false to require running
+	// `go run ./cmd/migrate up` explicitly; the server then refuses to start on an outdated schema.
+	migrator, err := database.NewMigrator(db, migrations.FS)
 	if err != nil {
-		log.Fatalf("Failed to auto-migrate GORM models: %v", err)
-	} else {
-		log.Println("GORM models auto-migrated successfully.")
+		log.Fatalf("Failed to load migrations: %v", err)
+	}
+	if os.Getenv("DB_AUTO_MIGRATE") == "false" {
+		pending, err := migrator.Pending()
+		if err != nil {
+			log.Fatalf("Failed to check migration status: %v", err)
+		}
+		if len(pending) > 0 {
+			log.Fatalf("Database schema is out of date: %d pending migration(s), starting with %06d_%s. Run `go run ./cmd/migrate up`.", len(pending), pending[0].Version, pending[0].Name)
+		}
+	} else if _, err := migrator.Up(0); err != nil {
+		log.Fatalf("Failed to apply migrations: %v", err)
 	}
 
 	defer database.CloseDB()
 
+	// Record every change to incomes, expenses, debts and savings goals in the audit log.
+	if err := services.RegisterAuditHooks(db); err != nil {
+		log.Fatalf("Failed to register audit hooks: %v", err)
+	}
+
 	router := gin.Default()
 
 	// CORS Middleware Configuration
@@ -72,14 +87,15 @@ func main() {
 	config := cors.DefaultConfig()
 	config.AllowOrigins = []string{"http://localhost:5173", "http://127.0.0.1:5173"} // Add your Vue dev server URL
 	config.AllowMethods = []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}
-	config.AllowHeaders = []string{"Origin", "Content-Type", "Accept", "Authorization"}
+	config.AllowHeaders = []string{"Origin", "Content-Type", "Accept", "Authorization", "If-Match"}
+	config.ExposeHeaders = []string{"X-Total-Count", "ETag"}
 	router.Use(cors.New(config))
 
 	// HTML template loading and static file serving for templates are removed.
 	// The frontend will be a separate JS application.
 
 	// Instantiate services
-	// userService := services.NewUserService(db) // Removed UserService
+	authService := services.NewAuthService(db, jwtSecret)
 	incomeService := services.NewIncomeService(db)
 	expenseService := services.NewExpenseService(db)
 	savingsService := services.NewSavingsService(db)
@@ -88,11 +104,52 @@ func main() {
 	aiAdviceService := services.NewAIAdviceService()
 	reportService := services.NewReportService(incomeService, expenseService)
 	notificationService := services.NewNotificationService(db) // Instantiate NotificationService
-	analyticsService := services.NewAnalyticsService(db)    // New AnalyticsService
+	analyticsService := services.NewAnalyticsService(db)       // New AnalyticsService
+	currencyService := services.NewCurrencyService(db)
+	accountService := services.NewAccountService(db)
+	recurringService := services.NewRecurringService(db, summaryService)
+	budgetService := services.NewBudgetService(db)
+	importService := services.NewImportService(db, summaryService)
+	duplicateService := services.NewDuplicateService(db, summaryService)
+	categoryRuleService := services.NewCategoryRuleService(db)
+	categoryService := services.NewCategoryService(db)
+	tagService := services.NewTagService(db)
+	searchService := services.NewSearchService(db)
+	transactionService := services.NewTransactionService(db)
+
+	// Attachments are stored on the local disk, under ATTACHMENT_DIR.
+	attachmentDir := os.Getenv("ATTACHMENT_DIR")
+	if attachmentDir == "" {
+		attachmentDir = "./data/attachments"
+	}
+	attachmentStorage, err := storage.NewLocal(attachmentDir)
+	if err != nil {
+		log.Fatalf("Failed to prepare attachment storage: %v", err)
+	}
+	attachmentService := services.NewAttachmentService(db, attachmentStorage)
+	if maxSizeMB := os.Getenv("ATTACHMENT_MAX_SIZE_MB"); maxSizeMB != "" {
+		mb, err := strconv.Atoi(maxSizeMB)
+		if err != nil || mb <= 0 {
+			log.Fatalf("ATTACHMENT_MAX_SIZE_MB must be a positive whole number, got %q", maxSizeMB)
+		}
+		attachmentService.MaxSize = int64(mb) << 20
+	}
+
+	// Deleted records stay in the trash for TRASH_RETENTION_DAYS; 0 keeps them until purged by hand.
+	trashService := services.NewTrashService(db, summaryService)
+	if retentionDays := os.Getenv("TRASH_RETENTION_DAYS"); retentionDays != "" {
+		days, err := strconv.Atoi(retentionDays)
+		if err != nil || days < 0 {
+			log.Fatalf("TRASH_RETENTION_DAYS must be a whole number of days, got %q", retentionDays)
+		}
+		trashService.Retention = time.Duration(days) * 24 * time.Hour
+	}
+
+	auditService := services.NewAuditService(db)
 
 	// Instantiate handlers
-	// authHandler := handlers.NewAuthHandler(userService) // Removed AuthHandler
-	incomeHandler := handlers.NewIncomeHandler(incomeService, summaryService) // Added summaryService
+	authHandler := handlers.NewAuthHandler(authService)
+	incomeHandler := handlers.NewIncomeHandler(incomeService, summaryService)    // Added summaryService
 	expenseHandler := handlers.NewExpenseHandler(expenseService, summaryService) // Added summaryService
 	savingsHandler := handlers.NewSavingsHandler(savingsService)
 	debtHandler := handlers.NewDebtHandler(debtService)
@@ -100,6 +157,20 @@ func main() {
 	aiAdviceHandler := handlers.NewAIAdviceHandler(aiAdviceService, summaryService)
 	reportHandler := handlers.NewReportHandler(reportService)
 	analyticsHandler := handlers.NewAnalyticsHandler(analyticsService) // New AnalyticsHandler
+	currencyHandler := handlers.NewCurrencyHandler(currencyService)
+	accountHandler := handlers.NewAccountHandler(accountService)
+	recurringHandler := handlers.NewRecurringHandler(recurringService)
+	budgetHandler := handlers.NewBudgetHandler(budgetService)
+	importHandler := handlers.NewImportHandler(importService)
+	duplicateHandler := handlers.NewDuplicateHandler(duplicateService)
+	categoryRuleHandler := handlers.NewCategoryRuleHandler(categoryRuleService)
+	categoryHandler := handlers.NewCategoryHandler(categoryService)
+	tagHandler := handlers.NewTagHandler(tagService)
+	attachmentHandler := handlers.NewAttachmentHandler(attachmentService)
+	searchHandler := handlers.NewSearchHandler(searchService)
+	transactionHandler := handlers.NewTransactionHandler(transactionService)
+	trashHandler := handlers.NewTrashHandler(trashService)
+	auditHandler := handlers.NewAuditHandler(auditService)
 	// viewHandler := handlers.NewViewHandler() // Removed as Go no longer serves HTML pages
 
 	// Frontend Page Routes are removed.
@@ -107,20 +178,22 @@ func main() {
 	// Example: router.GET("/", viewHandler.ShowHomePage) - REMOVED
 	// Example: router.GET("/dashboard", viewHandler.ShowDashboardPage) - REMOVED
 
-	// apiPublic := router.Group("/api") // This group is effectively empty now
-	// {
-	// apiPublic.POST("/register", authHandler.RegisterUser) // Removed
-	// apiPublic.POST("/login", authHandler.LoginUser)       // Removed
-	// }
-
-	// All API routes are now under /api/v1 and are public due to AuthMiddleware removal.
-	// For this project, assuming all data modification/retrieval requires auth.
-	// If there were public info APIs, they would remain in a non-protected group.
+	// Public authentication routes
+	authRoutes := router.Group("/api/v1/auth")
+	{
+		authRoutes.POST("/register", authHandler.RegisterHandler)
+		authRoutes.POST("/login", authHandler.LoginHandler)
+		authRoutes.POST("/refresh", authHandler.RefreshHandler)
+	}
 
-	// API routes are now directly under /api/v1 (no auth middleware)
-	apiV1 := router.Group("/api/v1")
+	// All other API routes require a valid access token; data is scoped to the authenticated user.
+	apiV1 := router.Group("/api/v1", middleware.AuthMiddleware(authService))
 	{
-		// No /profile route needed
+		apiV1.GET("/profile", authHandler.GetProfileHandler)
+		apiV1.PUT("/profile/base-currency", currencyHandler.UpdateBaseCurrencyHandler)
+		apiV1.GET("/search", searchHandler.SearchHandler)
+		apiV1.GET("/transactions", transactionHandler.ListTransactionsHandler)
+		apiV1.GET("/audit", auditHandler.ListAuditLogHandler)
 
 		incomeRoutes := apiV1.Group("/income")
 		{
@@ -129,45 +202,67 @@ func main() {
 			incomeRoutes.GET("", incomeHandler.ListIncomesHandler)
 			incomeRoutes.PUT("/:id", incomeHandler.UpdateIncomeHandler)
 			incomeRoutes.DELETE("/:id", incomeHandler.DeleteIncomeHandler)
+			incomeRoutes.POST("/bulk", incomeHandler.BulkCreateIncomesHandler)
+			incomeRoutes.PUT("/bulk", incomeHandler.BulkUpdateIncomesHandler)
+			incomeRoutes.POST("/bulk/delete", incomeHandler.BulkDeleteIncomesHandler)
+			incomeRoutes.GET("/:id/history", auditHandler.IncomeHistoryHandler)
+			incomeRoutes.POST("/:id/attachments", attachmentHandler.UploadIncomeAttachmentHandler)
+			incomeRoutes.GET("/:id/attachments", attachmentHandler.ListIncomeAttachmentsHandler)
 		}
 
-		expenseRoutes := apiV1.Group("/expenses") // Changed from apiProtected to apiV1
+		expenseRoutes := apiV1.Group("/expenses")
 		{
 			expenseRoutes.POST("", expenseHandler.CreateExpenseHandler)
 			expenseRoutes.GET("/:id", expenseHandler.GetExpenseHandler)
 			expenseRoutes.GET("", expenseHandler.ListExpensesHandler)
 			expenseRoutes.PUT("/:id", expenseHandler.UpdateExpenseHandler)
 			expenseRoutes.DELETE("/:id", expenseHandler.DeleteExpenseHandler)
+			expenseRoutes.POST("/bulk", expenseHandler.BulkCreateExpensesHandler)
+			expenseRoutes.PUT("/bulk", expenseHandler.BulkUpdateExpensesHandler)
+			expenseRoutes.POST("/bulk/delete", expenseHandler.BulkDeleteExpensesHandler)
+			expenseRoutes.GET("/:id/history", auditHandler.ExpenseHistoryHandler)
+			expenseRoutes.POST("/:id/attachments", attachmentHandler.UploadExpenseAttachmentHandler)
+			expenseRoutes.GET("/:id/attachments", attachmentHandler.ListExpenseAttachmentsHandler)
 		}
 
-		savingsRoutes := apiV1.Group("/savings") // Changed from apiProtected to apiV1
+		savingsRoutes := apiV1.Group("/savings")
 		{
 			savingsRoutes.POST("", savingsHandler.CreateSavingsHandler)
 			savingsRoutes.GET("/:id", savingsHandler.GetSavingsHandler)
 			savingsRoutes.GET("", savingsHandler.ListSavingsHandler)
 			savingsRoutes.PUT("/:id", savingsHandler.UpdateSavingsHandler)
 			savingsRoutes.DELETE("/:id", savingsHandler.DeleteSavingsHandler)
+			savingsRoutes.POST("/bulk", savingsHandler.BulkCreateSavingsHandler)
+			savingsRoutes.PUT("/bulk", savingsHandler.BulkUpdateSavingsHandler)
+			savingsRoutes.POST("/bulk/delete", savingsHandler.BulkDeleteSavingsHandler)
+			savingsRoutes.GET("/:id/history", auditHandler.SavingsHistoryHandler)
 		}
 
-		debtRoutes := apiV1.Group("/debts") // Changed from apiProtected to apiV1
+		debtRoutes := apiV1.Group("/debts")
 		{
 			debtRoutes.POST("", debtHandler.CreateDebtHandler)
 			debtRoutes.GET("/:id", debtHandler.GetDebtHandler)
 			debtRoutes.GET("", debtHandler.ListDebtsHandler)
 			debtRoutes.PUT("/:id", debtHandler.UpdateDebtHandler)
 			debtRoutes.DELETE("/:id", debtHandler.DeleteDebtHandler)
+			debtRoutes.POST("/bulk", debtHandler.BulkCreateDebtsHandler)
+			debtRoutes.PUT("/bulk", debtHandler.BulkUpdateDebtsHandler)
+			debtRoutes.POST("/bulk/delete", debtHandler.BulkDeleteDebtsHandler)
+			debtRoutes.GET("/:id/history", auditHandler.DebtHistoryHandler)
+			debtRoutes.POST("/:id/attachments", attachmentHandler.UploadDebtAttachmentHandler)
+			debtRoutes.GET("/:id/attachments", attachmentHandler.ListDebtAttachmentsHandler)
 		}
 
-		summaryRoutes := apiV1.Group("/summary") // Changed from apiProtected to apiV1
+		summaryRoutes := apiV1.Group("/summary")
 		{
 			summaryRoutes.GET("/monthly", summaryHandler.GetMonthlySummaryHandler)
 			summaryRoutes.GET("/weekly", summaryHandler.GetWeeklySummaryHandler)
 			summaryRoutes.GET("/yearly", summaryHandler.GetYearlySummaryHandler)
 		}
 
-		apiV1.GET("/advice", aiAdviceHandler.GetAdviceHandler) // Changed from apiProtected to apiV1
+		apiV1.GET("/advice", aiAdviceHandler.GetAdviceHandler)
 
-		reportRoutes := apiV1.Group("/reports") // Changed from apiProtected to apiV1
+		reportRoutes := apiV1.Group("/reports")
 		{
 			reportRoutes.GET("/csv", reportHandler.GenerateCSVReportHandler)
 			reportRoutes.GET("/pdf", reportHandler.GeneratePDFReportHandler)
@@ -177,6 +272,115 @@ func main() {
 		{
 			analyticsRoutes.GET("/expense-categories", analyticsHandler.GetExpenseBreakdownHandler)
 			analyticsRoutes.GET("/income-expense-trend", analyticsHandler.GetIncomeExpenseTrendHandler)
+			analyticsRoutes.GET("/tags", analyticsHandler.GetTagBreakdownHandler)
+		}
+
+		accountRoutes := apiV1.Group("/accounts")
+		{
+			accountRoutes.POST("", accountHandler.CreateAccountHandler)
+			accountRoutes.GET("/:id", accountHandler.GetAccountHandler)
+			accountRoutes.GET("", accountHandler.ListAccountsHandler)
+			accountRoutes.GET("/:id/ledger", accountHandler.GetAccountLedgerHandler)
+			accountRoutes.PUT("/:id", accountHandler.UpdateAccountHandler)
+			accountRoutes.DELETE("/:id", accountHandler.DeleteAccountHandler)
+		}
+
+		transferRoutes := apiV1.Group("/transfers")
+		{
+			transferRoutes.POST("", accountHandler.CreateTransferHandler)
+			transferRoutes.GET("/:id", accountHandler.GetTransferHandler)
+			transferRoutes.GET("", accountHandler.ListTransfersHandler)
+			transferRoutes.DELETE("/:id", accountHandler.DeleteTransferHandler)
+		}
+
+		recurringRoutes := apiV1.Group("/recurring")
+		{
+			recurringRoutes.POST("", recurringHandler.CreateRecurringHandler)
+			recurringRoutes.GET("/:id", recurringHandler.GetRecurringHandler)
+			recurringRoutes.GET("", recurringHandler.ListRecurringHandler)
+			recurringRoutes.PUT("/:id", recurringHandler.UpdateRecurringHandler)
+			recurringRoutes.DELETE("/:id", recurringHandler.DeleteRecurringHandler)
+		}
+
+		budgetRoutes := apiV1.Group("/budgets")
+		{
+			budgetRoutes.POST("", budgetHandler.CreateBudgetHandler)
+			budgetRoutes.GET("/status", budgetHandler.GetBudgetStatusHandler)
+			budgetRoutes.GET("/:id", budgetHandler.GetBudgetHandler)
+			budgetRoutes.GET("", budgetHandler.ListBudgetsHandler)
+			budgetRoutes.PUT("/:id", budgetHandler.UpdateBudgetHandler)
+			budgetRoutes.DELETE("/:id", budgetHandler.DeleteBudgetHandler)
+		}
+
+		importRoutes := apiV1.Group("/import")
+		{
+			importRoutes.POST("/csv", importHandler.ImportCSVHandler)
+			importRoutes.POST("/ofx", importHandler.ImportOFXHandler)
+			importRoutes.POST("/qif", importHandler.ImportQIFHandler)
+			importRoutes.POST("/profiles", importHandler.CreateImportProfileHandler)
+			importRoutes.GET("/profiles/:id", importHandler.GetImportProfileHandler)
+			importRoutes.GET("/profiles", importHandler.ListImportProfilesHandler)
+			importRoutes.PUT("/profiles/:id", importHandler.UpdateImportProfileHandler)
+			importRoutes.DELETE("/profiles/:id", importHandler.DeleteImportProfileHandler)
+		}
+
+		duplicateRoutes := apiV1.Group("/duplicates")
+		{
+			duplicateRoutes.GET("", duplicateHandler.ListDuplicatesHandler)
+			duplicateRoutes.POST("/scan", duplicateHandler.ScanDuplicatesHandler)
+			duplicateRoutes.GET("/:id", duplicateHandler.GetDuplicateHandler)
+			duplicateRoutes.POST("/:id/merge", duplicateHandler.MergeDuplicateHandler)
+			duplicateRoutes.POST("/:id/dismiss", duplicateHandler.DismissDuplicateHandler)
+		}
+
+		categoryRuleRoutes := apiV1.Group("/category-rules")
+		{
+			categoryRuleRoutes.POST("", categoryRuleHandler.CreateCategoryRuleHandler)
+			categoryRuleRoutes.POST("/test", categoryRuleHandler.TestCategoryRuleHandler)
+			categoryRuleRoutes.POST("/apply", categoryRuleHandler.ApplyCategoryRulesHandler)
+			categoryRuleRoutes.GET("/:id", categoryRuleHandler.GetCategoryRuleHandler)
+			categoryRuleRoutes.GET("", categoryRuleHandler.ListCategoryRulesHandler)
+			categoryRuleRoutes.PUT("/:id", categoryRuleHandler.UpdateCategoryRuleHandler)
+			categoryRuleRoutes.DELETE("/:id", categoryRuleHandler.DeleteCategoryRuleHandler)
+		}
+
+		categoryRoutes := apiV1.Group("/categories")
+		{
+			categoryRoutes.POST("", categoryHandler.CreateCategoryHandler)
+			categoryRoutes.GET("", categoryHandler.ListCategoriesHandler)
+			categoryRoutes.GET("/tree", categoryHandler.GetCategoryTreeHandler)
+			categoryRoutes.GET("/:id", categoryHandler.GetCategoryHandler)
+			categoryRoutes.PUT("/:id", categoryHandler.UpdateCategoryHandler)
+			categoryRoutes.DELETE("/:id", categoryHandler.DeleteCategoryHandler)
+		}
+
+		tagRoutes := apiV1.Group("/tags")
+		{
+			tagRoutes.GET("", tagHandler.ListTagsHandler)
+			tagRoutes.PUT("/:id", tagHandler.RenameTagHandler)
+			tagRoutes.DELETE("/:id", tagHandler.DeleteTagHandler)
+		}
+
+		attachmentRoutes := apiV1.Group("/attachments")
+		{
+			attachmentRoutes.GET("/:id", attachmentHandler.GetAttachmentHandler)
+			attachmentRoutes.GET("/:id/download", attachmentHandler.DownloadAttachmentHandler)
+			attachmentRoutes.DELETE("/:id", attachmentHandler.DeleteAttachmentHandler)
+		}
+
+		trashRoutes := apiV1.Group("/trash")
+		{
+			trashRoutes.GET("", trashHandler.ListTrashHandler)
+			trashRoutes.DELETE("", trashHandler.EmptyTrashHandler)
+			trashRoutes.POST("/:type/:id/restore", trashHandler.RestoreTrashHandler)
+			trashRoutes.DELETE("/:type/:id", trashHandler.PurgeTrashHandler)
+		}
+
+		exchangeRateRoutes := apiV1.Group("/exchange-rates")
+		{
+			exchangeRateRoutes.POST("", currencyHandler.CreateExchangeRateHandler)
+			exchangeRateRoutes.GET("", currencyHandler.ListExchangeRatesHandler)
+			exchangeRateRoutes.POST("/import", currencyHandler.ImportExchangeRatesHandler)
 		}
 	}
 
@@ -197,8 +401,51 @@ func main() {
 		log.Fatalf("Error adding cron job CheckDueDatesAndGoals: %v", errCron)
 	}
 
+	// Generate due recurring income and expenses hourly, so a new day's occurrences appear soon
+	// after midnight UTC. Runs are idempotent; a missed run is caught up by the next one.
+	materializeRecurring := func() {
+		created, err := recurringService.MaterializeDue(time.Now().UTC())
+		if err != nil {
+			log.Printf("Cron Job: Error generating recurring transactions: %v", err)
+		}
+		if created > 0 {
+			log.Printf("Cron Job: Generated %d recurring transaction(s)", created)
+		}
+	}
+	if _, errCron = cronScheduler.AddFunc("0 5 * * * *", materializeRecurring); errCron != nil {
+		log.Fatalf("Error adding cron job MaterializeDue: %v", errCron)
+	}
+	go materializeRecurring() // Catch up on anything that fell due while the server was down
+
+	// Purge records that have been in the trash for longer than the retention period, daily at 3:30 AM
+	// UTC, before the files of their attachments are removed.
+	if _, errCron = cronScheduler.AddFunc("0 30 3 * * *", func() {
+		purged, err := trashService.PurgeExpired()
+		if err != nil {
+			log.Printf("Cron Job: Error purging expired trash: %v", err)
+		}
+		if purged > 0 {
+			log.Printf("Cron Job: Purged %d record(s) from the trash", purged)
+		}
+	}); errCron != nil {
+		log.Fatalf("Error adding cron job PurgeExpired: %v", errCron)
+	}
+
+	// Remove the files of records that have been deleted for good, daily at 4 AM UTC.
+	if _, errCron = cronScheduler.AddFunc("0 0 4 * * *", func() {
+		removed, err := attachmentService.DeleteOrphanedAttachments()
+		if err != nil {
+			log.Printf("Cron Job: Error removing orphaned attachments: %v", err)
+		}
+		if removed > 0 {
+			log.Printf("Cron Job: Removed %d orphaned attachment(s)", removed)
+		}
+	}); errCron != nil {
+		log.Fatalf("Error adding cron job DeleteOrphanedAttachments: %v", errCron)
+	}
+
 	cronScheduler.Start()
-	log.Println("Cron scheduler started. Daily checks scheduled for 3:00 AM UTC.")
+	log.Println("Cron scheduler started. Daily checks scheduled for 3:00 AM UTC; recurring transactions generated hourly.")
 	// In a real application, consider graceful shutdown of the scheduler:
 	// defer cronScheduler.Stop() // This needs careful handling with server lifecycle
 
@@ -4,11 +4,18 @@ const apiClient = axios.create({
   baseURL: 'http://localhost:8080/api/v1', // Your Go API base URL
   headers: {
     'Content-Type': 'application/json',
-    // You might add Authorization header here if you re-implement JWT auth
-    // 'Authorization': `Bearer ${localStorage.getItem('token')}`
   }
 });
 
+// Attach the access token issued by /auth/login or /auth/register.
+apiClient.interceptors.request.use(config => {
+  const token = localStorage.getItem('token');
+  if (token) {
+    config.headers.Authorization = `Bearer ${token}`;
+  }
+  return config;
+});
+
 // Optional: Interceptors for request or response handling
 // apiClient.interceptors.response.use(response => response, error => {
 //   if (error.response && error.response.status === 401) {
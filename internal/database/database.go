@@ -4,18 +4,70 @@ import (
 	"fmt"
 	"log"
 	"os"
-
-	// "database/sql" // No longer directly using sql.DB for GetDB
+	"strings"
 
 	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
-	// _ "github.com/lib/pq" // GORM's driver will handle this
 )
 
 var gormDB *gorm.DB
 
+// Supported values for the DB_DRIVER environment variable.
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
+// defaultSQLitePath is used when DB_DRIVER=sqlite and DB_PATH is not set.
+const defaultSQLitePath = "finance_tracker.db"
+
 // ConnectDB initializes the GORM database connection using environment variables.
+// DB_DRIVER selects the engine: "postgres" (the default) or "sqlite".
 func ConnectDB() error {
+	driver := strings.ToLower(strings.TrimSpace(os.Getenv("DB_DRIVER")))
+	if driver == "" {
+		driver = DriverPostgres
+	}
+
+	var dialector gorm.Dialector
+	var err error
+	switch driver {
+	case DriverPostgres:
+		dialector, err = postgresDialector()
+	case DriverSQLite:
+		dialector = sqliteDialector()
+	default:
+		return fmt.Errorf("unsupported DB_DRIVER %q (expected %q or %q)", driver, DriverPostgres, DriverSQLite)
+	}
+	if err != nil {
+		return err
+	}
+
+	// TranslateError maps driver-specific errors (e.g. unique violations) to gorm's
+	// sentinel errors so services don't need to match engine-specific messages.
+	gormDB, err = gorm.Open(dialector, &gorm.Config{TranslateError: true})
+	if err != nil {
+		return fmt.Errorf("error opening database with GORM: %w", err)
+	}
+
+	// Optional: Ping the database to ensure connection is live
+	sqlDB, err := gormDB.DB()
+	if err != nil {
+		return fmt.Errorf("error getting underlying sql.DB from GORM: %w", err)
+	}
+	err = sqlDB.Ping()
+	if err != nil {
+		// sqlDB.Close() // GORM handles closing the underlying connection
+		return fmt.Errorf("error connecting to database (ping failed): %w", err)
+	}
+
+	log.Printf("Successfully connected to the %s database using GORM!", driver)
+	return nil
+}
+
+// postgresDialector builds the PostgreSQL dialector from the DB_* environment variables.
+func postgresDialector() (gorm.Dialector, error) {
 	dbUser := os.Getenv("DB_USER")
 	dbPassword := os.Getenv("DB_PASSWORD")
 	dbName := os.Getenv("DB_NAME")
@@ -25,14 +77,13 @@ func ConnectDB() error {
 
 	// Validate essential environment variables
 	if dbHost == "" {
-		return fmt.Errorf("DB_HOST environment variable is not set or is empty")
+		return nil, fmt.Errorf("DB_HOST environment variable is not set or is empty")
 	}
-	// DB_USER is typically required. The error log indicates it was set ('zayyadev') in this instance.
 	if dbUser == "" {
-		return fmt.Errorf("DB_USER environment variable is not set or is empty")
+		return nil, fmt.Errorf("DB_USER environment variable is not set or is empty")
 	}
 	if dbName == "" {
-		return fmt.Errorf("DB_NAME environment variable is not set or is empty")
+		return nil, fmt.Errorf("DB_NAME environment variable is not set or is empty")
 	}
 
 	if dbPort == "" {
@@ -45,26 +96,19 @@ func ConnectDB() error {
 
 	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=%s TimeZone=UTC",
 		dbHost, dbUser, dbPassword, dbName, dbPort, dbSSLMode)
+	return postgres.Open(dsn), nil
+}
 
-	var err error
-	gormDB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{})
-	if err != nil {
-		return fmt.Errorf("error opening database with GORM: %w", err)
+// sqliteDialector builds the SQLite dialector for the file named by DB_PATH.
+// Foreign keys are enabled, and WAL mode plus a busy timeout let the background
+// summary invalidation goroutines write while a request is being served.
+func sqliteDialector() gorm.Dialector {
+	dbPath := os.Getenv("DB_PATH")
+	if dbPath == "" {
+		dbPath = defaultSQLitePath
 	}
-
-	// Optional: Ping the database to ensure connection is live
-	sqlDB, err := gormDB.DB()
-	if err != nil {
-		return fmt.Errorf("error getting underlying sql.DB from GORM: %w", err)
-	}
-	err = sqlDB.Ping()
-	if err != nil {
-		// sqlDB.Close() // GORM handles closing the underlying connection
-		return fmt.Errorf("error connecting to database (ping failed): %w", err)
-	}
-
-	log.Println("Successfully connected to the database using GORM!")
-	return nil
+	dsn := fmt.Sprintf("file:%s?_foreign_keys=on&_journal_mode=WAL&_busy_timeout=5000", dbPath)
+	return sqlite.Open(dsn)
 }
 
 // GetDB returns the active GORM database connection.
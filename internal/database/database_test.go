@@ -0,0 +1,69 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type uniqueThing struct {
+	ID   uint   `gorm:"primaryKey"`
+	Name string `gorm:"uniqueIndex"`
+}
+
+func TestConnectDB_SQLite(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "finance_test.db")
+	t.Setenv("DB_DRIVER", "sqlite")
+	t.Setenv("DB_PATH", dbPath)
+	t.Setenv("DB_HOST", "") // Must not be required for SQLite
+
+	err := ConnectDB()
+	assert.NoError(t, err)
+	t.Cleanup(func() {
+		CloseDB()
+		gormDB = nil
+	})
+
+	db := GetDB()
+	assert.NotNil(t, db)
+	assert.Equal(t, "sqlite", db.Dialector.Name())
+	assert.FileExists(t, dbPath)
+
+	assert.NoError(t, db.AutoMigrate(&uniqueThing{}))
+	assert.NoError(t, db.Create(&uniqueThing{Name: "a"}).Error)
+	dupErr := db.Create(&uniqueThing{Name: "a"}).Error
+	assert.ErrorIs(t, dupErr, gorm.ErrDuplicatedKey, "ConnectDB should translate driver errors")
+	assert.True(t, IsUniqueViolation(dupErr))
+}
+
+func TestConnectDB_UnsupportedDriver(t *testing.T) {
+	t.Setenv("DB_DRIVER", "mysql")
+
+	err := ConnectDB()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported DB_DRIVER")
+}
+
+func TestConnectDB_PostgresRequiresHost(t *testing.T) {
+	t.Setenv("DB_DRIVER", "")
+	t.Setenv("DB_HOST", "")
+
+	err := ConnectDB()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "DB_HOST")
+}
+
+func TestIsUniqueViolation_UntranslatedSQLiteError(t *testing.T) {
+	db := openTestDB(t)
+	assert.NoError(t, db.AutoMigrate(&uniqueThing{}))
+
+	assert.NoError(t, db.Create(&uniqueThing{Name: "a"}).Error)
+	dupErr := db.Create(&uniqueThing{Name: "a"}).Error
+	assert.Error(t, dupErr)
+	assert.True(t, IsUniqueViolation(dupErr))
+
+	assert.False(t, IsUniqueViolation(nil))
+	assert.False(t, IsUniqueViolation(gorm.ErrRecordNotFound))
+}
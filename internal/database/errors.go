@@ -0,0 +1,23 @@
+package database
+
+import (
+	"errors"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// IsUniqueViolation reports whether err was caused by a unique constraint violation.
+// Connections opened by ConnectDB translate these to gorm.ErrDuplicatedKey; the message
+// checks cover connections opened without TranslateError (e.g. in tests).
+func IsUniqueViolation(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return true
+	}
+	msg := err.Error()
+	return strings.Contains(msg, "duplicate key value violates unique constraint") || // PostgreSQL
+		strings.Contains(msg, "UNIQUE constraint failed") // SQLite
+}
@@ -0,0 +1,338 @@
+package database
+
+import (
+	"fmt"
+	"io/fs"
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// schemaMigrationsTable records which migrations have been applied.
+const schemaMigrationsTable = "schema_migrations"
+
+// migrationFileRe matches files named like "000001_create_users_table.up.sql", or like
+// "000020_search_indexes.postgres.up.sql" for a migration that only applies to one engine.
+var migrationFileRe = regexp.MustCompile(`^(\d+)_([a-zA-Z0-9_]+)(?:\.(postgres|sqlite))?\.(up|down)\.sql$`)
+
+// sqliteReplacer rewrites the few PostgreSQL-only type names used in the migration
+// files into their SQLite equivalents, so a single set of files serves both engines.
+// TIMESTAMPTZ must become DATETIME because the SQLite driver only parses values back
+// into time.Time for date/datetime/timestamp column types.
+var sqliteReplacer = strings.NewReplacer(
+	"BIGSERIAL PRIMARY KEY", "INTEGER PRIMARY KEY AUTOINCREMENT",
+	"TIMESTAMPTZ", "DATETIME",
+)
+
+// ownedTables are the tables the first migrations create with a user_id column. Earlier versions
+// created them with AutoMigrate, without one, and the migrations leave existing tables alone.
+var ownedTables = []string{"incomes", "expenses", "savings", "debts", "financial_summaries"}
+
+// Migration is a single versioned schema change loaded from the migrations directory.
+type Migration struct {
+	Version uint64
+	Name    string
+	UpSQL   string
+	DownSQL string
+	Driver  string // When set, the scripts only run on this engine; others just record the migration
+}
+
+// MigrationStatus describes whether a migration has been applied to the database.
+type MigrationStatus struct {
+	Migration
+	Applied   bool
+	AppliedAt *time.Time
+}
+
+// schemaMigration is a row of the schema_migrations table.
+type schemaMigration struct {
+	Version   uint64
+	Name      string
+	AppliedAt time.Time
+}
+
+// Migrator applies and reverts the versioned SQL migrations against a database.
+type Migrator struct {
+	DB         *gorm.DB
+	migrations []Migration
+}
+
+// NewMigrator loads the migrations found in fsys and returns a Migrator for db.
+func NewMigrator(db *gorm.DB, fsys fs.FS) (*Migrator, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database connection not initialized in Migrator")
+	}
+	migrations, err := LoadMigrations(fsys)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{DB: db, migrations: migrations}, nil
+}
+
+// LoadMigrations reads all *.up.sql / *.down.sql pairs from the root of fsys, ordered by version.
+func LoadMigrations(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("could not read migrations: %w", err)
+	}
+
+	byVersion := make(map[uint64]*Migration)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		match := migrationFileRe.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue // Not a migration file (e.g. the embedding .go file)
+		}
+		version, err := strconv.ParseUint(match[1], 10, 64)
+		if err != nil || version == 0 {
+			return nil, fmt.Errorf("invalid migration version in %s", entry.Name())
+		}
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("could not read migration %s: %w", entry.Name(), err)
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2], Driver: match[3]}
+			byVersion[version] = m
+		} else if m.Name != match[2] {
+			return nil, fmt.Errorf("migration version %d is used by both %q and %q", version, m.Name, match[2])
+		} else if m.Driver != match[3] {
+			return nil, fmt.Errorf("migration %d_%s has up and down scripts for different engines", version, m.Name)
+		}
+		if match[4] == "up" {
+			m.UpSQL = string(content)
+		} else {
+			m.DownSQL = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if strings.TrimSpace(m.UpSQL) == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up.sql", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Status returns every known migration along with whether it has been applied.
+func (m *Migrator) Status() ([]MigrationStatus, error) {
+	applied, err := m.appliedMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := MigrationStatus{Migration: migration}
+		if row, ok := applied[migration.Version]; ok {
+			appliedAt := row.AppliedAt
+			status.Applied = true
+			status.AppliedAt = &appliedAt
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// Pending returns the migrations that have not been applied yet, in version order.
+func (m *Migrator) Pending() ([]Migration, error) {
+	applied, err := m.appliedMigrations()
+	if err != nil {
+		return nil, err
+	}
+	var pending []Migration
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; !ok {
+			pending = append(pending, migration)
+		}
+	}
+	return pending, nil
+}
+
+// Up applies up to steps pending migrations in version order (all of them if steps <= 0).
+// Each migration runs in its own transaction together with its schema_migrations row.
+func (m *Migrator) Up(steps int) ([]Migration, error) {
+	pending, err := m.Pending()
+	if err != nil {
+		return nil, err
+	}
+	if len(pending) == len(m.migrations) {
+		if err := m.checkUnownedTables(); err != nil {
+			return nil, err
+		}
+	}
+	if steps > 0 && steps < len(pending) {
+		pending = pending[:steps]
+	}
+
+	var done []Migration
+	for _, migration := range pending {
+		err := m.DB.Transaction(func(tx *gorm.DB) error {
+			if err := m.execScript(tx, migration, migration.UpSQL); err != nil {
+				return err
+			}
+			return tx.Table(schemaMigrationsTable).Create(&schemaMigration{
+				Version:   migration.Version,
+				Name:      migration.Name,
+				AppliedAt: time.Now().UTC(),
+			}).Error
+		})
+		if err != nil {
+			log.Printf("Error applying migration %d_%s: %v", migration.Version, migration.Name, err)
+			return done, fmt.Errorf("could not apply migration %d_%s: %w", migration.Version, migration.Name, err)
+		}
+		log.Printf("Applied migration %d_%s", migration.Version, migration.Name)
+		done = append(done, migration)
+	}
+	return done, nil
+}
+
+// Down reverts up to steps applied migrations, newest first (all of them if steps <= 0).
+func (m *Migrator) Down(steps int) ([]Migration, error) {
+	applied, err := m.appliedMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	var toRevert []Migration
+	for i := len(m.migrations) - 1; i >= 0; i-- {
+		if _, ok := applied[m.migrations[i].Version]; ok {
+			toRevert = append(toRevert, m.migrations[i])
+		}
+	}
+	if steps > 0 && steps < len(toRevert) {
+		toRevert = toRevert[:steps]
+	}
+
+	var done []Migration
+	for _, migration := range toRevert {
+		if strings.TrimSpace(migration.DownSQL) == "" {
+			return done, fmt.Errorf("migration %d_%s has no down.sql and cannot be reverted", migration.Version, migration.Name)
+		}
+		err := m.DB.Transaction(func(tx *gorm.DB) error {
+			if err := m.execScript(tx, migration, migration.DownSQL); err != nil {
+				return err
+			}
+			return tx.Exec("DELETE FROM "+schemaMigrationsTable+" WHERE version = ?", migration.Version).Error
+		})
+		if err != nil {
+			log.Printf("Error reverting migration %d_%s: %v", migration.Version, migration.Name, err)
+			return done, fmt.Errorf("could not revert migration %d_%s: %w", migration.Version, migration.Name, err)
+		}
+		log.Printf("Reverted migration %d_%s", migration.Version, migration.Name)
+		done = append(done, migration)
+	}
+	return done, nil
+}
+
+// checkUnownedTables refuses to migrate a database whose records were created before they had owners:
+// the first migrations would skip its tables and leave them without the user_id column every query needs.
+func (m *Migrator) checkUnownedTables() error {
+	for _, table := range ownedTables {
+		if m.DB.Migrator().HasTable(table) && !m.DB.Migrator().HasColumn(table, "user_id") {
+			return fmt.Errorf("table %s was created by an earlier version without a user_id column; add one holding the ID of the user who owns each row (e.g. ALTER TABLE %s ADD COLUMN user_id BIGINT NOT NULL DEFAULT 1) to every such table, then run the migrations again", table, table)
+		}
+	}
+	return nil
+}
+
+// ensureMigrationsTable creates the schema_migrations table if it does not exist yet.
+func (m *Migrator) ensureMigrationsTable() error {
+	err := m.DB.Exec(`CREATE TABLE IF NOT EXISTS ` + schemaMigrationsTable + ` (
+		version BIGINT PRIMARY KEY,
+		name VARCHAR(255) NOT NULL,
+		applied_at ` + m.dialectSQL("TIMESTAMPTZ") + ` NOT NULL
+	)`).Error
+	if err != nil {
+		return fmt.Errorf("could not create %s table: %w", schemaMigrationsTable, err)
+	}
+	return nil
+}
+
+func (m *Migrator) appliedMigrations() (map[uint64]schemaMigration, error) {
+	if err := m.ensureMigrationsTable(); err != nil {
+		return nil, err
+	}
+	var rows []schemaMigration
+	if err := m.DB.Table(schemaMigrationsTable).Order("version").Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("could not read %s: %w", schemaMigrationsTable, err)
+	}
+	applied := make(map[uint64]schemaMigration, len(rows))
+	for _, row := range rows {
+		applied[row.Version] = row
+	}
+	return applied, nil
+}
+
+// execScript runs each statement of one of migration's scripts in order. The scripts of a migration
+// for another engine are skipped.
+func (m *Migrator) execScript(tx *gorm.DB, migration Migration, script string) error {
+	if migration.Driver != "" && migration.Driver != m.DB.Dialector.Name() {
+		return nil
+	}
+	for _, stmt := range splitStatements(m.dialectSQL(script)) {
+		if err := tx.Exec(stmt).Error; err != nil {
+			return fmt.Errorf("%w (statement: %s)", err, stmt)
+		}
+	}
+	return nil
+}
+
+func (m *Migrator) dialectSQL(sql string) string {
+	if m.DB.Dialector.Name() == DriverSQLite {
+		return sqliteReplacer.Replace(sql)
+	}
+	return sql
+}
+
+// splitStatements splits a SQL script on semicolons, ignoring semicolons inside
+// quoted strings and "--" comments. Migrations are plain DDL, so this is sufficient.
+func splitStatements(script string) []string {
+	var statements []string
+	var current strings.Builder
+	inString, inComment := false, false
+
+	for i := 0; i < len(script); i++ {
+		ch := script[i]
+		switch {
+		case inComment:
+			if ch == '\n' {
+				inComment = false
+				current.WriteByte(ch)
+			}
+			continue
+		case inString:
+			if ch == '\'' {
+				inString = false
+			}
+		case ch == '-' && i+1 < len(script) && script[i+1] == '-':
+			inComment = true
+			continue
+		case ch == '\'':
+			inString = true
+		case ch == ';':
+			if stmt := strings.TrimSpace(current.String()); stmt != "" {
+				statements = append(statements, stmt)
+			}
+			current.Reset()
+			continue
+		}
+		current.WriteByte(ch)
+	}
+	if stmt := strings.TrimSpace(current.String()); stmt != "" {
+		statements = append(statements, stmt)
+	}
+	return statements
+}
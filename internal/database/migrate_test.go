@@ -0,0 +1,159 @@
+package database
+
+import (
+	"fmt"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// openTestDB opens a private shared-cache in-memory SQLite database, so every pooled
+// connection sees the same schema.
+func openTestDB(t *testing.T) *gorm.DB {
+	dsn := fmt.Sprintf("file:%s_%d?mode=memory&cache=shared", t.Name(), time.Now().UnixNano())
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{})
+	require.NoError(t, err, "Failed to connect to in-memory SQLite")
+	sqlDB, _ := db.DB()
+	t.Cleanup(func() { sqlDB.Close() })
+	return db
+}
+
+func testMigrationsFS() fstest.MapFS {
+	return fstest.MapFS{
+		"000001_create_things.up.sql":   {Data: []byte("CREATE TABLE things (id BIGSERIAL PRIMARY KEY, name TEXT NOT NULL DEFAULT 'a;b', created_at TIMESTAMPTZ); -- trailing; comment\nCREATE INDEX idx_things_name ON things(name);")},
+		"000001_create_things.down.sql": {Data: []byte("DROP TABLE things;")},
+		"000002_add_colour.up.sql":      {Data: []byte("ALTER TABLE things ADD COLUMN colour TEXT;")},
+		"000002_add_colour.down.sql":    {Data: []byte("ALTER TABLE things DROP COLUMN colour;")},
+		"README.md":                     {Data: []byte("ignored")},
+	}
+}
+
+func TestSplitStatements(t *testing.T) {
+	stmts := splitStatements("CREATE TABLE a (x TEXT DEFAULT 'semi;colon'); -- comment; here\n\nCREATE INDEX i ON a(x);\n")
+	assert.Equal(t, []string{
+		"CREATE TABLE a (x TEXT DEFAULT 'semi;colon')",
+		"CREATE INDEX i ON a(x)",
+	}, stmts)
+}
+
+func TestLoadMigrations(t *testing.T) {
+	migrations, err := LoadMigrations(testMigrationsFS())
+	require.NoError(t, err)
+	require.Len(t, migrations, 2)
+	assert.Equal(t, uint64(1), migrations[0].Version)
+	assert.Equal(t, "create_things", migrations[0].Name)
+	assert.Equal(t, uint64(2), migrations[1].Version)
+	assert.NotEmpty(t, migrations[1].DownSQL)
+
+	_, err = LoadMigrations(fstest.MapFS{"000001_only_down.down.sql": {Data: []byte("SELECT 1;")}})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "has no up.sql")
+}
+
+func TestMigrator_UpDownStatus(t *testing.T) {
+	db := openTestDB(t)
+	migrator, err := NewMigrator(db, testMigrationsFS())
+	require.NoError(t, err)
+
+	applied, err := migrator.Up(1)
+	require.NoError(t, err)
+	assert.Len(t, applied, 1)
+	assert.True(t, db.Migrator().HasTable("things"))
+	assert.False(t, db.Migrator().HasColumn("things", "colour"))
+
+	// BIGSERIAL is rewritten for SQLite, so ids autoincrement.
+	require.NoError(t, db.Exec("INSERT INTO things (name) VALUES ('x')").Error)
+	var id int64
+	require.NoError(t, db.Raw("SELECT id FROM things").Scan(&id).Error)
+	assert.Equal(t, int64(1), id)
+
+	statuses, err := migrator.Status()
+	require.NoError(t, err)
+	require.Len(t, statuses, 2)
+	assert.True(t, statuses[0].Applied)
+	assert.NotNil(t, statuses[0].AppliedAt)
+	assert.False(t, statuses[1].Applied)
+
+	applied, err = migrator.Up(0)
+	require.NoError(t, err)
+	assert.Len(t, applied, 1)
+	assert.True(t, db.Migrator().HasColumn("things", "colour"))
+
+	reverted, err := migrator.Down(1)
+	require.NoError(t, err)
+	require.Len(t, reverted, 1)
+	assert.Equal(t, uint64(2), reverted[0].Version)
+	assert.False(t, db.Migrator().HasColumn("things", "colour"))
+
+	pending, err := migrator.Pending()
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, uint64(2), pending[0].Version)
+}
+
+func TestMigrator_FailedMigrationIsRolledBack(t *testing.T) {
+	db := openTestDB(t)
+	fsys := fstest.MapFS{
+		"000001_broken.up.sql": {Data: []byte("CREATE TABLE ok_table (id INTEGER); CREATE TABLE broken (;")},
+	}
+	migrator, err := NewMigrator(db, fsys)
+	require.NoError(t, err)
+
+	_, err = migrator.Up(0)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "could not apply migration 1_broken")
+	assert.False(t, db.Migrator().HasTable("ok_table"), "Partial migration should be rolled back")
+
+	pending, err := migrator.Pending()
+	require.NoError(t, err)
+	assert.Len(t, pending, 1)
+}
+
+func TestMigrator_RefusesUnownedTables(t *testing.T) {
+	db := openTestDB(t)
+	// A table left by the AutoMigrate of an earlier version, from before records had owners.
+	require.NoError(t, db.Exec("CREATE TABLE expenses (id INTEGER PRIMARY KEY, amount TEXT)").Error)
+	migrator, err := NewMigrator(db, testMigrationsFS())
+	require.NoError(t, err)
+
+	_, err = migrator.Up(0)
+	assert.ErrorContains(t, err, "table expenses was created by an earlier version without a user_id column")
+	pending, err := migrator.Pending()
+	require.NoError(t, err)
+	assert.Len(t, pending, 2, "Nothing is applied")
+
+	require.NoError(t, db.Exec("ALTER TABLE expenses ADD COLUMN user_id BIGINT NOT NULL DEFAULT 1").Error)
+	applied, err := migrator.Up(0)
+	require.NoError(t, err)
+	assert.Len(t, applied, 2)
+}
+
+func TestMigrator_SkipsMigrationsForOtherEngines(t *testing.T) {
+	db := openTestDB(t)
+	fsys := fstest.MapFS{
+		"000001_create_things.up.sql":            {Data: []byte("CREATE TABLE things (id INTEGER, name TEXT);")},
+		"000002_things_search.postgres.up.sql":   {Data: []byte("CREATE INDEX idx_things_search ON things USING GIN (to_tsvector('english', name));")},
+		"000002_things_search.postgres.down.sql": {Data: []byte("DROP INDEX idx_things_search;")},
+	}
+	migrator, err := NewMigrator(db, fsys)
+	require.NoError(t, err)
+
+	applied, err := migrator.Up(0)
+	require.NoError(t, err)
+	require.Len(t, applied, 2, "The PostgreSQL-only migration is recorded on SQLite without running")
+	assert.Equal(t, DriverPostgres, applied[1].Driver)
+	assert.False(t, db.Migrator().HasIndex("things", "idx_things_search"))
+	_, err = migrator.Down(1)
+	require.NoError(t, err)
+
+	_, err = LoadMigrations(fstest.MapFS{
+		"000001_mixed.postgres.up.sql": {Data: []byte("SELECT 1;")},
+		"000001_mixed.down.sql":        {Data: []byte("SELECT 1;")},
+	})
+	assert.ErrorContains(t, err, "different engines")
+}
@@ -0,0 +1,307 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zayyadi/finance-tracker/internal/models"
+	"github.com/zayyadi/finance-tracker/internal/services"
+)
+
+// AccountHandler handles HTTP requests for accounts and transfers between them.
+type AccountHandler struct {
+	service *services.AccountService
+}
+
+// NewAccountHandler creates a new AccountHandler with the given service.
+func NewAccountHandler(service *services.AccountService) *AccountHandler {
+	return &AccountHandler{service: service}
+}
+
+// CreateAccountHandler handles the creation of a new account.
+func (h *AccountHandler) CreateAccountHandler(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req models.AccountCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	account := models.Account{
+		UserID:         userID,
+		Name:           req.Name,
+		Type:           req.Type,
+		Currency:       req.Currency,
+		OpeningBalance: req.OpeningBalance,
+	}
+	if err := h.service.CreateAccount(&account); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create account: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.AccountWithBalance{Account: account, Balance: account.OpeningBalance})
+}
+
+// GetAccountHandler handles fetching a single account with its current balance.
+func (h *AccountHandler) GetAccountHandler(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	accountIDUint64, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || accountIDUint64 == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid account ID format"})
+		return
+	}
+
+	account, err := h.service.GetAccountWithBalance(userID, uint(accountIDUint64))
+	if err != nil {
+		if strings.Contains(err.Error(), "account not found") {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve account: " + err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, account)
+}
+
+// ListAccountsHandler handles fetching the user's accounts with their balances, with pagination.
+func (h *AccountHandler) ListAccountsHandler(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit < 1 {
+		limit = 10
+	}
+	offset := (page - 1) * limit
+
+	accounts, err := h.service.GetAccounts(userID, offset, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve accounts: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, accounts)
+}
+
+// GetAccountLedgerHandler handles fetching an account's entries with their running balance.
+func (h *AccountHandler) GetAccountLedgerHandler(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	accountIDUint64, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || accountIDUint64 == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid account ID format"})
+		return
+	}
+
+	ledger, err := h.service.GetAccountLedger(userID, uint(accountIDUint64))
+	if err != nil {
+		if strings.Contains(err.Error(), "account not found") {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve account ledger: " + err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, ledger)
+}
+
+// UpdateAccountHandler handles updating an existing account.
+func (h *AccountHandler) UpdateAccountHandler(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	accountIDUint64, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || accountIDUint64 == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid account ID format"})
+		return
+	}
+
+	var req models.AccountUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+	if req.Name == nil && req.Type == nil && req.OpeningBalance == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "At least one field must be provided for update"})
+		return
+	}
+
+	if _, err := h.service.UpdateAccount(userID, uint(accountIDUint64), &req); err != nil {
+		if strings.Contains(err.Error(), "account not found") {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update account: " + err.Error()})
+		}
+		return
+	}
+
+	account, err := h.service.GetAccountWithBalance(userID, uint(accountIDUint64))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve updated account: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, account)
+}
+
+// DeleteAccountHandler handles deleting an account that has nothing booked against it.
+func (h *AccountHandler) DeleteAccountHandler(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	accountIDUint64, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || accountIDUint64 == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid account ID format"})
+		return
+	}
+
+	if err := h.service.DeleteAccount(userID, uint(accountIDUint64)); err != nil {
+		if strings.Contains(err.Error(), "account not found") {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		} else if strings.Contains(err.Error(), "still has transactions") {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete account: " + err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// CreateTransferHandler handles moving money between two of the user's accounts.
+func (h *AccountHandler) CreateTransferHandler(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req models.TransferCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	transfer, err := h.service.CreateTransfer(userID, &req)
+	if err != nil {
+		if strings.Contains(err.Error(), "could not") {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create transfer: " + err.Error()})
+		} else {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, transfer)
+}
+
+// GetTransferHandler handles fetching a single transfer.
+func (h *AccountHandler) GetTransferHandler(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	transferIDUint64, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || transferIDUint64 == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid transfer ID format"})
+		return
+	}
+
+	transfer, err := h.service.GetTransferByID(userID, uint(transferIDUint64))
+	if err != nil {
+		if strings.Contains(err.Error(), "transfer not found") {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve transfer: " + err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, transfer)
+}
+
+// ListTransfersHandler handles fetching the user's transfers with pagination.
+func (h *AccountHandler) ListTransfersHandler(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit < 1 {
+		limit = 10
+	}
+	offset := (page - 1) * limit
+
+	transfers, err := h.service.GetTransfers(userID, offset, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve transfers: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, transfers)
+}
+
+// DeleteTransferHandler handles deleting a transfer.
+func (h *AccountHandler) DeleteTransferHandler(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	transferIDUint64, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || transferIDUint64 == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid transfer ID format"})
+		return
+	}
+
+	if err := h.service.DeleteTransfer(userID, uint(transferIDUint64)); err != nil {
+		if strings.Contains(err.Error(), "transfer not found") {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Transfer not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete transfer: " + err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
@@ -24,18 +24,18 @@ func NewAIAdviceHandler(aiService *services.AIAdviceService, sumService *service
 
 // GetAdviceHandler fetches the latest monthly summary and then gets AI advice.
 func (h *AIAdviceHandler) GetAdviceHandler(c *gin.Context) {
-	// userID, err := GetUserIDFromContext(c) // UserID no longer needed for service call
-	// if err != nil {
-	// 	c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found: " + err.Error()})
-	// 	return
-	// }
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
 
 	// Fetch the latest monthly summary (e.g., for the current month)
 	targetDate := time.Now()
 	viewType := "overall" // AI advice should be based on the overall summary
-	summary, err := h.summaryService.GetOrCreateFinancialSummary("monthly", targetDate, viewType)
+	summary, err := h.summaryService.GetOrCreateFinancialSummary(userID, "monthly", targetDate, viewType)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get financial summary: " + err.Error()})
+		c.JSON(conversionErrorStatus(err), gin.H{"error": "Failed to get financial summary: " + err.Error()})
 		return
 	}
 
@@ -24,17 +24,31 @@ func NewAnalyticsHandler(service *services.AnalyticsService) *AnalyticsHandler {
 // @Description Retrieves total expenses for each category for the current calendar month.
 // @Tags analytics
 // @Produce json
+// @Param rollup query bool false "Count subcategories towards their top-level category"
 // @Success 200 {array} models.CategoryExpenseStat
+// @Failure 401 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /analytics/expense-categories [get]
 func (h *AnalyticsHandler) GetExpenseBreakdownHandler(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
 	// For now, use the current date to determine the target month.
 	// This could be extended to accept a date query parameter.
 	targetDate := time.Now()
 
-	stats, err := h.analyticsService.GetExpenseBreakdownByCategory(targetDate)
+	rollup, err := strconv.ParseBool(c.DefaultQuery("rollup", "false"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid rollup value. Use true or false."})
+		return
+	}
+
+	stats, err := h.analyticsService.GetExpenseBreakdownByCategory(userID, targetDate, rollup)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get expense breakdown: " + err.Error()})
+		c.JSON(conversionErrorStatus(err), gin.H{"error": "Failed to get expense breakdown: " + err.Error()})
 		return
 	}
 
@@ -57,6 +71,12 @@ func (h *AnalyticsHandler) GetExpenseBreakdownHandler(c *gin.Context) {
 // @Failure 500 {object} ErrorResponse
 // @Router /analytics/income-expense-trend [get]
 func (h *AnalyticsHandler) GetIncomeExpenseTrendHandler(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
 	numMonthsStr := c.DefaultQuery("months", "6") // Default to 6 months
 	numMonths, err := strconv.Atoi(numMonthsStr)
 	if err != nil || numMonths <= 0 {
@@ -64,9 +84,9 @@ func (h *AnalyticsHandler) GetIncomeExpenseTrendHandler(c *gin.Context) {
 		return
 	}
 
-	trend, err := h.analyticsService.GetIncomeExpenseTrend(numMonths)
+	trend, err := h.analyticsService.GetIncomeExpenseTrend(userID, numMonths)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get income-expense trend: " + err.Error()})
+		c.JSON(conversionErrorStatus(err), gin.H{"error": "Failed to get income-expense trend: " + err.Error()})
 		return
 	}
 
@@ -78,6 +98,41 @@ func (h *AnalyticsHandler) GetIncomeExpenseTrendHandler(c *gin.Context) {
 	c.JSON(http.StatusOK, trend)
 }
 
+// GetTagBreakdownHandler handles requests for income and expenses per tag.
+// @Summary Get income and expenses per tag for a month
+// @Description Retrieves total income and expenses carrying each tag in a calendar month. A record with several tags counts towards each.
+// @Tags analytics
+// @Produce json
+// @Param month query string false "Month as YYYY-MM (default: the current month)"
+// @Success 200 {array} models.TagStat
+// @Failure 400 {object} ErrorResponse "Invalid month"
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /analytics/tags [get]
+func (h *AnalyticsHandler) GetTagBreakdownHandler(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	targetDate := time.Now()
+	if month := c.Query("month"); month != "" {
+		targetDate, err = time.Parse("2006-01", month)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid month format. Use YYYY-MM."})
+			return
+		}
+	}
+
+	stats, err := h.analyticsService.GetTagBreakdown(userID, targetDate)
+	if err != nil {
+		c.JSON(conversionErrorStatus(err), gin.H{"error": "Failed to get tag breakdown: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, stats)
+}
+
 // ErrorResponse is a generic structure for error responses.
 // type ErrorResponse struct {
 //	 Error string `json:"error"`
@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zayyadi/finance-tracker/internal/services"
+)
+
+// AttachmentHandler handles HTTP requests for files attached to incomes, expenses and debts.
+type AttachmentHandler struct {
+	service *services.AttachmentService
+}
+
+// NewAttachmentHandler creates a new AttachmentHandler with the given service.
+func NewAttachmentHandler(service *services.AttachmentService) *AttachmentHandler {
+	return &AttachmentHandler{service: service}
+}
+
+// attachmentErrorStatus maps an attachment service error to an HTTP status code.
+func attachmentErrorStatus(err error) int {
+	switch {
+	case strings.Contains(err.Error(), "not found"):
+		return http.StatusNotFound
+	case strings.Contains(err.Error(), "too large"):
+		return http.StatusRequestEntityTooLarge
+	case strings.Contains(err.Error(), "invalid attachment"):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// UploadExpenseAttachmentHandler handles attaching a file to an expense.
+func (h *AttachmentHandler) UploadExpenseAttachmentHandler(c *gin.Context) {
+	h.upload(c, "expense")
+}
+
+// UploadIncomeAttachmentHandler handles attaching a file to an income.
+func (h *AttachmentHandler) UploadIncomeAttachmentHandler(c *gin.Context) {
+	h.upload(c, "income")
+}
+
+// UploadDebtAttachmentHandler handles attaching a file to a debt.
+func (h *AttachmentHandler) UploadDebtAttachmentHandler(c *gin.Context) {
+	h.upload(c, "debt")
+}
+
+// ListExpenseAttachmentsHandler handles listing the files attached to an expense.
+func (h *AttachmentHandler) ListExpenseAttachmentsHandler(c *gin.Context) {
+	h.list(c, "expense")
+}
+
+// ListIncomeAttachmentsHandler handles listing the files attached to an income.
+func (h *AttachmentHandler) ListIncomeAttachmentsHandler(c *gin.Context) {
+	h.list(c, "income")
+}
+
+// ListDebtAttachmentsHandler handles listing the files attached to a debt.
+func (h *AttachmentHandler) ListDebtAttachmentsHandler(c *gin.Context) {
+	h.list(c, "debt")
+}
+
+// upload attaches the "file" field of a multipart form to the record of recordType in the "id" path
+// parameter.
+func (h *AttachmentHandler) upload(c *gin.Context, recordType string) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	recordIDUint64, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || recordIDUint64 == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + recordType + " ID format"})
+		return
+	}
+
+	// Leave room for the multipart framing around the file.
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.service.MaxSize+1<<20)
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Attachment too large"})
+		} else {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "A file is required in the multipart field \"file\""})
+		}
+		return
+	}
+	if fileHeader.Size > h.service.MaxSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Attachment too large"})
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Could not read the uploaded file: " + err.Error()})
+		return
+	}
+	defer file.Close()
+
+	attachment, err := h.service.CreateAttachment(userID, recordType, uint(recordIDUint64), fileHeader.Filename, file)
+	if err != nil {
+		c.JSON(attachmentErrorStatus(err), gin.H{"error": "Failed to attach file: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, attachment)
+}
+
+// list returns the attachments of the record of recordType in the "id" path parameter.
+func (h *AttachmentHandler) list(c *gin.Context, recordType string) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	recordIDUint64, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || recordIDUint64 == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + recordType + " ID format"})
+		return
+	}
+
+	attachments, err := h.service.GetAttachments(userID, recordType, uint(recordIDUint64))
+	if err != nil {
+		c.JSON(attachmentErrorStatus(err), gin.H{"error": "Failed to retrieve attachments: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, attachments)
+}
+
+// GetAttachmentHandler handles fetching the details of a single attachment.
+func (h *AttachmentHandler) GetAttachmentHandler(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	attachmentIDUint64, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || attachmentIDUint64 == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid attachment ID format"})
+		return
+	}
+
+	attachment, err := h.service.GetAttachmentByID(userID, uint(attachmentIDUint64))
+	if err != nil {
+		c.JSON(attachmentErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, attachment)
+}
+
+// DownloadAttachmentHandler handles downloading the file of an attachment.
+func (h *AttachmentHandler) DownloadAttachmentHandler(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	attachmentIDUint64, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || attachmentIDUint64 == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid attachment ID format"})
+		return
+	}
+
+	attachment, file, err := h.service.OpenAttachment(userID, uint(attachmentIDUint64))
+	if err != nil {
+		c.JSON(attachmentErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	defer file.Close()
+
+	c.DataFromReader(http.StatusOK, attachment.Size, attachment.ContentType, file, map[string]string{
+		"Content-Disposition":    mime.FormatMediaType("attachment", map[string]string{"filename": attachment.FileName}),
+		"X-Content-Type-Options": "nosniff",
+	})
+}
+
+// DeleteAttachmentHandler handles deleting an attachment and its file.
+func (h *AttachmentHandler) DeleteAttachmentHandler(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	attachmentIDUint64, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || attachmentIDUint64 == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid attachment ID format"})
+		return
+	}
+
+	if err := h.service.DeleteAttachment(userID, uint(attachmentIDUint64)); err != nil {
+		c.JSON(attachmentErrorStatus(err), gin.H{"error": "Failed to delete attachment: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zayyadi/finance-tracker/internal/models"
+	"github.com/zayyadi/finance-tracker/internal/services"
+)
+
+// AuditHandler handles HTTP requests for the audit log of changes to records.
+type AuditHandler struct {
+	service *services.AuditService
+}
+
+// NewAuditHandler creates a new AuditHandler with the given service.
+func NewAuditHandler(service *services.AuditService) *AuditHandler {
+	return &AuditHandler{service: service}
+}
+
+// auditErrorStatus maps errors from the audit service to HTTP statuses.
+func auditErrorStatus(err error) int {
+	switch {
+	case strings.Contains(err.Error(), "invalid filter"), strings.Contains(err.Error(), "date format"):
+		return http.StatusBadRequest
+	case strings.Contains(err.Error(), "not found"):
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
+
+// IncomeHistoryHandler handles listing the changes to an income.
+func (h *AuditHandler) IncomeHistoryHandler(c *gin.Context) {
+	h.history(c, "income")
+}
+
+// ExpenseHistoryHandler handles listing the changes to an expense.
+func (h *AuditHandler) ExpenseHistoryHandler(c *gin.Context) {
+	h.history(c, "expense")
+}
+
+// DebtHistoryHandler handles listing the changes to a debt.
+func (h *AuditHandler) DebtHistoryHandler(c *gin.Context) {
+	h.history(c, "debt")
+}
+
+// SavingsHistoryHandler handles listing the changes to a savings goal.
+func (h *AuditHandler) SavingsHistoryHandler(c *gin.Context) {
+	h.history(c, "savings")
+}
+
+// history lists the changes to the record of recordType in the "id" path parameter, oldest first.
+func (h *AuditHandler) history(c *gin.Context, recordType string) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	recordIDUint64, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || recordIDUint64 == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + recordType + " ID format"})
+		return
+	}
+
+	entries, err := h.service.GetRecordHistory(userID, recordType, uint(recordIDUint64))
+	if err != nil {
+		status := auditErrorStatus(err)
+		if status == http.StatusInternalServerError {
+			c.JSON(status, gin.H{"error": "Failed to retrieve history: " + err.Error()})
+		} else {
+			c.JSON(status, gin.H{"error": err.Error()})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, entries)
+}
+
+// ListAuditLogHandler handles listing the changes to the user's records, newest first, with pagination.
+// "type", "record_id", "action", "source", "startDate" and "endDate" optionally narrow the list.
+func (h *AuditHandler) ListAuditLogHandler(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	pageStr := c.DefaultQuery("page", "1")
+	limitStr := c.DefaultQuery("limit", "10")
+
+	page, err := strconv.Atoi(pageStr)
+	if err != nil || page < 1 {
+		page = 1
+	}
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit < 1 {
+		limit = 10
+	}
+
+	filter := models.AuditFilter{
+		Type:      c.Query("type"),
+		Action:    c.Query("action"),
+		Source:    c.Query("source"),
+		StartDate: c.Query("startDate"),
+		EndDate:   c.Query("endDate"),
+	}
+	if recordIDStr := c.Query("record_id"); recordIDStr != "" {
+		recordIDUint64, err := strconv.ParseUint(recordIDStr, 10, 32)
+		if err != nil || recordIDUint64 == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid record ID format"})
+			return
+		}
+		filter.RecordID = uint(recordIDUint64)
+	}
+
+	entries, err := h.service.GetAuditLog(userID, filter, page, limit)
+	if err != nil {
+		status := auditErrorStatus(err)
+		if status == http.StatusInternalServerError {
+			c.JSON(status, gin.H{"error": "Failed to retrieve audit log: " + err.Error()})
+		} else {
+			c.JSON(status, gin.H{"error": err.Error()})
+		}
+		return
+	}
+	writePage(c, entries)
+}
@@ -0,0 +1,218 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zayyadi/finance-tracker/internal/models"
+	"github.com/zayyadi/finance-tracker/internal/services"
+)
+
+// BudgetHandler handles HTTP requests for category budgets.
+type BudgetHandler struct {
+	service *services.BudgetService
+}
+
+// NewBudgetHandler creates a new BudgetHandler with the given service.
+func NewBudgetHandler(service *services.BudgetService) *BudgetHandler {
+	return &BudgetHandler{service: service}
+}
+
+// CreateBudgetHandler handles the creation of a new budget.
+func (h *BudgetHandler) CreateBudgetHandler(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req models.BudgetCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	budget := models.Budget{
+		UserID:   userID,
+		Category: req.Category,
+		Period:   req.Period,
+		Amount:   req.Amount,
+		Currency: req.Currency,
+	}
+	if err := h.service.CreateBudget(&budget); err != nil {
+		if strings.Contains(err.Error(), "already exists") {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create budget: " + err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, budget)
+}
+
+// GetBudgetHandler handles fetching a single budget.
+func (h *BudgetHandler) GetBudgetHandler(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	budgetIDUint64, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || budgetIDUint64 == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid budget ID format"})
+		return
+	}
+
+	budget, err := h.service.GetBudgetByID(userID, uint(budgetIDUint64))
+	if err != nil {
+		if strings.Contains(err.Error(), "budget not found") {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve budget: " + err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, budget)
+}
+
+// ListBudgetsHandler handles fetching the user's budgets with pagination.
+// An optional "period" query parameter limits the list to weekly, monthly or yearly budgets.
+func (h *BudgetHandler) ListBudgetsHandler(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit < 1 {
+		limit = 10
+	}
+	offset := (page - 1) * limit
+
+	period := c.Query("period")
+	if period != "" && !validBudgetPeriod(period) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid period. Use weekly, monthly or yearly."})
+		return
+	}
+
+	budgets, err := h.service.GetBudgets(userID, period, offset, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve budgets: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, budgets)
+}
+
+// UpdateBudgetHandler handles updating an existing budget.
+func (h *BudgetHandler) UpdateBudgetHandler(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	budgetIDUint64, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || budgetIDUint64 == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid budget ID format"})
+		return
+	}
+
+	var req models.BudgetUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+	if req.Amount == nil && req.Currency == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "At least one field must be provided for update"})
+		return
+	}
+
+	budget, err := h.service.UpdateBudget(userID, uint(budgetIDUint64), &req)
+	if err != nil {
+		if strings.Contains(err.Error(), "budget not found") {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update budget: " + err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, budget)
+}
+
+// DeleteBudgetHandler handles deleting a budget.
+func (h *BudgetHandler) DeleteBudgetHandler(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	budgetIDUint64, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || budgetIDUint64 == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid budget ID format"})
+		return
+	}
+
+	if err := h.service.DeleteBudget(userID, uint(budgetIDUint64)); err != nil {
+		if strings.Contains(err.Error(), "budget not found") {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Budget not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete budget: " + err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// GetBudgetStatusHandler handles comparing budgets with actual spending.
+// Optional query parameters: "period" (weekly, monthly or yearly; default monthly) and "date"
+// (YYYY-MM-DD; default today), which selects the period and the day spending is projected from.
+func (h *BudgetHandler) GetBudgetStatusHandler(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	period := c.DefaultQuery("period", "monthly")
+	if !validBudgetPeriod(period) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid period. Use weekly, monthly or yearly."})
+		return
+	}
+
+	asOf := time.Now().UTC()
+	if dateStr := c.Query("date"); dateStr != "" {
+		asOf, err = time.Parse("2006-01-02", dateStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format. Use YYYY-MM-DD."})
+			return
+		}
+	}
+
+	status, err := h.service.GetBudgetStatus(userID, period, asOf)
+	if err != nil {
+		c.JSON(conversionErrorStatus(err), gin.H{"error": "Failed to get budget status: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+// validBudgetPeriod reports whether period is one budgets can be set for.
+func validBudgetPeriod(period string) bool {
+	return period == "weekly" || period == "monthly" || period == "yearly"
+}
@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zayyadi/finance-tracker/internal/models"
+	"github.com/zayyadi/finance-tracker/internal/services"
+)
+
+// CategoryHandler handles HTTP requests for income and expense categories.
+type CategoryHandler struct {
+	service *services.CategoryService
+}
+
+// NewCategoryHandler creates a new CategoryHandler with the given service.
+func NewCategoryHandler(service *services.CategoryService) *CategoryHandler {
+	return &CategoryHandler{service: service}
+}
+
+// categoryErrorStatus maps a category service error to an HTTP status code.
+func categoryErrorStatus(err error) int {
+	switch {
+	case strings.Contains(err.Error(), "category not found"):
+		return http.StatusNotFound
+	case strings.Contains(err.Error(), "invalid category"):
+		return http.StatusBadRequest
+	case strings.Contains(err.Error(), "already exists"), strings.Contains(err.Error(), "still"):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// CreateCategoryHandler handles the creation of a new category.
+func (h *CategoryHandler) CreateCategoryHandler(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req models.CategoryCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	category, err := h.service.CreateCategory(userID, &req)
+	if err != nil {
+		c.JSON(categoryErrorStatus(err), gin.H{"error": "Failed to create category: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, category)
+}
+
+// GetCategoryHandler handles fetching a single category.
+func (h *CategoryHandler) GetCategoryHandler(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	categoryIDUint64, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || categoryIDUint64 == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category ID format"})
+		return
+	}
+
+	category, err := h.service.GetCategoryByID(userID, uint(categoryIDUint64))
+	if err != nil {
+		c.JSON(categoryErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, category)
+}
+
+// ListCategoriesHandler handles fetching the user's categories with pagination. "kind" limits the list
+// to income or expense categories.
+func (h *CategoryHandler) ListCategoriesHandler(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	kind := c.Query("kind")
+	if kind != "" && kind != "income" && kind != "expense" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid kind. Use income or expense."})
+		return
+	}
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit < 1 {
+		limit = 10
+	}
+	offset := (page - 1) * limit
+
+	categories, err := h.service.GetCategories(userID, kind, offset, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve categories: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, categories)
+}
+
+// GetCategoryTreeHandler handles fetching all of the user's categories nested under their parents.
+// "kind" limits the tree to income or expense categories.
+func (h *CategoryHandler) GetCategoryTreeHandler(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	kind := c.Query("kind")
+	if kind != "" && kind != "income" && kind != "expense" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid kind. Use income or expense."})
+		return
+	}
+
+	tree, err := h.service.GetCategoryTree(userID, kind)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve categories: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, tree)
+}
+
+// UpdateCategoryHandler handles renaming, moving or restyling a category.
+func (h *CategoryHandler) UpdateCategoryHandler(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	categoryIDUint64, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || categoryIDUint64 == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category ID format"})
+		return
+	}
+
+	var req models.CategoryUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+	if req.Name == nil && req.ParentID == nil && req.Color == nil && req.Icon == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "At least one field must be provided for update"})
+		return
+	}
+
+	category, err := h.service.UpdateCategory(userID, uint(categoryIDUint64), &req)
+	if err != nil {
+		c.JSON(categoryErrorStatus(err), gin.H{"error": "Failed to update category: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, category)
+}
+
+// DeleteCategoryHandler handles deleting a category that is no longer used.
+func (h *CategoryHandler) DeleteCategoryHandler(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	categoryIDUint64, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || categoryIDUint64 == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category ID format"})
+		return
+	}
+
+	if err := h.service.DeleteCategory(userID, uint(categoryIDUint64)); err != nil {
+		c.JSON(categoryErrorStatus(err), gin.H{"error": "Failed to delete category: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
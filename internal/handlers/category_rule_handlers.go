@@ -0,0 +1,222 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zayyadi/finance-tracker/internal/models"
+	"github.com/zayyadi/finance-tracker/internal/services"
+)
+
+// CategoryRuleHandler handles HTTP requests for auto-categorization rules.
+type CategoryRuleHandler struct {
+	service *services.CategoryRuleService
+}
+
+// NewCategoryRuleHandler creates a new CategoryRuleHandler with the given service.
+func NewCategoryRuleHandler(service *services.CategoryRuleService) *CategoryRuleHandler {
+	return &CategoryRuleHandler{service: service}
+}
+
+// CreateCategoryRuleHandler handles the creation of a new rule.
+func (h *CategoryRuleHandler) CreateCategoryRuleHandler(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req models.CategoryRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	rule, err := h.service.CreateCategoryRule(userID, &req)
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid category rule") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create category rule: " + err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, rule)
+}
+
+// GetCategoryRuleHandler handles fetching a single rule.
+func (h *CategoryRuleHandler) GetCategoryRuleHandler(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	ruleIDUint64, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || ruleIDUint64 == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category rule ID format"})
+		return
+	}
+
+	rule, err := h.service.GetCategoryRuleByID(userID, uint(ruleIDUint64))
+	if err != nil {
+		if strings.Contains(err.Error(), "category rule not found") {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve category rule: " + err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+// ListCategoryRulesHandler handles fetching the user's rules, in the order they are tried, with pagination.
+func (h *CategoryRuleHandler) ListCategoryRulesHandler(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit < 1 {
+		limit = 10
+	}
+	offset := (page - 1) * limit
+
+	rules, err := h.service.GetCategoryRules(userID, offset, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve category rules: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, rules)
+}
+
+// UpdateCategoryRuleHandler handles replacing an existing rule.
+func (h *CategoryRuleHandler) UpdateCategoryRuleHandler(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	ruleIDUint64, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || ruleIDUint64 == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category rule ID format"})
+		return
+	}
+
+	var req models.CategoryRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	rule, err := h.service.UpdateCategoryRule(userID, uint(ruleIDUint64), &req)
+	if err != nil {
+		if strings.Contains(err.Error(), "category rule not found") {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		} else if strings.Contains(err.Error(), "invalid category rule") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update category rule: " + err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+// DeleteCategoryRuleHandler handles deleting a rule.
+func (h *CategoryRuleHandler) DeleteCategoryRuleHandler(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	ruleIDUint64, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || ruleIDUint64 == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category rule ID format"})
+		return
+	}
+
+	if err := h.service.DeleteCategoryRule(userID, uint(ruleIDUint64)); err != nil {
+		if strings.Contains(err.Error(), "category rule not found") {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete category rule: " + err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// TestCategoryRuleHandler handles running a rule from the request body against the user's existing
+// incomes and expenses, without saving the rule or changing anything. "limit" caps the listed matches.
+func (h *CategoryRuleHandler) TestCategoryRuleHandler(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit < 1 {
+		limit = 10
+	}
+
+	var req models.CategoryRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	result, err := h.service.TestCategoryRule(userID, &req, limit)
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid category rule") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to test category rule: " + err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// ApplyCategoryRulesHandler handles re-applying the user's rules to their existing incomes and expenses.
+// The optional body can set "overwrite" to include entries categorized by hand and "dry_run" to only count.
+func (h *CategoryRuleHandler) ApplyCategoryRulesHandler(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req models.CategoryRuleApplyRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	result, err := h.service.ApplyCategoryRules(userID, req.Overwrite, req.DryRun)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to apply category rules: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zayyadi/finance-tracker/internal/models"
+	"github.com/zayyadi/finance-tracker/internal/services"
+)
+
+// CurrencyHandler handles HTTP requests for the base currency setting and exchange rates.
+type CurrencyHandler struct {
+	service *services.CurrencyService
+}
+
+// NewCurrencyHandler creates a new CurrencyHandler with the given service.
+func NewCurrencyHandler(service *services.CurrencyService) *CurrencyHandler {
+	return &CurrencyHandler{service: service}
+}
+
+// UpdateBaseCurrencyHandler changes the authenticated user's reporting currency.
+func (h *CurrencyHandler) UpdateBaseCurrencyHandler(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req models.BaseCurrencyUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	if err := h.service.SetBaseCurrency(userID, req.BaseCurrency); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update base currency: " + err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"base_currency": req.BaseCurrency})
+}
+
+// CreateExchangeRateHandler adds a rate, replacing any existing rate for the same pair and date.
+func (h *CurrencyHandler) CreateExchangeRateHandler(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req models.ExchangeRateCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	rate := models.ExchangeRate{
+		UserID:       userID,
+		FromCurrency: req.FromCurrency,
+		ToCurrency:   req.ToCurrency,
+		Rate:         req.Rate,
+		Date:         req.Date,
+	}
+	if err := h.service.UpsertExchangeRate(&rate); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save exchange rate: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, rate)
+}
+
+// ListExchangeRatesHandler lists the user's exchange rates with pagination, optionally filtered by
+// the "from" and "to" currency query parameters.
+func (h *CurrencyHandler) ListExchangeRatesHandler(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	pageStr := c.DefaultQuery("page", "1")
+	limitStr := c.DefaultQuery("limit", "10")
+
+	page, err := strconv.Atoi(pageStr)
+	if err != nil || page < 1 {
+		page = 1
+	}
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit < 1 {
+		limit = 10
+	}
+	offset := (page - 1) * limit
+
+	from := strings.ToUpper(c.Query("from"))
+	to := strings.ToUpper(c.Query("to"))
+
+	rates, err := h.service.GetExchangeRates(userID, offset, limit, from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve exchange rates: " + err.Error()})
+		return
+	}
+
+	if rates == nil {
+		rates = []models.ExchangeRate{}
+	}
+	c.JSON(http.StatusOK, rates)
+}
+
+// ImportExchangeRatesHandler loads exchange rates from a CSV file, sent either as the "file" field
+// of a multipart form or as a raw text/csv request body.
+func (h *CurrencyHandler) ImportExchangeRatesHandler(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	var body io.Reader = c.Request.Body
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "A CSV file is required in the 'file' form field"})
+			return
+		}
+		file, err := fileHeader.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Could not read uploaded file: " + err.Error()})
+			return
+		}
+		defer file.Close()
+		body = file
+	}
+
+	imported, err := h.service.ImportExchangeRatesCSV(userID, body)
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid exchange rate CSV") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import exchange rates: " + err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ExchangeRateImportResponse{Imported: imported})
+}
@@ -22,11 +22,11 @@ func NewDebtHandler(service *services.DebtService) *DebtHandler {
 
 // CreateDebtHandler handles the creation of a new debt record.
 func (h *DebtHandler) CreateDebtHandler(c *gin.Context) {
-	// _, err := GetUserIDFromContext(c) // UserID no longer needed
-	// if err != nil {
-	// 	c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
-	// 	return
-	// }
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
 
 	var req models.DebtCreateRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -34,39 +34,28 @@ func (h *DebtHandler) CreateDebtHandler(c *gin.Context) {
 		return
 	}
 
-	description := ""
-	if req.Description != nil {
-		description = *req.Description
-	}
-	status := "Pending" // Default if not provided or if GORM default doesn't kick in via struct
-	if req.Status != nil && *req.Status != "" {
-		status = *req.Status
-	}
-
-	debt := models.Debt{
-		// UserID:      userID, // UserID removed
-		DebtorName:  req.DebtorName,
-		Description: description,
-		Amount:      req.Amount,
-		DueDate:     req.DueDate,
-		Status:      status,
-	}
+	debt := debtFromRequest(userID, req)
 
 	if err := h.service.CreateDebt(&debt); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create debt record: " + err.Error()})
+		if strings.Contains(err.Error(), "invalid tag") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create debt record: " + err.Error()})
+		}
 		return
 	}
 
+	c.Header("ETag", recordETag(debt.UpdatedAt))
 	c.JSON(http.StatusCreated, debt)
 }
 
 // GetDebtHandler handles fetching a single debt record.
 func (h *DebtHandler) GetDebtHandler(c *gin.Context) {
-	// _, err := GetUserIDFromContext(c) // UserID no longer needed
-	// if err != nil {
-	// 	c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
-	// 	return
-	// }
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
 
 	debtIDStr := c.Param("id")
 	debtIDUint64, err := strconv.ParseUint(debtIDStr, 10, 32)
@@ -80,9 +69,9 @@ func (h *DebtHandler) GetDebtHandler(c *gin.Context) {
 		return
 	}
 
-	debt, err := h.service.GetDebtByID(debtID) // UserID removed
+	debt, err := h.service.GetDebtByID(userID, debtID)
 	if err != nil {
-		if strings.Contains(err.Error(), "debt record not found") { // Updated error check
+		if strings.Contains(err.Error(), "debt record not found") {
 			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		} else {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve debt record: " + err.Error()})
@@ -90,16 +79,18 @@ func (h *DebtHandler) GetDebtHandler(c *gin.Context) {
 		return
 	}
 
+	c.Header("ETag", recordETag(debt.UpdatedAt))
 	c.JSON(http.StatusOK, debt)
 }
 
-// ListDebtsHandler handles fetching all debt records with pagination and optional status filter.
+// ListDebtsHandler handles fetching debt records with pagination, optionally with one status, filtered
+// and sorted by the query parameters read by parseListFilter.
 func (h *DebtHandler) ListDebtsHandler(c *gin.Context) {
-	// _, err := GetUserIDFromContext(c) // UserID no longer needed
-	// if err != nil {
-	// 	c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
-	// 	return
-	// }
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
 
 	pageStr := c.DefaultQuery("page", "1")
 	limitStr := c.DefaultQuery("limit", "10")
@@ -113,28 +104,36 @@ func (h *DebtHandler) ListDebtsHandler(c *gin.Context) {
 	if err != nil || limit < 1 {
 		limit = 10
 	}
-	offset := (page - 1) * limit
 
 	if statusFilter != "" && !(statusFilter == "Pending" || statusFilter == "Paid" || statusFilter == "Overdue") {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status filter. Allowed values: Pending, Paid, Overdue"})
 		return
 	}
+	filter, err := parseListFilter(c, "status")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
-	debts, err := h.service.GetDebts(offset, limit, statusFilter) // Changed from GetDebtsByUser
+	debts, err := h.service.GetDebts(userID, page, limit, statusFilter, filter)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve debt records: " + err.Error()})
+		if isListFilterError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve debt records: " + err.Error()})
+		}
 		return
 	}
-	c.JSON(http.StatusOK, debts)
+	writePage(c, debts)
 }
 
 // UpdateDebtHandler handles updating an existing debt record.
 func (h *DebtHandler) UpdateDebtHandler(c *gin.Context) {
-	// _, err := GetUserIDFromContext(c) // UserID no longer needed
-	// if err != nil {
-	// 	c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
-	// 	return
-	// }
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
 
 	debtIDStr := c.Param("id")
 	debtIDUint64, err := strconv.ParseUint(debtIDStr, 10, 32)
@@ -154,31 +153,37 @@ func (h *DebtHandler) UpdateDebtHandler(c *gin.Context) {
 		return
 	}
 	if req.DebtorName == nil && req.Description == nil && req.Amount == nil &&
-		req.DueDate == nil && req.Status == nil {
+		req.Currency == nil && req.DueDate == nil && req.Status == nil && req.Tags == nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "At least one field must be provided for update"})
 		return
 	}
 
-	updatedDebt, err := h.service.UpdateDebt(debtID, &req) // UserID removed
+	updatedDebt, err := h.service.UpdateDebt(userID, debtID, &req, ifMatchVersions(c)...)
 	if err != nil {
-		if strings.Contains(err.Error(), "debt record not found") { // Updated error check
+		if writeVersionConflict(c, err) {
+			return
+		}
+		if strings.Contains(err.Error(), "debt record not found") {
 			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		} else if strings.Contains(err.Error(), "invalid tag") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		} else {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update debt record: " + err.Error()})
 		}
 		return
 	}
 
+	c.Header("ETag", recordETag(updatedDebt.UpdatedAt))
 	c.JSON(http.StatusOK, updatedDebt)
 }
 
 // DeleteDebtHandler handles deleting a debt record.
 func (h *DebtHandler) DeleteDebtHandler(c *gin.Context) {
-	// _, err := GetUserIDFromContext(c) // UserID no longer needed
-	// if err != nil {
-	// 	c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
-	// 	return
-	// }
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
 
 	debtIDStr := c.Param("id")
 	debtIDUint64, err := strconv.ParseUint(debtIDStr, 10, 32)
@@ -192,8 +197,11 @@ func (h *DebtHandler) DeleteDebtHandler(c *gin.Context) {
 		return
 	}
 
-	err = h.service.DeleteDebt(debtID) // UserID removed
+	err = h.service.DeleteDebt(userID, debtID, ifMatchVersions(c)...)
 	if err != nil {
+		if writeVersionConflict(c, err) {
+			return
+		}
 		if strings.Contains(err.Error(), "debt record not found") {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Debt record not found"})
 		} else {
@@ -204,3 +212,99 @@ func (h *DebtHandler) DeleteDebtHandler(c *gin.Context) {
 
 	c.JSON(http.StatusNoContent, nil)
 }
+
+// debtFromRequest returns the debt described by a create request.
+func debtFromRequest(userID uint, req models.DebtCreateRequest) models.Debt {
+	description := ""
+	if req.Description != nil {
+		description = *req.Description
+	}
+	status := "Pending" // Default if not provided or if GORM default doesn't kick in via struct
+	if req.Status != nil && *req.Status != "" {
+		status = *req.Status
+	}
+
+	return models.Debt{
+		UserID:      userID,
+		DebtorName:  req.DebtorName,
+		Description: description,
+		Amount:      req.Amount,
+		Currency:    req.Currency,
+		DueDate:     req.DueDate,
+		Status:      status,
+		Tags:        req.Tags,
+	}
+}
+
+// BulkCreateDebtsHandler handles creating several debt records at once. They are created together or
+// not at all.
+func (h *DebtHandler) BulkCreateDebtsHandler(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req models.BulkRequest[models.DebtCreateRequest]
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+	debts := make([]models.Debt, len(req.Items))
+	for i, item := range req.Items {
+		debts[i] = debtFromRequest(userID, item)
+	}
+
+	result, err := h.service.BulkCreateDebts(debts)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create debt records: " + err.Error()})
+		return
+	}
+	writeBulkResult(c, result, http.StatusCreated)
+}
+
+// BulkUpdateDebtsHandler handles updating several debt records at once. They are updated together or
+// not at all.
+func (h *DebtHandler) BulkUpdateDebtsHandler(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req models.BulkRequest[models.DebtBulkUpdateItem]
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	result, err := h.service.BulkUpdateDebts(userID, req.Items)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update debt records: " + err.Error()})
+		return
+	}
+	writeBulkResult(c, result, http.StatusOK)
+}
+
+// BulkDeleteDebtsHandler handles deleting several debt records at once. They are deleted together or
+// not at all.
+func (h *DebtHandler) BulkDeleteDebtsHandler(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req models.BulkDeleteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	result, err := h.service.BulkDeleteDebts(userID, req.IDs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete debt records: " + err.Error()})
+		return
+	}
+	writeBulkResult(c, result, http.StatusOK)
+}
@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zayyadi/finance-tracker/internal/models"
+	"github.com/zayyadi/finance-tracker/internal/services"
+)
+
+// DuplicateHandler handles HTTP requests for the duplicate transaction review queue.
+type DuplicateHandler struct {
+	service *services.DuplicateService
+}
+
+// NewDuplicateHandler creates a new DuplicateHandler with the given service.
+func NewDuplicateHandler(service *services.DuplicateService) *DuplicateHandler {
+	return &DuplicateHandler{service: service}
+}
+
+// ListDuplicatesHandler handles fetching duplicate pairs with pagination. The "status" query parameter
+// selects pending (the default), merged or dismissed pairs.
+func (h *DuplicateHandler) ListDuplicatesHandler(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit < 1 {
+		limit = 10
+	}
+	offset := (page - 1) * limit
+
+	status := c.DefaultQuery("status", "pending")
+	if status != "pending" && status != "merged" && status != "dismissed" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status. Use pending, merged or dismissed."})
+		return
+	}
+
+	pairs, err := h.service.GetDuplicatePairs(userID, status, offset, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve duplicates: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, pairs)
+}
+
+// GetDuplicateHandler handles fetching a single duplicate pair.
+func (h *DuplicateHandler) GetDuplicateHandler(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	pairIDUint64, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || pairIDUint64 == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid duplicate pair ID format"})
+		return
+	}
+
+	pair, err := h.service.GetDuplicatePairByID(userID, uint(pairIDUint64))
+	if err != nil {
+		c.JSON(duplicateErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, pair)
+}
+
+// MergeDuplicateHandler handles merging a duplicate pair: one entry is deleted and the other kept.
+// The optional body's "keep_id" chooses the entry to keep; by default it is the earlier one.
+func (h *DuplicateHandler) MergeDuplicateHandler(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	pairIDUint64, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || pairIDUint64 == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid duplicate pair ID format"})
+		return
+	}
+
+	var req models.DuplicateMergeRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	pair, err := h.service.MergeDuplicatePair(userID, uint(pairIDUint64), req.KeepID)
+	if err != nil {
+		c.JSON(duplicateErrorStatus(err), gin.H{"error": "Failed to merge duplicates: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, pair)
+}
+
+// DismissDuplicateHandler handles marking a duplicate pair as two separate transactions.
+func (h *DuplicateHandler) DismissDuplicateHandler(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	pairIDUint64, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || pairIDUint64 == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid duplicate pair ID format"})
+		return
+	}
+
+	pair, err := h.service.DismissDuplicatePair(userID, uint(pairIDUint64))
+	if err != nil {
+		c.JSON(duplicateErrorStatus(err), gin.H{"error": "Failed to dismiss duplicates: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, pair)
+}
+
+// ScanDuplicatesHandler handles looking for duplicates in the user's existing history.
+func (h *DuplicateHandler) ScanDuplicatesHandler(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	flagged, err := h.service.ScanDuplicates(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to scan for duplicates: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.DuplicateScanResult{Flagged: flagged})
+}
+
+// duplicateErrorStatus maps a duplicate review error to an HTTP status.
+func duplicateErrorStatus(err error) int {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "duplicate pair not found"):
+		return http.StatusNotFound
+	case strings.Contains(msg, "is already") || strings.Contains(msg, "no longer exists"):
+		return http.StatusConflict
+	case strings.Contains(msg, "keep_id must be"):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
@@ -5,8 +5,10 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/zayyadi/finance-tracker/internal/database"
 	"github.com/zayyadi/finance-tracker/internal/models"
 	"github.com/zayyadi/finance-tracker/internal/services"
 )
@@ -27,39 +29,51 @@ func NewExpenseHandler(service *services.ExpenseService, summaryService *service
 
 // CreateExpenseHandler handles the creation of a new expense record.
 func (h *ExpenseHandler) CreateExpenseHandler(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
 	var req models.ExpenseCreateRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
 		return
 	}
 
-	expense := models.Expense{
-		Amount:   req.Amount,
-		Category: req.Category,
-		Date:     req.Date,
-		Note:     req.Note,
-	}
+	expense := expenseFromRequest(userID, req)
 
 	if err := h.service.CreateExpense(&expense); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create expense record: " + err.Error()})
+		if strings.Contains(err.Error(), "account not found") || strings.Contains(err.Error(), "account currency") || strings.Contains(err.Error(), "invalid category") || strings.Contains(err.Error(), "invalid tag") || strings.Contains(err.Error(), "invalid splits") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create expense record: " + err.Error()})
+		}
 		return
 	}
 
 	// Invalidate summaries
 	if h.summaryService != nil {
 		go func(dateOfItem database.CustomDate) { // Use a goroutine for non-blocking invalidation
-			err := h.summaryService.InvalidateSummariesForDate(dateOfItem.Time, []string{"monthly", "weekly", "yearly"})
+			err := h.summaryService.InvalidateSummariesForDate(userID, dateOfItem.Time, []string{"monthly", "weekly", "yearly"})
 			if err != nil {
 				log.Printf("Error invalidating summaries after creating expense: %v", err)
 			}
 		}(expense.Date)
 	}
 
+	c.Header("ETag", recordETag(expense.UpdatedAt))
 	c.JSON(http.StatusCreated, expense)
 }
 
 // GetExpenseHandler handles fetching a single expense record.
 func (h *ExpenseHandler) GetExpenseHandler(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
 	expenseIDStr := c.Param("id")
 	expenseIDUint64, err := strconv.ParseUint(expenseIDStr, 10, 32)
 	if err != nil {
@@ -72,7 +86,7 @@ func (h *ExpenseHandler) GetExpenseHandler(c *gin.Context) {
 		return
 	}
 
-	expense, err := h.service.GetExpenseByID(expenseID)
+	expense, err := h.service.GetExpenseByID(userID, expenseID)
 	if err != nil {
 		if strings.Contains(err.Error(), "expense record not found") {
 			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
@@ -82,11 +96,19 @@ func (h *ExpenseHandler) GetExpenseHandler(c *gin.Context) {
 		return
 	}
 
+	c.Header("ETag", recordETag(expense.UpdatedAt))
 	c.JSON(http.StatusOK, expense)
 }
 
-// ListExpensesHandler handles fetching all expense records with pagination.
+// ListExpensesHandler handles fetching expense records with pagination, filtered and sorted by the
+// query parameters read by parseListFilter.
 func (h *ExpenseHandler) ListExpensesHandler(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
 	pageStr := c.DefaultQuery("page", "1")
 	limitStr := c.DefaultQuery("limit", "10")
 
@@ -98,26 +120,33 @@ func (h *ExpenseHandler) ListExpensesHandler(c *gin.Context) {
 	if err != nil || limit < 1 {
 		limit = 10
 	}
-	offset := (page - 1) * limit
 
-	startDateStr := c.Query("startDate")
-	endDateStr := c.Query("endDate")
+	filter, err := parseListFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
-	expenses, err := h.service.GetExpenses(offset, limit, startDateStr, endDateStr)
+	expenses, err := h.service.GetExpenses(userID, page, limit, filter)
 	if err != nil {
-		// Check if the error is due to date parsing to return a more specific client error
-		if strings.Contains(err.Error(), "invalid start date format") || strings.Contains(err.Error(), "invalid end date format") {
+		if isListFilterError(err) {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		} else {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve expense records: " + err.Error()})
 		}
 		return
 	}
-	c.JSON(http.StatusOK, expenses)
+	writePage(c, expenses)
 }
 
 // UpdateExpenseHandler handles updating an existing expense record.
 func (h *ExpenseHandler) UpdateExpenseHandler(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
 	expenseIDStr := c.Param("id")
 	log.Printf("[ExpenseHandler] UpdateExpenseHandler: Received raw ID string: '%s'", expenseIDStr)
 	expenseIDUint64, err := strconv.ParseUint(expenseIDStr, 10, 32)
@@ -136,15 +165,20 @@ func (h *ExpenseHandler) UpdateExpenseHandler(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
 		return
 	}
-	if req.Amount == nil && req.Category == nil && req.Date == nil && req.Note == nil {
+	if req.Amount == nil && req.Currency == nil && req.AccountID == nil && req.Category == nil && req.CategoryID == nil && req.Date == nil && req.Note == nil && req.Tags == nil && req.Splits == nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "At least one field must be provided for update"})
 		return
 	}
 
-	updatedExpense, err := h.service.UpdateExpense(expenseID, &req)
+	updatedExpense, err := h.service.UpdateExpense(userID, expenseID, &req, ifMatchVersions(c)...)
 	if err != nil {
+		if writeVersionConflict(c, err) {
+			return
+		}
 		if strings.Contains(err.Error(), "expense record not found") {
 			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		} else if strings.Contains(err.Error(), "account not found") || strings.Contains(err.Error(), "account currency") || strings.Contains(err.Error(), "invalid category") || strings.Contains(err.Error(), "invalid tag") || strings.Contains(err.Error(), "invalid splits") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		} else {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update expense record: " + err.Error()})
 		}
@@ -154,7 +188,7 @@ func (h *ExpenseHandler) UpdateExpenseHandler(c *gin.Context) {
 	// Invalidate summaries
 	if h.summaryService != nil && updatedExpense.Date.Time != (time.Time{}) { // Ensure Date is valid
 		go func(dateOfItem database.CustomDate) {
-			err := h.summaryService.InvalidateSummariesForDate(dateOfItem.Time, []string{"monthly", "weekly", "yearly"})
+			err := h.summaryService.InvalidateSummariesForDate(userID, dateOfItem.Time, []string{"monthly", "weekly", "yearly"})
 			if err != nil {
 				log.Printf("Error invalidating summaries after updating expense: %v", err)
 			}
@@ -164,11 +198,18 @@ func (h *ExpenseHandler) UpdateExpenseHandler(c *gin.Context) {
 		// For simplicity, current implementation only invalidates for the new date.
 	}
 
+	c.Header("ETag", recordETag(updatedExpense.UpdatedAt))
 	c.JSON(http.StatusOK, updatedExpense)
 }
 
 // DeleteExpenseHandler handles deleting an expense record.
 func (h *ExpenseHandler) DeleteExpenseHandler(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
 	expenseIDStr := c.Param("id")
 	log.Printf("[ExpenseHandler] DeleteExpenseHandler: Received raw ID string: '%s'", expenseIDStr)
 	expenseIDUint64, err := strconv.ParseUint(expenseIDStr, 10, 32)
@@ -183,7 +224,7 @@ func (h *ExpenseHandler) DeleteExpenseHandler(c *gin.Context) {
 	}
 
 	// Fetch the expense first to get its date for summary invalidation
-	expenseToDelete, serviceErr := h.service.GetExpenseByID(expenseID)
+	expenseToDelete, serviceErr := h.service.GetExpenseByID(userID, expenseID)
 	if serviceErr != nil {
 		if strings.Contains(serviceErr.Error(), "expense record not found") {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Expense record not found"})
@@ -195,8 +236,11 @@ func (h *ExpenseHandler) DeleteExpenseHandler(c *gin.Context) {
 	dateOfDeletedItem := expenseToDelete.Date
 
 	// Delete the expense
-	err = h.service.DeleteExpense(expenseID)
+	err = h.service.DeleteExpense(userID, expenseID, ifMatchVersions(c)...)
 	if err != nil {
+		if writeVersionConflict(c, err) {
+			return
+		}
 		// This check might be redundant if GetExpenseByID already confirmed existence,
 		// but kept for safety or if DeleteExpense has other failure modes.
 		if strings.Contains(err.Error(), "expense record not found") {
@@ -210,7 +254,7 @@ func (h *ExpenseHandler) DeleteExpenseHandler(c *gin.Context) {
 	// Invalidate summaries
 	if h.summaryService != nil && dateOfDeletedItem.Time != (time.Time{}) {
 		go func(dateVal database.CustomDate) {
-			err := h.summaryService.InvalidateSummariesForDate(dateVal.Time, []string{"monthly", "weekly", "yearly"})
+			err := h.summaryService.InvalidateSummariesForDate(userID, dateVal.Time, []string{"monthly", "weekly", "yearly"})
 			if err != nil {
 				log.Printf("Error invalidating summaries after deleting expense: %v", err)
 			}
@@ -219,3 +263,95 @@ func (h *ExpenseHandler) DeleteExpenseHandler(c *gin.Context) {
 
 	c.JSON(http.StatusNoContent, nil)
 }
+
+// expenseFromRequest returns the expense described by a create request.
+func expenseFromRequest(userID uint, req models.ExpenseCreateRequest) models.Expense {
+	return models.Expense{
+		UserID:     userID,
+		Amount:     req.Amount,
+		Currency:   req.Currency,
+		AccountID:  req.AccountID,
+		Category:   req.Category,
+		CategoryID: req.CategoryID,
+		Date:       req.Date,
+		Note:       req.Note,
+		Tags:       req.Tags,
+		Splits:     services.ExpenseSplitsFromRequests(req.Splits),
+	}
+}
+
+// BulkCreateExpensesHandler handles creating several expense records at once. They are created together
+// or not at all.
+func (h *ExpenseHandler) BulkCreateExpensesHandler(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req models.BulkRequest[models.ExpenseCreateRequest]
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+	expenses := make([]models.Expense, len(req.Items))
+	for i, item := range req.Items {
+		expenses[i] = expenseFromRequest(userID, item)
+	}
+
+	result, err := h.service.BulkCreateExpenses(expenses)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create expense records: " + err.Error()})
+		return
+	}
+	invalidateSummariesAfterBulk(h.summaryService, userID, result.Dates)
+	writeBulkResult(c, result, http.StatusCreated)
+}
+
+// BulkUpdateExpensesHandler handles updating several expense records at once. They are updated together
+// or not at all.
+func (h *ExpenseHandler) BulkUpdateExpensesHandler(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req models.BulkRequest[models.ExpenseBulkUpdateItem]
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	result, err := h.service.BulkUpdateExpenses(userID, req.Items)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update expense records: " + err.Error()})
+		return
+	}
+	invalidateSummariesAfterBulk(h.summaryService, userID, result.Dates)
+	writeBulkResult(c, result, http.StatusOK)
+}
+
+// BulkDeleteExpensesHandler handles deleting several expense records at once. They are deleted together
+// or not at all.
+func (h *ExpenseHandler) BulkDeleteExpensesHandler(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req models.BulkDeleteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	result, err := h.service.BulkDeleteExpenses(userID, req.IDs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete expense records: " + err.Error()})
+		return
+	}
+	invalidateSummariesAfterBulk(h.summaryService, userID, result.Dates)
+	writeBulkResult(c, result, http.StatusOK)
+}
@@ -38,24 +38,30 @@ func setupExpenseTestRouter(t *testing.T) (*gin.Engine, *gorm.DB) {
 	db.Exec("DROP TABLE IF EXISTS Users")
 
 	// Auto-migrate schemas
-	err = db.AutoMigrate(&models.User{}, &models.Expense{})
+	err = db.AutoMigrate(&models.User{}, &models.Expense{}, &models.Category{}, &models.Tag{}, &models.Tagging{}, &models.ExpenseSplit{})
 	assert.NoError(t, err, "Failed to auto-migrate models")
 
 	// Seed a dummy user (optional, but good practice if any underlying service logic might require it)
 	db.Create(&models.User{Username: "testuser", Email: "test@example.com", PasswordHash: "hash"})
 
-
 	expenseService := services.NewExpenseService(db)
-	expenseHandler := NewExpenseHandler(expenseService)
+	expenseHandler := NewExpenseHandler(expenseService, nil)
 
 	router := gin.Default()
+	// Stand in for the auth middleware: every request is made as the seeded test user.
+	router.Use(func(c *gin.Context) {
+		c.Set(userIDContextKey, uint(1))
+		c.Next()
+	})
 	// Register expense routes
 	router.POST("/expenses", expenseHandler.CreateExpenseHandler) // Needed for creating items if tests require it
 	router.GET("/expenses/:id", expenseHandler.GetExpenseHandler)
 	router.PUT("/expenses/:id", expenseHandler.UpdateExpenseHandler)
 	router.DELETE("/expenses/:id", expenseHandler.DeleteExpenseHandler)
 	router.GET("/expenses", expenseHandler.ListExpensesHandler)
-
+	router.POST("/expenses/bulk", expenseHandler.BulkCreateExpensesHandler)
+	router.PUT("/expenses/bulk", expenseHandler.BulkUpdateExpensesHandler)
+	router.POST("/expenses/bulk/delete", expenseHandler.BulkDeleteExpensesHandler)
 
 	return router, db
 }
@@ -129,7 +135,7 @@ func TestDeleteExpenseHandler_ValidIDFormat_PassesParsing(t *testing.T) {
 	// and doesn't fail *before* ID parsing due to a DB error on a non-existent item.
 	// However, the service's DeleteExpense itself handles "not found".
 	// The goal here is just that ID parsing doesn't yield BadRequest.
-	dummyExpense := models.Expense{Amount: 1, Category: "test", Date: database.CustomDate{Time: time.Now()}}
+	dummyExpense := models.Expense{UserID: 1, Amount: 1, Category: "test", Date: database.CustomDate{Time: time.Now()}}
 	db.Create(&dummyExpense) // ID will be 1 (or more, depending on test execution order if DB wasn't perfectly isolated before)
 
 	validID := strconv.Itoa(int(dummyExpense.ID)) // Use a real ID from the DB
@@ -154,7 +160,7 @@ func TestDeleteExpenseHandler_ValidIDFormat_PassesParsing(t *testing.T) {
 
 func TestGetExpenseHandler_ValidIDFormat_PassesParsing(t *testing.T) {
 	router, db := setupExpenseTestRouter(t)
-	dummyExpense := models.Expense{Amount: 1, Category: "test", Date: database.CustomDate{Time: time.Now()}}
+	dummyExpense := models.Expense{UserID: 1, Amount: 1, Category: "test", Date: database.CustomDate{Time: time.Now()}}
 	db.Create(&dummyExpense)
 	validID := strconv.Itoa(int(dummyExpense.ID))
 
@@ -170,7 +176,7 @@ func TestGetExpenseHandler_ValidIDFormat_PassesParsing(t *testing.T) {
 
 func TestUpdateExpenseHandler_ValidIDFormat_PassesParsing(t *testing.T) {
 	router, db := setupExpenseTestRouter(t)
-	dummyExpense := models.Expense{Amount: 1, Category: "test", Date: database.CustomDate{Time: time.Now()}}
+	dummyExpense := models.Expense{UserID: 1, Amount: 1, Category: "test", Date: database.CustomDate{Time: time.Now()}}
 	db.Create(&dummyExpense)
 	validID := strconv.Itoa(int(dummyExpense.ID))
 
@@ -185,3 +191,153 @@ func TestUpdateExpenseHandler_ValidIDFormat_PassesParsing(t *testing.T) {
 		return rr.Code == http.StatusOK || rr.Code == http.StatusNotFound
 	}, "Expected StatusOK or StatusNotFound for valid ID %s, but got %d", validID, rr.Code)
 }
+
+func TestListExpensesHandler_InvalidFilters(t *testing.T) {
+	router, _ := setupExpenseTestRouter(t)
+
+	for _, query := range []string{"sort=colour", "order=sideways", "min_amount=cheap", "start_date=yesterday", "startDate=yesterday", "min_amount=20&max_amount=10", "minAmount=20", "status=Paid"} {
+		t.Run(query, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "/expenses?"+query, nil)
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, http.StatusBadRequest, rr.Code, "Expected BadRequest for query: "+query)
+		})
+	}
+}
+
+func TestListExpensesHandler_DateAliases(t *testing.T) {
+	router, db := setupExpenseTestRouter(t)
+	for day := 1; day <= 3; day++ {
+		db.Create(&models.Expense{UserID: 1, Amount: 100, Currency: "USD", Category: "Food", Date: database.CustomDate{Time: time.Date(2024, time.May, day, 0, 0, 0, 0, time.UTC)}})
+	}
+
+	for _, query := range []string{"start_date=2024-05-02&end_date=2024-05-02", "startDate=2024-05-02&endDate=2024-05-02"} {
+		t.Run(query, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "/expenses?"+query, nil)
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+			var page models.Page[models.Expense]
+			assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &page))
+			assert.Equal(t, int64(1), page.Total)
+		})
+	}
+}
+
+func TestListExpensesHandler_Page(t *testing.T) {
+	router, db := setupExpenseTestRouter(t)
+	for day := 1; day <= 3; day++ {
+		db.Create(&models.Expense{UserID: 1, Amount: 100, Currency: "USD", Category: "Food", Date: database.CustomDate{Time: time.Date(2024, time.May, day, 0, 0, 0, 0, time.UTC)}})
+	}
+
+	req, _ := http.NewRequest("GET", "/expenses?category=food&limit=1&page=2", nil)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "3", rr.Header().Get("X-Total-Count"))
+	var page models.Page[models.Expense]
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &page))
+	assert.Len(t, page.Items, 1)
+	assert.Equal(t, int64(3), page.Total)
+	assert.Equal(t, 2, page.Page)
+	assert.Equal(t, 1, page.Limit)
+	assert.True(t, page.HasMore)
+	assert.Equal(t, "/expenses?category=food&limit=1&page=3", page.Next, "Links keep the filters")
+	assert.Equal(t, "/expenses?category=food&limit=1&page=1", page.Prev)
+}
+
+func TestBulkExpensesHandlers(t *testing.T) {
+	router, db := setupExpenseTestRouter(t)
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := send("POST", "/expenses/bulk", `{"items": []}`)
+	assert.Equal(t, http.StatusBadRequest, rr.Code, "An empty batch is rejected")
+	rr = send("POST", "/expenses/bulk", `{"items": [{"amount": "10.00", "date": "2024-05-01"}, {"date": "2024-05-02"}]}`)
+	assert.Equal(t, http.StatusBadRequest, rr.Code, "Each item is validated")
+
+	rr = send("POST", "/expenses/bulk", `{"items": [{"amount": "10.00", "currency": "USD", "category": "Food", "date": "2024-05-01"}, {"amount": "20.00", "currency": "USD", "category": "Rent", "date": "2024-05-02"}]}`)
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	var created models.BulkResult[models.Expense]
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &created))
+	assert.True(t, created.Applied)
+	assert.Len(t, created.Results, 2)
+	firstID, secondID := created.Results[0].Item.ID, created.Results[1].Item.ID
+
+	rr = send("PUT", "/expenses/bulk", fmt.Sprintf(`{"items": [{"id": %d, "category": "Groceries"}, {"id": 999, "category": "Groceries"}]}`, firstID))
+	assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	var updated models.BulkResult[models.Expense]
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &updated))
+	assert.False(t, updated.Applied)
+	assert.Equal(t, models.BulkStatusNotApplied, updated.Results[0].Status)
+	assert.Equal(t, models.BulkStatusFailed, updated.Results[1].Status)
+	var first models.Expense
+	db.First(&first, firstID)
+	assert.Equal(t, "Food", first.Category, "A failed batch changes nothing")
+
+	rr = send("POST", "/expenses/bulk/delete", fmt.Sprintf(`{"ids": [%d, %d]}`, firstID, firstID))
+	assert.Equal(t, http.StatusBadRequest, rr.Code, "IDs must be unique")
+	rr = send("POST", "/expenses/bulk/delete", fmt.Sprintf(`{"ids": [%d, %d]}`, firstID, secondID))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var count int64
+	db.Model(&models.Expense{}).Count(&count)
+	assert.Equal(t, int64(0), count)
+}
+
+func TestExpenseHandlers_ETagFollowsUpdates(t *testing.T) {
+	router, _ := setupExpenseTestRouter(t)
+	send := func(method, path, etag, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		if etag != "" {
+			req.Header.Set("If-Match", etag)
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := send("POST", "/expenses", "", `{"amount": "10.00", "currency": "USD", "category": "Food", "date": "2024-05-01"}`)
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	var created models.Expense
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &created))
+	path := "/expenses/" + strconv.Itoa(int(created.ID))
+	etag := send("GET", path, "", "").Header().Get("ETag")
+	assert.Equal(t, rr.Header().Get("ETag"), etag, "A create responds with the ETag of the new record")
+
+	for _, body := range []string{`{"note": "lunch"}`, `{"tags": ["work"]}`} {
+		time.Sleep(time.Millisecond)
+		rr = send("PUT", path, etag, body)
+		assert.Equal(t, http.StatusOK, rr.Code, body)
+		current := send("GET", path, "", "").Header().Get("ETag")
+		assert.NotEqual(t, etag, current, "Changing only the tags gives a new ETag too")
+		assert.Equal(t, current, rr.Header().Get("ETag"), body)
+		etag = current
+	}
+
+	rr = send("DELETE", path, `"stale"`, "")
+	assert.Equal(t, http.StatusPreconditionFailed, rr.Code)
+	rr = send("DELETE", path, etag, "")
+	assert.Equal(t, http.StatusNoContent, rr.Code)
+}
+
+func TestListExpensesHandler_LimitIsCapped(t *testing.T) {
+	router, _ := setupExpenseTestRouter(t)
+
+	req, _ := http.NewRequest("GET", "/expenses?limit=1000000", nil)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var page models.Page[models.Expense]
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &page))
+	assert.Equal(t, models.MaxPageLimit, page.Limit)
+}
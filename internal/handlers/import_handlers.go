@@ -0,0 +1,424 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zayyadi/finance-tracker/internal/models"
+	"github.com/zayyadi/finance-tracker/internal/services"
+)
+
+// maxStatementSize is the largest import request accepted, in bytes.
+const maxStatementSize int64 = 10 << 20
+
+// ImportHandler handles HTTP requests for statement imports and CSV import profiles.
+type ImportHandler struct {
+	service *services.ImportService
+}
+
+// NewImportHandler creates a new ImportHandler with the given service.
+func NewImportHandler(service *services.ImportService) *ImportHandler {
+	return &ImportHandler{service: service}
+}
+
+// CreateImportProfileHandler handles saving a new CSV column mapping.
+func (h *ImportHandler) CreateImportProfileHandler(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req models.ImportProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	profile, err := h.service.CreateImportProfile(userID, &req)
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid import profile") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else if strings.Contains(err.Error(), "already exists") {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create import profile: " + err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, profile)
+}
+
+// GetImportProfileHandler handles fetching a single import profile.
+func (h *ImportHandler) GetImportProfileHandler(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	profileIDUint64, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || profileIDUint64 == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid import profile ID format"})
+		return
+	}
+
+	profile, err := h.service.GetImportProfileByID(userID, uint(profileIDUint64))
+	if err != nil {
+		if strings.Contains(err.Error(), "import profile not found") {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve import profile: " + err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+// ListImportProfilesHandler handles fetching the user's import profiles with pagination.
+func (h *ImportHandler) ListImportProfilesHandler(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit < 1 {
+		limit = 10
+	}
+	offset := (page - 1) * limit
+
+	profiles, err := h.service.GetImportProfiles(userID, offset, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve import profiles: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, profiles)
+}
+
+// UpdateImportProfileHandler handles replacing the mapping of an import profile. The body has the
+// same shape as for creation; omitted fields are reset to their defaults.
+func (h *ImportHandler) UpdateImportProfileHandler(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	profileIDUint64, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || profileIDUint64 == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid import profile ID format"})
+		return
+	}
+
+	var req models.ImportProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	profile, err := h.service.UpdateImportProfile(userID, uint(profileIDUint64), &req)
+	if err != nil {
+		if strings.Contains(err.Error(), "import profile not found") {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		} else if strings.Contains(err.Error(), "invalid import profile") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else if strings.Contains(err.Error(), "already exists") {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update import profile: " + err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+// DeleteImportProfileHandler handles deleting an import profile.
+func (h *ImportHandler) DeleteImportProfileHandler(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	profileIDUint64, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || profileIDUint64 == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid import profile ID format"})
+		return
+	}
+
+	if err := h.service.DeleteImportProfile(userID, uint(profileIDUint64)); err != nil {
+		if strings.Contains(err.Error(), "import profile not found") {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Import profile not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete import profile: " + err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// ImportCSVHandler imports a CSV bank statement as income and expenses. The file is sent as the
+// "file" field of a multipart form, or as a raw text/csv body. The column mapping is either a saved
+// profile ("profile_id" parameter) or a one-off "mapping" parameter holding the same JSON as a profile.
+// Parameters are read from the query string, or from the fields of a multipart form; a raw body is
+// never parsed as a form. With "dry_run=true" nothing is saved and the response previews the import.
+func (h *ImportHandler) ImportCSVHandler(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !readImportRequest(c) {
+		return
+	}
+	dryRun, ok := importDryRun(c)
+	if !ok {
+		return
+	}
+
+	var profile *models.ImportProfile
+	if profileIDStr := importParam(c, "profile_id"); profileIDStr != "" {
+		profileIDUint64, err := strconv.ParseUint(profileIDStr, 10, 32)
+		if err != nil || profileIDUint64 == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid import profile ID format"})
+			return
+		}
+		profile, err = h.service.GetImportProfileByID(userID, uint(profileIDUint64))
+		if err != nil {
+			if strings.Contains(err.Error(), "import profile not found") {
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			} else {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve import profile: " + err.Error()})
+			}
+			return
+		}
+	} else if mapping := importParam(c, "mapping"); mapping != "" {
+		var req models.ImportProfileRequest
+		if err := json.Unmarshal([]byte(mapping), &req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid mapping: " + err.Error()})
+			return
+		}
+		profile, err = h.service.ProfileFromRequest(userID, &req)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	} else {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Either profile_id or a mapping is required"})
+		return
+	}
+
+	body, err := statementBody(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer body.Close()
+
+	result, err := h.service.ImportCSV(userID, body, profile, dryRun)
+	if err != nil {
+		c.JSON(importErrorStatus(err), gin.H{"error": "Failed to import statement: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// ImportOFXHandler imports an OFX or QFX bank statement as income and expenses. The file is sent as
+// the "file" field of a multipart form or as the raw request body, whatever its Content-Type. An optional
+// "account_id" parameter, read like those of a CSV import, books the transactions to one of the user's
+// accounts and compares its balance with the statement's. With "dry_run=true" nothing is saved and the response previews the import.
+func (h *ImportHandler) ImportOFXHandler(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !readImportRequest(c) {
+		return
+	}
+	dryRun, ok := importDryRun(c)
+	if !ok {
+		return
+	}
+	accountID, ok := importAccountID(c)
+	if !ok {
+		return
+	}
+
+	body, err := statementBody(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer body.Close()
+
+	result, err := h.service.ImportOFX(userID, body, accountID, dryRun)
+	if err != nil {
+		c.JSON(importErrorStatus(err), gin.H{"error": "Failed to import statement: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// ImportQIFHandler imports a QIF export from desktop finance software as income and expenses. The
+// file is sent like an OFX statement, with the same optional "account_id" and "dry_run" parameters.
+// QIF dates are read as MM/DD/YYYY unless "date_format=DD/MM/YYYY" is given.
+func (h *ImportHandler) ImportQIFHandler(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !readImportRequest(c) {
+		return
+	}
+	dryRun, ok := importDryRun(c)
+	if !ok {
+		return
+	}
+	accountID, ok := importAccountID(c)
+	if !ok {
+		return
+	}
+	dateFormat := importParam(c, "date_format")
+
+	body, err := statementBody(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer body.Close()
+
+	result, err := h.service.ImportQIF(userID, body, accountID, dateFormat, dryRun)
+	if err != nil {
+		c.JSON(importErrorStatus(err), gin.H{"error": "Failed to import statement: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// importDryRun reads the optional "dry_run" parameter of an import, false when it is missing. It writes a
+// 400 response and reports false when the value is not a boolean.
+func importDryRun(c *gin.Context) (bool, bool) {
+	dryRunStr := importParam(c, "dry_run")
+	if dryRunStr == "" {
+		return false, true
+	}
+	dryRun, err := strconv.ParseBool(dryRunStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid dry_run value. Use true or false."})
+		return false, false
+	}
+	return dryRun, true
+}
+
+// importAccountID reads the optional "account_id" parameter of an import. It writes a
+// 400 response and reports false when the value is not a valid ID.
+func importAccountID(c *gin.Context) (*uint, bool) {
+	accountIDStr := importParam(c, "account_id")
+	if accountIDStr == "" {
+		return nil, true
+	}
+	accountIDUint64, err := strconv.ParseUint(accountIDStr, 10, 32)
+	if err != nil || accountIDUint64 == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid account ID format"})
+		return nil, false
+	}
+	accountID := uint(accountIDUint64)
+	return &accountID, true
+}
+
+// readImportRequest caps the size of an import request and, for a multipart upload, parses the form so
+// its fields can be read. It writes a 413 or 400 response and reports false when the request cannot be
+// read.
+func readImportRequest(c *gin.Context) bool {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxStatementSize)
+	if !isMultipart(c) {
+		return true
+	}
+	if _, err := c.MultipartForm(); err != nil {
+		if statementTooLarge(err) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("Statement too large: imports are limited to %d MB", maxStatementSize>>20)})
+		} else {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid multipart form: " + err.Error()})
+		}
+		return false
+	}
+	return true
+}
+
+// isMultipart reports whether an import is uploaded as a multipart form. Any other body is the statement
+// itself, whatever its Content-Type, and is never parsed as a form.
+func isMultipart(c *gin.Context) bool {
+	return strings.HasPrefix(c.ContentType(), "multipart/")
+}
+
+// importParam reads a parameter of an import from the query string, or else from the fields of a
+// multipart form.
+func importParam(c *gin.Context, name string) string {
+	if value := c.Query(name); value != "" || !isMultipart(c) {
+		return value
+	}
+	return c.PostForm(name)
+}
+
+// statementTooLarge reports whether err comes from reading past maxStatementSize.
+func statementTooLarge(err error) bool {
+	var tooLarge *http.MaxBytesError
+	return errors.As(err, &tooLarge)
+}
+
+// statementBody returns the uploaded statement: the "file" field of a multipart form, or else the
+// raw request body.
+func statementBody(c *gin.Context) (io.ReadCloser, error) {
+	if !isMultipart(c) {
+		return c.Request.Body, nil
+	}
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return nil, fmt.Errorf("a statement file is required in the 'file' form field")
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		return nil, fmt.Errorf("could not read uploaded file: %w", err)
+	}
+	return file, nil
+}
+
+// importErrorStatus maps an import error to an HTTP status: problems with the file or the chosen
+// account are the client's, anything else is ours.
+func importErrorStatus(err error) int {
+	if statementTooLarge(err) {
+		return http.StatusRequestEntityTooLarge
+	}
+	msg := err.Error()
+	if strings.Contains(msg, "invalid CSV import") || strings.Contains(msg, "invalid OFX import") ||
+		strings.Contains(msg, "invalid QIF import") ||
+		strings.Contains(msg, "account not found") || strings.Contains(msg, "account currency") {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
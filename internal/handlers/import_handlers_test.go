@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/zayyadi/finance-tracker/internal/models"
+	"github.com/zayyadi/finance-tracker/internal/services"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// setupImportTestRouter initializes an in-memory SQLite database and sets up the Gin router with the
+// statement import routes for testing.
+func setupImportTestRouter(t *testing.T) (*gin.Engine, *gorm.DB) {
+	gin.SetMode(gin.TestMode)
+
+	dsn := fmt.Sprintf("file:import_handler_%s_%d?mode=memory&cache=shared", t.Name(), time.Now().UnixNano())
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{})
+	assert.NoError(t, err, "Failed to connect to in-memory SQLite")
+	sqlDB, err := db.DB()
+	assert.NoError(t, err)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	err = db.AutoMigrate(&models.User{}, &models.Account{}, &models.Income{}, &models.Expense{}, &models.Category{}, &models.CategoryRule{},
+		&models.DuplicatePair{}, &models.ImportProfile{}, &models.Tag{}, &models.Tagging{}, &models.ExpenseSplit{})
+	assert.NoError(t, err, "Failed to auto-migrate models")
+	db.Create(&models.User{Username: "testuser", Email: "test@example.com", PasswordHash: "hash"})
+
+	importHandler := NewImportHandler(services.NewImportService(db, nil))
+	router := gin.Default()
+	// Stand in for the auth middleware: every request is made as the seeded test user.
+	router.Use(func(c *gin.Context) {
+		c.Set(userIDContextKey, uint(1))
+		c.Next()
+	})
+	router.POST("/import/csv", importHandler.ImportCSVHandler)
+	router.POST("/import/ofx", importHandler.ImportOFXHandler)
+	router.POST("/import/qif", importHandler.ImportQIFHandler)
+	return router, db
+}
+
+func TestImportHandlers_RawBodyIsNotReadAsForm(t *testing.T) {
+	router, _ := setupImportTestRouter(t)
+	send := func(path, contentType, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", contentType)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	// curl --data-binary sends application/x-www-form-urlencoded unless told otherwise.
+	qif := "!Type:Bank\nD05/01/2024\nT-12.50\nPCoffee\n^\n"
+	rr := send("/import/qif?dry_run=true", "application/x-www-form-urlencoded", qif)
+	assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	rr = send("/import/qif?dry_run=true&account_id=abc", "text/plain", qif)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	mapping := `{"date_column": "Date", "amount_column": "Amount", "date_format": "2006-01-02"}`
+	csv := "Date,Amount\n2024-05-01,-12.50\n"
+	rr = send("/import/csv?dry_run=true&mapping="+strings.ReplaceAll(mapping, " ", ""), "application/x-www-form-urlencoded", csv)
+	assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	rr = send("/import/csv?dry_run=true", "application/x-www-form-urlencoded", "mapping="+mapping)
+	assert.Equal(t, http.StatusBadRequest, rr.Code, "Form fields are only read from multipart uploads")
+
+	rr = send("/import/csv?dry_run=true&mapping="+strings.ReplaceAll(mapping, " ", ""), "text/csv", csv+strings.Repeat("2024-05-01,-1.00\n", int(maxStatementSize)/17+1))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rr.Code)
+	rr = send("/import/qif?dry_run=true", "text/plain", "!Type:Bank\n"+strings.Repeat("MMemo\n", int(maxStatementSize)/6+1))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rr.Code)
+}
+
+func TestImportHandlers_DryRunFormField(t *testing.T) {
+	router, db := setupImportTestRouter(t)
+	upload := func(path, statement string, fields map[string]string) *httptest.ResponseRecorder {
+		var body bytes.Buffer
+		form := multipart.NewWriter(&body)
+		for name, value := range fields {
+			assert.NoError(t, form.WriteField(name, value))
+		}
+		file, err := form.CreateFormFile("file", "statement")
+		assert.NoError(t, err)
+		_, err = file.Write([]byte(statement))
+		assert.NoError(t, err)
+		assert.NoError(t, form.Close())
+		req, _ := http.NewRequest("POST", path, &body)
+		req.Header.Set("Content-Type", form.FormDataContentType())
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	mapping := `{"date_column": "Date", "amount_column": "Amount", "date_format": "2006-01-02"}`
+	csv := "Date,Amount\n2024-05-01,-12.50\n"
+	rr := upload("/import/csv", csv, map[string]string{"mapping": mapping, "dry_run": "true"})
+	assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	rr = upload("/import/csv", csv, map[string]string{"mapping": mapping, "dry_run": "maybe"})
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	ofx := "OFXHEADER:100\nDATA:OFXSGML\n\n<OFX><BANKMSGSRSV1><STMTTRNRS><STMTRS><CURDEF>USD<BANKTRANLIST>\n" +
+		"<STMTTRN><TRNTYPE>DEBIT<DTPOSTED>20240603<TRNAMT>-42.10<FITID>T1002<NAME>GROCER</STMTTRN>\n" +
+		"</BANKTRANLIST></STMTRS></STMTTRNRS></BANKMSGSRSV1></OFX>\n"
+	rr = upload("/import/ofx", ofx, map[string]string{"dry_run": "true"})
+	assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	rr = upload("/import/qif", "!Type:Bank\nD05/01/2024\nT-12.50\nPCoffee\n^\n", map[string]string{"dry_run": "true"})
+	assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	var count int64
+	db.Model(&models.Expense{}).Count(&count)
+	assert.Equal(t, int64(0), count, "A dry run sent as a form field saves nothing")
+}
@@ -29,39 +29,51 @@ func NewIncomeHandler(service *services.IncomeService, summaryService *services.
 
 // CreateIncomeHandler handles the creation of a new income record.
 func (h *IncomeHandler) CreateIncomeHandler(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
 	var req models.IncomeCreateRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
 		return
 	}
 
-	income := models.Income{
-		Amount:   req.Amount,
-		Category: req.Category,
-		Date:     req.Date,
-		Note:     req.Note,
-	}
+	income := incomeFromRequest(userID, req)
 
 	if err := h.service.CreateIncome(&income); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create income record: " + err.Error()})
+		if strings.Contains(err.Error(), "account not found") || strings.Contains(err.Error(), "account currency") || strings.Contains(err.Error(), "invalid category") || strings.Contains(err.Error(), "invalid tag") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create income record: " + err.Error()})
+		}
 		return
 	}
 
 	// Invalidate summaries
 	if h.summaryService != nil {
 		go func(dateOfItem database.CustomDate) { // Use a goroutine for non-blocking invalidation
-			err := h.summaryService.InvalidateSummariesForDate(dateOfItem.Time, []string{"monthly", "weekly", "yearly"})
+			err := h.summaryService.InvalidateSummariesForDate(userID, dateOfItem.Time, []string{"monthly", "weekly", "yearly"})
 			if err != nil {
 				log.Printf("Error invalidating summaries after creating income: %v", err)
 			}
 		}(income.Date)
 	}
 
+	c.Header("ETag", recordETag(income.UpdatedAt))
 	c.JSON(http.StatusCreated, income)
 }
 
 // GetIncomeHandler handles fetching a single income record.
 func (h *IncomeHandler) GetIncomeHandler(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
 	incomeIDStr := c.Param("id")
 	incomeIDUint64, err := strconv.ParseUint(incomeIDStr, 10, 32)
 	if err != nil {
@@ -74,7 +86,7 @@ func (h *IncomeHandler) GetIncomeHandler(c *gin.Context) {
 		return
 	}
 
-	income, err := h.service.GetIncomeByID(incomeID)
+	income, err := h.service.GetIncomeByID(userID, incomeID)
 	if err != nil {
 		if strings.Contains(err.Error(), "income record not found") {
 			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
@@ -84,11 +96,19 @@ func (h *IncomeHandler) GetIncomeHandler(c *gin.Context) {
 		return
 	}
 
+	c.Header("ETag", recordETag(income.UpdatedAt))
 	c.JSON(http.StatusOK, income)
 }
 
-// ListIncomesHandler handles fetching all income records with pagination.
+// ListIncomesHandler handles fetching income records with pagination, filtered and sorted by the query
+// parameters read by parseListFilter.
 func (h *IncomeHandler) ListIncomesHandler(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
 	pageStr := c.DefaultQuery("page", "1")
 	limitStr := c.DefaultQuery("limit", "10")
 
@@ -100,27 +120,33 @@ func (h *IncomeHandler) ListIncomesHandler(c *gin.Context) {
 	if err != nil || limit < 1 {
 		limit = 10
 	}
-	offset := (page - 1) * limit
 
-	incomes, err := h.service.GetIncomes(offset, limit)
+	filter, err := parseListFilter(c)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve income records: " + err.Error()})
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	if incomes == nil { // Return empty list instead of nil if no records
-		incomes = []models.Income{}
+	incomes, err := h.service.GetIncomes(userID, page, limit, filter)
+	if err != nil {
+		if isListFilterError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve income records: " + err.Error()})
+		}
+		return
 	}
-	c.JSON(http.StatusOK, incomes)
+	writePage(c, incomes)
 }
 
 // UpdateIncomeHandler handles updating an existing income record.
 func (h *IncomeHandler) UpdateIncomeHandler(c *gin.Context) {
-	// userID, exists := c.Get("userID")
-	// if !exists {
-	// 	c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
-	// 	return
-	// }
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
 	incomeIDStr := c.Param("id")
 	incomeIDUint64, err := strconv.ParseUint(incomeIDStr, 10, 32)
 	if err != nil {
@@ -138,15 +164,20 @@ func (h *IncomeHandler) UpdateIncomeHandler(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
 		return
 	}
-	if req.Amount == nil && req.Category == nil && req.Date == nil && req.Note == nil {
+	if req.Amount == nil && req.Currency == nil && req.AccountID == nil && req.Category == nil && req.CategoryID == nil && req.Date == nil && req.Note == nil && req.Tags == nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "At least one field must be provided for update"})
 		return
 	}
 
-	updatedIncome, err := h.service.UpdateIncome(incomeID, &req)
+	updatedIncome, err := h.service.UpdateIncome(userID, incomeID, &req, ifMatchVersions(c)...)
 	if err != nil {
+		if writeVersionConflict(c, err) {
+			return
+		}
 		if strings.Contains(err.Error(), "income record not found") {
 			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		} else if strings.Contains(err.Error(), "account not found") || strings.Contains(err.Error(), "account currency") || strings.Contains(err.Error(), "invalid category") || strings.Contains(err.Error(), "invalid tag") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		} else {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update income record: " + err.Error()})
 		}
@@ -156,7 +187,7 @@ func (h *IncomeHandler) UpdateIncomeHandler(c *gin.Context) {
 	// Invalidate summaries
 	if h.summaryService != nil && updatedIncome.Date.Time != (time.Time{}) { // Ensure Date is valid
 		go func(dateOfItem database.CustomDate) {
-			err := h.summaryService.InvalidateSummariesForDate(dateOfItem.Time, []string{"monthly", "weekly", "yearly"})
+			err := h.summaryService.InvalidateSummariesForDate(userID, dateOfItem.Time, []string{"monthly", "weekly", "yearly"})
 			if err != nil {
 				log.Printf("Error invalidating summaries after updating income: %v", err)
 			}
@@ -166,11 +197,18 @@ func (h *IncomeHandler) UpdateIncomeHandler(c *gin.Context) {
 		// For simplicity, current implementation only invalidates for the new date.
 	}
 
+	c.Header("ETag", recordETag(updatedIncome.UpdatedAt))
 	c.JSON(http.StatusOK, updatedIncome)
 }
 
 // DeleteIncomeHandler handles deleting an income record.
 func (h *IncomeHandler) DeleteIncomeHandler(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
 	incomeIDStr := c.Param("id")
 	incomeIDUint64, err := strconv.ParseUint(incomeIDStr, 10, 32)
 	if err != nil {
@@ -184,7 +222,7 @@ func (h *IncomeHandler) DeleteIncomeHandler(c *gin.Context) {
 	}
 
 	// Fetch the income first to get its date for summary invalidation
-	incomeToDelete, serviceErr := h.service.GetIncomeByID(incomeID)
+	incomeToDelete, serviceErr := h.service.GetIncomeByID(userID, incomeID)
 	if serviceErr != nil {
 		if strings.Contains(serviceErr.Error(), "income record not found") {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Income record not found"})
@@ -196,8 +234,11 @@ func (h *IncomeHandler) DeleteIncomeHandler(c *gin.Context) {
 	dateOfDeletedItem := incomeToDelete.Date
 
 	// Delete the income
-	err = h.service.DeleteIncome(incomeID)
+	err = h.service.DeleteIncome(userID, incomeID, ifMatchVersions(c)...)
 	if err != nil {
+		if writeVersionConflict(c, err) {
+			return
+		}
 		if strings.Contains(err.Error(), "income record not found") {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Income record not found during deletion"})
 		} else {
@@ -209,7 +250,7 @@ func (h *IncomeHandler) DeleteIncomeHandler(c *gin.Context) {
 	// Invalidate summaries
 	if h.summaryService != nil && dateOfDeletedItem.Time != (time.Time{}) {
 		go func(dateVal database.CustomDate) {
-			err := h.summaryService.InvalidateSummariesForDate(dateVal.Time, []string{"monthly", "weekly", "yearly"})
+			err := h.summaryService.InvalidateSummariesForDate(userID, dateVal.Time, []string{"monthly", "weekly", "yearly"})
 			if err != nil {
 				log.Printf("Error invalidating summaries after deleting income: %v", err)
 			}
@@ -218,3 +259,94 @@ func (h *IncomeHandler) DeleteIncomeHandler(c *gin.Context) {
 
 	c.JSON(http.StatusNoContent, nil)
 }
+
+// incomeFromRequest returns the income described by a create request.
+func incomeFromRequest(userID uint, req models.IncomeCreateRequest) models.Income {
+	return models.Income{
+		UserID:     userID,
+		Amount:     req.Amount,
+		Currency:   req.Currency,
+		AccountID:  req.AccountID,
+		Category:   req.Category,
+		CategoryID: req.CategoryID,
+		Date:       req.Date,
+		Note:       req.Note,
+		Tags:       req.Tags,
+	}
+}
+
+// BulkCreateIncomesHandler handles creating several income records at once. They are created together
+// or not at all.
+func (h *IncomeHandler) BulkCreateIncomesHandler(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req models.BulkRequest[models.IncomeCreateRequest]
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+	incomes := make([]models.Income, len(req.Items))
+	for i, item := range req.Items {
+		incomes[i] = incomeFromRequest(userID, item)
+	}
+
+	result, err := h.service.BulkCreateIncomes(incomes)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create income records: " + err.Error()})
+		return
+	}
+	invalidateSummariesAfterBulk(h.summaryService, userID, result.Dates)
+	writeBulkResult(c, result, http.StatusCreated)
+}
+
+// BulkUpdateIncomesHandler handles updating several income records at once. They are updated together
+// or not at all.
+func (h *IncomeHandler) BulkUpdateIncomesHandler(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req models.BulkRequest[models.IncomeBulkUpdateItem]
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	result, err := h.service.BulkUpdateIncomes(userID, req.Items)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update income records: " + err.Error()})
+		return
+	}
+	invalidateSummariesAfterBulk(h.summaryService, userID, result.Dates)
+	writeBulkResult(c, result, http.StatusOK)
+}
+
+// BulkDeleteIncomesHandler handles deleting several income records at once. They are deleted together
+// or not at all.
+func (h *IncomeHandler) BulkDeleteIncomesHandler(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req models.BulkDeleteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	result, err := h.service.BulkDeleteIncomes(userID, req.IDs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete income records: " + err.Error()})
+		return
+	}
+	invalidateSummariesAfterBulk(h.summaryService, userID, result.Dates)
+	writeBulkResult(c, result, http.StatusOK)
+}
@@ -35,15 +35,20 @@ func setupIncomeTestRouter(t *testing.T) (*gin.Engine, *gorm.DB) {
 	db.Exec("DROP TABLE IF EXISTS Income")
 	db.Exec("DROP TABLE IF EXISTS Users")
 
-	err = db.AutoMigrate(&models.User{}, &models.Income{})
+	err = db.AutoMigrate(&models.User{}, &models.Income{}, &models.Category{}, &models.Tag{}, &models.Tagging{})
 	assert.NoError(t, err, "Failed to auto-migrate models")
 
 	db.Create(&models.User{Username: "testuser", Email: "test@example.com", PasswordHash: "hash"})
 
 	incomeService := services.NewIncomeService(db)
-	incomeHandler := NewIncomeHandler(incomeService)
+	incomeHandler := NewIncomeHandler(incomeService, nil)
 
 	router := gin.Default()
+	// Stand in for the auth middleware: every request is made as the seeded test user.
+	router.Use(func(c *gin.Context) {
+		c.Set(userIDContextKey, uint(1))
+		c.Next()
+	})
 	router.GET("/income/:id", incomeHandler.GetIncomeHandler)
 	router.PUT("/income/:id", incomeHandler.UpdateIncomeHandler)
 	router.DELETE("/income/:id", incomeHandler.DeleteIncomeHandler)
@@ -116,7 +121,7 @@ func TestDeleteIncomeHandler_InvalidIDFormat(t *testing.T) {
 
 func TestGetIncomeHandler_ValidIDFormat_PassesParsing(t *testing.T) {
 	router, db := setupIncomeTestRouter(t)
-	dummyIncome := models.Income{Amount: 1, Category: "test", Date: database.CustomDate{Time: time.Now()}}
+	dummyIncome := models.Income{UserID: 1, Amount: 1, Category: "test", Date: database.CustomDate{Time: time.Now()}}
 	db.Create(&dummyIncome)
 	validID := strconv.Itoa(int(dummyIncome.ID))
 
@@ -132,7 +137,7 @@ func TestGetIncomeHandler_ValidIDFormat_PassesParsing(t *testing.T) {
 
 func TestUpdateIncomeHandler_ValidIDFormat_PassesParsing(t *testing.T) {
 	router, db := setupIncomeTestRouter(t)
-	dummyIncome := models.Income{Amount: 1, Category: "test", Date: database.CustomDate{Time: time.Now()}}
+	dummyIncome := models.Income{UserID: 1, Amount: 1, Category: "test", Date: database.CustomDate{Time: time.Now()}}
 	db.Create(&dummyIncome)
 	validID := strconv.Itoa(int(dummyIncome.ID))
 
@@ -150,7 +155,7 @@ func TestUpdateIncomeHandler_ValidIDFormat_PassesParsing(t *testing.T) {
 
 func TestDeleteIncomeHandler_ValidIDFormat_PassesParsing(t *testing.T) {
 	router, db := setupIncomeTestRouter(t)
-	dummyIncome := models.Income{Amount: 1, Category: "test", Date: database.CustomDate{Time: time.Now()}}
+	dummyIncome := models.Income{UserID: 1, Amount: 1, Category: "test", Date: database.CustomDate{Time: time.Now()}}
 	db.Create(&dummyIncome)
 	validID := strconv.Itoa(int(dummyIncome.ID))
 
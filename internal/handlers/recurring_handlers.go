@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zayyadi/finance-tracker/internal/models"
+	"github.com/zayyadi/finance-tracker/internal/services"
+)
+
+// RecurringHandler handles HTTP requests for recurring transaction templates.
+type RecurringHandler struct {
+	service *services.RecurringService
+}
+
+// NewRecurringHandler creates a new RecurringHandler with the given service.
+func NewRecurringHandler(service *services.RecurringService) *RecurringHandler {
+	return &RecurringHandler{service: service}
+}
+
+// CreateRecurringHandler handles the creation of a new recurring template.
+func (h *RecurringHandler) CreateRecurringHandler(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req models.RecurringCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	recurring, err := h.service.CreateRecurring(userID, &req)
+	if err != nil {
+		if strings.Contains(err.Error(), "account not found") || strings.Contains(err.Error(), "account currency") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else if strings.Contains(err.Error(), "could not") {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create recurring transaction: " + err.Error()})
+		} else {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, recurring)
+}
+
+// GetRecurringHandler handles fetching a single recurring template.
+func (h *RecurringHandler) GetRecurringHandler(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	recurringIDUint64, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || recurringIDUint64 == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid recurring transaction ID format"})
+		return
+	}
+
+	recurring, err := h.service.GetRecurringByID(userID, uint(recurringIDUint64))
+	if err != nil {
+		if strings.Contains(err.Error(), "recurring transaction not found") {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve recurring transaction: " + err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, recurring)
+}
+
+// ListRecurringHandler handles fetching the user's recurring templates with pagination.
+func (h *RecurringHandler) ListRecurringHandler(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit < 1 {
+		limit = 10
+	}
+	offset := (page - 1) * limit
+
+	recurring, err := h.service.GetRecurring(userID, offset, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve recurring transactions: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, recurring)
+}
+
+// UpdateRecurringHandler handles updating a recurring template. Changes only affect future occurrences.
+func (h *RecurringHandler) UpdateRecurringHandler(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	recurringIDUint64, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || recurringIDUint64 == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid recurring transaction ID format"})
+		return
+	}
+
+	var req models.RecurringUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+	if req.Amount == nil && req.Category == nil && req.Note == nil && req.EndDate == nil && req.Active == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "At least one field must be provided for update"})
+		return
+	}
+
+	recurring, err := h.service.UpdateRecurring(userID, uint(recurringIDUint64), &req)
+	if err != nil {
+		if strings.Contains(err.Error(), "recurring transaction not found") {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		} else if strings.Contains(err.Error(), "end_date") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update recurring transaction: " + err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, recurring)
+}
+
+// DeleteRecurringHandler handles deleting a recurring template. Rows it already generated are kept.
+func (h *RecurringHandler) DeleteRecurringHandler(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	recurringIDUint64, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || recurringIDUint64 == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid recurring transaction ID format"})
+		return
+	}
+
+	if err := h.service.DeleteRecurring(userID, uint(recurringIDUint64)); err != nil {
+		if strings.Contains(err.Error(), "recurring transaction not found") {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Recurring transaction not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete recurring transaction: " + err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
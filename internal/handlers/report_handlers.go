@@ -24,11 +24,11 @@ const defaultDateFormat = "2006-01-02"
 // GenerateCSVReportHandler handles the generation of a CSV transaction report.
 // Expects "startDate" and "endDate" query parameters in "YYYY-MM-DD" format.
 func (h *ReportHandler) GenerateCSVReportHandler(c *gin.Context) {
-	// userID, err := GetUserIDFromContext(c) // UserID no longer used by service
-	// if err != nil {
-	// 	c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found: " + err.Error()})
-	// 	return
-	// }
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
 
 	startDateStr := c.Query("startDate")
 	endDateStr := c.Query("endDate")
@@ -58,21 +58,9 @@ func (h *ReportHandler) GenerateCSVReportHandler(c *gin.Context) {
 	// For date-only columns, direct comparison is fine. If timestamped, endDate might need to be end of day.
 	// Assuming date columns in DB are DATE type, so direct comparison is fine.
 
-	// The placeholder UserID (1) from GetUserIDFromContext will be passed if not removed,
-	// but GenerateTransactionsCSV service method was updated to not expect it.
-	// For clarity, we can just pass a conceptual "default" user or remove if the service truly ignores it.
-	// Since service was updated, we don't need to pass userID.
-	// We need to ensure the service method GenerateTransactionsCSV signature was updated.
-	// Assuming it was: GenerateTransactionsCSV(startDate, endDate time.Time)
-
-	// Let's verify GenerateTransactionsCSV signature. Assuming it's (startDate, endDate).
-	// If it still expects userID, then GetUserIDFromContext() is needed here.
-	// Based on previous step "Adapt Data Models and Services", ReportService.GenerateTransactionsCSV
-	// should have been updated to not require UserID.
-	// So, the call becomes:
-	csvData, err := h.service.GenerateTransactionsCSV(startDate, endDate)
+	csvData, err := h.service.GenerateTransactionsCSV(userID, startDate, endDate)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate CSV report: " + err.Error()})
+		c.JSON(conversionErrorStatus(err), gin.H{"error": "Failed to generate CSV report: " + err.Error()})
 		return
 	}
 
@@ -89,11 +77,11 @@ func (h *ReportHandler) GenerateCSVReportHandler(c *gin.Context) {
 // GeneratePDFReportHandler handles the generation of a PDF transaction report.
 // Expects "startDate" and "endDate" query parameters in "YYYY-MM-DD" format.
 func (h *ReportHandler) GeneratePDFReportHandler(c *gin.Context) {
-	// userID, err := GetUserIDFromContext(c) // UserID no longer used by service
-	// if err != nil {
-	// 	c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found: " + err.Error()})
-	// 	return
-	// }
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
 
 	startDateStr := c.Query("startDate")
 	endDateStr := c.Query("endDate")
@@ -120,10 +108,9 @@ func (h *ReportHandler) GeneratePDFReportHandler(c *gin.Context) {
 		return
 	}
 
-	// Assuming GenerateTransactionsPDF service method was also updated.
-	pdfBuffer, err := h.service.GenerateTransactionsPDF(startDate, endDate)
+	pdfBuffer, err := h.service.GenerateTransactionsPDF(userID, startDate, endDate)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate PDF report: " + err.Error()})
+		c.JSON(conversionErrorStatus(err), gin.H{"error": "Failed to generate PDF report: " + err.Error()})
 		return
 	}
 
@@ -8,6 +8,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/zayyadi/finance-tracker/internal/models"
 	"github.com/zayyadi/finance-tracker/internal/services"
+	"github.com/zayyadi/finance-tracker/internal/types"
 )
 
 // SavingsHandler handles HTTP requests for savings goal records.
@@ -22,40 +23,41 @@ func NewSavingsHandler(service *services.SavingsService) *SavingsHandler {
 
 // CreateSavingsHandler handles the creation of a new savings goal.
 func (h *SavingsHandler) CreateSavingsHandler(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
 	var req models.SavingsCreateRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
 		return
 	}
 
-	currentAmount := 0.0
-	if req.CurrentAmount != nil {
-		currentAmount = *req.CurrentAmount
-	}
-	notes := ""
-	if req.Notes != nil {
-		notes = *req.Notes
-	}
-
-	savings := models.Savings{
-		GoalName:      req.GoalName,
-		GoalAmount:    req.GoalAmount,
-		CurrentAmount: currentAmount,
-		StartDate:     req.StartDate,
-		TargetDate:    req.TargetDate,
-		Notes:         notes,
-	}
+	savings := savingsFromRequest(userID, req)
 
 	if err := h.service.CreateSavings(&savings); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create savings goal: " + err.Error()})
+		if strings.Contains(err.Error(), "invalid tag") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create savings goal: " + err.Error()})
+		}
 		return
 	}
 
+	c.Header("ETag", recordETag(savings.UpdatedAt))
 	c.JSON(http.StatusCreated, savings)
 }
 
 // GetSavingsHandler handles fetching a single savings goal.
 func (h *SavingsHandler) GetSavingsHandler(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
 	savingsIDStr := c.Param("id")
 	savingsIDUint64, err := strconv.ParseUint(savingsIDStr, 10, 32)
 	if err != nil {
@@ -68,7 +70,7 @@ func (h *SavingsHandler) GetSavingsHandler(c *gin.Context) {
 		return
 	}
 
-	savings, err := h.service.GetSavingsByID(savingsID)
+	savings, err := h.service.GetSavingsByID(userID, savingsID)
 	if err != nil {
 		if strings.Contains(err.Error(), "savings goal not found") {
 			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
@@ -78,11 +80,19 @@ func (h *SavingsHandler) GetSavingsHandler(c *gin.Context) {
 		return
 	}
 
+	c.Header("ETag", recordETag(savings.UpdatedAt))
 	c.JSON(http.StatusOK, savings)
 }
 
-// ListSavingsHandler handles fetching all savings goals with pagination.
+// ListSavingsHandler handles fetching savings goals with pagination, filtered and sorted by the query
+// parameters read by parseListFilter.
 func (h *SavingsHandler) ListSavingsHandler(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
 	pageStr := c.DefaultQuery("page", "1")
 	limitStr := c.DefaultQuery("limit", "10")
 
@@ -94,18 +104,33 @@ func (h *SavingsHandler) ListSavingsHandler(c *gin.Context) {
 	if err != nil || limit < 1 {
 		limit = 10
 	}
-	offset := (page - 1) * limit
 
-	savingsList, err := h.service.GetSavings(offset, limit) // Changed from GetSavingsByUser
+	filter, err := parseListFilter(c)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve savings goals: " + err.Error()})
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	c.JSON(http.StatusOK, savingsList)
+
+	savingsList, err := h.service.GetSavings(userID, page, limit, filter)
+	if err != nil {
+		if isListFilterError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve savings goals: " + err.Error()})
+		}
+		return
+	}
+	writePage(c, savingsList)
 }
 
 // UpdateSavingsHandler handles updating an existing savings goal.
 func (h *SavingsHandler) UpdateSavingsHandler(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
 	savingsIDStr := c.Param("id")
 	savingsIDUint64, err := strconv.ParseUint(savingsIDStr, 10, 32)
 	if err != nil {
@@ -124,26 +149,38 @@ func (h *SavingsHandler) UpdateSavingsHandler(c *gin.Context) {
 		return
 	}
 	if req.GoalName == nil && req.GoalAmount == nil && req.CurrentAmount == nil &&
-		req.StartDate == nil && req.TargetDate == nil && req.Notes == nil {
+		req.Currency == nil && req.StartDate == nil && req.TargetDate == nil && req.Notes == nil && req.Tags == nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "At least one field must be provided for update"})
 		return
 	}
 
-	updatedSavings, err := h.service.UpdateSavings(savingsID, &req)
+	updatedSavings, err := h.service.UpdateSavings(userID, savingsID, &req, ifMatchVersions(c)...)
 	if err != nil {
+		if writeVersionConflict(c, err) {
+			return
+		}
 		if strings.Contains(err.Error(), "savings goal not found") {
 			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		} else if strings.Contains(err.Error(), "invalid tag") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		} else {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update savings goal: " + err.Error()})
 		}
 		return
 	}
 
+	c.Header("ETag", recordETag(updatedSavings.UpdatedAt))
 	c.JSON(http.StatusOK, updatedSavings)
 }
 
 // DeleteSavingsHandler handles deleting a savings goal.
 func (h *SavingsHandler) DeleteSavingsHandler(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
 	savingsIDStr := c.Param("id")
 	savingsIDUint64, err := strconv.ParseUint(savingsIDStr, 10, 32)
 	if err != nil {
@@ -156,8 +193,11 @@ func (h *SavingsHandler) DeleteSavingsHandler(c *gin.Context) {
 		return
 	}
 
-	err = h.service.DeleteSavings(savingsID)
+	err = h.service.DeleteSavings(userID, savingsID, ifMatchVersions(c)...)
 	if err != nil {
+		if writeVersionConflict(c, err) {
+			return
+		}
 		if strings.Contains(err.Error(), "savings goal not found") {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Savings goal not found"})
 		} else {
@@ -168,3 +208,100 @@ func (h *SavingsHandler) DeleteSavingsHandler(c *gin.Context) {
 
 	c.JSON(http.StatusNoContent, nil)
 }
+
+// savingsFromRequest returns the savings goal described by a create request.
+func savingsFromRequest(userID uint, req models.SavingsCreateRequest) models.Savings {
+	var currentAmount types.Money
+	if req.CurrentAmount != nil {
+		currentAmount = *req.CurrentAmount
+	}
+	notes := ""
+	if req.Notes != nil {
+		notes = *req.Notes
+	}
+
+	return models.Savings{
+		UserID:        userID,
+		GoalName:      req.GoalName,
+		GoalAmount:    req.GoalAmount,
+		Currency:      req.Currency,
+		CurrentAmount: currentAmount,
+		StartDate:     req.StartDate,
+		TargetDate:    req.TargetDate,
+		Notes:         notes,
+		Tags:          req.Tags,
+	}
+}
+
+// BulkCreateSavingsHandler handles creating several savings goals at once. They are created together or
+// not at all.
+func (h *SavingsHandler) BulkCreateSavingsHandler(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req models.BulkRequest[models.SavingsCreateRequest]
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+	savingsList := make([]models.Savings, len(req.Items))
+	for i, item := range req.Items {
+		savingsList[i] = savingsFromRequest(userID, item)
+	}
+
+	result, err := h.service.BulkCreateSavings(savingsList)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create savings goals: " + err.Error()})
+		return
+	}
+	writeBulkResult(c, result, http.StatusCreated)
+}
+
+// BulkUpdateSavingsHandler handles updating several savings goals at once. They are updated together or
+// not at all.
+func (h *SavingsHandler) BulkUpdateSavingsHandler(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req models.BulkRequest[models.SavingsBulkUpdateItem]
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	result, err := h.service.BulkUpdateSavings(userID, req.Items)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update savings goals: " + err.Error()})
+		return
+	}
+	writeBulkResult(c, result, http.StatusOK)
+}
+
+// BulkDeleteSavingsHandler handles deleting several savings goals at once. They are deleted together or
+// not at all.
+func (h *SavingsHandler) BulkDeleteSavingsHandler(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req models.BulkDeleteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	result, err := h.service.BulkDeleteSavings(userID, req.IDs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete savings goals: " + err.Error()})
+		return
+	}
+	writeBulkResult(c, result, http.StatusOK)
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/zayyadi/finance-tracker/internal/database"
 	"github.com/zayyadi/finance-tracker/internal/models"
 	"github.com/zayyadi/finance-tracker/internal/services"
+	"github.com/zayyadi/finance-tracker/internal/types"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
@@ -38,26 +39,27 @@ func setupSavingsTestRouter(t *testing.T) (*gin.Engine, *gorm.DB) {
 	db.Exec("DROP TABLE IF EXISTS Users") // In case of implicit dependencies or future use
 
 	// Auto-migrate schemas
-	err = db.AutoMigrate(&models.User{}, &models.Savings{})
+	err = db.AutoMigrate(&models.User{}, &models.Savings{}, &models.Tag{}, &models.Tagging{})
 	assert.NoError(t, err, "Failed to auto-migrate models")
 
 	// Create a dummy user if any FK constraints might apply implicitly or for other services
-	// For current Savings model, UserID is commented out, so direct FK is not an issue.
-	// However, creating one is good practice for a more complete test setup.
 	db.Create(&models.User{Username: "testuser", Email: "test@example.com", PasswordHash: "hash"})
 
-
 	savingsService := services.NewSavingsService(db)
 	savingsHandler := NewSavingsHandler(savingsService)
 
 	router := gin.Default()
+	// Stand in for the auth middleware: every request is made as the seeded test user.
+	router.Use(func(c *gin.Context) {
+		c.Set(userIDContextKey, uint(1))
+		c.Next()
+	})
 	// Register only the routes needed for these tests
 	router.POST("/savings", savingsHandler.CreateSavingsHandler)
 	router.PUT("/savings/:id", savingsHandler.UpdateSavingsHandler)
 	router.GET("/savings/:id", savingsHandler.GetSavingsHandler)
 	router.DELETE("/savings/:id", savingsHandler.DeleteSavingsHandler) // Added missing DELETE route
 
-
 	return router, db
 }
 
@@ -103,7 +105,7 @@ func TestCreateSavingsHandler_WithCustomDateFormat(t *testing.T) {
 		assert.Equal(t, expectedTime.Day(), dbGoal.TargetDate.Time.Day(), "DB Day mismatch")
 	}
 	assert.Equal(t, "Test Vacation", dbGoal.GoalName)
-	assert.Equal(t, 1200.50, dbGoal.GoalAmount)
+	assert.Equal(t, types.NewMoneyFromMinor(120050), dbGoal.GoalAmount, "JSON 1200.50 must be stored exactly")
 }
 
 func TestUpdateSavingsHandler_WithCustomDateFormat(t *testing.T) {
@@ -114,6 +116,7 @@ func TestUpdateSavingsHandler_WithCustomDateFormat(t *testing.T) {
 	initialTargetCustomDate, _ := time.Parse("2006-01-02", initialTargetDateStr)
 
 	initialGoal := models.Savings{
+		UserID:     1,
 		GoalName:   "Initial Goal",
 		GoalAmount: 500.00,
 		TargetDate: &database.CustomDate{Time: initialTargetCustomDate},
@@ -149,7 +152,6 @@ func TestUpdateSavingsHandler_WithCustomDateFormat(t *testing.T) {
 	}
 	assert.Equal(t, "Updated Holiday Plan", updatedGoalResp.GoalName, "GoalName mismatch in response")
 
-
 	// 3. Verify in DB
 	var dbGoal models.Savings
 	result := db.First(&dbGoal, initialGoal.ID)
@@ -196,7 +198,6 @@ func TestCreateSavingsHandler_WithNullStartDate(t *testing.T) {
 		assert.Equal(t, expectedTargetTime.Day(), createdGoal.TargetDate.Time.Day())
 	}
 
-
 	// Verify in DB
 	var dbGoal models.Savings
 	db.First(&dbGoal, createdGoal.ID)
@@ -207,37 +208,38 @@ func TestCreateSavingsHandler_WithNullStartDate(t *testing.T) {
 func TestUpdateSavingsHandler_SetDateToNull(t *testing.T) {
 	router, db := setupSavingsTestRouter(t)
 
-    // Seed an initial savings goal with a target date
+	// Seed an initial savings goal with a target date
 	initialTargetDate, _ := time.Parse("2006-01-02", "2025-01-01")
-    initialGoal := models.Savings{
-        GoalName:   "Goal to make TargetDate null",
-        GoalAmount: 100.0,
-        TargetDate: &database.CustomDate{Time: initialTargetDate},
-    }
-    db.Create(&initialGoal)
-    assert.NotZero(t, initialGoal.ID)
-
-    // Update TargetDate to null, and add another field to bypass "at least one field" check
-    updatePayload := `{ "target_date": null, "notes": "Set target date to null" }`
-    req, _ := http.NewRequest("PUT", "/savings/"+strconv.Itoa(int(initialGoal.ID)), bytes.NewBuffer([]byte(updatePayload)))
-    req.Header.Set("Content-Type", "application/json")
-    rr := httptest.NewRecorder()
-    router.ServeHTTP(rr, req)
-
-    assert.Equal(t, http.StatusOK, rr.Code)
-    var updatedGoalResp models.Savings
-    json.Unmarshal(rr.Body.Bytes(), &updatedGoalResp)
-    // This assertion might fail if GORM re-fetches and Scan results in a non-nil CustomDate with zero Time
-
-    // Verify in DB first - this is the most critical check
-    var dbGoal models.Savings
-    db.First(&dbGoal, initialGoal.ID)
-    assert.Nil(t, dbGoal.TargetDate, "TargetDate should be nil in DB after update to null")
-
-    // Then check response - if DB is nil, response should also be nil (or represent a zero time)
-    // After JSON unmarshalling `null` into a *database.CustomDate, the pointer might be non-nil
-    // but point to a CustomDate with a zero Time.
-    assert.True(t, updatedGoalResp.TargetDate == nil || updatedGoalResp.TargetDate.Time.IsZero(), "TargetDate should be effectively nil in response after update to null")
+	initialGoal := models.Savings{
+		UserID:     1,
+		GoalName:   "Goal to make TargetDate null",
+		GoalAmount: 100.0,
+		TargetDate: &database.CustomDate{Time: initialTargetDate},
+	}
+	db.Create(&initialGoal)
+	assert.NotZero(t, initialGoal.ID)
+
+	// Update TargetDate to null, and add another field to bypass "at least one field" check
+	updatePayload := `{ "target_date": null, "notes": "Set target date to null" }`
+	req, _ := http.NewRequest("PUT", "/savings/"+strconv.Itoa(int(initialGoal.ID)), bytes.NewBuffer([]byte(updatePayload)))
+	req.Header.Set("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var updatedGoalResp models.Savings
+	json.Unmarshal(rr.Body.Bytes(), &updatedGoalResp)
+	// This assertion might fail if GORM re-fetches and Scan results in a non-nil CustomDate with zero Time
+
+	// Verify in DB first - this is the most critical check
+	var dbGoal models.Savings
+	db.First(&dbGoal, initialGoal.ID)
+	assert.Nil(t, dbGoal.TargetDate, "TargetDate should be nil in DB after update to null")
+
+	// Then check response - if DB is nil, response should also be nil (or represent a zero time)
+	// After JSON unmarshalling `null` into a *database.CustomDate, the pointer might be non-nil
+	// but point to a CustomDate with a zero Time.
+	assert.True(t, updatedGoalResp.TargetDate == nil || updatedGoalResp.TargetDate.Time.IsZero(), "TargetDate should be effectively nil in response after update to null")
 }
 
 var invalidSavingsIDs = []string{"not-a-number", " ", "1.0", "1a2b", "-1", "0"}
@@ -304,7 +306,7 @@ func TestDeleteSavingsHandler_InvalidIDFormat(t *testing.T) {
 
 func TestGetSavingsHandler_ValidIDFormat_PassesParsing(t *testing.T) {
 	router, db := setupSavingsTestRouter(t)
-	dummySavings := models.Savings{GoalName:"Test", GoalAmount: 100}
+	dummySavings := models.Savings{UserID: 1, GoalName: "Test", GoalAmount: 100}
 	db.Create(&dummySavings)
 	validID := strconv.Itoa(int(dummySavings.ID))
 
@@ -320,7 +322,7 @@ func TestGetSavingsHandler_ValidIDFormat_PassesParsing(t *testing.T) {
 
 func TestUpdateSavingsHandler_ValidIDFormat_PassesParsing(t *testing.T) {
 	router, db := setupSavingsTestRouter(t)
-	dummySavings := models.Savings{GoalName:"Test", GoalAmount: 100}
+	dummySavings := models.Savings{UserID: 1, GoalName: "Test", GoalAmount: 100}
 	db.Create(&dummySavings)
 	validID := strconv.Itoa(int(dummySavings.ID))
 
@@ -338,7 +340,7 @@ func TestUpdateSavingsHandler_ValidIDFormat_PassesParsing(t *testing.T) {
 
 func TestDeleteSavingsHandler_ValidIDFormat_PassesParsing(t *testing.T) {
 	router, db := setupSavingsTestRouter(t)
-	dummySavings := models.Savings{GoalName:"Test", GoalAmount: 100}
+	dummySavings := models.Savings{UserID: 1, GoalName: "Test", GoalAmount: 100}
 	db.Create(&dummySavings)
 	validID := strconv.Itoa(int(dummySavings.ID))
 
@@ -351,3 +353,53 @@ func TestDeleteSavingsHandler_ValidIDFormat_PassesParsing(t *testing.T) {
 		return rr.Code == http.StatusNoContent || rr.Code == http.StatusNotFound
 	}, "Expected StatusNoContent or StatusNotFound for valid ID %s, but got %d", validID, rr.Code)
 }
+
+func TestSavingsHandlers_IfMatch(t *testing.T) {
+	router, _ := setupSavingsTestRouter(t)
+	send := func(method, path, etag, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		if etag != "" {
+			req.Header.Set("If-Match", etag)
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := send("POST", "/savings", "", `{"goal_name": "Bike", "goal_amount": 500}`)
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	var created models.Savings
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &created))
+	path := "/savings/" + strconv.Itoa(int(created.ID))
+	rr = send("GET", path, "", "")
+	fetched := rr.Header().Get("ETag")
+	assert.NotEmpty(t, fetched)
+	assert.Equal(t, fetched, send("GET", path, "", "").Header().Get("ETag"), "The ETag only changes with the record")
+
+	// The first of two browsers to save wins; the second is told the goal changed under it.
+	time.Sleep(time.Millisecond)
+	rr = send("PUT", path, fetched, `{"notes": "first"}`)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	saved := rr.Header().Get("ETag")
+	assert.NotEqual(t, fetched, saved)
+	assert.Equal(t, saved, send("GET", path, "", "").Header().Get("ETag"), "An update responds with the new ETag")
+
+	rr = send("PUT", path, fetched, `{"notes": "second"}`)
+	assert.Equal(t, http.StatusPreconditionFailed, rr.Code)
+	assert.Equal(t, saved, rr.Header().Get("ETag"))
+	rr = send("GET", path, "", "")
+	var current models.Savings
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &current))
+	assert.Equal(t, "first", current.Notes)
+
+	rr = send("PUT", path, `"other", `+saved, `{"notes": "second"}`)
+	assert.Equal(t, http.StatusOK, rr.Code, "Any of the listed ETags may match")
+	rr = send("PUT", "/savings/999", `*`, `{"notes": "second"}`)
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	rr = send("DELETE", path, saved, "")
+	assert.Equal(t, http.StatusPreconditionFailed, rr.Code)
+	rr = send("DELETE", path, "*", "")
+	assert.Equal(t, http.StatusNoContent, rr.Code)
+}
@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zayyadi/finance-tracker/internal/services"
+)
+
+// SearchHandler handles HTTP requests for searching records.
+type SearchHandler struct {
+	service *services.SearchService
+}
+
+// NewSearchHandler creates a new SearchHandler with the given service.
+func NewSearchHandler(service *services.SearchService) *SearchHandler {
+	return &SearchHandler{service: service}
+}
+
+// SearchHandler handles searching the text of the user's incomes, expenses, debts and savings goals,
+// best matches first, with pagination. "q" is the search query and "type" optionally limits the
+// search to one type of record.
+func (h *SearchHandler) SearchHandler(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	pageStr := c.DefaultQuery("page", "1")
+	limitStr := c.DefaultQuery("limit", "10")
+
+	page, err := strconv.Atoi(pageStr)
+	if err != nil || page < 1 {
+		page = 1
+	}
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit < 1 {
+		limit = 10
+	}
+	offset := (page - 1) * limit
+
+	results, err := h.service.Search(userID, c.Query("q"), c.Query("type"), offset, limit)
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid search") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search records: " + err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, results)
+}
@@ -22,11 +22,11 @@ func NewSummaryHandler(service *services.SummaryService) *SummaryHandler {
 // GetMonthlySummaryHandler handles requests for monthly financial summaries.
 // Expects a "date" query parameter in "YYYY-MM" format.
 func (h *SummaryHandler) GetMonthlySummaryHandler(c *gin.Context) {
-	// _, err := GetUserIDFromContext(c) // UserID no longer needed for service call
-	// if err != nil {
-	// 	c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
-	// 	return
-	// }
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
 
 	var req models.SummaryRequest
 	if err := c.ShouldBindQuery(&req); err != nil {
@@ -47,9 +47,9 @@ func (h *SummaryHandler) GetMonthlySummaryHandler(c *gin.Context) {
 		return
 	}
 
-	summary, err := h.service.GetOrCreateFinancialSummary("monthly", targetDate, view)
+	summary, err := h.service.GetOrCreateFinancialSummary(userID, "monthly", targetDate, view)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get or create monthly summary: " + err.Error()})
+		c.JSON(conversionErrorStatus(err), gin.H{"error": "Failed to get or create monthly summary: " + err.Error()})
 		return
 	}
 	c.JSON(http.StatusOK, summary)
@@ -58,11 +58,11 @@ func (h *SummaryHandler) GetMonthlySummaryHandler(c *gin.Context) {
 // GetWeeklySummaryHandler handles requests for weekly financial summaries.
 // Expects a "date" query parameter in "YYYY-MM-DD" format (any date within the desired week).
 func (h *SummaryHandler) GetWeeklySummaryHandler(c *gin.Context) {
-	// _, err := GetUserIDFromContext(c) // UserID no longer needed
-	// if err != nil {
-	// 	c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
-	// 	return
-	// }
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
 
 	var req models.SummaryRequest
 	if err := c.ShouldBindQuery(&req); err != nil {
@@ -83,9 +83,9 @@ func (h *SummaryHandler) GetWeeklySummaryHandler(c *gin.Context) {
 		return
 	}
 
-	summary, err := h.service.GetOrCreateFinancialSummary("weekly", targetDate, view)
+	summary, err := h.service.GetOrCreateFinancialSummary(userID, "weekly", targetDate, view)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get or create weekly summary: " + err.Error()})
+		c.JSON(conversionErrorStatus(err), gin.H{"error": "Failed to get or create weekly summary: " + err.Error()})
 		return
 	}
 	c.JSON(http.StatusOK, summary)
@@ -94,11 +94,11 @@ func (h *SummaryHandler) GetWeeklySummaryHandler(c *gin.Context) {
 // GetYearlySummaryHandler handles requests for yearly financial summaries.
 // Expects a "date" query parameter in "YYYY" format.
 func (h *SummaryHandler) GetYearlySummaryHandler(c *gin.Context) {
-	// _, err := GetUserIDFromContext(c) // UserID no longer needed
-	// if err != nil {
-	// 	c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
-	// 	return
-	// }
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
 
 	var req models.SummaryRequest
 	if err := c.ShouldBindQuery(&req); err != nil {
@@ -119,9 +119,9 @@ func (h *SummaryHandler) GetYearlySummaryHandler(c *gin.Context) {
 		return
 	}
 
-	summary, err := h.service.GetOrCreateFinancialSummary("yearly", targetDate, view)
+	summary, err := h.service.GetOrCreateFinancialSummary(userID, "yearly", targetDate, view)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get or create yearly summary: " + err.Error()})
+		c.JSON(conversionErrorStatus(err), gin.H{"error": "Failed to get or create yearly summary: " + err.Error()})
 		return
 	}
 	c.JSON(http.StatusOK, summary)
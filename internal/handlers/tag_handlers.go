@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zayyadi/finance-tracker/internal/models"
+	"github.com/zayyadi/finance-tracker/internal/services"
+)
+
+// TagHandler handles HTTP requests for tags.
+type TagHandler struct {
+	service *services.TagService
+}
+
+// NewTagHandler creates a new TagHandler with the given service.
+func NewTagHandler(service *services.TagService) *TagHandler {
+	return &TagHandler{service: service}
+}
+
+// ListTagsHandler handles fetching all of the user's tags with how often each is used.
+func (h *TagHandler) ListTagsHandler(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	tags, err := h.service.GetTags(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve tags: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, tags)
+}
+
+// RenameTagHandler handles renaming a tag. Renaming it to another existing tag merges the two.
+func (h *TagHandler) RenameTagHandler(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	tagIDUint64, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || tagIDUint64 == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tag ID format"})
+		return
+	}
+
+	var req models.TagUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	tag, err := h.service.RenameTag(userID, uint(tagIDUint64), req.Name)
+	if err != nil {
+		if strings.Contains(err.Error(), "tag not found") {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		} else if strings.Contains(err.Error(), "invalid tag") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to rename tag: " + err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, tag)
+}
+
+// DeleteTagHandler handles deleting a tag, which removes it from every record that carries it.
+func (h *TagHandler) DeleteTagHandler(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	tagIDUint64, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || tagIDUint64 == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tag ID format"})
+		return
+	}
+
+	if err := h.service.DeleteTag(userID, uint(tagIDUint64)); err != nil {
+		if strings.Contains(err.Error(), "tag not found") {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete tag: " + err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zayyadi/finance-tracker/internal/models"
+	"github.com/zayyadi/finance-tracker/internal/services"
+)
+
+// TransactionHandler handles HTTP requests for the combined income and expense ledger.
+type TransactionHandler struct {
+	service *services.TransactionService
+}
+
+// NewTransactionHandler creates a new TransactionHandler with the given service.
+func NewTransactionHandler(service *services.TransactionService) *TransactionHandler {
+	return &TransactionHandler{service: service}
+}
+
+// ListTransactionsHandler handles fetching a page of the user's incomes and expenses as one ledger
+// with running balances. Pages are chained with the "cursor" query parameter; "type", "startDate",
+// "endDate", "category", "min_amount", "max_amount", "q" and "order" filter and order the ledger.
+func (h *TransactionHandler) ListTransactionsHandler(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit < 1 {
+		limit = 10
+	}
+	filter := models.TransactionFilter{
+		Type:      c.Query("type"),
+		StartDate: c.Query("startDate"),
+		EndDate:   c.Query("endDate"),
+		Category:  c.Query("category"),
+		MinAmount: c.Query("min_amount"),
+		MaxAmount: c.Query("max_amount"),
+		Query:     c.Query("q"),
+		Order:     c.Query("order"),
+	}
+
+	page, err := h.service.GetTransactions(userID, filter, c.Query("cursor"), limit)
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve transactions: " + err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zayyadi/finance-tracker/internal/services"
+)
+
+// TrashHandler handles HTTP requests for deleted records.
+type TrashHandler struct {
+	service *services.TrashService
+}
+
+// NewTrashHandler creates a new TrashHandler with the given service.
+func NewTrashHandler(service *services.TrashService) *TrashHandler {
+	return &TrashHandler{service: service}
+}
+
+// trashErrorStatus maps errors from the trash service to HTTP statuses.
+func trashErrorStatus(err error) int {
+	switch {
+	case strings.Contains(err.Error(), "invalid trash type"):
+		return http.StatusBadRequest
+	case strings.Contains(err.Error(), "not found in trash"):
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
+
+// parseTrashRecord reads the record type and ID of a trash route. It responds with 400 and returns false
+// when the ID is invalid.
+func parseTrashRecord(c *gin.Context) (string, uint, bool) {
+	recordIDUint64, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || recordIDUint64 == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid record ID format"})
+		return "", 0, false
+	}
+	return c.Param("type"), uint(recordIDUint64), true
+}
+
+// ListTrashHandler handles listing the user's deleted records, most recently deleted first, with
+// pagination. "type" optionally limits the list to one type of record.
+func (h *TrashHandler) ListTrashHandler(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	pageStr := c.DefaultQuery("page", "1")
+	limitStr := c.DefaultQuery("limit", "10")
+
+	page, err := strconv.Atoi(pageStr)
+	if err != nil || page < 1 {
+		page = 1
+	}
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit < 1 {
+		limit = 10
+	}
+
+	items, err := h.service.GetTrash(userID, c.Query("type"), page, limit)
+	if err != nil {
+		status := trashErrorStatus(err)
+		if status == http.StatusInternalServerError {
+			c.JSON(status, gin.H{"error": "Failed to retrieve trash: " + err.Error()})
+		} else {
+			c.JSON(status, gin.H{"error": err.Error()})
+		}
+		return
+	}
+	writePage(c, items)
+}
+
+// RestoreTrashHandler handles bringing back a deleted record.
+func (h *TrashHandler) RestoreTrashHandler(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	recordType, recordID, ok := parseTrashRecord(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.RestoreRecord(userID, recordType, recordID); err != nil {
+		status := trashErrorStatus(err)
+		if status == http.StatusInternalServerError {
+			c.JSON(status, gin.H{"error": "Failed to restore record: " + err.Error()})
+		} else {
+			c.JSON(status, gin.H{"error": err.Error()})
+		}
+		return
+	}
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// PurgeTrashHandler handles removing a deleted record for good.
+func (h *TrashHandler) PurgeTrashHandler(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	recordType, recordID, ok := parseTrashRecord(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.PurgeRecord(userID, recordType, recordID); err != nil {
+		status := trashErrorStatus(err)
+		if status == http.StatusInternalServerError {
+			c.JSON(status, gin.H{"error": "Failed to purge record: " + err.Error()})
+		} else {
+			c.JSON(status, gin.H{"error": err.Error()})
+		}
+		return
+	}
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// EmptyTrashHandler handles removing all of the user's deleted records for good.
+func (h *TrashHandler) EmptyTrashHandler(c *gin.Context) {
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	purged, err := h.service.EmptyTrash(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to empty trash: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"purged": purged})
+}
@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zayyadi/finance-tracker/internal/middleware"
+	"github.com/zayyadi/finance-tracker/internal/models"
+	"github.com/zayyadi/finance-tracker/internal/services"
+)
+
+// userIDContextKey is the gin context key under which AuthMiddleware stores the authenticated user's ID.
+const userIDContextKey = middleware.UserIDContextKey
+
+// GetUserIDFromContext returns the authenticated user's ID set by AuthMiddleware.
+func GetUserIDFromContext(c *gin.Context) (uint, error) {
+	value, exists := c.Get(userIDContextKey)
+	if !exists {
+		return 0, fmt.Errorf("user ID not found in context")
+	}
+	userID, ok := value.(uint)
+	if !ok || userID == 0 {
+		return 0, fmt.Errorf("invalid user ID in context")
+	}
+	return userID, nil
+}
+
+// conversionErrorStatus maps errors from services that convert amounts into the base currency.
+// A missing exchange rate is a data problem the user can fix, so it is reported as 422.
+func conversionErrorStatus(err error) int {
+	if strings.Contains(err.Error(), "no exchange rate") {
+		return http.StatusUnprocessableEntity
+	}
+	return http.StatusInternalServerError
+}
+
+// listQueryParams are the query parameters the income, expense, debt and savings lists understand.
+// "startDate" and "endDate" are kept as aliases of "start_date" and "end_date" for older clients.
+var listQueryParams = map[string]bool{
+	"page": true, "limit": true,
+	"start_date": true, "end_date": true, "startDate": true, "endDate": true,
+	"category": true, "min_amount": true, "max_amount": true, "note": true, "tags": true,
+	"sort": true, "order": true,
+}
+
+// parseListFilter reads the filter and sort query parameters shared by the income, expense, debt and
+// savings lists. "category" and "tags" are comma-separated lists. Query parameters that are neither
+// shared nor in extra, the ones only that list reads, are rejected.
+func parseListFilter(c *gin.Context, extra ...string) (models.ListFilter, error) {
+	for name := range c.Request.URL.Query() {
+		if !listQueryParams[name] && !slices.Contains(extra, name) {
+			return models.ListFilter{}, fmt.Errorf("invalid filter: unknown query parameter %q", name)
+		}
+	}
+	tags, err := services.ParseTagFilter(c.Query("tags"))
+	if err != nil {
+		return models.ListFilter{}, err
+	}
+	var categories []string
+	for _, category := range strings.Split(c.Query("category"), ",") {
+		if strings.TrimSpace(category) != "" {
+			categories = append(categories, category)
+		}
+	}
+	return models.ListFilter{
+		StartDate:  strings.TrimSpace(c.DefaultQuery("start_date", c.Query("startDate"))),
+		EndDate:    strings.TrimSpace(c.DefaultQuery("end_date", c.Query("endDate"))),
+		Categories: categories,
+		MinAmount:  strings.TrimSpace(c.Query("min_amount")),
+		MaxAmount:  strings.TrimSpace(c.Query("max_amount")),
+		Note:       c.Query("note"),
+		Tags:       tags,
+		Sort:       strings.TrimSpace(c.Query("sort")),
+		Order:      strings.ToLower(strings.TrimSpace(c.Query("order"))),
+	}, nil
+}
+
+// isListFilterError reports whether a list service rejected the filter or sort query parameters.
+func isListFilterError(err error) bool {
+	for _, reason := range []string{"invalid filter", "invalid start date format", "invalid end date format", "invalid tag"} {
+		if strings.Contains(err.Error(), reason) {
+			return true
+		}
+	}
+	return false
+}
+
+// writePage responds with a page of a list. Links to the neighbouring pages repeat the request with
+// another "page" query parameter, and the X-Total-Count header carries the number of items on all pages.
+func writePage[T any](c *gin.Context, page *models.Page[T]) {
+	link := func(number int) string {
+		u := *c.Request.URL
+		query := u.Query()
+		query.Set("page", strconv.Itoa(number))
+		query.Set("limit", strconv.Itoa(page.Limit))
+		u.RawQuery = query.Encode()
+		return u.RequestURI()
+	}
+	if page.HasMore {
+		page.Next = link(page.Page + 1)
+	}
+	if page.Page > 1 {
+		page.Prev = link(page.Page - 1)
+	}
+	c.Header("X-Total-Count", strconv.FormatInt(page.Total, 10))
+	c.JSON(http.StatusOK, page)
+}
+
+// writeBulkResult responds with the outcome of a bulk request: status when it was applied, or 422 when an
+// item failed and nothing was saved.
+func writeBulkResult[T any](c *gin.Context, result *models.BulkResult[T], status int) {
+	if !result.Applied {
+		status = http.StatusUnprocessableEntity
+	}
+	c.JSON(status, result)
+}
+
+// invalidateSummariesAfterBulk drops, in the background, the stored summaries covering the dates of the
+// incomes or expenses changed by a bulk request. Each summary period is dropped once.
+func invalidateSummariesAfterBulk(summaryService *services.SummaryService, userID uint, dates []time.Time) {
+	if summaryService == nil || len(dates) == 0 {
+		return
+	}
+	go func() {
+		if err := summaryService.InvalidateSummariesForDates(userID, dates, []string{"monthly", "weekly", "yearly"}); err != nil {
+			log.Printf("Error invalidating summaries after bulk request: %v", err)
+		}
+	}()
+}
+
+// recordETag returns the entity tag of a record last changed at updatedAt. Timestamps are cut to the
+// microsecond, the precision PostgreSQL stores them at.
+func recordETag(updatedAt time.Time) string {
+	return `"` + strconv.FormatInt(updatedAt.UnixMicro(), 36) + `"`
+}
+
+// ifMatchVersions returns the versions of a record named by the If-Match header of a request to change
+// it. The service makes the change only while the record is at one of them, checking and changing it in
+// one step. It returns none, so the change always goes ahead, without the header or when it is "*".
+func ifMatchVersions(c *gin.Context) []time.Time {
+	ifMatch := c.GetHeader("If-Match")
+	if ifMatch == "" {
+		return nil
+	}
+	var versions []time.Time
+	for _, candidate := range strings.Split(ifMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return nil
+		}
+		if len(candidate) < 2 || candidate[0] != '"' || candidate[len(candidate)-1] != '"' {
+			continue // Not an entity tag of ours, so no record matches it
+		}
+		micros, err := strconv.ParseInt(candidate[1:len(candidate)-1], 36, 64)
+		if err != nil {
+			continue
+		}
+		versions = append(versions, time.UnixMicro(micros))
+	}
+	if len(versions) == 0 {
+		// No record was last changed at the zero time, so the change is refused.
+		versions = append(versions, time.Time{})
+	}
+	return versions
+}
+
+// writeVersionConflict responds with 412 and the current entity tag of the record when err says it has
+// been changed since the version named by If-Match, and reports whether it did.
+func writeVersionConflict(c *gin.Context, err error) bool {
+	var conflict *services.VersionConflictError
+	if !errors.As(err, &conflict) {
+		return false
+	}
+	c.Header("ETag", recordETag(conflict.UpdatedAt))
+	c.JSON(http.StatusPreconditionFailed, gin.H{"error": "The record has been changed since it was fetched; fetch it again and retry"})
+	return true
+}
@@ -33,7 +33,7 @@ func (vh *ViewHandler) ShowDashboardPage(c *gin.Context) {
 }
 
 // IsUserAuthenticated and GetUserFromContext are removed as they are no longer needed.
-// The GetUserIDFromContext in utils.go will be removed in a subsequent step if unused.
+// API authentication is handled by middleware.AuthMiddleware and GetUserIDFromContext in utils.go.
 /*
 // IsUserAuthenticated checks if a user is authenticated.
 // Since AuthMiddleware is removed, this will always return false or needs redefinition.
@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zayyadi/finance-tracker/internal/services"
+)
+
+// UserIDContextKey is the gin context key under which AuthMiddleware stores the authenticated user's ID.
+const UserIDContextKey = "userID"
+
+// AuthMiddleware validates the "Authorization: Bearer <token>" header and stores the
+// authenticated user's ID in the gin context under UserIDContextKey.
+// Requests without a valid access token are rejected with 401.
+func AuthMiddleware(authService *services.AuthService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authHeader := c.GetHeader("Authorization")
+		if authHeader == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Authorization header is required"})
+			return
+		}
+
+		parts := strings.SplitN(authHeader, " ", 2)
+		if len(parts) != 2 || !strings.EqualFold(parts[0], "Bearer") || strings.TrimSpace(parts[1]) == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Authorization header must be in the format 'Bearer <token>'"})
+			return
+		}
+
+		userID, err := authService.ValidateAccessToken(strings.TrimSpace(parts[1]))
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+			return
+		}
+
+		c.Set(UserIDContextKey, userID)
+		c.Next()
+	}
+}
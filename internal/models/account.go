@@ -0,0 +1,59 @@
+package models
+
+import (
+	"github.com/zayyadi/finance-tracker/internal/types"
+	"gorm.io/gorm"
+)
+
+// Account is a place money is held: a bank account, cash, or a card. Income and expenses
+// may be booked against an account, and transfers move money between accounts.
+type Account struct {
+	gorm.Model
+	UserID         uint        `json:"user_id" gorm:"not null;index"`
+	Name           string      `json:"name" gorm:"type:varchar(100);not null"`
+	Type           string      `json:"type" gorm:"type:varchar(20);not null"`                  // bank, cash or card
+	Currency       string      `json:"currency" gorm:"type:varchar(3);not null;default:'USD'"` // Every amount booked against the account is in this currency
+	OpeningBalance types.Money `json:"opening_balance" gorm:"not null;default:0"`
+}
+
+// AccountCreateRequest defines the expected request body for creating an account.
+type AccountCreateRequest struct {
+	Name           string      `json:"name" binding:"required,max=100"`
+	Type           string      `json:"type" binding:"required,oneof=bank cash card"`
+	Currency       string      `json:"currency,omitempty" binding:"omitempty,iso4217"` // Defaults to the user's base currency
+	OpeningBalance types.Money `json:"opening_balance"`                                // May be negative, e.g. a card with an outstanding balance
+}
+
+// AccountUpdateRequest defines the expected request body for updating an account.
+// The currency cannot change once transactions have been booked in it.
+type AccountUpdateRequest struct {
+	Name           *string      `json:"name,omitempty" binding:"omitempty,max=100"`
+	Type           *string      `json:"type,omitempty" binding:"omitempty,oneof=bank cash card"`
+	OpeningBalance *types.Money `json:"opening_balance,omitempty"`
+}
+
+// AccountWithBalance is an account together with its current balance: the opening balance plus
+// income and incoming transfers, minus expenses and outgoing transfers.
+type AccountWithBalance struct {
+	Account
+	Balance types.Money `json:"balance"`
+}
+
+// AccountEntry is one line of an account's ledger, with the running balance after it.
+type AccountEntry struct {
+	Type     string      `json:"type"` // income, expense, transfer_in or transfer_out
+	ID       uint        `json:"id"`   // ID of the income, expense or transfer
+	Date     string      `json:"date"` // YYYY-MM-DD
+	Category string      `json:"category,omitempty"`
+	Note     string      `json:"note,omitempty"`
+	Amount   types.Money `json:"amount"` // Signed: negative for money leaving the account
+	Balance  types.Money `json:"balance"`
+}
+
+// AccountLedgerResponse is the response body of the account ledger endpoint.
+type AccountLedgerResponse struct {
+	Account        Account        `json:"account"`
+	OpeningBalance types.Money    `json:"opening_balance"`
+	Entries        []AccountEntry `json:"entries"`
+	Balance        types.Money    `json:"balance"`
+}
@@ -1,14 +1,28 @@
 package models
 
+import "github.com/zayyadi/finance-tracker/internal/types"
+
 // CategoryExpenseStat represents the total expenses for a category.
 type CategoryExpenseStat struct {
-	Category    string  `json:"category"`
-	TotalAmount float64 `json:"total_amount"`
+	Category    string      `json:"category"`
+	CategoryID  *uint       `json:"category_id,omitempty"`
+	Color       string      `json:"color,omitempty"`
+	TotalAmount types.Money `json:"total_amount"`
+	Currency    string      `json:"currency"` // The user's base currency
 }
 
 // MonthlyTrendStat represents the income and expenses for a month.
 type MonthlyTrendStat struct {
-	Month         string  `json:"month"` // Format: YYYY-MM
-	TotalIncome   float64 `json:"total_income"`
-	TotalExpenses float64 `json:"total_expenses"`
+	Month         string      `json:"month"` // Format: YYYY-MM
+	TotalIncome   types.Money `json:"total_income"`
+	TotalExpenses types.Money `json:"total_expenses"`
+	Currency      string      `json:"currency"` // The user's base currency
+}
+
+// TagStat represents the income and expenses carrying a tag in a month.
+type TagStat struct {
+	Tag           string      `json:"tag"`
+	TotalIncome   types.Money `json:"total_income"`
+	TotalExpenses types.Money `json:"total_expenses"`
+	Currency      string      `json:"currency"` // The user's base currency
 }
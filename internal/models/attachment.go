@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// Attachment is a file, such as a receipt photo or a signed IOU, attached to an income, expense or
+// debt. The file itself is kept in the attachment storage under StorageKey.
+type Attachment struct {
+	ID          uint      `json:"id" gorm:"primarykey"`
+	CreatedAt   time.Time `json:"created_at"`
+	UserID      uint      `json:"user_id" gorm:"not null;index"`
+	RecordType  string    `json:"record_type" gorm:"type:varchar(10);not null;index:idx_attachments_record"` // income, expense or debt
+	RecordID    uint      `json:"record_id" gorm:"not null;index:idx_attachments_record"`
+	FileName    string    `json:"file_name" gorm:"type:varchar(255);not null"`
+	ContentType string    `json:"content_type" gorm:"type:varchar(100);not null"` // Detected from the contents, not taken from the upload
+	Size        int64     `json:"size"`                                           // In bytes
+	StorageKey  string    `json:"-" gorm:"type:varchar(255);not null;uniqueIndex"`
+}
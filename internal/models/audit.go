@@ -0,0 +1,77 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// AuditEntry records one change to an income, expense, debt or savings goal: who made it, how, and
+// what each changed column held before and after.
+type AuditEntry struct {
+	ID         uint         `json:"id" gorm:"primarykey"`
+	CreatedAt  time.Time    `json:"created_at" gorm:"index"`
+	UserID     uint         `json:"user_id" gorm:"not null;index:idx_audit_entries_record"`                      // Owner of the record
+	RecordType string       `json:"record_type" gorm:"type:varchar(10);not null;index:idx_audit_entries_record"` // income, expense, debt or savings
+	RecordID   uint         `json:"record_id" gorm:"not null;index:idx_audit_entries_record"`
+	Action     string       `json:"action" gorm:"type:varchar(10);not null"` // create, update, delete, restore or purge
+	ActorID    *uint        `json:"actor_id,omitempty"`                      // The user who made the change; absent for scheduled jobs
+	Source     string       `json:"source" gorm:"type:varchar(20);not null"` // api, bulk, import, recurring or retention
+	Changes    AuditChanges `json:"changes" gorm:"type:text"`
+}
+
+// AuditChange is the value of a column before and after a change, as JSON. A column that did not
+// exist on one side, such as before a create, is null there.
+type AuditChange struct {
+	Before json.RawMessage `json:"before"`
+	After  json.RawMessage `json:"after"`
+}
+
+// AuditChanges maps the columns a change touched to their values before and after it. It is stored as
+// a JSON object.
+type AuditChanges map[string]AuditChange
+
+// Value implements the driver.Valuer interface.
+func (c AuditChanges) Value() (driver.Value, error) {
+	if c == nil {
+		return "{}", nil
+	}
+	data, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan implements the sql.Scanner interface.
+func (c *AuditChanges) Scan(value interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*c = AuditChanges{}
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into AuditChanges", value)
+	}
+	changes := AuditChanges{}
+	if err := json.Unmarshal(data, &changes); err != nil {
+		return fmt.Errorf("cannot scan AuditChanges: %w", err)
+	}
+	*c = changes
+	return nil
+}
+
+// AuditFilter narrows the audit log. Empty fields do not filter.
+type AuditFilter struct {
+	Type      string // income, expense, debt or savings
+	RecordID  uint
+	Action    string // create, update, delete, restore or purge
+	Source    string // api, bulk, import, recurring or retention
+	StartDate string // YYYY-MM-DD, inclusive
+	EndDate   string // YYYY-MM-DD, inclusive
+}
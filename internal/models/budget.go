@@ -0,0 +1,54 @@
+package models
+
+import (
+	"github.com/zayyadi/finance-tracker/internal/types"
+	"gorm.io/gorm"
+)
+
+// Budget is a spending limit for one expense category over a period, e.g. "Food: 400/month".
+// A user has at most one budget per category and period.
+type Budget struct {
+	gorm.Model
+	UserID   uint        `json:"user_id" gorm:"not null;uniqueIndex:idx_user_budget_category_period"`
+	Category string      `json:"category" gorm:"not null;uniqueIndex:idx_user_budget_category_period"`
+	Period   string      `json:"period" gorm:"type:varchar(10);not null;uniqueIndex:idx_user_budget_category_period"` // weekly, monthly or yearly
+	Amount   types.Money `json:"amount" gorm:"not null;default:0"`
+	Currency string      `json:"currency" gorm:"type:varchar(3);not null;default:'USD'"`
+}
+
+// BudgetCreateRequest defines the expected request body for creating a budget.
+type BudgetCreateRequest struct {
+	Category string      `json:"category" binding:"required"`
+	Period   string      `json:"period,omitempty" binding:"omitempty,oneof=weekly monthly yearly"` // Defaults to monthly
+	Amount   types.Money `json:"amount" binding:"required,gt=0"`
+	Currency string      `json:"currency,omitempty" binding:"omitempty,iso4217"` // Defaults to the user's base currency
+}
+
+// BudgetUpdateRequest defines the expected request body for updating a budget.
+type BudgetUpdateRequest struct {
+	Amount   *types.Money `json:"amount,omitempty" binding:"omitempty,gt=0"`
+	Currency *string      `json:"currency,omitempty" binding:"omitempty,iso4217"`
+}
+
+// BudgetStatus compares one budget with the actual spending in its category, in the user's base currency.
+type BudgetStatus struct {
+	BudgetID       uint        `json:"budget_id"`
+	Category       string      `json:"category"`
+	Budgeted       types.Money `json:"budgeted"`
+	Spent          types.Money `json:"spent"`
+	Remaining      types.Money `json:"remaining"`       // Negative once the budget is exceeded
+	PercentUsed    float64     `json:"percent_used"`    // Spent as a percentage of Budgeted, e.g. 62.5
+	ProjectedSpend types.Money `json:"projected_spend"` // Spent extrapolated to the end of the period at the current daily rate
+	OverBudget     bool        `json:"over_budget"`
+}
+
+// BudgetStatusResponse is the response body of the budget status endpoint.
+type BudgetStatusResponse struct {
+	Period      string         `json:"period"`
+	StartDate   string         `json:"start_date"` // YYYY-MM-DD
+	EndDate     string         `json:"end_date"`   // YYYY-MM-DD
+	DaysElapsed int            `json:"days_elapsed"`
+	DaysTotal   int            `json:"days_total"`
+	Currency    string         `json:"currency"` // The user's base currency
+	Budgets     []BudgetStatus `json:"budgets"`
+}
@@ -0,0 +1,66 @@
+package models
+
+import "time"
+
+// MaxBulkItems is the largest number of items accepted by one bulk request.
+const MaxBulkItems = 100
+
+// BulkRequest is the body of a bulk create or update. The items are saved together or not at all.
+type BulkRequest[T any] struct {
+	Items []T `json:"items" binding:"required,min=1,max=100,dive"`
+}
+
+// BulkDeleteRequest is the body of a bulk delete.
+type BulkDeleteRequest struct {
+	IDs []uint `json:"ids" binding:"required,min=1,max=100,unique,dive,gt=0"`
+}
+
+// IncomeBulkUpdateItem is one income record to update in a bulk update.
+type IncomeBulkUpdateItem struct {
+	ID uint `json:"id" binding:"required"`
+	IncomeUpdateRequest
+}
+
+// ExpenseBulkUpdateItem is one expense record to update in a bulk update.
+type ExpenseBulkUpdateItem struct {
+	ID uint `json:"id" binding:"required"`
+	ExpenseUpdateRequest
+}
+
+// DebtBulkUpdateItem is one debt record to update in a bulk update.
+type DebtBulkUpdateItem struct {
+	ID uint `json:"id" binding:"required"`
+	DebtUpdateRequest
+}
+
+// SavingsBulkUpdateItem is one savings goal to update in a bulk update.
+type SavingsBulkUpdateItem struct {
+	ID uint `json:"id" binding:"required"`
+	SavingsUpdateRequest
+}
+
+// Statuses of the items of a bulk request.
+const (
+	BulkStatusCreated    = "created"
+	BulkStatusUpdated    = "updated"
+	BulkStatusDeleted    = "deleted"
+	BulkStatusFailed     = "failed"
+	BulkStatusNotApplied = "not_applied" // The item succeeded, but was rolled back because another one failed
+)
+
+// BulkItemResult is the outcome of one item of a bulk request.
+type BulkItemResult[T any] struct {
+	Index  int    `json:"index"`           // Position of the item in the request, counting from 0
+	ID     uint   `json:"id,omitempty"`    // Record the item updates or deletes
+	Status string `json:"status"`          // One of the BulkStatus constants
+	Error  string `json:"error,omitempty"` // Why the item failed
+	Item   *T     `json:"item,omitempty"`  // The record as saved; absent for deletions and items not applied
+}
+
+// BulkResult is the outcome of a bulk request, with one result per item in the order of the request.
+type BulkResult[T any] struct {
+	Applied bool                `json:"applied"` // Whether the changes were saved, which they are only if no item failed
+	Failed  int                 `json:"failed"`  // Number of items that failed
+	Results []BulkItemResult[T] `json:"results"`
+	Dates   []time.Time         `json:"-"` // Dates of the incomes and expenses changed, old and new, whose summaries are stale
+}
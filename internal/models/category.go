@@ -0,0 +1,40 @@
+package models
+
+import "gorm.io/gorm"
+
+// Category is one of a user's income or expense categories. Categories nest: a subcategory points at
+// its parent, which must be of the same kind, and analytics can roll subcategories up into their
+// top-level category. Names are unique per user and kind, ignoring case.
+type Category struct {
+	gorm.Model
+	UserID   uint   `json:"user_id" gorm:"not null;uniqueIndex:idx_categories_user_kind_name"`
+	Kind     string `json:"kind" gorm:"type:varchar(10);not null;uniqueIndex:idx_categories_user_kind_name"` // income or expense
+	Name     string `json:"name" gorm:"type:varchar(100);not null;uniqueIndex:idx_categories_user_kind_name"`
+	ParentID *uint  `json:"parent_id,omitempty" gorm:"index"`
+	Color    string `json:"color,omitempty" gorm:"type:varchar(7)"` // #RRGGBB
+	Icon     string `json:"icon,omitempty" gorm:"type:varchar(50)"`
+}
+
+// CategoryCreateRequest defines the expected request body for creating a category.
+type CategoryCreateRequest struct {
+	Name     string `json:"name" binding:"required,max=100"`
+	Kind     string `json:"kind" binding:"required,oneof=income expense"`
+	ParentID *uint  `json:"parent_id,omitempty"`
+	Color    string `json:"color,omitempty" binding:"omitempty,hexcolor,len=7"`
+	Icon     string `json:"icon,omitempty" binding:"omitempty,max=50"`
+}
+
+// CategoryUpdateRequest defines the expected request body for updating a category. All fields are
+// optional; the kind of a category cannot change.
+type CategoryUpdateRequest struct {
+	Name     *string `json:"name,omitempty" binding:"omitempty,max=100"` // Renaming also renames the category on its entries, budgets, recurring transactions and rules
+	ParentID *uint   `json:"parent_id,omitempty"`                        // 0 makes it a top-level category
+	Color    *string `json:"color,omitempty" binding:"omitempty,hexcolor,len=7"`
+	Icon     *string `json:"icon,omitempty" binding:"omitempty,max=50"`
+}
+
+// CategoryNode is a category together with its subcategories.
+type CategoryNode struct {
+	Category
+	Children []CategoryNode `json:"children"`
+}
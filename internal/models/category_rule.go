@@ -0,0 +1,71 @@
+package models
+
+import (
+	"github.com/zayyadi/finance-tracker/internal/types"
+	"gorm.io/gorm"
+)
+
+// CategoryRule assigns a category to incomes or expenses that match its conditions: text in the note,
+// a regular expression on the note, and/or an amount range. Every condition that is set must match.
+// Rules are tried in ascending priority order and the first match wins.
+type CategoryRule struct {
+	gorm.Model
+	UserID       uint         `json:"user_id" gorm:"not null;index:idx_category_rules_user_priority"`
+	Name         string       `json:"name" gorm:"type:varchar(100);not null"`
+	Priority     int          `json:"priority" gorm:"not null;default:0;index:idx_category_rules_user_priority"` // Lower numbers are tried first
+	Type         string       `json:"type" gorm:"type:varchar(10);not null;default:'expense'"`                   // income, expense or both
+	NoteContains string       `json:"note_contains,omitempty"`                                                   // Case-insensitive
+	NoteRegex    string       `json:"note_regex,omitempty"`                                                      // Go regular expression syntax; (?i) makes it case-insensitive
+	MinAmount    *types.Money `json:"min_amount,omitempty"`                                                      // Inclusive
+	MaxAmount    *types.Money `json:"max_amount,omitempty"`                                                      // Inclusive
+	Currency     string       `json:"currency,omitempty" gorm:"type:varchar(3)"`                                 // Only match amounts in this currency
+	Category     string       `json:"category" gorm:"not null"`
+	Active       bool         `json:"active" gorm:"not null"`
+}
+
+// CategoryRuleRequest defines the expected request body for creating, replacing or testing a rule.
+// At least one of note_contains, note_regex, min_amount and max_amount must be set.
+type CategoryRuleRequest struct {
+	Name         string       `json:"name" binding:"required,max=100"`
+	Priority     int          `json:"priority,omitempty"`
+	Type         string       `json:"type,omitempty" binding:"omitempty,oneof=income expense both"` // Defaults to expense
+	NoteContains string       `json:"note_contains,omitempty"`
+	NoteRegex    string       `json:"note_regex,omitempty"`
+	MinAmount    *types.Money `json:"min_amount,omitempty" binding:"omitempty,gte=0"`
+	MaxAmount    *types.Money `json:"max_amount,omitempty" binding:"omitempty,gte=0"`
+	Currency     string       `json:"currency,omitempty" binding:"omitempty,iso4217"`
+	Category     string       `json:"category" binding:"required"`
+	Active       *bool        `json:"active,omitempty"` // Defaults to true
+}
+
+// CategoryRuleMatch is an existing income or expense matched by a rule.
+type CategoryRuleMatch struct {
+	Type        string      `json:"type"` // income or expense
+	ID          uint        `json:"id"`
+	Date        string      `json:"date"` // YYYY-MM-DD
+	Amount      types.Money `json:"amount"`
+	Currency    string      `json:"currency"`
+	Category    string      `json:"category"` // The current category
+	Note        string      `json:"note,omitempty"`
+	WouldChange bool        `json:"would_change"` // Whether the rule's category differs from the current one
+}
+
+// CategoryRuleTestResult is the response body of testing a rule against the existing history.
+type CategoryRuleTestResult struct {
+	Matched      int                 `json:"matched"`      // All matching entries
+	WouldChange  int                 `json:"would_change"` // Matching entries with a different category
+	Transactions []CategoryRuleMatch `json:"transactions"` // The most recent matches, up to the limit
+}
+
+// CategoryRuleApplyRequest defines the optional request body for re-applying rules to existing entries.
+type CategoryRuleApplyRequest struct {
+	Overwrite bool `json:"overwrite,omitempty"` // Also recategorize entries whose category was chosen by hand
+	DryRun    bool `json:"dry_run,omitempty"`
+}
+
+// CategoryRuleApplyResult is the response body of re-applying rules to existing entries.
+type CategoryRuleApplyResult struct {
+	DryRun   bool `json:"dry_run"`
+	Examined int  `json:"examined"`
+	Updated  int  `json:"updated"`
+}
@@ -2,34 +2,41 @@ package models
 
 import (
 	"github.com/zayyadi/finance-tracker/internal/database" // Corrected import
+	"github.com/zayyadi/finance-tracker/internal/types"
 	"gorm.io/gorm"
 )
 
 // Debt represents a debt owed by or to the user.
 type Debt struct {
 	gorm.Model
-	// UserID      uint      `json:"user_id" gorm:"not null;index"` // Removed
+	UserID      uint                `json:"user_id" gorm:"not null;index"`
 	DebtorName  string              `json:"debtor_name" binding:"required" gorm:"not null"`
 	Description string              `json:"description,omitempty"`
-	Amount      float64             `json:"amount" binding:"required,gt=0" gorm:"not null;default:0"`
-	DueDate     database.CustomDate `json:"due_date" binding:"required" gorm:"not null"`
+	Amount      types.Money         `json:"amount" binding:"required,gt=0" gorm:"not null;default:0"`
+	Currency    string              `json:"currency" gorm:"type:varchar(3);not null;default:'USD'"` // ISO 4217 code of the amounts
+	DueDate     database.CustomDate `json:"due_date" binding:"required" gorm:"type:date;not null;index"`
 	Status      string              `json:"status" binding:"required,oneof=Pending Paid Overdue" gorm:"not null;default:'Pending'"`
+	Tags        []string            `json:"tags,omitempty" gorm:"-"`
 }
 
 // DebtCreateRequest is used for creating a new debt record.
 type DebtCreateRequest struct {
 	DebtorName  string              `json:"debtor_name" binding:"required"`
 	Description *string             `json:"description,omitempty"`
-	Amount      float64             `json:"amount" binding:"required,gt=0"`
+	Amount      types.Money         `json:"amount" binding:"required,gt=0"`
+	Currency    string              `json:"currency,omitempty" binding:"omitempty,iso4217"` // Defaults to the user's base currency
 	DueDate     database.CustomDate `json:"due_date" binding:"required"`
 	Status      *string             `json:"status,omitempty" binding:"omitempty,oneof=Pending Paid Overdue"` // Defaults to 'Pending' in service
+	Tags        []string            `json:"tags,omitempty" binding:"omitempty,dive,max=50"`
 }
 
 // DebtUpdateRequest is used for updating an existing debt record.
 type DebtUpdateRequest struct {
 	DebtorName  *string              `json:"debtor_name,omitempty"`
 	Description *string              `json:"description,omitempty"` // Pointer to allow explicitly setting to empty vs. not providing
-	Amount      *float64             `json:"amount,omitempty" binding:"omitempty,gt=0"`
+	Amount      *types.Money         `json:"amount,omitempty" binding:"omitempty,gt=0"`
+	Currency    *string              `json:"currency,omitempty" binding:"omitempty,iso4217"`
 	DueDate     *database.CustomDate `json:"due_date,omitempty"`
 	Status      *string              `json:"status,omitempty" binding:"omitempty,oneof=Pending Paid Overdue"`
+	Tags        *[]string            `json:"tags,omitempty" binding:"omitempty,dive,max=50"` // Replaces all tags; [] removes them
 }
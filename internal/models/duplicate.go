@@ -0,0 +1,54 @@
+package models
+
+import (
+	"time"
+
+	"github.com/zayyadi/finance-tracker/internal/types"
+	"gorm.io/gorm"
+)
+
+// DuplicatePair flags two incomes or two expenses that look like the same transaction recorded twice:
+// the same amount and currency, dates a few days apart at most, and a matching note or category.
+// A pair is flagged once; after it is merged or dismissed it is not flagged again.
+type DuplicatePair struct {
+	gorm.Model
+	UserID        uint       `json:"user_id" gorm:"not null;uniqueIndex:idx_duplicate_pairs_user_pair;index:idx_duplicate_pairs_user_status"`
+	Type          string     `json:"type" gorm:"type:varchar(10);not null;uniqueIndex:idx_duplicate_pairs_user_pair"` // income or expense
+	TransactionID uint       `json:"transaction_id" gorm:"not null;uniqueIndex:idx_duplicate_pairs_user_pair"`        // The later of the two entries
+	DuplicateOfID uint       `json:"duplicate_of_id" gorm:"not null;uniqueIndex:idx_duplicate_pairs_user_pair"`       // The earlier entry it appears to duplicate
+	DaysApart     int        `json:"days_apart" gorm:"not null;default:0"`
+	MatchedOn     string     `json:"matched_on" gorm:"type:varchar(20);not null"`                                                     // note, category or note+category
+	Status        string     `json:"status" gorm:"type:varchar(20);not null;default:'pending';index:idx_duplicate_pairs_user_status"` // pending, merged or dismissed
+	KeptID        *uint      `json:"kept_id,omitempty"`                                                                               // The entry kept by a merge
+	ResolvedAt    *time.Time `json:"resolved_at,omitempty"`
+}
+
+// DuplicateTransaction is one side of a duplicate pair.
+type DuplicateTransaction struct {
+	ID         uint        `json:"id"`
+	Date       string      `json:"date"` // YYYY-MM-DD
+	Amount     types.Money `json:"amount"`
+	Currency   string      `json:"currency"`
+	AccountID  *uint       `json:"account_id,omitempty"`
+	Category   string      `json:"category"`
+	Note       string      `json:"note,omitempty"`
+	ExternalID *string     `json:"external_id,omitempty"`
+	Deleted    bool        `json:"deleted,omitempty"` // Deleted since the pair was flagged, e.g. by merging it
+}
+
+// DuplicatePairResponse is a duplicate pair together with the two entries it is about.
+type DuplicatePairResponse struct {
+	DuplicatePair
+	Transaction *DuplicateTransaction `json:"transaction"`
+	DuplicateOf *DuplicateTransaction `json:"duplicate_of"`
+}
+
+// DuplicateMergeRequest defines the optional request body for merging a duplicate pair.
+type DuplicateMergeRequest struct {
+	KeepID *uint `json:"keep_id,omitempty"` // transaction_id or duplicate_of_id; defaults to duplicate_of_id, the earlier entry
+}
+
+// DuplicateScanResult is the response body of a scan for duplicates in the existing history.
+type DuplicateScanResult struct {
+	Flagged int `json:"flagged"` // Newly flagged pairs
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"github.com/zayyadi/finance-tracker/internal/database"
+	"gorm.io/gorm"
+)
+
+// ExchangeRate is the rate to convert one unit of FromCurrency into ToCurrency, effective from Date
+// until a later rate for the same pair. Rates are per user, like every other record.
+type ExchangeRate struct {
+	gorm.Model
+	UserID       uint                `json:"user_id" gorm:"not null;index;uniqueIndex:idx_user_rate_pair_date"`
+	FromCurrency string              `json:"from_currency" gorm:"type:varchar(3);not null;uniqueIndex:idx_user_rate_pair_date"`
+	ToCurrency   string              `json:"to_currency" gorm:"type:varchar(3);not null;uniqueIndex:idx_user_rate_pair_date"`
+	Rate         float64             `json:"rate" gorm:"not null"`
+	Date         database.CustomDate `json:"date" gorm:"type:date;not null;uniqueIndex:idx_user_rate_pair_date"`
+}
+
+// ExchangeRateCreateRequest defines the expected request body for adding or replacing a single rate.
+type ExchangeRateCreateRequest struct {
+	FromCurrency string              `json:"from_currency" binding:"required,iso4217"`
+	ToCurrency   string              `json:"to_currency" binding:"required,iso4217,nefield=FromCurrency"`
+	Rate         float64             `json:"rate" binding:"required,gt=0"`
+	Date         database.CustomDate `json:"date" binding:"required"`
+}
+
+// ExchangeRateImportResponse reports the outcome of a CSV rate import.
+type ExchangeRateImportResponse struct {
+	Imported int `json:"imported"`
+}
@@ -1,33 +1,76 @@
 package models
 
 import (
+	"time"
+
 	"github.com/zayyadi/finance-tracker/internal/database" // Corrected import
+	"github.com/zayyadi/finance-tracker/internal/types"
 	"gorm.io/gorm"
 )
 
 // Expense struct corresponds to the Expenses table schema.
 type Expense struct {
 	gorm.Model
-	// UserID   uint    `json:"user_id" gorm:"not null;index"` // Removed
-	Amount   float64            `json:"amount" binding:"required,gt=0" gorm:"not null;default:0"`
-	Category string             `json:"category" binding:"required" gorm:"not null"`
-	Date     database.CustomDate `json:"date" binding:"required" gorm:"not null"`
-	Note     string             `json:"note,omitempty"`
+	UserID              uint                `json:"user_id" gorm:"not null;index;uniqueIndex:idx_expenses_user_external_id"`
+	Amount              types.Money         `json:"amount" binding:"required,gt=0" gorm:"not null;default:0"`
+	Currency            string              `json:"currency" gorm:"type:varchar(3);not null;default:'USD'"`                                   // ISO 4217 code of the amounts
+	AccountID           *uint               `json:"account_id,omitempty" gorm:"index"`                                                        // Optional account the expense was paid from
+	RecurringID         *uint               `json:"recurring_id,omitempty" gorm:"uniqueIndex:idx_expenses_recurring_date"`                    // Set on rows generated from a recurring template
+	ExternalID          *string             `json:"external_id,omitempty" gorm:"type:varchar(255);uniqueIndex:idx_expenses_user_external_id"` // The bank's transaction ID on imported rows
+	Category            string              `json:"category" binding:"required" gorm:"not null"`
+	CategoryID          *uint               `json:"category_id,omitempty" gorm:"index"` // The category named by Category
+	CategoryRuleID      *uint               `json:"category_rule_id,omitempty"`         // Set when a category rule chose the category, cleared when it is set by hand
+	Date                database.CustomDate `json:"date" binding:"required" gorm:"type:date;not null;index;uniqueIndex:idx_expenses_recurring_date"`
+	Note                string              `json:"note,omitempty"`
+	PossibleDuplicateOf []uint              `json:"possible_duplicate_of,omitempty" gorm:"-"` // Set on create: IDs of existing expenses this one may duplicate
+	Tags                []string            `json:"tags,omitempty" gorm:"-"`
+	Splits              []ExpenseSplit      `json:"splits,omitempty" gorm:"-"` // Set when the expense is split across categories
+}
+
+// ExpenseSplit is one line of an expense split across several categories, such as the groceries on a
+// supermarket receipt that also has household goods. The lines of an expense add up to its amount and
+// are in its currency. Category totals count the lines instead of the expense's own category.
+type ExpenseSplit struct {
+	ID         uint        `json:"id" gorm:"primarykey"`
+	CreatedAt  time.Time   `json:"created_at"`
+	ExpenseID  uint        `json:"expense_id" gorm:"not null;index"`
+	Amount     types.Money `json:"amount" gorm:"not null"`
+	Category   string      `json:"category" gorm:"not null"`
+	CategoryID *uint       `json:"category_id,omitempty" gorm:"index"`
+	Note       string      `json:"note,omitempty"`
+}
+
+// ExpenseSplitRequest defines one split line in an expense create or update request.
+type ExpenseSplitRequest struct {
+	Amount     types.Money `json:"amount" binding:"required,gt=0"`
+	Category   string      `json:"category,omitempty"`    // Defaults to Uncategorized
+	CategoryID *uint       `json:"category_id,omitempty"` // Alternative to category; wins when both are given
+	Note       string      `json:"note,omitempty"`
 }
 
 // ExpenseCreateRequest defines the expected request body for creating an expense.
 type ExpenseCreateRequest struct {
-	Amount   float64            `json:"amount" binding:"required,gt=0"`
-	Category string             `json:"category" binding:"required"`
-	Date     database.CustomDate `json:"date" binding:"required"`
-	Note     string             `json:"note,omitempty"`
+	Amount     types.Money           `json:"amount" binding:"required,gt=0"`
+	Currency   string                `json:"currency,omitempty" binding:"omitempty,iso4217"` // Defaults to the user's base currency
+	AccountID  *uint                 `json:"account_id,omitempty"`                           // Optional; the currency must match the account's
+	Category   string                `json:"category,omitempty"`                             // Optional; chosen by the category rules when omitted
+	CategoryID *uint                 `json:"category_id,omitempty"`                          // Alternative to category; wins when both are given
+	Date       database.CustomDate   `json:"date" binding:"required"`
+	Note       string                `json:"note,omitempty"`
+	Tags       []string              `json:"tags,omitempty" binding:"omitempty,dive,max=50"`
+	Splits     []ExpenseSplitRequest `json:"splits,omitempty" binding:"omitempty,dive"` // Optional; must add up to the amount
 }
 
 // ExpenseUpdateRequest defines the expected request body for updating an expense.
 // All fields are optional, allowing partial updates.
 type ExpenseUpdateRequest struct {
-	Amount   *float64            `json:"amount,omitempty" binding:"omitempty,gt=0"`
-	Category *string             `json:"category,omitempty"`
-	Date     *database.CustomDate `json:"date,omitempty"`
-	Note     *string             `json:"note,omitempty"`
+	Amount     *types.Money           `json:"amount,omitempty" binding:"omitempty,gt=0"`
+	Currency   *string                `json:"currency,omitempty" binding:"omitempty,iso4217"`
+	AccountID  *uint                  `json:"account_id,omitempty"` // 0 detaches the record from its account
+	Category   *string                `json:"category,omitempty"`
+	CategoryID *uint                  `json:"category_id,omitempty"` // Alternative to category; wins when both are given
+	Date       *database.CustomDate   `json:"date,omitempty"`
+	Note       *string                `json:"note,omitempty"`
+	Tags       *[]string              `json:"tags,omitempty" binding:"omitempty,dive,max=50"` // Replaces all tags; [] removes them
+	Splits     *[]ExpenseSplitRequest `json:"splits,omitempty" binding:"omitempty,dive"`      // Replaces all split lines; [] removes them
 }
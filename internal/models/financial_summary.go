@@ -1,6 +1,7 @@
 package models
 
 import (
+	"github.com/zayyadi/finance-tracker/internal/types"
 	"time"
 
 	"gorm.io/gorm"
@@ -9,13 +10,16 @@ import (
 // FinancialSummary struct corresponds to the FinancialSummaries table schema.
 type FinancialSummary struct {
 	gorm.Model
-	// UserID          uint      `json:"user_id" gorm:"not null;index;uniqueIndex:idx_user_type_period"` // Removed
-	SummaryType     string    `json:"summary_type" gorm:"not null;uniqueIndex:idx_type_period"` // 'weekly', 'monthly', 'yearly'
-	PeriodStartDate time.Time `json:"period_start_date" gorm:"not null;uniqueIndex:idx_type_period"`
-	PeriodEndDate   time.Time `json:"period_end_date" gorm:"not null"`
-	TotalIncome     float64   `json:"total_income" gorm:"not null;default:0"`
-	TotalExpenses   float64   `json:"total_expenses" gorm:"not null;default:0"`
-	NetBalance      float64   `json:"net_balance" gorm:"not null;default:0"`
+	UserID          uint        `json:"user_id" gorm:"not null;index;uniqueIndex:idx_user_type_period"`
+	SummaryType     string      `json:"summary_type" gorm:"not null;uniqueIndex:idx_user_type_period"` // 'weekly', 'monthly', 'yearly'
+	PeriodStartDate time.Time   `json:"period_start_date" gorm:"not null;uniqueIndex:idx_user_type_period"`
+	PeriodEndDate   time.Time   `json:"period_end_date" gorm:"not null"`
+	TotalIncome     types.Money `json:"total_income" gorm:"not null;default:0"`
+	TotalExpenses   types.Money `json:"total_expenses" gorm:"not null;default:0"`
+	NetBalance      types.Money `json:"net_balance" gorm:"not null;default:0"`
+	Currency        string      `json:"currency" gorm:"type:varchar(3);not null;default:'USD'"` // Base currency the totals were converted to
+	// Expenses per category, counting the lines of split expenses. Not stored; filled in on every request.
+	ExpensesByCategory map[string]types.Money `json:"expenses_by_category,omitempty" gorm:"-"`
 }
 
 // SummaryRequest is used for handlers to parse query parameters for summary generation.
@@ -0,0 +1,99 @@
+package models
+
+import (
+	"github.com/zayyadi/finance-tracker/internal/types"
+	"gorm.io/gorm"
+)
+
+// ImportProfile is a saved column mapping for one bank's CSV export, so a statement can be imported
+// again without describing its layout each time. Columns are named by their header, or by their
+// 1-based position when the file has no header row.
+type ImportProfile struct {
+	gorm.Model
+	UserID            uint   `json:"user_id" gorm:"not null;uniqueIndex:idx_user_import_profile_name"`
+	Name              string `json:"name" gorm:"type:varchar(100);not null;uniqueIndex:idx_user_import_profile_name"`
+	Delimiter         string `json:"delimiter" gorm:"type:varchar(1);not null;default:','"`
+	HasHeader         bool   `json:"has_header" gorm:"not null"`
+	SkipRows          int    `json:"skip_rows" gorm:"not null;default:0"` // Lines before the header (or the first row), e.g. an account summary
+	DateColumn        string `json:"date_column" gorm:"not null"`
+	DateFormat        string `json:"date_format" gorm:"type:varchar(30);not null;default:'YYYY-MM-DD'"` // e.g. DD/MM/YYYY
+	AmountColumn      string `json:"amount_column,omitempty"`                                           // A single signed amount column...
+	AmountSign        string `json:"amount_sign" gorm:"type:varchar(20);not null;default:'income_positive'"`
+	DebitColumn       string `json:"debit_column,omitempty"`  // ...or separate columns for money out
+	CreditColumn      string `json:"credit_column,omitempty"` // and money in
+	DecimalSeparator  string `json:"decimal_separator" gorm:"type:varchar(1);not null;default:'.'"`
+	DescriptionColumn string `json:"description_column,omitempty"` // Imported as the note
+	CategoryColumn    string `json:"category_column,omitempty"`
+	IncomeCategory    string `json:"income_category" gorm:"not null;default:'Uncategorized'"`  // Used when a row has no category and matches no rule
+	ExpenseCategory   string `json:"expense_category" gorm:"not null;default:'Uncategorized'"` // Used when a row has no category and matches no rule
+	AccountID         *uint  `json:"account_id,omitempty"`                                     // Account the statement belongs to
+	Currency          string `json:"currency,omitempty" gorm:"type:varchar(3)"`                // Defaults to the account's or the user's base currency
+}
+
+// ImportProfileRequest defines the expected request body for creating or replacing an import profile.
+// Either amount_column, or debit_column and/or credit_column, must be set.
+type ImportProfileRequest struct {
+	Name              string `json:"name" binding:"required,max=100"`
+	Delimiter         string `json:"delimiter,omitempty" binding:"omitempty,len=1"` // Defaults to ","
+	HasHeader         *bool  `json:"has_header,omitempty"`                          // Defaults to true
+	SkipRows          int    `json:"skip_rows,omitempty" binding:"omitempty,min=0,max=100"`
+	DateColumn        string `json:"date_column" binding:"required"`
+	DateFormat        string `json:"date_format,omitempty"` // YYYY, YY, MM and DD tokens; defaults to YYYY-MM-DD
+	AmountColumn      string `json:"amount_column,omitempty"`
+	AmountSign        string `json:"amount_sign,omitempty" binding:"omitempty,oneof=income_positive expense_positive"` // Defaults to income_positive
+	DebitColumn       string `json:"debit_column,omitempty"`
+	CreditColumn      string `json:"credit_column,omitempty"`
+	DecimalSeparator  string `json:"decimal_separator,omitempty"` // "." (default) or ","
+	DescriptionColumn string `json:"description_column,omitempty"`
+	CategoryColumn    string `json:"category_column,omitempty"`
+	IncomeCategory    string `json:"income_category,omitempty"`
+	ExpenseCategory   string `json:"expense_category,omitempty"`
+	AccountID         *uint  `json:"account_id,omitempty"`
+	Currency          string `json:"currency,omitempty" binding:"omitempty,iso4217"`
+}
+
+// ImportedTransaction is one statement row as it was (or, in a dry run, would be) imported.
+type ImportedTransaction struct {
+	Line                int            `json:"line"`
+	Type                string         `json:"type"` // income or expense
+	ID                  uint           `json:"id,omitempty"`
+	Date                string         `json:"date"` // YYYY-MM-DD
+	Amount              types.Money    `json:"amount"`
+	Currency            string         `json:"currency"`
+	Category            string         `json:"category"`
+	CategoryID          *uint          `json:"category_id,omitempty"`      // Unset on a dry run when the category does not exist yet
+	CategoryRuleID      *uint          `json:"category_rule_id,omitempty"` // The rule that chose the category, if any
+	Note                string         `json:"note,omitempty"`
+	ExternalID          string         `json:"external_id,omitempty"`           // The bank's transaction ID, e.g. an OFX FITID
+	PossibleDuplicateOf []uint         `json:"possible_duplicate_of,omitempty"` // IDs of existing entries this row may duplicate
+	Splits              []ExpenseSplit `json:"splits,omitempty"`                // The split lines of a split expense
+}
+
+// ImportRowError reports a statement row that could not be imported.
+type ImportRowError struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+// StatementBalance is the balance a statement reports, next to the balance we have for the account.
+type StatementBalance struct {
+	AccountNumber     string       `json:"account_number,omitempty"` // Masked to the last four characters
+	Currency          string       `json:"currency,omitempty"`
+	StartDate         string       `json:"start_date,omitempty"` // YYYY-MM-DD
+	EndDate           string       `json:"end_date,omitempty"`   // YYYY-MM-DD
+	LedgerBalance     *types.Money `json:"ledger_balance,omitempty"`
+	LedgerBalanceDate string       `json:"ledger_balance_date,omitempty"` // YYYY-MM-DD
+	AccountBalance    *types.Money `json:"account_balance,omitempty"`     // Our balance of the import account on the ledger balance date, including this import
+	Difference        *types.Money `json:"difference,omitempty"`          // LedgerBalance minus AccountBalance; zero when the two agree
+}
+
+// ImportResult is the response body of an import. Rows with errors are skipped, as are rows that were
+// imported before; the others are created together, or not at all.
+type ImportResult struct {
+	DryRun       bool                  `json:"dry_run"`
+	Imported     int                   `json:"imported"`
+	Transactions []ImportedTransaction `json:"transactions"`
+	Duplicates   []ImportedTransaction `json:"duplicates"`
+	Errors       []ImportRowError      `json:"errors"`
+	Statement    *StatementBalance     `json:"statement,omitempty"` // Only for formats that report a balance
+}
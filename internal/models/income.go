@@ -2,26 +2,39 @@ package models
 
 import (
 	"github.com/zayyadi/finance-tracker/internal/database" // Corrected import
+	"github.com/zayyadi/finance-tracker/internal/types"
 	"gorm.io/gorm"
 )
 
 // Income struct corresponds to the Income table schema.
 type Income struct {
-	gorm.Model // ID, CreatedAt, UpdatedAt, DeletedAt
-	// UserID     uint   `json:"user_id" gorm:"not null;index"` // Removed for single-user mode
-	Amount   float64            `json:"amount" binding:"required,gt=0" gorm:"not null;default:0"`
-	Category string             `json:"category" binding:"required" gorm:"not null"`
-	Date     database.CustomDate `json:"date" binding:"required" gorm:"not null"`
-	Note     string             `json:"note,omitempty"` // Allow empty, GORM handles it
+	gorm.Model                              // ID, CreatedAt, UpdatedAt, DeletedAt
+	UserID              uint                `json:"user_id" gorm:"not null;index;uniqueIndex:idx_incomes_user_external_id"`
+	Amount              types.Money         `json:"amount" binding:"required,gt=0" gorm:"not null;default:0"`
+	Currency            string              `json:"currency" gorm:"type:varchar(3);not null;default:'USD'"`                                  // ISO 4217 code of the amounts
+	AccountID           *uint               `json:"account_id,omitempty" gorm:"index"`                                                       // Optional account the income was paid into
+	RecurringID         *uint               `json:"recurring_id,omitempty" gorm:"uniqueIndex:idx_incomes_recurring_date"`                    // Set on rows generated from a recurring template
+	ExternalID          *string             `json:"external_id,omitempty" gorm:"type:varchar(255);uniqueIndex:idx_incomes_user_external_id"` // The bank's transaction ID on imported rows
+	Category            string              `json:"category" binding:"required" gorm:"not null"`
+	CategoryID          *uint               `json:"category_id,omitempty" gorm:"index"` // The category named by Category
+	CategoryRuleID      *uint               `json:"category_rule_id,omitempty"`         // Set when a category rule chose the category, cleared when it is set by hand
+	Date                database.CustomDate `json:"date" binding:"required" gorm:"type:date;not null;index;uniqueIndex:idx_incomes_recurring_date"`
+	Note                string              `json:"note,omitempty"`                           // Allow empty, GORM handles it
+	PossibleDuplicateOf []uint              `json:"possible_duplicate_of,omitempty" gorm:"-"` // Set on create: IDs of existing incomes this one may duplicate
+	Tags                []string            `json:"tags,omitempty" gorm:"-"`
 }
 
 // IncomeCreateRequest defines the expected request body for creating income,
 // excluding fields that should be set by the server (ID, UserID, CreatedAt, UpdatedAt).
 type IncomeCreateRequest struct {
-	Amount   float64            `json:"amount" binding:"required,gt=0"`
-	Category string             `json:"category" binding:"required"`
-	Date     database.CustomDate `json:"date" binding:"required"`
-	Note     string             `json:"note,omitempty"` // Keep as string, GORM handles empty string fine
+	Amount     types.Money         `json:"amount" binding:"required,gt=0"`
+	Currency   string              `json:"currency,omitempty" binding:"omitempty,iso4217"` // Defaults to the user's base currency
+	AccountID  *uint               `json:"account_id,omitempty"`                           // Optional; the currency must match the account's
+	Category   string              `json:"category,omitempty"`                             // Optional; chosen by the category rules when omitted
+	CategoryID *uint               `json:"category_id,omitempty"`                          // Alternative to category; wins when both are given
+	Date       database.CustomDate `json:"date" binding:"required"`
+	Note       string              `json:"note,omitempty"` // Keep as string, GORM handles empty string fine
+	Tags       []string            `json:"tags,omitempty" binding:"omitempty,dive,max=50"`
 }
 
 // IncomeUpdateRequest defines the expected request body for updating income.
@@ -29,8 +42,12 @@ type IncomeCreateRequest struct {
 // Using pointers ensures that only provided fields are updated and can distinguish between
 // a zero value (e.g. 0 for amount) and a field not being provided.
 type IncomeUpdateRequest struct {
-	Amount   *float64            `json:"amount,omitempty" binding:"omitempty,gt=0"`
-	Category *string             `json:"category,omitempty"`
-	Date     *database.CustomDate `json:"date,omitempty"`
-	Note     *string             `json:"note,omitempty"`
+	Amount     *types.Money         `json:"amount,omitempty" binding:"omitempty,gt=0"`
+	Currency   *string              `json:"currency,omitempty" binding:"omitempty,iso4217"`
+	AccountID  *uint                `json:"account_id,omitempty"` // 0 detaches the record from its account
+	Category   *string              `json:"category,omitempty"`
+	CategoryID *uint                `json:"category_id,omitempty"` // Alternative to category; wins when both are given
+	Date       *database.CustomDate `json:"date,omitempty"`
+	Note       *string              `json:"note,omitempty"`
+	Tags       *[]string            `json:"tags,omitempty" binding:"omitempty,dive,max=50"` // Replaces all tags; [] removes them
 }
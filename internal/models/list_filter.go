@@ -0,0 +1,15 @@
+package models
+
+// ListFilter holds the optional filters and sort order of the income, expense, debt and savings
+// lists. The fields hold the raw query parameters, except for the parsed Categories and Tags.
+type ListFilter struct {
+	StartDate  string   // YYYY-MM-DD, inclusive. The due date of debts, the target date of savings goals
+	EndDate    string   // YYYY-MM-DD, inclusive
+	Categories []string // Incomes and expenses only; records may be in any of them
+	MinAmount  string   // Inclusive. The goal amount of savings goals
+	MaxAmount  string   // Inclusive
+	Note       string   // Text the note, debt description or savings notes must contain
+	Tags       []string // Normalized; records must carry every one
+	Sort       string   // A sort field of the list; defaults to its date
+	Order      string   // asc or desc
+}
@@ -0,0 +1,15 @@
+package models
+
+// MaxPageLimit is the largest number of items returned on one page of a list.
+const MaxPageLimit = 100
+
+// Page is one page of a paginated list.
+type Page[T any] struct {
+	Items   []T    `json:"items"`
+	Total   int64  `json:"total"` // Number of items on all pages
+	Page    int    `json:"page"`  // Counting from 1
+	Limit   int    `json:"limit"` // Largest number of items on a page
+	HasMore bool   `json:"has_more"`
+	Next    string `json:"next,omitempty"` // Link to the next page, absent on the last one
+	Prev    string `json:"prev,omitempty"` // Link to the previous page, absent on the first one
+}
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"github.com/zayyadi/finance-tracker/internal/database"
+	"github.com/zayyadi/finance-tracker/internal/types"
+	"gorm.io/gorm"
+)
+
+// RecurringTransaction is a template for income or expenses that repeat, such as rent, salary or
+// subscriptions. The scheduler materializes each due occurrence as an Income or Expense row that
+// points back to the template, so every occurrence is generated exactly once.
+type RecurringTransaction struct {
+	gorm.Model
+	UserID      uint                 `json:"user_id" gorm:"not null;index"`
+	Type        string               `json:"type" gorm:"type:varchar(10);not null"` // income or expense
+	Amount      types.Money          `json:"amount" gorm:"not null;default:0"`
+	Currency    string               `json:"currency" gorm:"type:varchar(3);not null;default:'USD'"`
+	AccountID   *uint                `json:"account_id,omitempty" gorm:"index"`
+	Category    string               `json:"category" gorm:"not null"`
+	Note        string               `json:"note,omitempty"`
+	Frequency   string               `json:"frequency" gorm:"type:varchar(10);not null"`                // daily, weekly, monthly or yearly
+	Interval    int                  `json:"interval" gorm:"column:repeat_interval;not null;default:1"` // Every Interval days/weeks/months/years
+	DayOfMonth  *int                 `json:"day_of_month,omitempty"`                                    // Monthly and yearly only: 1-31, or -1 for the last day
+	StartDate   database.CustomDate  `json:"start_date" gorm:"type:date;not null"`
+	EndDate     *database.CustomDate `json:"end_date,omitempty" gorm:"type:date"` // Last day an occurrence may fall on
+	NextRunDate database.CustomDate  `json:"next_run_date" gorm:"type:date;not null;index"`
+	Active      bool                 `json:"active" gorm:"not null"` // Cleared once the end date has passed
+}
+
+// RecurringCreateRequest defines the expected request body for creating a recurring template.
+// A day_of_month past the end of a shorter month falls on that month's last day.
+type RecurringCreateRequest struct {
+	Type       string               `json:"type" binding:"required,oneof=income expense"`
+	Amount     types.Money          `json:"amount" binding:"required,gt=0"`
+	Currency   string               `json:"currency,omitempty" binding:"omitempty,iso4217"`
+	AccountID  *uint                `json:"account_id,omitempty"`
+	Category   string               `json:"category" binding:"required"`
+	Note       string               `json:"note,omitempty"`
+	Frequency  string               `json:"frequency" binding:"required,oneof=daily weekly monthly yearly"`
+	Interval   int                  `json:"interval,omitempty" binding:"omitempty,min=1,max=366"` // Defaults to 1
+	DayOfMonth *int                 `json:"day_of_month,omitempty" binding:"omitempty,min=-1,max=31,ne=0"`
+	StartDate  database.CustomDate  `json:"start_date" binding:"required"`
+	EndDate    *database.CustomDate `json:"end_date,omitempty"`
+}
+
+// RecurringUpdateRequest defines the expected request body for updating a recurring template.
+// Changes apply to occurrences generated from now on; existing rows are left as they are.
+// The schedule itself cannot be changed: end the template and create a new one instead.
+type RecurringUpdateRequest struct {
+	Amount   *types.Money         `json:"amount,omitempty" binding:"omitempty,gt=0"`
+	Category *string              `json:"category,omitempty"`
+	Note     *string              `json:"note,omitempty"`
+	EndDate  *database.CustomDate `json:"end_date,omitempty"`
+	Active   *bool                `json:"active,omitempty"` // Re-activating resumes from today, skipping occurrences missed while paused
+}
@@ -8,8 +8,8 @@ import (
 type SavingsGoal struct {
 	gorm.Model
 	GoalName      string           `json:"goal_name" binding:"required"`
-	GoalAmount    float64          `json:"goal_amount" binding:"required"`
-	CurrentAmount float64          `json:"current_amount"`
+	GoalAmount    types.Money      `json:"goal_amount" binding:"required"`
+	CurrentAmount types.Money      `json:"current_amount"`
 	StartDate     types.CustomDate `json:"start_date"` // Optional, defaults to creation date if not provided
 	TargetDate    types.CustomDate `json:"target_date"`
 	Notes         string           `json:"notes"`
@@ -19,8 +19,8 @@ type SavingsGoal struct {
 // SavingsGoalUpdateRequest defines the structure for updating a savings goal.
 type SavingsGoalUpdateRequest struct {
 	GoalName      *string           `json:"goal_name"`
-	GoalAmount    *float64          `json:"goal_amount"`
-	CurrentAmount *float64          `json:"current_amount"`
+	GoalAmount    *types.Money      `json:"goal_amount"`
+	CurrentAmount *types.Money      `json:"current_amount"`
 	StartDate     *types.CustomDate `json:"start_date"`
 	TargetDate    *types.CustomDate `json:"target_date"`
 	Notes         *string           `json:"notes"`
@@ -0,0 +1,18 @@
+package models
+
+import (
+	"github.com/zayyadi/finance-tracker/internal/database"
+	"github.com/zayyadi/finance-tracker/internal/types"
+)
+
+// SearchResult is an income, expense, debt or savings goal matching a search.
+type SearchResult struct {
+	Type     string               `json:"type"` // income, expense, debt or savings
+	ID       uint                 `json:"id"`
+	Date     *database.CustomDate `json:"date,omitempty"` // The due date of a debt, the target date of a savings goal
+	Amount   types.Money          `json:"amount"`         // The goal amount of a savings goal
+	Currency string               `json:"currency"`
+	Title    string               `json:"title"`          // The category, debtor name or goal name
+	Text     string               `json:"text,omitempty"` // The note, description or notes
+	Rank     float64              `json:"rank"`           // Higher is a better match
+}
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Tag is a free-form label, such as "vacation-2026" or "reimbursable", that can be put on any number
+// of incomes, expenses, debts and savings goals. Names are stored in lower case and are unique per user.
+type Tag struct {
+	gorm.Model
+	UserID uint   `json:"user_id" gorm:"not null;uniqueIndex:idx_tags_user_name"`
+	Name   string `json:"name" gorm:"type:varchar(50);not null;uniqueIndex:idx_tags_user_name"`
+}
+
+// Tagging puts a tag on one record. RecordType names the table the record is in.
+type Tagging struct {
+	ID         uint      `json:"id" gorm:"primarykey"`
+	CreatedAt  time.Time `json:"created_at"`
+	TagID      uint      `json:"tag_id" gorm:"not null;index;uniqueIndex:idx_taggings_record_tag,priority:3"`
+	RecordType string    `json:"record_type" gorm:"type:varchar(10);not null;uniqueIndex:idx_taggings_record_tag,priority:1"` // income, expense, debt or savings
+	RecordID   uint      `json:"record_id" gorm:"not null;uniqueIndex:idx_taggings_record_tag,priority:2"`
+}
+
+// TagUsage is a tag with the number of records it is on.
+type TagUsage struct {
+	ID    uint   `json:"id"`
+	Name  string `json:"name"`
+	Count int64  `json:"count"`
+}
+
+// TagUpdateRequest defines the expected request body for renaming a tag.
+type TagUpdateRequest struct {
+	Name string `json:"name" binding:"required,max=50"`
+}
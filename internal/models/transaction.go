@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+
+	"github.com/zayyadi/finance-tracker/internal/database"
+	"github.com/zayyadi/finance-tracker/internal/types"
+)
+
+// Transaction is an income or an expense in the combined transaction ledger, with the running
+// balance after it.
+type Transaction struct {
+	Type       string              `json:"type"` // income or expense
+	ID         uint                `json:"id"`   // ID of the income or expense
+	Date       database.CustomDate `json:"date"`
+	CreatedAt  time.Time           `json:"-"` // Orders transactions on the same day
+	Category   string              `json:"category"`
+	CategoryID *uint               `json:"category_id,omitempty"`
+	Note       string              `json:"note,omitempty"`
+	Amount     types.Money         `json:"amount"` // Signed: negative for expenses. In Currency
+	Currency   string              `json:"currency"`
+	AccountID  *uint               `json:"account_id,omitempty"`
+	Balance    types.Money         `json:"balance"` // In the user's base currency
+}
+
+// TransactionFilter selects the transactions listed by the ledger. Every field is optional and
+// holds the raw query parameter.
+type TransactionFilter struct {
+	Type      string // income or expense
+	StartDate string // YYYY-MM-DD, inclusive
+	EndDate   string // YYYY-MM-DD, inclusive
+	Category  string
+	MinAmount string // Inclusive, compared with the unsigned amount
+	MaxAmount string // Inclusive, compared with the unsigned amount
+	Query     string // Text the note or category must contain
+	Order     string // asc (oldest first, the default) or desc
+}
+
+// TransactionPage is one page of the transaction ledger.
+type TransactionPage struct {
+	Transactions []Transaction `json:"transactions"`
+	Currency     string        `json:"currency"`              // The user's base currency, which balances are in
+	NextCursor   string        `json:"next_cursor,omitempty"` // Pass as "cursor" for the next page; absent on the last page
+}
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"github.com/zayyadi/finance-tracker/internal/database"
+	"github.com/zayyadi/finance-tracker/internal/types"
+	"gorm.io/gorm"
+)
+
+// Transfer moves money between two of a user's accounts. It changes both balances but is
+// neither income nor expense, so it never appears in summaries or analytics.
+type Transfer struct {
+	gorm.Model
+	UserID        uint                `json:"user_id" gorm:"not null;index"`
+	FromAccountID uint                `json:"from_account_id" gorm:"not null;index"`
+	ToAccountID   uint                `json:"to_account_id" gorm:"not null;index"`
+	Amount        types.Money         `json:"amount" gorm:"not null;default:0"`    // Debited from the source account, in its currency
+	ToAmount      types.Money         `json:"to_amount" gorm:"not null;default:0"` // Credited to the destination account, in its currency
+	Date          database.CustomDate `json:"date" gorm:"type:date;not null;index"`
+	Note          string              `json:"note,omitempty"`
+}
+
+// TransferCreateRequest defines the expected request body for creating a transfer.
+// ToAmount is only needed when the two accounts hold different currencies.
+type TransferCreateRequest struct {
+	FromAccountID uint                `json:"from_account_id" binding:"required"`
+	ToAccountID   uint                `json:"to_account_id" binding:"required,nefield=FromAccountID"`
+	Amount        types.Money         `json:"amount" binding:"required,gt=0"`
+	ToAmount      *types.Money        `json:"to_amount,omitempty" binding:"omitempty,gt=0"`
+	Date          database.CustomDate `json:"date" binding:"required"`
+	Note          string              `json:"note,omitempty"`
+}
@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+
+	"github.com/zayyadi/finance-tracker/internal/database"
+	"github.com/zayyadi/finance-tracker/internal/types"
+)
+
+// TrashItem is a deleted income, expense, debt or savings goal that can still be restored.
+type TrashItem struct {
+	Type      string               `json:"type"` // income, expense, debt or savings
+	ID        uint                 `json:"id"`
+	Date      *database.CustomDate `json:"date,omitempty"` // The due date of a debt, the target date of a savings goal
+	Amount    types.Money          `json:"amount"`         // The goal amount of a savings goal
+	Currency  string               `json:"currency"`
+	Title     string               `json:"title"`          // The category, debtor name or goal name
+	Text      string               `json:"text,omitempty"` // The note, description or notes
+	DeletedAt time.Time            `json:"deleted_at"`
+	PurgeAt   *time.Time           `json:"purge_at,omitempty"` // When it is removed for good; absent when it is kept until purged by hand
+}
@@ -1,16 +1,74 @@
 package models
 
-import "gorm.io/gorm"
+import (
+	"time"
 
-// User represents a user in the system.
-// Even if not directly used by all services in a single-user app context,
-// it's needed for schema completeness if other tables (like income/expense
-// in the main DB schema) have foreign keys to it.
-// For testing analytics_service, GORM's AutoMigrate will create this table.
+	"gorm.io/gorm"
+)
+
+// User represents an account that owns income, expense, savings and debt records.
+// Every data table carries a user_id column referencing this table, and all
+// /api/v1 routes are scoped to the user identified by the access token.
 type User struct {
 	gorm.Model
-	Username     string `gorm:"type:varchar(100);uniqueIndex;not null"`
-	Email        string `gorm:"type:varchar(100);uniqueIndex;not null"`
-	PasswordHash string `gorm:"type:varchar(255);not null"`
-	// Add any other fields that might be part of your User model
+	Username     string `json:"username" gorm:"type:varchar(100);uniqueIndex;not null"`
+	Email        string `json:"email" gorm:"type:varchar(100);uniqueIndex;not null"`
+	PasswordHash string `json:"-" gorm:"type:varchar(255);not null"`                         // Never serialized
+	BaseCurrency string `json:"base_currency" gorm:"type:varchar(3);not null;default:'USD'"` // Reporting currency for summaries, analytics and reports
+}
+
+// DefaultCurrency is the base currency of new accounts and of records created without a currency.
+const DefaultCurrency = "USD"
+
+// RegisterRequest defines the expected request body for creating a new account.
+type RegisterRequest struct {
+	Username     string `json:"username" binding:"required,min=3,max=100"`
+	Email        string `json:"email" binding:"required,email,max=100"`
+	Password     string `json:"password" binding:"required,min=8,max=72"`            // bcrypt only uses the first 72 bytes
+	BaseCurrency string `json:"base_currency,omitempty" binding:"omitempty,iso4217"` // Defaults to DefaultCurrency
+}
+
+// LoginRequest defines the expected request body for logging in.
+type LoginRequest struct {
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"required"`
+}
+
+// RefreshRequest defines the expected request body for exchanging a refresh token.
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// UserResponse is the public representation of a user, without credentials.
+type UserResponse struct {
+	ID           uint      `json:"id"`
+	Username     string    `json:"username"`
+	Email        string    `json:"email"`
+	BaseCurrency string    `json:"base_currency"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// BaseCurrencyUpdateRequest defines the expected request body for changing the reporting currency.
+type BaseCurrencyUpdateRequest struct {
+	BaseCurrency string `json:"base_currency" binding:"required,iso4217"`
+}
+
+// AuthResponse is returned by the register, login and refresh endpoints.
+type AuthResponse struct {
+	AccessToken  string       `json:"access_token"`
+	RefreshToken string       `json:"refresh_token"`
+	TokenType    string       `json:"token_type"`
+	ExpiresIn    int64        `json:"expires_in"` // Access token lifetime in seconds
+	User         UserResponse `json:"user"`
+}
+
+// ToResponse converts a User into its public representation.
+func (u *User) ToResponse() UserResponse {
+	return UserResponse{
+		ID:           u.ID,
+		Username:     u.Username,
+		Email:        u.Email,
+		BaseCurrency: u.BaseCurrency,
+		CreatedAt:    u.CreatedAt,
+	}
 }
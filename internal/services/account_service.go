@@ -0,0 +1,376 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/zayyadi/finance-tracker/internal/database"
+	"github.com/zayyadi/finance-tracker/internal/models"
+	"github.com/zayyadi/finance-tracker/internal/types"
+	"gorm.io/gorm"
+)
+
+// AccountService provides methods for managing accounts, their balances and transfers between them.
+type AccountService struct {
+	DB *gorm.DB
+}
+
+// NewAccountService creates a new AccountService with a GORM database connection.
+func NewAccountService(db *gorm.DB) *AccountService {
+	if db == nil {
+		log.Println("Warning: NewAccountService called with nil DB, attempting to use global GetDB()")
+		db = database.GetDB()
+	}
+	return &AccountService{DB: db}
+}
+
+// CreateAccount inserts a new account. An empty currency defaults to the user's base currency.
+func (s *AccountService) CreateAccount(account *models.Account) error {
+	if s.DB == nil {
+		return fmt.Errorf("database connection not initialized in AccountService")
+	}
+	currency, err := resolveCurrency(s.DB, account.UserID, account.Currency)
+	if err != nil {
+		return fmt.Errorf("could not create account: %w", err)
+	}
+	account.Currency = currency
+	if err := s.DB.Create(account).Error; err != nil {
+		log.Printf("Error creating account for user %d: %v", account.UserID, err)
+		return fmt.Errorf("could not create account: %w", err)
+	}
+	return nil
+}
+
+// GetAccountByID retrieves a specific account by its ID, scoped to the given user.
+func (s *AccountService) GetAccountByID(userID uint, accountID uint) (*models.Account, error) {
+	if s.DB == nil {
+		return nil, fmt.Errorf("database connection not initialized in AccountService")
+	}
+	return findAccount(s.DB, userID, accountID)
+}
+
+// GetAccounts retrieves a user's accounts with their current balances, with pagination.
+func (s *AccountService) GetAccounts(userID uint, offset int, limit int) ([]models.AccountWithBalance, error) {
+	if s.DB == nil {
+		return nil, fmt.Errorf("database connection not initialized in AccountService")
+	}
+	var accounts []models.Account
+	if err := s.DB.Where("user_id = ?", userID).Order("name, id").Offset(offset).Limit(limit).Find(&accounts).Error; err != nil {
+		log.Printf("Error retrieving accounts for user %d: %v", userID, err)
+		return nil, fmt.Errorf("could not retrieve accounts: %w", err)
+	}
+
+	result := make([]models.AccountWithBalance, 0, len(accounts))
+	for _, account := range accounts {
+		balance, err := s.accountBalance(userID, &account)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, models.AccountWithBalance{Account: account, Balance: balance})
+	}
+	return result, nil
+}
+
+// GetAccountWithBalance retrieves an account together with its current balance.
+func (s *AccountService) GetAccountWithBalance(userID uint, accountID uint) (*models.AccountWithBalance, error) {
+	account, err := s.GetAccountByID(userID, accountID)
+	if err != nil {
+		return nil, err
+	}
+	balance, err := s.accountBalance(userID, account)
+	if err != nil {
+		return nil, err
+	}
+	return &models.AccountWithBalance{Account: *account, Balance: balance}, nil
+}
+
+// UpdateAccount updates an existing account owned by the given user.
+func (s *AccountService) UpdateAccount(userID uint, accountID uint, updateData *models.AccountUpdateRequest) (*models.Account, error) {
+	if s.DB == nil {
+		return nil, fmt.Errorf("database connection not initialized in AccountService")
+	}
+
+	existingAccount, err := s.GetAccountByID(userID, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := make(map[string]interface{})
+	if updateData.Name != nil {
+		updates["name"] = *updateData.Name
+	}
+	if updateData.Type != nil {
+		updates["type"] = *updateData.Type
+	}
+	if updateData.OpeningBalance != nil {
+		updates["opening_balance"] = *updateData.OpeningBalance
+	}
+	if len(updates) == 0 {
+		return existingAccount, nil
+	}
+
+	if err := s.DB.Model(existingAccount).Where("id = ? AND user_id = ?", accountID, userID).Updates(updates).Error; err != nil {
+		log.Printf("Error updating account %d: %v", accountID, err)
+		return nil, fmt.Errorf("could not update account: %w", err)
+	}
+	return existingAccount, nil
+}
+
+// DeleteAccount deletes an account owned by the given user. Accounts that still have income,
+// expenses, transfers or recurring templates booked against them cannot be deleted, since their
+// balances would vanish.
+func (s *AccountService) DeleteAccount(userID uint, accountID uint) error {
+	if s.DB == nil {
+		return fmt.Errorf("database connection not initialized in AccountService")
+	}
+	if _, err := s.GetAccountByID(userID, accountID); err != nil {
+		return err
+	}
+
+	var incomes, expenses, transfers, recurring int64
+	if err := s.DB.Model(&models.Income{}).Where("user_id = ? AND account_id = ?", userID, accountID).Count(&incomes).Error; err != nil {
+		return fmt.Errorf("could not delete account: %w", err)
+	}
+	if err := s.DB.Model(&models.Expense{}).Where("user_id = ? AND account_id = ?", userID, accountID).Count(&expenses).Error; err != nil {
+		return fmt.Errorf("could not delete account: %w", err)
+	}
+	if err := s.DB.Model(&models.Transfer{}).Where("user_id = ? AND (from_account_id = ? OR to_account_id = ?)", userID, accountID, accountID).Count(&transfers).Error; err != nil {
+		return fmt.Errorf("could not delete account: %w", err)
+	}
+	if err := s.DB.Model(&models.RecurringTransaction{}).Where("user_id = ? AND account_id = ?", userID, accountID).Count(&recurring).Error; err != nil {
+		return fmt.Errorf("could not delete account: %w", err)
+	}
+	if incomes+expenses+transfers+recurring > 0 {
+		return fmt.Errorf("account still has transactions; move or delete them first")
+	}
+
+	if err := s.DB.Where("id = ? AND user_id = ?", accountID, userID).Delete(&models.Account{}).Error; err != nil {
+		log.Printf("Error deleting account %d: %v", accountID, err)
+		return fmt.Errorf("could not delete account: %w", err)
+	}
+	return nil
+}
+
+// GetAccountLedger lists every income, expense and transfer booked against an account in date
+// order, with the running balance after each entry.
+func (s *AccountService) GetAccountLedger(userID uint, accountID uint) (*models.AccountLedgerResponse, error) {
+	account, err := s.GetAccountByID(userID, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	var incomes []models.Income
+	if err := s.DB.Where("user_id = ? AND account_id = ?", userID, accountID).Find(&incomes).Error; err != nil {
+		return nil, fmt.Errorf("could not retrieve account income: %w", err)
+	}
+	var expenses []models.Expense
+	if err := s.DB.Where("user_id = ? AND account_id = ?", userID, accountID).Find(&expenses).Error; err != nil {
+		return nil, fmt.Errorf("could not retrieve account expenses: %w", err)
+	}
+	var transfers []models.Transfer
+	if err := s.DB.Where("user_id = ? AND (from_account_id = ? OR to_account_id = ?)", userID, accountID, accountID).Find(&transfers).Error; err != nil {
+		return nil, fmt.Errorf("could not retrieve account transfers: %w", err)
+	}
+
+	// Entries are ordered by date, then by creation time so same-day entries keep the order they were recorded in.
+	type ledgerEntry struct {
+		entry     models.AccountEntry
+		createdAt int64
+	}
+	var entries []ledgerEntry
+	for _, income := range incomes {
+		entries = append(entries, ledgerEntry{models.AccountEntry{Type: "income", ID: income.ID, Date: income.Date.Format("2006-01-02"), Category: income.Category, Note: income.Note, Amount: income.Amount}, income.CreatedAt.UnixNano()})
+	}
+	for _, expense := range expenses {
+		entries = append(entries, ledgerEntry{models.AccountEntry{Type: "expense", ID: expense.ID, Date: expense.Date.Format("2006-01-02"), Category: expense.Category, Note: expense.Note, Amount: -expense.Amount}, expense.CreatedAt.UnixNano()})
+	}
+	for _, transfer := range transfers {
+		if transfer.FromAccountID == accountID {
+			entries = append(entries, ledgerEntry{models.AccountEntry{Type: "transfer_out", ID: transfer.ID, Date: transfer.Date.Format("2006-01-02"), Note: transfer.Note, Amount: -transfer.Amount}, transfer.CreatedAt.UnixNano()})
+		}
+		if transfer.ToAccountID == accountID {
+			entries = append(entries, ledgerEntry{models.AccountEntry{Type: "transfer_in", ID: transfer.ID, Date: transfer.Date.Format("2006-01-02"), Note: transfer.Note, Amount: transfer.ToAmount}, transfer.CreatedAt.UnixNano()})
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].entry.Date != entries[j].entry.Date {
+			return entries[i].entry.Date < entries[j].entry.Date
+		}
+		return entries[i].createdAt < entries[j].createdAt
+	})
+
+	ledger := &models.AccountLedgerResponse{
+		Account:        *account,
+		OpeningBalance: account.OpeningBalance,
+		Entries:        make([]models.AccountEntry, 0, len(entries)),
+		Balance:        account.OpeningBalance,
+	}
+	for _, e := range entries {
+		ledger.Balance += e.entry.Amount
+		e.entry.Balance = ledger.Balance
+		ledger.Entries = append(ledger.Entries, e.entry)
+	}
+	return ledger, nil
+}
+
+// accountBalance computes an account's current balance in its own currency.
+func (s *AccountService) accountBalance(userID uint, account *models.Account) (types.Money, error) {
+	return s.accountBalanceOn(userID, account, time.Time{})
+}
+
+// accountBalanceOn computes an account's balance at the end of the given day, or its current
+// balance when asOf is zero.
+func (s *AccountService) accountBalanceOn(userID uint, account *models.Account, asOf time.Time) (types.Money, error) {
+	sum := func(query *gorm.DB, column string) (types.Money, error) {
+		if !asOf.IsZero() {
+			query = query.Where("date <= ?", asOf.Format("2006-01-02"))
+		}
+		var total types.Money
+		err := query.Select("COALESCE(SUM(" + column + "), 0)").Scan(&total).Error
+		return total, err
+	}
+
+	income, err := sum(s.DB.Model(&models.Income{}).Where("user_id = ? AND account_id = ?", userID, account.ID), "amount")
+	if err != nil {
+		return 0, fmt.Errorf("could not calculate account balance: %w", err)
+	}
+	expenses, err := sum(s.DB.Model(&models.Expense{}).Where("user_id = ? AND account_id = ?", userID, account.ID), "amount")
+	if err != nil {
+		return 0, fmt.Errorf("could not calculate account balance: %w", err)
+	}
+	transfersIn, err := sum(s.DB.Model(&models.Transfer{}).Where("user_id = ? AND to_account_id = ?", userID, account.ID), "to_amount")
+	if err != nil {
+		return 0, fmt.Errorf("could not calculate account balance: %w", err)
+	}
+	transfersOut, err := sum(s.DB.Model(&models.Transfer{}).Where("user_id = ? AND from_account_id = ?", userID, account.ID), "amount")
+	if err != nil {
+		return 0, fmt.Errorf("could not calculate account balance: %w", err)
+	}
+	return account.OpeningBalance + income - expenses + transfersIn - transfersOut, nil
+}
+
+// CreateTransfer moves money between two of the user's accounts. When both accounts hold the same
+// currency the credited amount equals the debited one; otherwise the caller must supply ToAmount.
+func (s *AccountService) CreateTransfer(userID uint, req *models.TransferCreateRequest) (*models.Transfer, error) {
+	if s.DB == nil {
+		return nil, fmt.Errorf("database connection not initialized in AccountService")
+	}
+	if req.FromAccountID == req.ToAccountID {
+		return nil, fmt.Errorf("cannot transfer to the same account")
+	}
+
+	from, err := findAccount(s.DB, userID, req.FromAccountID)
+	if err != nil {
+		return nil, fmt.Errorf("source %w", err)
+	}
+	to, err := findAccount(s.DB, userID, req.ToAccountID)
+	if err != nil {
+		return nil, fmt.Errorf("destination %w", err)
+	}
+
+	toAmount := req.Amount
+	if req.ToAmount != nil {
+		toAmount = *req.ToAmount
+	} else if from.Currency != to.Currency {
+		return nil, fmt.Errorf("to_amount is required for a transfer from %s to %s", from.Currency, to.Currency)
+	}
+	if from.Currency == to.Currency && toAmount != req.Amount {
+		return nil, fmt.Errorf("to_amount must equal amount for accounts in the same currency")
+	}
+
+	transfer := &models.Transfer{
+		UserID:        userID,
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Amount:        req.Amount,
+		ToAmount:      toAmount,
+		Date:          req.Date,
+		Note:          req.Note,
+	}
+	if err := s.DB.Create(transfer).Error; err != nil {
+		log.Printf("Error creating transfer for user %d: %v", userID, err)
+		return nil, fmt.Errorf("could not create transfer: %w", err)
+	}
+	return transfer, nil
+}
+
+// GetTransferByID retrieves a specific transfer by its ID, scoped to the given user.
+func (s *AccountService) GetTransferByID(userID uint, transferID uint) (*models.Transfer, error) {
+	if s.DB == nil {
+		return nil, fmt.Errorf("database connection not initialized in AccountService")
+	}
+	var transfer models.Transfer
+	if err := s.DB.Where("id = ? AND user_id = ?", transferID, userID).First(&transfer).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("transfer not found")
+		}
+		log.Printf("Error retrieving transfer %d for user %d: %v", transferID, userID, err)
+		return nil, fmt.Errorf("could not retrieve transfer: %w", err)
+	}
+	return &transfer, nil
+}
+
+// GetTransfers retrieves a user's transfers with pagination, newest first.
+func (s *AccountService) GetTransfers(userID uint, offset int, limit int) ([]models.Transfer, error) {
+	if s.DB == nil {
+		return nil, fmt.Errorf("database connection not initialized in AccountService")
+	}
+	var transfers []models.Transfer
+	if err := s.DB.Where("user_id = ?", userID).Order("date desc, created_at desc").Offset(offset).Limit(limit).Find(&transfers).Error; err != nil {
+		log.Printf("Error retrieving transfers for user %d: %v", userID, err)
+		return nil, fmt.Errorf("could not retrieve transfers: %w", err)
+	}
+	if transfers == nil {
+		return []models.Transfer{}, nil
+	}
+	return transfers, nil
+}
+
+// DeleteTransfer deletes a transfer owned by the given user, restoring both account balances.
+func (s *AccountService) DeleteTransfer(userID uint, transferID uint) error {
+	if s.DB == nil {
+		return fmt.Errorf("database connection not initialized in AccountService")
+	}
+	result := s.DB.Where("id = ? AND user_id = ?", transferID, userID).Delete(&models.Transfer{})
+	if result.Error != nil {
+		log.Printf("Error deleting transfer %d: %v", transferID, result.Error)
+		return fmt.Errorf("could not delete transfer: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("transfer not found, no rows deleted")
+	}
+	return nil
+}
+
+// findAccount loads one of the user's accounts.
+func findAccount(db *gorm.DB, userID uint, accountID uint) (*models.Account, error) {
+	var account models.Account
+	if err := db.Where("id = ? AND user_id = ?", accountID, userID).First(&account).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("account not found")
+		}
+		log.Printf("Error retrieving account %d for user %d: %v", accountID, userID, err)
+		return nil, fmt.Errorf("could not retrieve account: %w", err)
+	}
+	return &account, nil
+}
+
+// resolveAccountCurrency returns the currency for a record booked against accountID. Amounts on an
+// account must be in the account's currency, which is also the default; without an account the
+// user's base currency is the default.
+func resolveAccountCurrency(db *gorm.DB, userID uint, accountID *uint, currency string) (string, error) {
+	if accountID == nil {
+		return resolveCurrency(db, userID, currency)
+	}
+	account, err := findAccount(db, userID, *accountID)
+	if err != nil {
+		return "", err
+	}
+	if currency != "" && currency != account.Currency {
+		return "", fmt.Errorf("currency %s does not match the account currency %s", currency, account.Currency)
+	}
+	return account.Currency, nil
+}
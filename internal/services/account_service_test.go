@@ -0,0 +1,146 @@
+package services
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zayyadi/finance-tracker/internal/database"
+	"github.com/zayyadi/finance-tracker/internal/models"
+	"github.com/zayyadi/finance-tracker/internal/types"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// setupAccountTestDB initializes an in-memory SQLite database for account service testing.
+func setupAccountTestDB(t *testing.T) *gorm.DB {
+	dsn := fmt.Sprintf("file:%s_%d?mode=memory&cache=shared", t.Name(), time.Now().UnixNano())
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{})
+	require.NoError(t, err, "Failed to connect to in-memory SQLite")
+
+	sqlDB, _ := db.DB()
+	t.Cleanup(func() { sqlDB.Close() })
+
+	err = db.AutoMigrate(&models.User{}, &models.Account{}, &models.Transfer{}, &models.Income{}, &models.Expense{}, &models.FinancialSummary{}, &models.ExchangeRate{}, &models.RecurringTransaction{}, &models.DuplicatePair{}, &models.CategoryRule{}, &models.Category{}, &models.Tag{}, &models.Tagging{}, &models.ExpenseSplit{})
+	require.NoError(t, err, "Failed to auto-migrate models")
+	seedTestUser(t, db)
+
+	return db
+}
+
+func createTestAccount(t *testing.T, service *AccountService, name, currency string, opening types.Money) *models.Account {
+	account := &models.Account{UserID: testUserID, Name: name, Type: "bank", Currency: currency, OpeningBalance: opening}
+	require.NoError(t, service.CreateAccount(account))
+	return account
+}
+
+func TestAccountService_BalanceAndLedger(t *testing.T) {
+	db := setupAccountTestDB(t)
+	accountService := NewAccountService(db)
+	incomeService := NewIncomeService(db)
+	expenseService := NewExpenseService(db)
+	summaryService := NewSummaryService(db)
+
+	checking := createTestAccount(t, accountService, "Checking", "", types.Money(10000))
+	cash := createTestAccount(t, accountService, "Wallet", "USD", 0)
+	assert.Equal(t, "USD", checking.Currency, "Accounts default to the user's base currency")
+
+	day := func(d int) database.CustomDate {
+		return database.CustomDate{Time: time.Date(2024, time.May, d, 0, 0, 0, 0, time.UTC)}
+	}
+	require.NoError(t, incomeService.CreateIncome(&models.Income{UserID: testUserID, AccountID: &checking.ID, Amount: types.Money(50000), Category: "Salary", Date: day(1)}))
+	require.NoError(t, expenseService.CreateExpense(&models.Expense{UserID: testUserID, AccountID: &checking.ID, Amount: types.Money(2500), Category: "Groceries", Date: day(3)}))
+	_, err := accountService.CreateTransfer(testUserID, &models.TransferCreateRequest{FromAccountID: checking.ID, ToAccountID: cash.ID, Amount: types.Money(4000), Date: day(2)})
+	require.NoError(t, err)
+
+	accounts, err := accountService.GetAccounts(testUserID, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, accounts, 2)
+	assert.Equal(t, "Checking", accounts[0].Name)
+	assert.Equal(t, types.Money(10000+50000-4000-2500), accounts[0].Balance)
+	assert.Equal(t, types.Money(4000), accounts[1].Balance)
+
+	ledger, err := accountService.GetAccountLedger(testUserID, checking.ID)
+	require.NoError(t, err)
+	require.Len(t, ledger.Entries, 3)
+	assert.Equal(t, []string{"income", "transfer_out", "expense"}, []string{ledger.Entries[0].Type, ledger.Entries[1].Type, ledger.Entries[2].Type})
+	assert.Equal(t, types.Money(60000), ledger.Entries[0].Balance)
+	assert.Equal(t, types.Money(56000), ledger.Entries[1].Balance)
+	assert.Equal(t, types.Money(53500), ledger.Entries[2].Balance)
+	assert.Equal(t, types.Money(53500), ledger.Balance)
+
+	// Transfers are neither income nor expense.
+	summary, err := summaryService.GetOrCreateFinancialSummary(testUserID, "monthly", day(1).Time, "overall")
+	require.NoError(t, err)
+	assert.Equal(t, types.Money(50000), summary.TotalIncome)
+	assert.Equal(t, types.Money(2500), summary.TotalExpenses)
+}
+
+func TestAccountService_TransferValidation(t *testing.T) {
+	db := setupAccountTestDB(t)
+	service := NewAccountService(db)
+	usd := createTestAccount(t, service, "USD Checking", "USD", 0)
+	eur := createTestAccount(t, service, "EUR Savings", "EUR", 0)
+	date := database.CustomDate{Time: time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC)}
+
+	_, err := service.CreateTransfer(testUserID, &models.TransferCreateRequest{FromAccountID: usd.ID, ToAccountID: eur.ID, Amount: types.Money(1000), Date: date})
+	assert.ErrorContains(t, err, "to_amount is required for a transfer from USD to EUR")
+
+	toAmount := types.Money(920)
+	transfer, err := service.CreateTransfer(testUserID, &models.TransferCreateRequest{FromAccountID: usd.ID, ToAccountID: eur.ID, Amount: types.Money(1000), ToAmount: &toAmount, Date: date})
+	require.NoError(t, err)
+	assert.Equal(t, types.Money(920), transfer.ToAmount)
+
+	eurBalance, err := service.GetAccountWithBalance(testUserID, eur.ID)
+	require.NoError(t, err)
+	assert.Equal(t, types.Money(920), eurBalance.Balance)
+
+	_, err = service.CreateTransfer(testUserID+1, &models.TransferCreateRequest{FromAccountID: usd.ID, ToAccountID: eur.ID, Amount: types.Money(1000), ToAmount: &toAmount, Date: date})
+	assert.ErrorContains(t, err, "source account not found", "Another user's accounts cannot be used")
+}
+
+func TestAccountService_IncomeCurrencyMustMatchAccount(t *testing.T) {
+	db := setupAccountTestDB(t)
+	accountService := NewAccountService(db)
+	incomeService := NewIncomeService(db)
+	eur := createTestAccount(t, accountService, "EUR Checking", "EUR", 0)
+	date := database.CustomDate{Time: time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC)}
+
+	income := &models.Income{UserID: testUserID, AccountID: &eur.ID, Amount: types.Money(1000), Category: "Salary", Date: date}
+	require.NoError(t, incomeService.CreateIncome(income))
+	assert.Equal(t, "EUR", income.Currency, "Income booked to an account takes its currency")
+
+	err := incomeService.CreateIncome(&models.Income{UserID: testUserID, AccountID: &eur.ID, Amount: types.Money(1000), Currency: "USD", Category: "Salary", Date: date})
+	assert.ErrorContains(t, err, "currency USD does not match the account currency EUR")
+
+	usd := "USD"
+	_, err = incomeService.UpdateIncome(testUserID, income.ID, &models.IncomeUpdateRequest{Currency: &usd})
+	assert.ErrorContains(t, err, "does not match the account currency")
+
+	detach := uint(0)
+	_, err = incomeService.UpdateIncome(testUserID, income.ID, &models.IncomeUpdateRequest{AccountID: &detach, Currency: &usd})
+	require.NoError(t, err)
+	var stored models.Income
+	require.NoError(t, db.First(&stored, income.ID).Error)
+	assert.Nil(t, stored.AccountID)
+	assert.Equal(t, "USD", stored.Currency)
+}
+
+func TestAccountService_DeleteAccountWithTransactions(t *testing.T) {
+	db := setupAccountTestDB(t)
+	accountService := NewAccountService(db)
+	expenseService := NewExpenseService(db)
+	account := createTestAccount(t, accountService, "Card", "USD", 0)
+	expense := &models.Expense{UserID: testUserID, AccountID: &account.ID, Amount: types.Money(1000), Category: "Fuel", Date: database.CustomDate{Time: time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC)}}
+	require.NoError(t, expenseService.CreateExpense(expense))
+
+	err := accountService.DeleteAccount(testUserID, account.ID)
+	assert.ErrorContains(t, err, "still has transactions")
+
+	require.NoError(t, expenseService.DeleteExpense(testUserID, expense.ID))
+	assert.NoError(t, accountService.DeleteAccount(testUserID, account.ID))
+	_, err = accountService.GetAccountByID(testUserID, account.ID)
+	assert.ErrorContains(t, err, "account not found")
+}
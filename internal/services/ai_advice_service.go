@@ -66,7 +66,8 @@ func (s *AIAdviceService) GetFinancialAdvice(summary *models.FinancialSummary) (
 	}
 
 	prompt := fmt.Sprintf(
-		"Given this financial summary: Total Income %.2f, Total Expenses %.2f, Net Balance %.2f for the period %s to %s, provide concise financial advice in 2-3 short sentences.",
+		"Given this financial summary in %s: Total Income %s, Total Expenses %s, Net Balance %s for the period %s to %s, provide concise financial advice in 2-3 short sentences.",
+		summary.Currency,
 		summary.TotalIncome,
 		summary.TotalExpenses,
 		summary.NetBalance,
@@ -1,13 +1,14 @@
 package services
 
 import (
-	"database/sql"
 	"errors"
 	"fmt"
 	"log"
+	"sort"
 	"time"
 
 	"github.com/zayyadi/finance-tracker/internal/models"
+	"github.com/zayyadi/finance-tracker/internal/types"
 	"gorm.io/gorm"
 )
 
@@ -21,8 +22,9 @@ func NewAnalyticsService(db *gorm.DB) *AnalyticsService {
 	return &AnalyticsService{DB: db}
 }
 
-// GetExpenseBreakdownByCategory calculates expense breakdown by category for a given month.
-func (s *AnalyticsService) GetExpenseBreakdownByCategory(targetDate time.Time) ([]models.CategoryExpenseStat, error) {
+// GetExpenseBreakdownByCategory calculates a user's expense breakdown by category for a given month.
+// With rollup set, expenses in subcategories count towards their top-level category instead.
+func (s *AnalyticsService) GetExpenseBreakdownByCategory(userID uint, targetDate time.Time, rollup bool) ([]models.CategoryExpenseStat, error) {
 	if s.DB == nil {
 		return nil, errors.New("database connection not initialized in AnalyticsService")
 	}
@@ -31,42 +33,77 @@ func (s *AnalyticsService) GetExpenseBreakdownByCategory(targetDate time.Time) (
 	// This avoids issues with varying month lengths.
 	endDate := startDate.AddDate(0, 1, 0).AddDate(0, 0, -1)
 
+	converter, err := NewCurrencyService(s.DB).NewConverter(userID)
+	if err != nil {
+		return nil, fmt.Errorf("error preparing currency conversion: %w", err)
+	}
+
+	// Totals are converted and combined per category in Go, so the ordering has to happen after conversion too.
+	totals, err := expenseTotalsByCategory(s.DB, converter, userID, startDate, endDate)
+	if err != nil {
+		log.Printf("Error getting expense breakdown by category for user %d, %s: %v", userID, startDate.Format("2006-01"), err)
+		return nil, err
+	}
 
-	var stats []models.CategoryExpenseStat
-	result := s.DB.Model(&models.Expense{}).
-		Select("category, SUM(amount) as total_amount").
-		Where("date BETWEEN ? AND ?", startDate, endDate). // Assuming 'date' column exists in Expense model
-		Group("category").
-		Order("total_amount DESC").
-		Scan(&stats)
-	if result.Error != nil {
-		log.Printf("Error getting expense breakdown by category for %s: %v", startDate.Format("2006-01"), result.Error)
-		return nil, result.Error
+	categories, err := loadCategoryRollup(s.DB, userID, "expense")
+	if err != nil {
+		log.Printf("Error loading expense categories for user %d: %v", userID, err)
+		return nil, err
+	}
+	byCategory := make(map[string]*models.CategoryExpenseStat, len(totals))
+	for name, total := range totals {
+		stat := models.CategoryExpenseStat{Category: name, Currency: converter.Base}
+		if category := categories.lookup(name); category != nil {
+			if rollup {
+				category = categories.root(category)
+			}
+			stat.Category, stat.CategoryID, stat.Color = category.Name, &category.ID, category.Color
+		}
+		if existing, ok := byCategory[stat.Category]; ok {
+			existing.TotalAmount += total
+			continue
+		}
+		stat.TotalAmount = total
+		byCategory[stat.Category] = &stat
+	}
+	stats := make([]models.CategoryExpenseStat, 0, len(byCategory))
+	for _, stat := range byCategory {
+		stats = append(stats, *stat)
 	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].TotalAmount != stats[j].TotalAmount {
+			return stats[i].TotalAmount > stats[j].TotalAmount
+		}
+		return stats[i].Category < stats[j].Category
+	})
 	return stats, nil
 }
 
-// GetIncomeExpenseTrend calculates income and expense trends for the last numMonths.
-func (s *AnalyticsService) GetIncomeExpenseTrend(numMonths int) ([]models.MonthlyTrendStat, error) {
+// GetIncomeExpenseTrend calculates a user's income and expense trends for the last numMonths.
+func (s *AnalyticsService) GetIncomeExpenseTrend(userID uint, numMonths int) ([]models.MonthlyTrendStat, error) {
 	if s.DB == nil {
 		return nil, errors.New("database connection not initialized in AnalyticsService")
 	}
 	var trend []models.MonthlyTrendStat
 	today := time.Now()
 
+	converter, err := NewCurrencyService(s.DB).NewConverter(userID)
+	if err != nil {
+		return nil, fmt.Errorf("error preparing currency conversion: %w", err)
+	}
+
 	for i := 0; i < numMonths; i++ {
 		targetMonthDate := today.AddDate(0, -i, 0)
 		monthStartDate := time.Date(targetMonthDate.Year(), targetMonthDate.Month(), 1, 0, 0, 0, 0, targetMonthDate.Location())
 		// Calculate monthEndDate similar to GetExpenseBreakdownByCategory for robustness
-		monthEndDate := monthStartDate.AddDate(0, 1, 0).AddDate(0,0,-1)
+		monthEndDate := monthStartDate.AddDate(0, 1, 0).AddDate(0, 0, -1)
 
-
-		totalIncome, err := s.calculateTotalForPeriod(monthStartDate, monthEndDate, &models.Income{})
+		totalIncome, err := s.calculateTotalForPeriod(converter, userID, monthStartDate, monthEndDate, &models.Income{})
 		if err != nil {
 			return nil, fmt.Errorf("error calculating income for %s: %w", monthStartDate.Format("2006-01"), err)
 		}
 
-		totalExpenses, err := s.calculateTotalForPeriod(monthStartDate, monthEndDate, &models.Expense{})
+		totalExpenses, err := s.calculateTotalForPeriod(converter, userID, monthStartDate, monthEndDate, &models.Expense{})
 		if err != nil {
 			return nil, fmt.Errorf("error calculating expenses for %s: %w", monthStartDate.Format("2006-01"), err)
 		}
@@ -75,6 +112,7 @@ func (s *AnalyticsService) GetIncomeExpenseTrend(numMonths int) ([]models.Monthl
 			Month:         monthStartDate.Format("2006-01"), // Format as YYYY-MM
 			TotalIncome:   totalIncome,
 			TotalExpenses: totalExpenses,
+			Currency:      converter.Base,
 		})
 	}
 
@@ -85,18 +123,94 @@ func (s *AnalyticsService) GetIncomeExpenseTrend(numMonths int) ([]models.Monthl
 	return trend, nil
 }
 
-// calculateTotalForPeriod is a helper function to calculate sum of 'amount' for a given model.
-func (s *AnalyticsService) calculateTotalForPeriod(startDate, endDate time.Time, modelInstance interface{}) (float64, error) {
-	var total sql.NullFloat64 // Use sql.NullFloat64 for cases where sum might be null.
-	result := s.DB.Model(modelInstance).
-		Where("date BETWEEN ? AND ?", startDate, endDate). // Ensure 'date' column exists in the model
-		Select("COALESCE(SUM(amount), 0)").               // Return 0 if no records or sum is NULL
-		Scan(&total)
-
-	if result.Error != nil {
+// calculateTotalForPeriod is a helper function to calculate a user's sum of 'amount' for a given model,
+// converted into the base currency.
+func (s *AnalyticsService) calculateTotalForPeriod(converter *CurrencyConverter, userID uint, startDate, endDate time.Time, modelInstance interface{}) (types.Money, error) {
+	total, err := converter.SumAmounts(s.DB.Model(modelInstance).
+		Where("user_id = ? AND date BETWEEN ? AND ?", userID, startDate.Format("2006-01-02"), endDate.Format("2006-01-02")))
+	if err != nil {
 		// Log the error with model type for better debugging
-		log.Printf("Error calculating total between %s and %s for model %T: %v", startDate.Format("2006-01-02"), endDate.Format("2006-01-02"), modelInstance, result.Error)
-		return 0, result.Error
+		log.Printf("Error calculating total for user %d between %s and %s for model %T: %v", userID, startDate.Format("2006-01-02"), endDate.Format("2006-01-02"), modelInstance, err)
+		return 0, err
+	}
+	return total, nil // Returns 0 if there were no records
+}
+
+// expenseTotalsByCategory sums a user's expenses between startDate and endDate (inclusive) per category,
+// converted into the base currency. Split expenses count towards the categories of their lines. Amounts
+// are summed per category and currency in SQL, then converted and combined.
+func expenseTotalsByCategory(db *gorm.DB, converter *CurrencyConverter, userID uint, startDate, endDate time.Time) (map[string]types.Money, error) {
+	from, to := startDate.Format("2006-01-02"), endDate.Format("2006-01-02")
+	unsplit := db.Model(&models.Expense{}).Select("category, currency, date, amount").
+		Where("user_id = ? AND date BETWEEN ? AND ?", userID, from, to).
+		Where("NOT EXISTS (SELECT 1 FROM expense_splits WHERE expense_splits.expense_id = expenses.id)")
+	lines := db.Model(&models.ExpenseSplit{}).
+		Select("expense_splits.category, expenses.currency, expenses.date, expense_splits.amount").
+		Joins("JOIN expenses ON expenses.id = expense_splits.expense_id").
+		Where("expenses.user_id = ? AND expenses.date BETWEEN ? AND ? AND expenses.deleted_at IS NULL", userID, from, to)
+	return converter.SumAmountsBy(db.Table("(? UNION ALL ?) AS expense_lines", unsplit, lines), "category")
+}
+
+// GetTagBreakdown totals a user's income and expenses per tag for a given month, largest expenses first.
+// A record with several tags counts towards each of them, so the totals can add up to more than the month's.
+func (s *AnalyticsService) GetTagBreakdown(userID uint, targetDate time.Time) ([]models.TagStat, error) {
+	if s.DB == nil {
+		return nil, errors.New("database connection not initialized in AnalyticsService")
 	}
-	return total.Float64, nil // Returns 0.0 if sum was NULL (handled by COALESCE)
+	startDate := time.Date(targetDate.Year(), targetDate.Month(), 1, 0, 0, 0, 0, targetDate.Location())
+	endDate := startDate.AddDate(0, 1, 0).AddDate(0, 0, -1)
+
+	converter, err := NewCurrencyService(s.DB).NewConverter(userID)
+	if err != nil {
+		return nil, fmt.Errorf("error preparing currency conversion: %w", err)
+	}
+
+	incomeTotals, err := totalsByTag(s.DB, converter, "income", userID, startDate, endDate)
+	if err != nil {
+		log.Printf("Error getting income by tag for user %d, %s: %v", userID, startDate.Format("2006-01"), err)
+		return nil, err
+	}
+	expenseTotals, err := totalsByTag(s.DB, converter, "expense", userID, startDate, endDate)
+	if err != nil {
+		log.Printf("Error getting expenses by tag for user %d, %s: %v", userID, startDate.Format("2006-01"), err)
+		return nil, err
+	}
+
+	byTag := make(map[string]*models.TagStat)
+	statFor := func(tag string) *models.TagStat {
+		if byTag[tag] == nil {
+			byTag[tag] = &models.TagStat{Tag: tag, Currency: converter.Base}
+		}
+		return byTag[tag]
+	}
+	for tag, total := range incomeTotals {
+		statFor(tag).TotalIncome = total
+	}
+	for tag, total := range expenseTotals {
+		statFor(tag).TotalExpenses = total
+	}
+	stats := make([]models.TagStat, 0, len(byTag))
+	for _, stat := range byTag {
+		stats = append(stats, *stat)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].TotalExpenses != stats[j].TotalExpenses {
+			return stats[i].TotalExpenses > stats[j].TotalExpenses
+		}
+		if stats[i].TotalIncome != stats[j].TotalIncome {
+			return stats[i].TotalIncome > stats[j].TotalIncome
+		}
+		return stats[i].Tag < stats[j].Tag
+	})
+	return stats, nil
+}
+
+// totalsByTag sums a user's incomes or expenses between startDate and endDate (inclusive) per tag,
+// converted into the base currency.
+func totalsByTag(db *gorm.DB, converter *CurrencyConverter, recordType string, userID uint, startDate, endDate time.Time) (map[string]types.Money, error) {
+	table := taggedTables[recordType]
+	return converter.SumAmountsBy(db.Model(transactionModel(recordType)).
+		Joins("JOIN taggings ON taggings.record_type = ? AND taggings.record_id = "+table+".id", recordType).
+		Joins("JOIN tags ON tags.id = taggings.tag_id").
+		Where(table+".user_id = ? AND "+table+".date BETWEEN ? AND ?", userID, startDate.Format("2006-01-02"), endDate.Format("2006-01-02")), "tags.name")
 }
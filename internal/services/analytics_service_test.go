@@ -7,40 +7,51 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/zayyadi/finance-tracker/internal/database" // Corrected import path for CustomDate
 	"github.com/zayyadi/finance-tracker/internal/models"
+	"github.com/zayyadi/finance-tracker/internal/types"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
 
+// testUserID is the owner of all records seeded by the service tests.
+const testUserID uint = 1
+
 // setupTestDB initializes an in-memory SQLite database for testing.
 func setupTestDB(t *testing.T) *gorm.DB {
 	db, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
 	assert.NoError(t, err, "Failed to connect to in-memory SQLite")
 
 	// Auto-migrate schemas.
-	// GORM will create tables based on these structs. Since UserID is not an active field
-	// in models.Income and models.Expense, the created tables for tests will not have user_id columns.
-	// This aligns with testing the service logic which operates without user_id.
+	// GORM will create tables based on these structs, including the user_id columns.
 
 	// Drop tables first to ensure a clean state for each test
 	// Order matters due to potential foreign key constraints if they were active
-	// (though for our test setup with GORM creating schema from structs without UserID, FKs are not an issue)
 	db.Exec("DROP TABLE IF EXISTS FinancialSummary")
-	db.Exec("DROP TABLE IF EXISTS Debts") // Assuming Debt model might exist from other tests/full schema
-	db.Exec("DROP TABLE IF EXISTS Savings")// Assuming Savings model might exist
+	db.Exec("DROP TABLE IF EXISTS Debts")   // Assuming Debt model might exist from other tests/full schema
+	db.Exec("DROP TABLE IF EXISTS Savings") // Assuming Savings model might exist
 	db.Exec("DROP TABLE IF EXISTS Expenses")
 	db.Exec("DROP TABLE IF EXISTS Income")
 	db.Exec("DROP TABLE IF EXISTS Users")
 
-
-	err = db.AutoMigrate(&models.User{}, &models.Income{}, &models.Expense{}, &models.FinancialSummary{}, &models.Debt{}, &models.Savings{})
+	err = db.AutoMigrate(&models.User{}, &models.Income{}, &models.Expense{}, &models.FinancialSummary{}, &models.Debt{}, &models.Savings{}, &models.ExchangeRate{}, &models.Category{}, &models.Tag{}, &models.Tagging{}, &models.ExpenseSplit{})
 	assert.NoError(t, err, "Failed to auto-migrate models")
+	seedTestUser(t, db)
 
 	return db
 }
 
+// seedTestUser creates the user that owns the seeded records; services look up its base currency.
+func seedTestUser(t *testing.T, db *gorm.DB) {
+	user := models.User{Model: gorm.Model{ID: testUserID}, Username: "testuser", Email: "test@example.com", PasswordHash: "hash", BaseCurrency: models.DefaultCurrency}
+	err := db.Where(models.User{Model: gorm.Model{ID: testUserID}}).FirstOrCreate(&user).Error
+	assert.NoError(t, err, "Failed to seed test user")
+}
+
 // seedExpenses populates the database with expense data.
 func seedExpenses(t *testing.T, db *gorm.DB, expenses []models.Expense) {
 	for _, expense := range expenses {
+		if expense.UserID == 0 {
+			expense.UserID = testUserID
+		}
 		err := db.Create(&expense).Error
 		assert.NoError(t, err, "Failed to seed expense: %+v", expense)
 	}
@@ -49,6 +60,9 @@ func seedExpenses(t *testing.T, db *gorm.DB, expenses []models.Expense) {
 // seedIncomes populates the database with income data.
 func seedIncomes(t *testing.T, db *gorm.DB, incomes []models.Income) {
 	for _, income := range incomes {
+		if income.UserID == 0 {
+			income.UserID = testUserID
+		}
 		err := db.Create(&income).Error
 		assert.NoError(t, err, "Failed to seed income: %+v", income)
 	}
@@ -62,7 +76,7 @@ func TestGetExpenseBreakdownByCategory_NoData(t *testing.T) {
 	analyticsService := NewAnalyticsService(db)
 
 	targetDate := time.Date(2023, time.November, 15, 0, 0, 0, 0, time.UTC)
-	stats, err := analyticsService.GetExpenseBreakdownByCategory(targetDate)
+	stats, err := analyticsService.GetExpenseBreakdownByCategory(testUserID, targetDate, false)
 
 	assert.NoError(t, err)
 	assert.Empty(t, stats, "Expected empty stats for no data")
@@ -91,7 +105,7 @@ func TestGetExpenseBreakdownByCategory_WithData(t *testing.T) {
 	}
 	seedExpenses(t, db, expensesToSeed)
 
-	stats, err := analyticsService.GetExpenseBreakdownByCategory(nov2023.AddDate(0,0,14)) // Target date within Nov 2023
+	stats, err := analyticsService.GetExpenseBreakdownByCategory(testUserID, nov2023.AddDate(0, 0, 14), false) // Target date within Nov 2023
 	assert.NoError(t, err)
 	assert.Len(t, stats, 3, "Expected 3 categories for November 2023")
 
@@ -105,13 +119,13 @@ func TestGetExpenseBreakdownByCategory_WithData(t *testing.T) {
 
 	for _, stat := range stats {
 		if stat.Category == "Food" {
-			assert.Equal(t, 70.0, stat.TotalAmount, "Food total amount incorrect")
+			assert.Equal(t, types.Money(70), stat.TotalAmount, "Food total amount incorrect")
 			foundFood = true
 		} else if stat.Category == "Transport" {
-			assert.Equal(t, 30.0, stat.TotalAmount, "Transport total amount incorrect")
+			assert.Equal(t, types.Money(30), stat.TotalAmount, "Transport total amount incorrect")
 			foundTransport = true
 		} else if stat.Category == "Utilities" {
-			assert.Equal(t, 70.0, stat.TotalAmount, "Utilities total amount incorrect")
+			assert.Equal(t, types.Money(70), stat.TotalAmount, "Utilities total amount incorrect")
 			foundUtilities = true
 		}
 	}
@@ -126,18 +140,17 @@ func TestGetExpenseBreakdownByCategory_WithData(t *testing.T) {
 	if len(stats) == 3 {
 		// Check that Transport is the last one with 30.0
 		assert.Equal(t, "Transport", stats[2].Category)
-		assert.Equal(t, 30.0, stats[2].TotalAmount)
+		assert.Equal(t, types.Money(30), stats[2].TotalAmount)
 
 		// Check that the first two items are Food and Utilities, both with 70.0
 		// Their relative order (stats[0] vs stats[1]) can vary.
-		isFoodFirst := stats[0].Category == "Food" && stats[0].TotalAmount == 70.0 && stats[1].Category == "Utilities" && stats[1].TotalAmount == 70.0
-		isUtilitiesFirst := stats[0].Category == "Utilities" && stats[0].TotalAmount == 70.0 && stats[1].Category == "Food" && stats[1].TotalAmount == 70.0
+		isFoodFirst := stats[0].Category == "Food" && stats[0].TotalAmount == 70 && stats[1].Category == "Utilities" && stats[1].TotalAmount == 70
+		isUtilitiesFirst := stats[0].Category == "Utilities" && stats[0].TotalAmount == 70 && stats[1].Category == "Food" && stats[1].TotalAmount == 70
 
 		assert.True(t, isFoodFirst || isUtilitiesFirst, "Expected Food and Utilities (both 70.0) to be the top two categories.")
 	}
 }
 
-
 // TestGetIncomeExpenseTrend_NoData tests behavior with no income/expense data.
 func TestGetIncomeExpenseTrend_NoData(t *testing.T) {
 	db := setupTestDB(t)
@@ -146,16 +159,16 @@ func TestGetIncomeExpenseTrend_NoData(t *testing.T) {
 	analyticsService := NewAnalyticsService(db)
 
 	numMonths := 3
-	trend, err := analyticsService.GetIncomeExpenseTrend(numMonths)
+	trend, err := analyticsService.GetIncomeExpenseTrend(testUserID, numMonths)
 
 	assert.NoError(t, err)
 	assert.Len(t, trend, numMonths, "Expected N entries for N months")
 
 	for i, monthlyStat := range trend {
-		assert.Equal(t, 0.0, monthlyStat.TotalIncome, "Expected 0 income for month %d", i)
-		assert.Equal(t, 0.0, monthlyStat.TotalExpenses, "Expected 0 expenses for month %d", i)
+		assert.Equal(t, types.Money(0), monthlyStat.TotalIncome, "Expected 0 income for month %d", i)
+		assert.Equal(t, types.Money(0), monthlyStat.TotalExpenses, "Expected 0 expenses for month %d", i)
 		// Check month format, e.g., "YYYY-MM"
-		expectedMonth := time.Now().AddDate(0, -(numMonths-1-i), 0)
+		expectedMonth := time.Now().AddDate(0, -(numMonths - 1 - i), 0)
 		assert.Equal(t, expectedMonth.Format("2006-01"), monthlyStat.Month, "Month format or value incorrect for month %d", i)
 	}
 }
@@ -202,31 +215,30 @@ func TestGetIncomeExpenseTrend_WithData(t *testing.T) {
 	expensesMonth3 := []models.Expense{
 		{Amount: 250, Category: "Dining", Date: database.CustomDate{Time: month3Start.AddDate(0, 0, 2)}},
 		// Add an expense from a different month to ensure it's not picked up for current month's expenses
-		{Amount: 5000, Category: "Old", Date: database.CustomDate{Time: month1Start.AddDate(0,0,5)}},
+		{Amount: 5000, Category: "Old", Date: database.CustomDate{Time: month1Start.AddDate(0, 0, 5)}},
 	}
 	seedIncomes(t, db, incomesMonth3)
 	seedExpenses(t, db, expensesMonth3)
 
-
-	trend, err := analyticsService.GetIncomeExpenseTrend(numMonths)
+	trend, err := analyticsService.GetIncomeExpenseTrend(testUserID, numMonths)
 	assert.NoError(t, err)
 	assert.Len(t, trend, numMonths, "Expected N trend entries for N months")
 
 	// Trend should be chronological (oldest to newest)
 	// Assert Month 1
 	assert.Equal(t, month1Start.Format("2006-01"), trend[0].Month)
-	assert.Equal(t, 1000.0, trend[0].TotalIncome)
-	assert.Equal(t, 200.0 + 5000.0, trend[0].TotalExpenses) // 5000 was also in month1
+	assert.Equal(t, types.Money(1000), trend[0].TotalIncome)
+	assert.Equal(t, types.Money(200+5000), trend[0].TotalExpenses) // 5000 was also in month1
 
 	// Assert Month 2
 	assert.Equal(t, month2Start.Format("2006-01"), trend[1].Month)
-	assert.Equal(t, 1200.0, trend[1].TotalIncome)
-	assert.Equal(t, 300.0, trend[1].TotalExpenses)
+	assert.Equal(t, types.Money(1200), trend[1].TotalIncome)
+	assert.Equal(t, types.Money(300), trend[1].TotalExpenses)
 
 	// Assert Month 3 (current)
 	assert.Equal(t, month3Start.Format("2006-01"), trend[2].Month)
-	assert.Equal(t, 1100.0, trend[2].TotalIncome)
-	assert.Equal(t, 250.0, trend[2].TotalExpenses)
+	assert.Equal(t, types.Money(1100), trend[2].TotalIncome)
+	assert.Equal(t, types.Money(250), trend[2].TotalExpenses)
 }
 
 func TestGetExpenseBreakdownByCategory_Order(t *testing.T) {
@@ -235,27 +247,27 @@ func TestGetExpenseBreakdownByCategory_Order(t *testing.T) {
 	t.Cleanup(func() { sqlDB.Close() })
 	analyticsService := NewAnalyticsService(db)
 
-	targetMonth := time.Date(2024, time.January, 1, 0,0,0,0, time.UTC)
+	targetMonth := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
 
 	expensesToSeed := []models.Expense{
-		{Amount: 10, Category: "C", Date: database.CustomDate{Time: targetMonth.AddDate(0,0,1)}},
-		{Amount: 30, Category: "A", Date: database.CustomDate{Time: targetMonth.AddDate(0,0,2)}},
-		{Amount: 20, Category: "B", Date: database.CustomDate{Time: targetMonth.AddDate(0,0,3)}},
+		{Amount: 10, Category: "C", Date: database.CustomDate{Time: targetMonth.AddDate(0, 0, 1)}},
+		{Amount: 30, Category: "A", Date: database.CustomDate{Time: targetMonth.AddDate(0, 0, 2)}},
+		{Amount: 20, Category: "B", Date: database.CustomDate{Time: targetMonth.AddDate(0, 0, 3)}},
 	}
 	seedExpenses(t, db, expensesToSeed)
 
-	stats, err := analyticsService.GetExpenseBreakdownByCategory(targetMonth)
+	stats, err := analyticsService.GetExpenseBreakdownByCategory(testUserID, targetMonth, false)
 	assert.NoError(t, err)
 	assert.Len(t, stats, 3)
 
 	assert.Equal(t, "A", stats[0].Category)
-	assert.Equal(t, 30.0, stats[0].TotalAmount)
+	assert.Equal(t, types.Money(30), stats[0].TotalAmount)
 
 	assert.Equal(t, "B", stats[1].Category)
-	assert.Equal(t, 20.0, stats[1].TotalAmount)
+	assert.Equal(t, types.Money(20), stats[1].TotalAmount)
 
 	assert.Equal(t, "C", stats[2].Category)
-	assert.Equal(t, 10.0, stats[2].TotalAmount)
+	assert.Equal(t, types.Money(10), stats[2].TotalAmount)
 }
 
 // TestGetIncomeExpenseTrend_WithData_EdgeCase_NoExpensesInOneMonth tests behavior
@@ -286,19 +298,19 @@ func TestGetIncomeExpenseTrend_WithData_EdgeCase_NoExpensesInOneMonth(t *testing
 	})
 	// No expenses seeded for month2
 
-	trend, err := analyticsService.GetIncomeExpenseTrend(numMonths)
+	trend, err := analyticsService.GetIncomeExpenseTrend(testUserID, numMonths)
 	assert.NoError(t, err)
 	assert.Len(t, trend, numMonths)
 
 	// Assert Month 1
 	assert.Equal(t, month1Start.Format("2006-01"), trend[0].Month)
-	assert.Equal(t, 1000.0, trend[0].TotalIncome)
-	assert.Equal(t, 200.0, trend[0].TotalExpenses)
+	assert.Equal(t, types.Money(1000), trend[0].TotalIncome)
+	assert.Equal(t, types.Money(200), trend[0].TotalExpenses)
 
 	// Assert Month 2
 	assert.Equal(t, month2Start.Format("2006-01"), trend[1].Month)
-	assert.Equal(t, 1500.0, trend[1].TotalIncome)
-	assert.Equal(t, 0.0, trend[1].TotalExpenses, "Expected 0 expenses for current month")
+	assert.Equal(t, types.Money(1500), trend[1].TotalIncome)
+	assert.Equal(t, types.Money(0), trend[1].TotalExpenses, "Expected 0 expenses for current month")
 }
 
 // TestGetIncomeExpenseTrend_WithData_EdgeCase_NoIncomeInOneMonth tests behavior
@@ -329,17 +341,17 @@ func TestGetIncomeExpenseTrend_WithData_EdgeCase_NoIncomeInOneMonth(t *testing.T
 	})
 	// No income seeded for month2
 
-	trend, err := analyticsService.GetIncomeExpenseTrend(numMonths)
+	trend, err := analyticsService.GetIncomeExpenseTrend(testUserID, numMonths)
 	assert.NoError(t, err)
 	assert.Len(t, trend, numMonths)
 
 	// Assert Month 1
 	assert.Equal(t, month1Start.Format("2006-01"), trend[0].Month)
-	assert.Equal(t, 1000.0, trend[0].TotalIncome)
-	assert.Equal(t, 200.0, trend[0].TotalExpenses)
+	assert.Equal(t, types.Money(1000), trend[0].TotalIncome)
+	assert.Equal(t, types.Money(200), trend[0].TotalExpenses)
 
 	// Assert Month 2
 	assert.Equal(t, month2Start.Format("2006-01"), trend[1].Month)
-	assert.Equal(t, 0.0, trend[1].TotalIncome, "Expected 0 income for current month")
-	assert.Equal(t, 300.0, trend[1].TotalExpenses)
+	assert.Equal(t, types.Money(0), trend[1].TotalIncome, "Expected 0 income for current month")
+	assert.Equal(t, types.Money(300), trend[1].TotalExpenses)
 }
@@ -0,0 +1,264 @@
+package services
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/zayyadi/finance-tracker/internal/database"
+	"github.com/zayyadi/finance-tracker/internal/models"
+	"github.com/zayyadi/finance-tracker/internal/storage"
+	"gorm.io/gorm"
+)
+
+// DefaultMaxAttachmentSize is the largest file accepted as an attachment unless configured otherwise.
+const DefaultMaxAttachmentSize int64 = 10 << 20
+
+// attachmentTypes lists the content types accepted for attachments: photos and scans of receipts and
+// documents.
+var attachmentTypes = map[string]bool{
+	"image/jpeg":      true,
+	"image/png":       true,
+	"image/gif":       true,
+	"image/webp":      true,
+	"application/pdf": true,
+}
+
+// AttachmentService manages files attached to incomes, expenses and debts.
+type AttachmentService struct {
+	DB      *gorm.DB
+	Storage storage.Storage
+	MaxSize int64 // Largest accepted file in bytes
+}
+
+// NewAttachmentService creates a new AttachmentService that keeps files in store.
+func NewAttachmentService(db *gorm.DB, store storage.Storage) *AttachmentService {
+	if db == nil {
+		log.Println("Warning: NewAttachmentService called with nil DB, attempting to use global GetDB()")
+		db = database.GetDB()
+	}
+	return &AttachmentService{DB: db, Storage: store, MaxSize: DefaultMaxAttachmentSize}
+}
+
+// attachableModel returns the model of the records of recordType that can have attachments, or nil.
+func attachableModel(recordType string) interface{} {
+	switch recordType {
+	case "income":
+		return &models.Income{}
+	case "expense":
+		return &models.Expense{}
+	case "debt":
+		return &models.Debt{}
+	}
+	return nil
+}
+
+// checkRecord returns an error unless the user has a record of recordType with the given ID.
+func (s *AttachmentService) checkRecord(userID uint, recordType string, recordID uint) error {
+	model := attachableModel(recordType)
+	if model == nil {
+		return fmt.Errorf("invalid attachment: %s records cannot have attachments", recordType)
+	}
+	var count int64
+	if err := s.DB.Model(model).Where("id = ? AND user_id = ?", recordID, userID).Count(&count).Error; err != nil {
+		return fmt.Errorf("could not check %s %d: %w", recordType, recordID, err)
+	}
+	if count == 0 {
+		return fmt.Errorf("%s record not found", recordType)
+	}
+	return nil
+}
+
+// CreateAttachment stores the file read from r and attaches it to a record. The content type is
+// detected from the file itself, and must be an image or a PDF.
+func (s *AttachmentService) CreateAttachment(userID uint, recordType string, recordID uint, fileName string, r io.Reader) (*models.Attachment, error) {
+	if s.DB == nil || s.Storage == nil {
+		return nil, fmt.Errorf("database connection or storage not initialized in AttachmentService")
+	}
+	if err := s.checkRecord(userID, recordType, recordID); err != nil {
+		return nil, err
+	}
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(r, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("could not read upload: %w", err)
+	}
+	if n == 0 {
+		return nil, fmt.Errorf("invalid attachment: the file is empty")
+	}
+	head = head[:n]
+	contentType, _, _ := strings.Cut(http.DetectContentType(head), ";")
+	if !attachmentTypes[contentType] {
+		return nil, fmt.Errorf("invalid attachment: files of type %s are not accepted; upload a JPEG, PNG, GIF or WebP image or a PDF", contentType)
+	}
+
+	key, err := newStorageKey(userID)
+	if err != nil {
+		return nil, err
+	}
+	// Read one byte past the limit, so an oversized file is noticed without storing all of it.
+	size, err := s.Storage.Save(key, io.LimitReader(io.MultiReader(bytes.NewReader(head), r), s.MaxSize+1))
+	if err != nil {
+		log.Printf("Error storing attachment for %s %d: %v", recordType, recordID, err)
+		return nil, fmt.Errorf("could not store attachment: %w", err)
+	}
+	if size > s.MaxSize {
+		s.deleteFile(key)
+		return nil, fmt.Errorf("attachment too large: files are limited to %d MB", s.MaxSize>>20)
+	}
+
+	attachment := models.Attachment{
+		UserID:      userID,
+		RecordType:  recordType,
+		RecordID:    recordID,
+		FileName:    cleanFileName(fileName),
+		ContentType: contentType,
+		Size:        size,
+		StorageKey:  key,
+	}
+	if err := s.DB.Create(&attachment).Error; err != nil {
+		s.deleteFile(key)
+		log.Printf("Error saving attachment for %s %d: %v", recordType, recordID, err)
+		return nil, fmt.Errorf("could not save attachment: %w", err)
+	}
+	return &attachment, nil
+}
+
+// GetAttachments lists the files attached to a record, oldest first.
+func (s *AttachmentService) GetAttachments(userID uint, recordType string, recordID uint) ([]models.Attachment, error) {
+	if s.DB == nil {
+		return nil, fmt.Errorf("database connection not initialized in AttachmentService")
+	}
+	if err := s.checkRecord(userID, recordType, recordID); err != nil {
+		return nil, err
+	}
+	var attachments []models.Attachment
+	err := s.DB.Where("user_id = ? AND record_type = ? AND record_id = ?", userID, recordType, recordID).
+		Order("created_at, id").Find(&attachments).Error
+	if err != nil {
+		log.Printf("Error retrieving attachments of %s %d: %v", recordType, recordID, err)
+		return nil, fmt.Errorf("could not retrieve attachments: %w", err)
+	}
+	if attachments == nil {
+		return []models.Attachment{}, nil
+	}
+	return attachments, nil
+}
+
+// GetAttachmentByID retrieves a specific attachment by its ID, scoped to the given user.
+func (s *AttachmentService) GetAttachmentByID(userID uint, attachmentID uint) (*models.Attachment, error) {
+	if s.DB == nil {
+		return nil, fmt.Errorf("database connection not initialized in AttachmentService")
+	}
+	var attachment models.Attachment
+	if err := s.DB.Where("id = ? AND user_id = ?", attachmentID, userID).First(&attachment).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("attachment not found")
+		}
+		log.Printf("Error retrieving attachment %d for user %d: %v", attachmentID, userID, err)
+		return nil, fmt.Errorf("could not retrieve attachment: %w", err)
+	}
+	return &attachment, nil
+}
+
+// OpenAttachment returns an attachment with its contents. The caller must close the reader.
+func (s *AttachmentService) OpenAttachment(userID uint, attachmentID uint) (*models.Attachment, io.ReadCloser, error) {
+	attachment, err := s.GetAttachmentByID(userID, attachmentID)
+	if err != nil {
+		return nil, nil, err
+	}
+	file, err := s.Storage.Open(attachment.StorageKey)
+	if err != nil {
+		log.Printf("Error opening file of attachment %d: %v", attachmentID, err)
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, nil, fmt.Errorf("attachment not found: the file is missing from storage")
+		}
+		return nil, nil, fmt.Errorf("could not open attachment: %w", err)
+	}
+	return attachment, file, nil
+}
+
+// DeleteAttachment removes an attachment and its file.
+func (s *AttachmentService) DeleteAttachment(userID uint, attachmentID uint) error {
+	if s.DB == nil {
+		return fmt.Errorf("database connection not initialized in AttachmentService")
+	}
+	attachment, err := s.GetAttachmentByID(userID, attachmentID)
+	if err != nil {
+		if strings.Contains(err.Error(), "attachment not found") {
+			return fmt.Errorf("attachment not found, no rows deleted")
+		}
+		return err
+	}
+	if err := s.DB.Delete(attachment).Error; err != nil {
+		log.Printf("Error deleting attachment %d: %v", attachmentID, err)
+		return fmt.Errorf("could not delete attachment: %w", err)
+	}
+	s.deleteFile(attachment.StorageKey)
+	return nil
+}
+
+// DeleteOrphanedAttachments removes the attachments, and their files, of records that have been
+// deleted for good. Attachments of soft-deleted records are kept, so they come back if the record is
+// restored. It returns the number of attachments removed.
+func (s *AttachmentService) DeleteOrphanedAttachments() (int, error) {
+	if s.DB == nil {
+		return 0, fmt.Errorf("database connection not initialized in AttachmentService")
+	}
+	var orphans []models.Attachment
+	for _, recordType := range []string{"income", "expense", "debt"} {
+		table := taggedTables[recordType]
+		var batch []models.Attachment
+		err := s.DB.Where("record_type = ? AND NOT EXISTS (SELECT 1 FROM "+table+" WHERE "+table+".id = attachments.record_id AND "+table+".user_id = attachments.user_id)", recordType).
+			Find(&batch).Error
+		if err != nil {
+			return 0, fmt.Errorf("could not find orphaned %s attachments: %w", recordType, err)
+		}
+		orphans = append(orphans, batch...)
+	}
+	for _, attachment := range orphans {
+		if err := s.DB.Delete(&attachment).Error; err != nil {
+			return 0, fmt.Errorf("could not delete attachment %d: %w", attachment.ID, err)
+		}
+		s.deleteFile(attachment.StorageKey)
+	}
+	return len(orphans), nil
+}
+
+// deleteFile removes a stored file. A file that cannot be removed is only logged: its attachment is
+// gone either way.
+func (s *AttachmentService) deleteFile(key string) {
+	if err := s.Storage.Delete(key); err != nil {
+		log.Printf("Error deleting stored file %s: %v", key, err)
+	}
+}
+
+// newStorageKey returns a new random key for a file of the given user.
+func newStorageKey(userID uint) (string, error) {
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return "", fmt.Errorf("could not generate storage key: %w", err)
+	}
+	return fmt.Sprintf("%d/%s", userID, hex.EncodeToString(random)), nil
+}
+
+// cleanFileName keeps the last element of an uploaded file's name, which is only used for display
+// and downloads.
+func cleanFileName(name string) string {
+	name = path.Base(strings.ReplaceAll(strings.TrimSpace(name), "\\", "/"))
+	if name == "." || name == "/" || name == "" {
+		return "attachment"
+	}
+	if len(name) > 255 {
+		name = strings.ToValidUTF8(name[len(name)-255:], "") // Keeps the extension
+	}
+	return name
+}
@@ -0,0 +1,87 @@
+package services
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zayyadi/finance-tracker/internal/database"
+	"github.com/zayyadi/finance-tracker/internal/models"
+	"github.com/zayyadi/finance-tracker/internal/storage"
+	"github.com/zayyadi/finance-tracker/internal/types"
+)
+
+// testPNG is the start of a PNG file, enough for its type to be detected.
+var testPNG = append([]byte("\x89PNG\r\n\x1a\n"), bytes.Repeat([]byte{0}, 100)...)
+
+func TestAttachmentService_AttachesFiles(t *testing.T) {
+	db := setupAccountTestDB(t)
+	require.NoError(t, db.AutoMigrate(&models.Debt{}, &models.Attachment{}))
+	store, err := storage.NewLocal(t.TempDir())
+	require.NoError(t, err)
+	service := NewAttachmentService(db, store)
+	day := database.CustomDate{Time: time.Date(2024, time.June, 3, 0, 0, 0, 0, time.UTC)}
+	expense := &models.Expense{UserID: testUserID, Amount: types.Money(4500), Category: "Food", Date: day}
+	require.NoError(t, NewExpenseService(db).CreateExpense(expense))
+
+	receipt, err := service.CreateAttachment(testUserID, "expense", expense.ID, `C:\scans\receipt.png`, bytes.NewReader(testPNG))
+	require.NoError(t, err)
+	assert.Equal(t, "receipt.png", receipt.FileName)
+	assert.Equal(t, "image/png", receipt.ContentType)
+	assert.Equal(t, int64(len(testPNG)), receipt.Size)
+
+	// The type comes from the contents, not the name.
+	_, err = service.CreateAttachment(testUserID, "expense", expense.ID, "notes.png", bytes.NewReader([]byte("just some text")))
+	assert.ErrorContains(t, err, "invalid attachment")
+	_, err = service.CreateAttachment(testUserID, "expense", expense.ID, "empty.pdf", bytes.NewReader(nil))
+	assert.ErrorContains(t, err, "invalid attachment")
+	service.MaxSize = 64
+	_, err = service.CreateAttachment(testUserID, "expense", expense.ID, "big.png", bytes.NewReader(testPNG))
+	assert.ErrorContains(t, err, "too large")
+	service.MaxSize = DefaultMaxAttachmentSize
+
+	// Records must exist and belong to the user.
+	_, err = service.CreateAttachment(testUserID, "expense", expense.ID+1, "receipt.png", bytes.NewReader(testPNG))
+	assert.ErrorContains(t, err, "expense record not found")
+	_, err = service.CreateAttachment(testUserID+1, "expense", expense.ID, "receipt.png", bytes.NewReader(testPNG))
+	assert.ErrorContains(t, err, "expense record not found")
+	_, err = service.CreateAttachment(testUserID, "savings", 1, "receipt.png", bytes.NewReader(testPNG))
+	assert.ErrorContains(t, err, "invalid attachment")
+
+	attachments, err := service.GetAttachments(testUserID, "expense", expense.ID)
+	require.NoError(t, err)
+	require.Len(t, attachments, 1, "Rejected uploads are not kept")
+	assert.Equal(t, receipt.ID, attachments[0].ID)
+
+	attachment, file, err := service.OpenAttachment(testUserID, receipt.ID)
+	require.NoError(t, err)
+	contents, err := io.ReadAll(file)
+	require.NoError(t, file.Close())
+	require.NoError(t, err)
+	assert.Equal(t, testPNG, contents)
+	assert.Equal(t, receipt.FileName, attachment.FileName)
+	_, _, err = service.OpenAttachment(testUserID+1, receipt.ID)
+	assert.ErrorContains(t, err, "attachment not found")
+
+	require.NoError(t, service.DeleteAttachment(testUserID, receipt.ID))
+	_, err = store.Open(receipt.StorageKey)
+	assert.ErrorIs(t, err, storage.ErrNotFound, "The file is deleted with the attachment")
+	assert.ErrorContains(t, service.DeleteAttachment(testUserID, receipt.ID), "attachment not found")
+
+	// Attachments stay while a record is soft-deleted, and go once it is deleted for good.
+	receipt, err = service.CreateAttachment(testUserID, "expense", expense.ID, "receipt.png", bytes.NewReader(testPNG))
+	require.NoError(t, err)
+	require.NoError(t, db.Delete(expense).Error)
+	removed, err := service.DeleteOrphanedAttachments()
+	require.NoError(t, err)
+	assert.Equal(t, 0, removed)
+	require.NoError(t, db.Unscoped().Delete(expense).Error)
+	removed, err = service.DeleteOrphanedAttachments()
+	require.NoError(t, err)
+	assert.Equal(t, 1, removed)
+	_, err = store.Open(receipt.StorageKey)
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+}
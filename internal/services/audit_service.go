@@ -0,0 +1,400 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"reflect"
+	"time"
+
+	"github.com/zayyadi/finance-tracker/internal/database"
+	"github.com/zayyadi/finance-tracker/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// auditedTables maps the tables whose changes are recorded in the audit log to their record types.
+var auditedTables = map[string]string{
+	"incomes":  "income",
+	"expenses": "expense",
+	"debts":    "debt",
+	"savings":  "savings",
+}
+
+// auditIgnoredColumns are left out of the recorded changes: they identify the record or change with
+// every write.
+var auditIgnoredColumns = map[string]bool{"id": true, "user_id": true, "created_at": true, "updated_at": true}
+
+// Audit sources. Changes are made through the API unless withAuditSource says otherwise.
+const (
+	auditSourceAPI       = "api"
+	auditSourceBulk      = "bulk"
+	auditSourceImport    = "import"
+	auditSourceRecurring = "recurring"
+	auditSourceRetention = "retention"
+)
+
+// auditSystemSources are the sources of changes made by scheduled jobs rather than by a user.
+var auditSystemSources = map[string]bool{auditSourceRecurring: true, auditSourceRetention: true}
+
+var (
+	auditValidTypes   = map[string]bool{"income": true, "expense": true, "debt": true, "savings": true}
+	auditValidActions = map[string]bool{"create": true, "update": true, "delete": true, "restore": true, "purge": true}
+	auditValidSources = map[string]bool{auditSourceAPI: true, auditSourceBulk: true, auditSourceImport: true, auditSourceRecurring: true, auditSourceRetention: true}
+)
+
+type auditSourceKey struct{}
+
+// withAuditSource returns db with the changes made through it recorded as coming from source.
+func withAuditSource(db *gorm.DB, source string) *gorm.DB {
+	return db.WithContext(context.WithValue(db.Statement.Context, auditSourceKey{}, source))
+}
+
+// auditValues maps the columns of one record, except the ignored ones, to their values as JSON.
+type auditValues map[string]json.RawMessage
+
+// RegisterAuditHooks installs the GORM callbacks that record every create, update and delete of an
+// income, expense, debt or savings goal in the audit log. Entries are written in the same transaction
+// as the change, so a change that cannot be recorded is not made.
+func RegisterAuditHooks(db *gorm.DB) error {
+	if err := db.Callback().Create().After("gorm:create").Register("audit:after_create", auditAfterCreate); err != nil {
+		return err
+	}
+	if err := db.Callback().Update().Before("gorm:update").Register("audit:before_update", auditBeforeChange); err != nil {
+		return err
+	}
+	if err := db.Callback().Update().After("gorm:update").Register("audit:after_update", auditAfterChange); err != nil {
+		return err
+	}
+	if err := db.Callback().Delete().Before("gorm:delete").Register("audit:before_delete", auditBeforeChange); err != nil {
+		return err
+	}
+	return db.Callback().Delete().After("gorm:delete").Register("audit:after_delete", auditAfterChange)
+}
+
+// auditedStatement reports whether the statement of db writes to an audited table.
+func auditedStatement(db *gorm.DB) bool {
+	_, audited := auditedTables[db.Statement.Table]
+	return audited && db.Statement.Schema != nil && db.Error == nil
+}
+
+// auditAfterCreate records the records just created.
+func auditAfterCreate(db *gorm.DB) {
+	if !auditedStatement(db) || db.Statement.RowsAffected == 0 {
+		return
+	}
+	var entries []models.AuditEntry
+	eachRecord(db.Statement.ReflectValue, func(record reflect.Value) {
+		values := recordAuditValues(db.Statement.Context, db.Statement.Schema, record)
+		if entry := newAuditEntry(db, record, "create", nil, values); entry != nil {
+			entries = append(entries, *entry)
+		}
+	})
+	saveAuditEntries(db, entries)
+}
+
+// auditBeforeChange keeps the records an update or delete is about to change, to compare them with
+// afterwards.
+func auditBeforeChange(db *gorm.DB) {
+	if !auditedStatement(db) {
+		return
+	}
+	stmt := db.Statement
+	query := db.Session(&gorm.Session{NewDB: true}).Unscoped().Table(stmt.Table)
+	where, hasWhere := stmt.Clauses["WHERE"]
+	if hasWhere && where.Expression != nil {
+		query = query.Clauses(where.Expression)
+	}
+	id := primaryKeyOf(stmt)
+	if id != 0 {
+		query = query.Where("id = ?", id)
+	} else if !hasWhere {
+		return // GORM refuses updates and deletes without conditions
+	}
+	before, err := loadAuditRecords(query, stmt.Schema)
+	if err != nil {
+		_ = db.AddError(fmt.Errorf("could not record audit entry: %w", err))
+		return
+	}
+	db.InstanceSet("audit:before", before)
+}
+
+// auditAfterChange records the differences between the records kept by auditBeforeChange and what an
+// update or delete left of them.
+func auditAfterChange(db *gorm.DB) {
+	if !auditedStatement(db) {
+		return
+	}
+	value, ok := db.InstanceGet("audit:before")
+	before, _ := value.(map[uint]auditRecord)
+	if !ok || len(before) == 0 {
+		return
+	}
+	ids := make([]uint, 0, len(before))
+	for id := range before {
+		ids = append(ids, id)
+	}
+	after, err := loadAuditRecords(db.Session(&gorm.Session{NewDB: true}).Unscoped().Table(db.Statement.Table).Where("id IN ?", ids), db.Statement.Schema)
+	if err != nil {
+		_ = db.AddError(fmt.Errorf("could not record audit entry: %w", err))
+		return
+	}
+
+	var entries []models.AuditEntry
+	for _, id := range ids {
+		old := before[id]
+		current, exists := after[id]
+		action := "update"
+		switch {
+		case !exists:
+			action = "purge"
+		case isDeleted(old.values) && !isDeleted(current.values):
+			action = "restore"
+		case !isDeleted(old.values) && isDeleted(current.values):
+			action = "delete"
+		}
+		if entry := newAuditEntry(db, old.record, action, old.values, current.values); entry != nil {
+			entries = append(entries, *entry)
+		}
+	}
+	saveAuditEntries(db, entries)
+}
+
+// auditRecord is a record as loaded for the audit log.
+type auditRecord struct {
+	record reflect.Value
+	values auditValues
+}
+
+// loadAuditRecords loads the records query selects, by ID.
+func loadAuditRecords(query *gorm.DB, s *schema.Schema) (map[uint]auditRecord, error) {
+	rows := reflect.New(reflect.SliceOf(s.ModelType))
+	if err := query.Find(rows.Interface()).Error; err != nil {
+		return nil, err
+	}
+	records := make(map[uint]auditRecord, rows.Elem().Len())
+	for i := 0; i < rows.Elem().Len(); i++ {
+		record := rows.Elem().Index(i)
+		id := recordID(query.Statement.Context, s, record)
+		records[id] = auditRecord{record: record, values: recordAuditValues(query.Statement.Context, s, record)}
+	}
+	return records, nil
+}
+
+// newAuditEntry returns the entry for one change to record, or nil when no recorded column changed.
+func newAuditEntry(db *gorm.DB, record reflect.Value, action string, before, after auditValues) *models.AuditEntry {
+	changes := models.AuditChanges{}
+	for column, value := range after {
+		if !bytes.Equal(before[column], value) && !(before[column] == nil && bytes.Equal(value, []byte("null"))) {
+			changes[column] = models.AuditChange{Before: before[column], After: value}
+		}
+	}
+	for column, value := range before {
+		if _, ok := after[column]; !ok && !bytes.Equal(value, []byte("null")) {
+			changes[column] = models.AuditChange{Before: value}
+		}
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+
+	ctx := db.Statement.Context
+	userIDField := db.Statement.Schema.LookUpField("user_id")
+	var userID uint
+	if userIDField != nil {
+		if value, zero := userIDField.ValueOf(ctx, record); !zero {
+			userID, _ = value.(uint)
+		}
+	}
+	source, _ := ctx.Value(auditSourceKey{}).(string)
+	if source == "" {
+		source = auditSourceAPI
+	}
+	entry := &models.AuditEntry{
+		UserID:     userID,
+		RecordType: auditedTables[db.Statement.Table],
+		RecordID:   recordID(ctx, db.Statement.Schema, record),
+		Action:     action,
+		Source:     source,
+		Changes:    changes,
+	}
+	// Records are only ever changed by their owner, except by scheduled jobs.
+	if !auditSystemSources[source] {
+		entry.ActorID = &userID
+	}
+	return entry
+}
+
+// saveAuditEntries writes entries in the transaction of the change they record.
+func saveAuditEntries(db *gorm.DB, entries []models.AuditEntry) {
+	if len(entries) == 0 {
+		return
+	}
+	if err := db.Session(&gorm.Session{NewDB: true}).Create(&entries).Error; err != nil {
+		log.Printf("Error recording audit entries for %s: %v", db.Statement.Table, err)
+		_ = db.AddError(fmt.Errorf("could not record audit entry: %w", err))
+	}
+}
+
+// eachRecord calls fn with each record in value, which is a record or a slice of records.
+func eachRecord(value reflect.Value, fn func(reflect.Value)) {
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			fn(reflect.Indirect(value.Index(i)))
+		}
+	case reflect.Struct:
+		fn(value)
+	}
+}
+
+// recordAuditValues returns the recorded columns of record with their values as JSON.
+func recordAuditValues(ctx context.Context, s *schema.Schema, record reflect.Value) auditValues {
+	values := auditValues{}
+	for _, field := range s.Fields {
+		if field.DBName == "" || auditIgnoredColumns[field.DBName] {
+			continue
+		}
+		value, _ := field.ValueOf(ctx, record)
+		data, err := json.Marshal(value)
+		if err != nil {
+			data = []byte(fmt.Sprintf("%q", fmt.Sprint(value)))
+		}
+		values[field.DBName] = data
+	}
+	return values
+}
+
+// recordID returns the primary key of record.
+func recordID(ctx context.Context, s *schema.Schema, record reflect.Value) uint {
+	if s.PrioritizedPrimaryField == nil {
+		return 0
+	}
+	value, _ := s.PrioritizedPrimaryField.ValueOf(ctx, record)
+	id, _ := value.(uint)
+	return id
+}
+
+// primaryKeyOf returns the primary key of the model a statement was given, or 0 when it has none.
+func primaryKeyOf(stmt *gorm.Statement) uint {
+	if stmt.ReflectValue.Kind() != reflect.Struct {
+		return 0
+	}
+	return recordID(stmt.Context, stmt.Schema, stmt.ReflectValue)
+}
+
+// isDeleted reports whether the recorded values are those of a soft-deleted record.
+func isDeleted(values auditValues) bool {
+	deletedAt, ok := values["deleted_at"]
+	return ok && !bytes.Equal(deletedAt, []byte("null"))
+}
+
+// AuditService reads the audit log of changes to incomes, expenses, debts and savings goals.
+type AuditService struct {
+	DB *gorm.DB
+}
+
+// NewAuditService creates a new AuditService with a GORM database connection.
+func NewAuditService(db *gorm.DB) *AuditService {
+	if db == nil {
+		log.Println("Warning: NewAuditService called with nil DB, attempting to use global GetDB()")
+		db = database.GetDB()
+	}
+	return &AuditService{DB: db}
+}
+
+// GetRecordHistory retrieves the changes to one of the user's records of recordType, oldest first. The
+// history of deleted and purged records is kept.
+func (s *AuditService) GetRecordHistory(userID uint, recordType string, recordID uint) ([]models.AuditEntry, error) {
+	if s.DB == nil {
+		return nil, fmt.Errorf("database connection not initialized in AuditService")
+	}
+	model := auditedModel(recordType)
+	if model == nil {
+		return nil, fmt.Errorf("invalid filter: unknown type %q, expected income, expense, debt or savings", recordType)
+	}
+	var entries []models.AuditEntry
+	err := s.DB.Where("user_id = ? AND record_type = ? AND record_id = ?", userID, recordType, recordID).
+		Order("created_at, id").Find(&entries).Error
+	if err != nil {
+		log.Printf("Error retrieving history of %s %d for user %d: %v", recordType, recordID, userID, err)
+		return nil, fmt.Errorf("could not retrieve history: %w", err)
+	}
+	if len(entries) == 0 {
+		// Records from before the audit log started have no history yet.
+		var count int64
+		if err := s.DB.Unscoped().Model(model).Where("id = ? AND user_id = ?", recordID, userID).Count(&count).Error; err != nil {
+			return nil, fmt.Errorf("could not retrieve history: %w", err)
+		}
+		if count == 0 {
+			return nil, fmt.Errorf("%s record not found", recordType)
+		}
+		return []models.AuditEntry{}, nil
+	}
+	return entries, nil
+}
+
+// GetAuditLog retrieves a page, counting from 1, of the changes to the user's records matching filter,
+// newest first.
+func (s *AuditService) GetAuditLog(userID uint, filter models.AuditFilter, page int, limit int) (*models.Page[models.AuditEntry], error) {
+	if s.DB == nil {
+		return nil, fmt.Errorf("database connection not initialized in AuditService")
+	}
+	query := s.DB.Model(&models.AuditEntry{}).Where("user_id = ?", userID)
+	if filter.Type != "" {
+		if !auditValidTypes[filter.Type] {
+			return nil, fmt.Errorf("invalid filter: unknown type %q, expected income, expense, debt or savings", filter.Type)
+		}
+		query = query.Where("record_type = ?", filter.Type)
+	}
+	if filter.RecordID != 0 {
+		query = query.Where("record_id = ?", filter.RecordID)
+	}
+	if filter.Action != "" {
+		if !auditValidActions[filter.Action] {
+			return nil, fmt.Errorf("invalid filter: unknown action %q, expected create, update, delete, restore or purge", filter.Action)
+		}
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.Source != "" {
+		if !auditValidSources[filter.Source] {
+			return nil, fmt.Errorf("invalid filter: unknown source %q, expected api, bulk, import, recurring or retention", filter.Source)
+		}
+		query = query.Where("source = ?", filter.Source)
+	}
+	if filter.StartDate != "" {
+		start, err := time.Parse("2006-01-02", filter.StartDate)
+		if err != nil {
+			return nil, fmt.Errorf("invalid start date format: %w", err)
+		}
+		query = query.Where("created_at >= ?", start)
+	}
+	if filter.EndDate != "" {
+		end, err := time.Parse("2006-01-02", filter.EndDate)
+		if err != nil {
+			return nil, fmt.Errorf("invalid end date format: %w", err)
+		}
+		query = query.Where("created_at < ?", end.AddDate(0, 0, 1))
+	}
+
+	entries, err := paginate[models.AuditEntry](query.Order(clause.OrderBy{Columns: []clause.OrderByColumn{
+		{Column: clause.Column{Name: "created_at"}, Desc: true},
+		{Column: clause.Column{Name: "id"}, Desc: true},
+	}}), page, limit)
+	if err != nil {
+		log.Printf("Error retrieving audit log for user %d: %v", userID, err)
+		return nil, fmt.Errorf("could not retrieve audit log: %w", err)
+	}
+	return entries, nil
+}
+
+// auditedModel returns the model of the records of recordType, or nil.
+func auditedModel(recordType string) interface{} {
+	if !auditValidTypes[recordType] {
+		return nil
+	}
+	return trashedModel(recordType)
+}
@@ -0,0 +1,140 @@
+package services
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zayyadi/finance-tracker/internal/database"
+	"github.com/zayyadi/finance-tracker/internal/models"
+	"github.com/zayyadi/finance-tracker/internal/types"
+	"gorm.io/gorm"
+)
+
+func setupAuditTestDB(t *testing.T) *gorm.DB {
+	db := setupTrashTestDB(t)
+	require.NoError(t, db.AutoMigrate(&models.AuditEntry{}))
+	require.NoError(t, RegisterAuditHooks(db))
+	return db
+}
+
+func TestAuditService_RecordHistory(t *testing.T) {
+	db := setupAuditTestDB(t)
+	service := NewAuditService(db)
+	expenseService := NewExpenseService(db)
+	trashService := NewTrashService(db, nil)
+	date := database.CustomDate{Time: time.Date(2024, time.May, 3, 0, 0, 0, 0, time.UTC)}
+
+	expense := models.Expense{UserID: testUserID, Amount: types.NewMoneyFromMinor(1500), Category: "Food", Date: date, Note: "lunch"}
+	require.NoError(t, expenseService.CreateExpense(&expense))
+	note := "team lunch"
+	amount := types.NewMoneyFromMinor(1800)
+	_, err := expenseService.UpdateExpense(testUserID, expense.ID, &models.ExpenseUpdateRequest{Amount: &amount, Note: &note})
+	require.NoError(t, err)
+	_, err = expenseService.UpdateExpense(testUserID, expense.ID, &models.ExpenseUpdateRequest{Note: &note})
+	require.NoError(t, err)
+	require.NoError(t, expenseService.DeleteExpense(testUserID, expense.ID))
+	require.NoError(t, trashService.RestoreRecord(testUserID, "expense", expense.ID))
+	require.NoError(t, expenseService.DeleteExpense(testUserID, expense.ID))
+	require.NoError(t, trashService.PurgeRecord(testUserID, "expense", expense.ID))
+
+	history, err := service.GetRecordHistory(testUserID, "expense", expense.ID)
+	require.NoError(t, err, "The history of a purged record is kept")
+	actions := make([]string, len(history))
+	for i, entry := range history {
+		actions[i] = entry.Action
+		assert.Equal(t, "api", entry.Source)
+		require.NotNil(t, entry.ActorID)
+		assert.Equal(t, testUserID, *entry.ActorID)
+	}
+	assert.Equal(t, []string{"create", "update", "delete", "restore", "delete", "purge"}, actions, "Updates that change nothing are not recorded")
+
+	created := history[0].Changes
+	assert.JSONEq(t, `"lunch"`, string(created["note"].After))
+	assert.JSONEq(t, "null", string(created["note"].Before))
+	assert.NotContains(t, created, "updated_at")
+	assert.NotContains(t, created, "deleted_at", "Null columns of a new record are left out")
+
+	updated := history[1].Changes
+	assert.Len(t, updated, 2)
+	assert.JSONEq(t, `"lunch"`, string(updated["note"].Before))
+	assert.JSONEq(t, `"team lunch"`, string(updated["note"].After))
+	var before, after types.Money
+	require.NoError(t, json.Unmarshal(updated["amount"].Before, &before))
+	require.NoError(t, json.Unmarshal(updated["amount"].After, &after))
+	assert.Equal(t, types.NewMoneyFromMinor(1500), before)
+	assert.Equal(t, amount, after)
+
+	assert.Contains(t, history[2].Changes, "deleted_at")
+	assert.JSONEq(t, "null", string(history[3].Changes["deleted_at"].After))
+	assert.JSONEq(t, `"team lunch"`, string(history[5].Changes["note"].Before))
+	assert.JSONEq(t, "null", string(history[5].Changes["note"].After))
+
+	_, err = service.GetRecordHistory(testUserID+1, "expense", expense.ID)
+	assert.ErrorContains(t, err, "expense record not found")
+	_, err = service.GetRecordHistory(testUserID, "transfer", expense.ID)
+	assert.ErrorContains(t, err, "invalid filter")
+
+	// Records from before the audit log started have an empty history.
+	untracked := models.Debt{UserID: testUserID, DebtorName: "Sam", Amount: types.NewMoneyFromMinor(5000), Currency: "USD", DueDate: date, Status: "Pending"}
+	require.NoError(t, db.Create(&untracked).Error)
+	require.NoError(t, db.Where("record_type = ?", "debt").Delete(&models.AuditEntry{}).Error)
+	history, err = service.GetRecordHistory(testUserID, "debt", untracked.ID)
+	require.NoError(t, err)
+	assert.Empty(t, history)
+}
+
+func TestAuditService_SourcesAndFeed(t *testing.T) {
+	db := setupAuditTestDB(t)
+	service := NewAuditService(db)
+	debtService := NewDebtService(db)
+	date := database.CustomDate{Time: time.Date(2024, time.May, 3, 0, 0, 0, 0, time.UTC)}
+
+	debt := models.Debt{UserID: testUserID, DebtorName: "Sam", Amount: types.NewMoneyFromMinor(5000), Currency: "USD", DueDate: date, Status: "Pending"}
+	require.NoError(t, debtService.CreateDebt(&debt))
+	result, err := debtService.BulkDeleteDebts(testUserID, []uint{debt.ID})
+	require.NoError(t, err)
+	require.True(t, result.Applied)
+	_, err = debtService.BulkDeleteDebts(testUserID, []uint{debt.ID})
+	require.NoError(t, err)
+
+	savings := models.Savings{UserID: testUserID, GoalName: "Bike", GoalAmount: types.NewMoneyFromMinor(50000), Currency: "USD"}
+	require.NoError(t, db.Create(&savings).Error)
+	require.NoError(t, db.Unscoped().Model(&savings).Update("deleted_at", time.Now().UTC().Add(-31*24*time.Hour)).Error)
+	purged, err := NewTrashService(db, nil).PurgeExpired()
+	require.NoError(t, err)
+	require.Equal(t, int64(1), purged)
+
+	feed, err := service.GetAuditLog(testUserID, models.AuditFilter{}, 1, 10)
+	require.NoError(t, err)
+	require.Equal(t, int64(5), feed.Total, "A bulk request that is not applied leaves no entries")
+	assert.Equal(t, "purge", feed.Items[0].Action, "The feed is newest first")
+	assert.Equal(t, "retention", feed.Items[0].Source)
+	assert.Nil(t, feed.Items[0].ActorID, "Scheduled jobs have no actor")
+
+	feed, err = service.GetAuditLog(testUserID, models.AuditFilter{Source: "bulk"}, 1, 10)
+	require.NoError(t, err)
+	require.Len(t, feed.Items, 1)
+	assert.Equal(t, "delete", feed.Items[0].Action)
+	assert.Equal(t, debt.ID, feed.Items[0].RecordID)
+
+	feed, err = service.GetAuditLog(testUserID, models.AuditFilter{Type: "debt", RecordID: debt.ID, Action: "create"}, 1, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), feed.Total)
+	today := time.Now().UTC().Format("2006-01-02")
+	feed, err = service.GetAuditLog(testUserID, models.AuditFilter{StartDate: today, EndDate: today}, 1, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), feed.Total)
+	feed, err = service.GetAuditLog(testUserID+1, models.AuditFilter{}, 1, 10)
+	require.NoError(t, err)
+	assert.Empty(t, feed.Items)
+
+	_, err = service.GetAuditLog(testUserID, models.AuditFilter{Action: "rename"}, 1, 10)
+	assert.ErrorContains(t, err, "invalid filter")
+	_, err = service.GetAuditLog(testUserID, models.AuditFilter{Source: "cli"}, 1, 10)
+	assert.ErrorContains(t, err, "invalid filter")
+	_, err = service.GetAuditLog(testUserID, models.AuditFilter{StartDate: "May 3"}, 1, 10)
+	assert.ErrorContains(t, err, "invalid start date format")
+}
@@ -0,0 +1,208 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/zayyadi/finance-tracker/internal/database"
+	"github.com/zayyadi/finance-tracker/internal/models"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+const (
+	accessTokenTTL  = 15 * time.Minute
+	refreshTokenTTL = 7 * 24 * time.Hour
+
+	tokenTypeAccess  = "access"
+	tokenTypeRefresh = "refresh"
+)
+
+// tokenClaims are the JWT claims issued by AuthService.
+// TokenType distinguishes access tokens from refresh tokens so one cannot be used as the other.
+type tokenClaims struct {
+	TokenType string `json:"typ"`
+	jwt.RegisteredClaims
+}
+
+// AuthService handles user registration, login and JWT issuance/validation.
+type AuthService struct {
+	DB        *gorm.DB
+	jwtSecret []byte
+}
+
+// NewAuthService creates a new AuthService with a GORM database connection and the JWT signing secret.
+func NewAuthService(db *gorm.DB, jwtSecret string) *AuthService {
+	if db == nil {
+		log.Println("Warning: NewAuthService called with nil DB, attempting to use global GetDB()")
+		db = database.GetDB()
+	}
+	return &AuthService{DB: db, jwtSecret: []byte(jwtSecret)}
+}
+
+// Register creates a new user account and returns a fresh token pair for it.
+func (s *AuthService) Register(req *models.RegisterRequest) (*models.AuthResponse, error) {
+	if s.DB == nil {
+		return nil, fmt.Errorf("database connection not initialized in AuthService")
+	}
+
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+	username := strings.TrimSpace(req.Username)
+
+	var existing int64
+	if err := s.DB.Model(&models.User{}).Where("email = ? OR username = ?", email, username).Count(&existing).Error; err != nil {
+		log.Printf("Error checking for existing user %s: %v", email, err)
+		return nil, fmt.Errorf("could not register user: %w", err)
+	}
+	if existing > 0 {
+		return nil, fmt.Errorf("user with this email or username already exists")
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, fmt.Errorf("could not hash password: %w", err)
+	}
+
+	baseCurrency := req.BaseCurrency
+	if baseCurrency == "" {
+		baseCurrency = models.DefaultCurrency
+	}
+
+	user := models.User{
+		Username:     username,
+		Email:        email,
+		PasswordHash: string(hash),
+		BaseCurrency: baseCurrency,
+	}
+	if err := s.DB.Create(&user).Error; err != nil {
+		log.Printf("Error creating user %s: %v", email, err)
+		return nil, fmt.Errorf("could not register user: %w", err)
+	}
+
+	return s.issueTokens(&user)
+}
+
+// Login verifies the credentials and returns a fresh token pair.
+func (s *AuthService) Login(req *models.LoginRequest) (*models.AuthResponse, error) {
+	if s.DB == nil {
+		return nil, fmt.Errorf("database connection not initialized in AuthService")
+	}
+
+	var user models.User
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+	if err := s.DB.Where("email = ?", email).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("invalid email or password")
+		}
+		log.Printf("Error retrieving user %s for login: %v", email, err)
+		return nil, fmt.Errorf("could not log in: %w", err)
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password)); err != nil {
+		return nil, fmt.Errorf("invalid email or password")
+	}
+
+	return s.issueTokens(&user)
+}
+
+// Refresh exchanges a valid refresh token for a new token pair.
+func (s *AuthService) Refresh(refreshToken string) (*models.AuthResponse, error) {
+	userID, err := s.parseToken(refreshToken, tokenTypeRefresh)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.GetUserByID(userID)
+	if err != nil {
+		// The account may have been deleted since the token was issued.
+		return nil, fmt.Errorf("invalid token: %w", err)
+	}
+	return s.issueTokens(user)
+}
+
+// ValidateAccessToken checks an access token and returns the user ID it was issued for.
+func (s *AuthService) ValidateAccessToken(accessToken string) (uint, error) {
+	return s.parseToken(accessToken, tokenTypeAccess)
+}
+
+// GetUserByID retrieves a user by ID.
+func (s *AuthService) GetUserByID(userID uint) (*models.User, error) {
+	if s.DB == nil {
+		return nil, fmt.Errorf("database connection not initialized in AuthService")
+	}
+	var user models.User
+	if err := s.DB.First(&user, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("user not found")
+		}
+		log.Printf("Error retrieving user %d: %v", userID, err)
+		return nil, fmt.Errorf("could not retrieve user: %w", err)
+	}
+	return &user, nil
+}
+
+func (s *AuthService) issueTokens(user *models.User) (*models.AuthResponse, error) {
+	now := time.Now()
+	accessToken, err := s.signToken(user.ID, tokenTypeAccess, now, accessTokenTTL)
+	if err != nil {
+		return nil, err
+	}
+	refreshToken, err := s.signToken(user.ID, tokenTypeRefresh, now, refreshTokenTTL)
+	if err != nil {
+		return nil, err
+	}
+	return &models.AuthResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(accessTokenTTL.Seconds()),
+		User:         user.ToResponse(),
+	}, nil
+}
+
+func (s *AuthService) signToken(userID uint, tokenType string, issuedAt time.Time, ttl time.Duration) (string, error) {
+	if len(s.jwtSecret) == 0 {
+		return "", fmt.Errorf("JWT secret is not configured")
+	}
+	claims := tokenClaims{
+		TokenType: tokenType,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   strconv.FormatUint(uint64(userID), 10),
+			IssuedAt:  jwt.NewNumericDate(issuedAt),
+			ExpiresAt: jwt.NewNumericDate(issuedAt.Add(ttl)),
+		},
+	}
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.jwtSecret)
+	if err != nil {
+		return "", fmt.Errorf("could not sign %s token: %w", tokenType, err)
+	}
+	return signed, nil
+}
+
+func (s *AuthService) parseToken(tokenString string, expectedType string) (uint, error) {
+	if len(s.jwtSecret) == 0 {
+		return 0, fmt.Errorf("JWT secret is not configured")
+	}
+
+	var claims tokenClaims
+	_, err := jwt.ParseWithClaims(tokenString, &claims, func(token *jwt.Token) (interface{}, error) {
+		return s.jwtSecret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
+	if err != nil {
+		return 0, fmt.Errorf("invalid token: %w", err)
+	}
+	if claims.TokenType != expectedType {
+		return 0, fmt.Errorf("invalid token: expected %s token", expectedType)
+	}
+
+	userID, err := strconv.ParseUint(claims.Subject, 10, 32)
+	if err != nil || userID == 0 {
+		return 0, fmt.Errorf("invalid token: malformed subject")
+	}
+	return uint(userID), nil
+}
@@ -0,0 +1,100 @@
+package services
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zayyadi/finance-tracker/internal/models"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// setupAuthTestDB initializes an in-memory SQLite database for auth service testing.
+func setupAuthTestDB(t *testing.T) *gorm.DB {
+	dsn := fmt.Sprintf("file:%s_%d?mode=memory&cache=shared", t.Name(), time.Now().UnixNano())
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{})
+	assert.NoError(t, err, "Failed to connect to in-memory SQLite")
+
+	sqlDB, _ := db.DB()
+	t.Cleanup(func() { sqlDB.Close() })
+
+	err = db.AutoMigrate(&models.User{})
+	assert.NoError(t, err, "Failed to auto-migrate models")
+	return db
+}
+
+func TestAuthService_RegisterAndLogin(t *testing.T) {
+	db := setupAuthTestDB(t)
+	service := NewAuthService(db, "test-secret")
+
+	registered, err := service.Register(&models.RegisterRequest{Username: "alice", Email: "Alice@Example.com", Password: "correct-horse"})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, registered.AccessToken)
+	assert.NotEmpty(t, registered.RefreshToken)
+	assert.Equal(t, "alice@example.com", registered.User.Email, "Email should be normalized")
+
+	var stored models.User
+	db.First(&stored, registered.User.ID)
+	assert.NotEqual(t, "correct-horse", stored.PasswordHash, "Password must be hashed")
+
+	userID, err := service.ValidateAccessToken(registered.AccessToken)
+	assert.NoError(t, err)
+	assert.Equal(t, registered.User.ID, userID)
+
+	loggedIn, err := service.Login(&models.LoginRequest{Email: "alice@example.com", Password: "correct-horse"})
+	assert.NoError(t, err)
+	assert.Equal(t, registered.User.ID, loggedIn.User.ID)
+
+	_, err = service.Login(&models.LoginRequest{Email: "alice@example.com", Password: "wrong-password"})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid email or password")
+
+	_, err = service.Login(&models.LoginRequest{Email: "nobody@example.com", Password: "correct-horse"})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid email or password")
+}
+
+func TestAuthService_RegisterDuplicate(t *testing.T) {
+	db := setupAuthTestDB(t)
+	service := NewAuthService(db, "test-secret")
+
+	_, err := service.Register(&models.RegisterRequest{Username: "bob", Email: "bob@example.com", Password: "password123"})
+	assert.NoError(t, err)
+
+	_, err = service.Register(&models.RegisterRequest{Username: "bob2", Email: "bob@example.com", Password: "password123"})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "already exists")
+}
+
+func TestAuthService_TokenTypesAreNotInterchangeable(t *testing.T) {
+	db := setupAuthTestDB(t)
+	service := NewAuthService(db, "test-secret")
+
+	tokens, err := service.Register(&models.RegisterRequest{Username: "carol", Email: "carol@example.com", Password: "password123"})
+	assert.NoError(t, err)
+
+	_, err = service.ValidateAccessToken(tokens.RefreshToken)
+	assert.Error(t, err, "Refresh token must not be accepted as an access token")
+
+	_, err = service.Refresh(tokens.AccessToken)
+	assert.Error(t, err, "Access token must not be accepted as a refresh token")
+
+	refreshed, err := service.Refresh(tokens.RefreshToken)
+	assert.NoError(t, err)
+	assert.Equal(t, tokens.User.ID, refreshed.User.ID)
+}
+
+func TestAuthService_RejectsTokenSignedWithOtherSecret(t *testing.T) {
+	db := setupAuthTestDB(t)
+	issuer := NewAuthService(db, "secret-one")
+	verifier := NewAuthService(db, "secret-two")
+
+	tokens, err := issuer.Register(&models.RegisterRequest{Username: "dave", Email: "dave@example.com", Password: "password123"})
+	assert.NoError(t, err)
+
+	_, err = verifier.ValidateAccessToken(tokens.AccessToken)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid token")
+}
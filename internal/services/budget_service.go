@@ -0,0 +1,219 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"github.com/zayyadi/finance-tracker/internal/database"
+	"github.com/zayyadi/finance-tracker/internal/models"
+	"github.com/zayyadi/finance-tracker/internal/types"
+	"gorm.io/gorm"
+)
+
+// BudgetService provides methods for managing category budgets and tracking spending against them.
+type BudgetService struct {
+	DB *gorm.DB
+}
+
+// NewBudgetService creates a new BudgetService with a GORM database connection.
+func NewBudgetService(db *gorm.DB) *BudgetService {
+	if db == nil {
+		log.Println("Warning: NewBudgetService called with nil DB, attempting to use global GetDB()")
+		db = database.GetDB()
+	}
+	return &BudgetService{DB: db}
+}
+
+// CreateBudget inserts a new budget. An empty period defaults to monthly and an empty currency to the
+// user's base currency.
+func (s *BudgetService) CreateBudget(budget *models.Budget) error {
+	if s.DB == nil {
+		return fmt.Errorf("database connection not initialized in BudgetService")
+	}
+	if budget.Period == "" {
+		budget.Period = "monthly"
+	}
+	currency, err := resolveCurrency(s.DB, budget.UserID, budget.Currency)
+	if err != nil {
+		return fmt.Errorf("could not create budget: %w", err)
+	}
+	budget.Currency = currency
+	// Budgets match expenses by category name, so use the spelling of an existing category.
+	category, err := findCategoryByName(s.DB, budget.UserID, "expense", budget.Category)
+	if err != nil {
+		return fmt.Errorf("could not create budget: %w", err)
+	}
+	if category != nil {
+		budget.Category = category.Name
+	} else {
+		budget.Category = normalizeCategoryName(budget.Category)
+	}
+
+	var count int64
+	if err := s.DB.Model(&models.Budget{}).Where("user_id = ? AND category = ? AND period = ?", budget.UserID, budget.Category, budget.Period).Count(&count).Error; err != nil {
+		log.Printf("Error checking existing budgets for user %d: %v", budget.UserID, err)
+		return fmt.Errorf("could not create budget: %w", err)
+	}
+	if count > 0 {
+		return fmt.Errorf("a %s budget for category %q already exists", budget.Period, budget.Category)
+	}
+
+	if err := s.DB.Create(budget).Error; err != nil {
+		log.Printf("Error creating budget for user %d: %v", budget.UserID, err)
+		return fmt.Errorf("could not create budget: %w", err)
+	}
+	return nil
+}
+
+// GetBudgetByID retrieves a specific budget by its ID, scoped to the given user.
+func (s *BudgetService) GetBudgetByID(userID uint, budgetID uint) (*models.Budget, error) {
+	if s.DB == nil {
+		return nil, fmt.Errorf("database connection not initialized in BudgetService")
+	}
+	var budget models.Budget
+	if err := s.DB.Where("id = ? AND user_id = ?", budgetID, userID).First(&budget).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("budget not found")
+		}
+		log.Printf("Error retrieving budget %d for user %d: %v", budgetID, userID, err)
+		return nil, fmt.Errorf("could not retrieve budget: %w", err)
+	}
+	return &budget, nil
+}
+
+// GetBudgets retrieves a user's budgets with pagination, optionally limited to one period.
+func (s *BudgetService) GetBudgets(userID uint, period string, offset int, limit int) ([]models.Budget, error) {
+	if s.DB == nil {
+		return nil, fmt.Errorf("database connection not initialized in BudgetService")
+	}
+	query := s.DB.Where("user_id = ?", userID)
+	if period != "" {
+		query = query.Where("period = ?", period)
+	}
+	var budgets []models.Budget
+	if err := query.Order("period, category").Offset(offset).Limit(limit).Find(&budgets).Error; err != nil {
+		log.Printf("Error retrieving budgets for user %d: %v", userID, err)
+		return nil, fmt.Errorf("could not retrieve budgets: %w", err)
+	}
+	if budgets == nil {
+		return []models.Budget{}, nil
+	}
+	return budgets, nil
+}
+
+// UpdateBudget updates the amount or currency of an existing budget owned by the given user.
+func (s *BudgetService) UpdateBudget(userID uint, budgetID uint, updateData *models.BudgetUpdateRequest) (*models.Budget, error) {
+	if s.DB == nil {
+		return nil, fmt.Errorf("database connection not initialized in BudgetService")
+	}
+	existing, err := s.GetBudgetByID(userID, budgetID)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := make(map[string]interface{})
+	if updateData.Amount != nil {
+		updates["amount"] = *updateData.Amount
+	}
+	if updateData.Currency != nil {
+		updates["currency"] = *updateData.Currency
+	}
+	if len(updates) == 0 {
+		return existing, nil
+	}
+
+	if err := s.DB.Model(existing).Where("id = ? AND user_id = ?", budgetID, userID).Updates(updates).Error; err != nil {
+		log.Printf("Error updating budget %d: %v", budgetID, err)
+		return nil, fmt.Errorf("could not update budget: %w", err)
+	}
+	return s.GetBudgetByID(userID, budgetID)
+}
+
+// DeleteBudget deletes a budget. Budgets are removed permanently so the category can be budgeted again.
+func (s *BudgetService) DeleteBudget(userID uint, budgetID uint) error {
+	if s.DB == nil {
+		return fmt.Errorf("database connection not initialized in BudgetService")
+	}
+	result := s.DB.Unscoped().Where("id = ? AND user_id = ?", budgetID, userID).Delete(&models.Budget{})
+	if result.Error != nil {
+		log.Printf("Error deleting budget %d: %v", budgetID, result.Error)
+		return fmt.Errorf("could not delete budget: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("budget not found, no rows deleted")
+	}
+	return nil
+}
+
+// GetBudgetStatus compares each of the user's budgets for the given period with the expenses in the
+// period containing asOf, using the same per-category totals as the expense breakdown. Spending up to
+// asOf is extrapolated to the end of the period to project whether the budget will hold.
+func (s *BudgetService) GetBudgetStatus(userID uint, period string, asOf time.Time) (*models.BudgetStatusResponse, error) {
+	if s.DB == nil {
+		return nil, fmt.Errorf("database connection not initialized in BudgetService")
+	}
+	asOf = dateOnly(asOf)
+	startDate, endDate, err := CalculatePeriodDates(asOf, period)
+	if err != nil {
+		return nil, err
+	}
+	daysTotal := int(endDate.Sub(startDate).Hours()/24) + 1
+	daysElapsed := int(asOf.Sub(startDate).Hours()/24) + 1
+	if daysElapsed > daysTotal {
+		daysElapsed = daysTotal
+	}
+
+	var budgets []models.Budget
+	if err := s.DB.Where("user_id = ? AND period = ?", userID, period).Order("category").Find(&budgets).Error; err != nil {
+		log.Printf("Error retrieving %s budgets for user %d: %v", period, userID, err)
+		return nil, fmt.Errorf("could not retrieve budgets: %w", err)
+	}
+
+	converter, err := NewCurrencyService(s.DB).NewConverter(userID)
+	if err != nil {
+		return nil, fmt.Errorf("error preparing currency conversion: %w", err)
+	}
+	spentByCategory, err := expenseTotalsByCategory(s.DB, converter, userID, startDate, endDate)
+	if err != nil {
+		log.Printf("Error calculating spending by category for user %d, %s: %v", userID, startDate.Format("2006-01-02"), err)
+		return nil, err
+	}
+
+	response := &models.BudgetStatusResponse{
+		Period:      period,
+		StartDate:   startDate.Format("2006-01-02"),
+		EndDate:     endDate.Format("2006-01-02"),
+		DaysElapsed: daysElapsed,
+		DaysTotal:   daysTotal,
+		Currency:    converter.Base,
+		Budgets:     make([]models.BudgetStatus, 0, len(budgets)),
+	}
+	for _, budget := range budgets {
+		budgeted, err := converter.Convert(budget.Amount, budget.Currency, endDate)
+		if err != nil {
+			return nil, err
+		}
+		spent := spentByCategory[budget.Category]
+
+		status := models.BudgetStatus{
+			BudgetID:       budget.ID,
+			Category:       budget.Category,
+			Budgeted:       budgeted,
+			Spent:          spent,
+			Remaining:      budgeted - spent,
+			ProjectedSpend: spent,
+			OverBudget:     spent > budgeted,
+		}
+		if budgeted > 0 {
+			status.PercentUsed = math.Round(float64(spent)/float64(budgeted)*1000) / 10
+		}
+		if daysElapsed < daysTotal {
+			status.ProjectedSpend = types.Money(math.Round(float64(spent) * float64(daysTotal) / float64(daysElapsed)))
+		}
+		response.Budgets = append(response.Budgets, status)
+	}
+	return response, nil
+}
@@ -0,0 +1,74 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zayyadi/finance-tracker/internal/database"
+	"github.com/zayyadi/finance-tracker/internal/models"
+	"github.com/zayyadi/finance-tracker/internal/types"
+)
+
+func TestBudgetService_Status(t *testing.T) {
+	db := setupAccountTestDB(t)
+	require.NoError(t, db.AutoMigrate(&models.Budget{}))
+	service := NewBudgetService(db)
+	expenseService := NewExpenseService(db)
+
+	food := &models.Budget{UserID: testUserID, Category: "Food", Amount: types.Money(40000)}
+	require.NoError(t, service.CreateBudget(food))
+	assert.Equal(t, "monthly", food.Period)
+	assert.Equal(t, "USD", food.Currency)
+	require.NoError(t, service.CreateBudget(&models.Budget{UserID: testUserID, Category: "Fuel", Amount: types.Money(10000)}))
+
+	day := func(d int) database.CustomDate {
+		return database.CustomDate{Time: time.Date(2024, time.June, d, 0, 0, 0, 0, time.UTC)}
+	}
+	require.NoError(t, expenseService.CreateExpense(&models.Expense{UserID: testUserID, Amount: types.Money(15000), Category: "Food", Date: day(2)}))
+	require.NoError(t, expenseService.CreateExpense(&models.Expense{UserID: testUserID, Amount: types.Money(9000), Category: "Food", Date: day(9)}))
+	require.NoError(t, expenseService.CreateExpense(&models.Expense{UserID: testUserID, Amount: types.Money(12000), Category: "Fuel", Date: day(5)}))
+	require.NoError(t, expenseService.CreateExpense(&models.Expense{UserID: testUserID, Amount: types.Money(5000), Category: "Books", Date: day(5)}))
+
+	status, err := service.GetBudgetStatus(testUserID, "monthly", time.Date(2024, time.June, 10, 15, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, "2024-06-01", status.StartDate)
+	assert.Equal(t, "2024-06-30", status.EndDate)
+	assert.Equal(t, 10, status.DaysElapsed)
+	assert.Equal(t, 30, status.DaysTotal)
+	require.Len(t, status.Budgets, 2, "Categories without a budget are not reported")
+
+	foodStatus := status.Budgets[0]
+	assert.Equal(t, "Food", foodStatus.Category)
+	assert.Equal(t, types.Money(24000), foodStatus.Spent)
+	assert.Equal(t, types.Money(16000), foodStatus.Remaining)
+	assert.Equal(t, 60.0, foodStatus.PercentUsed)
+	assert.Equal(t, types.Money(72000), foodStatus.ProjectedSpend, "24000 over 10 of 30 days")
+	assert.False(t, foodStatus.OverBudget)
+
+	fuelStatus := status.Budgets[1]
+	assert.Equal(t, types.Money(-2000), fuelStatus.Remaining)
+	assert.Equal(t, 120.0, fuelStatus.PercentUsed)
+	assert.True(t, fuelStatus.OverBudget)
+
+	// Once the period is over the projection is simply what was spent.
+	status, err = service.GetBudgetStatus(testUserID, "monthly", time.Date(2024, time.June, 30, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, types.Money(24000), status.Budgets[0].ProjectedSpend)
+}
+
+func TestBudgetService_DuplicateAndRecreate(t *testing.T) {
+	db := setupAccountTestDB(t)
+	require.NoError(t, db.AutoMigrate(&models.Budget{}))
+	service := NewBudgetService(db)
+
+	budget := &models.Budget{UserID: testUserID, Category: "Food", Period: "monthly", Amount: types.Money(40000)}
+	require.NoError(t, service.CreateBudget(budget))
+	err := service.CreateBudget(&models.Budget{UserID: testUserID, Category: "Food", Period: "monthly", Amount: types.Money(1000)})
+	assert.ErrorContains(t, err, "already exists")
+	require.NoError(t, service.CreateBudget(&models.Budget{UserID: testUserID, Category: "Food", Period: "weekly", Amount: types.Money(1000)}), "Another period is a separate budget")
+
+	require.NoError(t, service.DeleteBudget(testUserID, budget.ID))
+	assert.NoError(t, service.CreateBudget(&models.Budget{UserID: testUserID, Category: "Food", Period: "monthly", Amount: types.Money(45000)}), "A deleted budget can be set again")
+}
@@ -0,0 +1,78 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/zayyadi/finance-tracker/internal/models"
+	"gorm.io/gorm"
+)
+
+// errBulkItemsFailed rolls back the transaction of a bulk request in which an item failed.
+var errBulkItemsFailed = errors.New("bulk items failed")
+
+// bulkOp applies item i of a bulk request using tx. It returns the record as saved, if any, and the dates
+// of the incomes and expenses it changed.
+type bulkOp[T any] func(tx *gorm.DB, i int) (*T, []time.Time, error)
+
+// runBulk applies the n items of a bulk request in one transaction, giving successful items status. ids
+// are the records the items update or delete, or nil for creates. Each item runs in a savepoint of its
+// own, so every failing item is reported rather than only the first; if any fails, none is saved.
+func runBulk[T any](db *gorm.DB, status string, ids []uint, n int, op bulkOp[T]) (*models.BulkResult[T], error) {
+	if n == 0 || n > models.MaxBulkItems {
+		return nil, fmt.Errorf("invalid bulk request: send between 1 and %d items", models.MaxBulkItems)
+	}
+	result := &models.BulkResult[T]{Results: make([]models.BulkItemResult[T], n)}
+	var dates []time.Time
+	err := withAuditSource(db, auditSourceBulk).Transaction(func(tx *gorm.DB) error {
+		for i := 0; i < n; i++ {
+			item := &result.Results[i]
+			item.Index = i
+			if ids != nil {
+				item.ID = ids[i]
+			}
+			err := tx.Transaction(func(itemTx *gorm.DB) error {
+				saved, itemDates, err := op(itemTx, i)
+				if err != nil {
+					return err
+				}
+				item.Status, item.Item = status, saved
+				dates = append(dates, itemDates...)
+				return nil
+			})
+			if err != nil {
+				item.Status, item.Error = models.BulkStatusFailed, err.Error()
+				result.Failed++
+			}
+		}
+		if result.Failed > 0 {
+			return errBulkItemsFailed
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errBulkItemsFailed) {
+		log.Printf("Error applying bulk request: %v", err)
+		return nil, fmt.Errorf("could not apply bulk request: %w", err)
+	}
+	if result.Failed > 0 {
+		for i := range result.Results {
+			if result.Results[i].Status != models.BulkStatusFailed {
+				result.Results[i].Status, result.Results[i].Item = models.BulkStatusNotApplied, nil
+			}
+		}
+		return result, nil
+	}
+	result.Applied, result.Dates = true, dates
+	return result, nil
+}
+
+// bulkUpdateIDs returns the IDs of the records updated by items.
+func bulkUpdateIDs[T any](items []T, id func(T) uint) []uint {
+	ids := make([]uint, len(items))
+	for i, item := range items {
+		ids[i] = id(item)
+	}
+	return ids
+}
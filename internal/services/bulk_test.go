@@ -0,0 +1,127 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zayyadi/finance-tracker/internal/database"
+	"github.com/zayyadi/finance-tracker/internal/models"
+	"github.com/zayyadi/finance-tracker/internal/types"
+	"gorm.io/gorm"
+)
+
+func TestExpenseService_BulkCreateIsAtomic(t *testing.T) {
+	db := setupAccountTestDB(t)
+	service := NewExpenseService(db)
+	missingAccount := uint(999)
+	date := database.CustomDate{Time: time.Date(2024, time.May, 3, 0, 0, 0, 0, time.UTC)}
+
+	result, err := service.BulkCreateExpenses([]models.Expense{
+		{UserID: testUserID, Amount: types.NewMoneyFromMinor(1000), Category: "Food", Date: date},
+		{UserID: testUserID, Amount: types.NewMoneyFromMinor(2000), Category: "Rent", Date: date, AccountID: &missingAccount},
+		{UserID: testUserID, Amount: types.NewMoneyFromMinor(3000), Category: "Food", Date: date, Tags: []string{"   "}},
+	})
+	require.NoError(t, err)
+	assert.False(t, result.Applied)
+	assert.Equal(t, 2, result.Failed)
+	assert.Equal(t, models.BulkStatusNotApplied, result.Results[0].Status)
+	assert.Nil(t, result.Results[0].Item)
+	assert.Equal(t, models.BulkStatusFailed, result.Results[1].Status)
+	assert.Contains(t, result.Results[1].Error, "account not found")
+	assert.Equal(t, models.BulkStatusFailed, result.Results[2].Status, "Every failing item is reported, not only the first")
+	assert.Contains(t, result.Results[2].Error, "invalid tag")
+	assert.Empty(t, result.Dates)
+
+	var count int64
+	db.Model(&models.Expense{}).Count(&count)
+	assert.Equal(t, int64(0), count, "Nothing is saved when an item fails")
+
+	result, err = service.BulkCreateExpenses([]models.Expense{
+		{UserID: testUserID, Amount: types.NewMoneyFromMinor(1000), Category: "Food", Date: date, Tags: []string{"groceries"}},
+		{UserID: testUserID, Amount: types.NewMoneyFromMinor(2000), Category: "Rent", Date: date},
+	})
+	require.NoError(t, err)
+	assert.True(t, result.Applied)
+	assert.Equal(t, 0, result.Failed)
+	for _, item := range result.Results {
+		assert.Equal(t, models.BulkStatusCreated, item.Status)
+		require.NotNil(t, item.Item)
+		assert.NotZero(t, item.Item.ID)
+	}
+	db.Model(&models.Expense{}).Count(&count)
+	assert.Equal(t, int64(2), count)
+	assert.Len(t, result.Dates, 2)
+}
+
+func TestExpenseService_BulkUpdateAndDelete(t *testing.T) {
+	db := setupAccountTestDB(t)
+	service := NewExpenseService(db)
+	may := database.CustomDate{Time: time.Date(2024, time.May, 3, 0, 0, 0, 0, time.UTC)}
+	june := database.CustomDate{Time: time.Date(2024, time.June, 10, 0, 0, 0, 0, time.UTC)}
+	first := models.Expense{UserID: testUserID, Amount: types.NewMoneyFromMinor(1000), Category: "Misc", Date: may}
+	second := models.Expense{UserID: testUserID, Amount: types.NewMoneyFromMinor(2000), Category: "Misc", Date: may}
+	require.NoError(t, service.CreateExpense(&first))
+	require.NoError(t, service.CreateExpense(&second))
+
+	food := "Food"
+	result, err := service.BulkUpdateExpenses(testUserID, []models.ExpenseBulkUpdateItem{
+		{ID: first.ID, ExpenseUpdateRequest: models.ExpenseUpdateRequest{Category: &food}},
+		{ID: second.ID, ExpenseUpdateRequest: models.ExpenseUpdateRequest{Category: &food, Date: &june}},
+		{ID: 999, ExpenseUpdateRequest: models.ExpenseUpdateRequest{Category: &food}},
+	})
+	require.NoError(t, err)
+	assert.False(t, result.Applied)
+	assert.Equal(t, uint(999), result.Results[2].ID)
+	assert.Contains(t, result.Results[2].Error, "expense record not found")
+	reloaded, err := service.GetExpenseByID(testUserID, first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Misc", reloaded.Category, "The updates are rolled back")
+
+	result, err = service.BulkUpdateExpenses(testUserID, []models.ExpenseBulkUpdateItem{
+		{ID: first.ID, ExpenseUpdateRequest: models.ExpenseUpdateRequest{Category: &food}},
+		{ID: second.ID, ExpenseUpdateRequest: models.ExpenseUpdateRequest{Category: &food, Date: &june}},
+	})
+	require.NoError(t, err)
+	require.True(t, result.Applied)
+	assert.Equal(t, models.BulkStatusUpdated, result.Results[1].Status)
+	assert.Equal(t, "Food", result.Results[1].Item.Category)
+	assert.Contains(t, result.Dates, may.Time, "The old date of a moved expense is stale too")
+	assert.Contains(t, result.Dates, june.Time)
+
+	result, err = service.BulkDeleteExpenses(testUserID, []uint{first.ID, second.ID})
+	require.NoError(t, err)
+	require.True(t, result.Applied)
+	assert.Equal(t, models.BulkStatusDeleted, result.Results[0].Status)
+	assert.Equal(t, first.ID, result.Results[0].ID)
+	var count int64
+	db.Model(&models.Expense{}).Count(&count)
+	assert.Equal(t, int64(0), count)
+}
+
+func TestInvalidateSummariesForDates_EachPeriodOnce(t *testing.T) {
+	db := setupSummaryTestDB(t)
+	service := NewSummaryService(db)
+	dates := []time.Time{
+		time.Date(2023, time.July, 3, 0, 0, 0, 0, time.UTC),
+		time.Date(2023, time.July, 4, 0, 0, 0, 0, time.UTC),
+		time.Date(2023, time.July, 20, 0, 0, 0, 0, time.UTC),
+	}
+	seedFinancialSummary(t, db, "weekly", dates[0], 100, 50)
+	seedFinancialSummary(t, db, "weekly", dates[2], 100, 50)
+	seedFinancialSummary(t, db, "monthly", dates[0], 1000, 500)
+
+	deletes := 0
+	require.NoError(t, db.Callback().Delete().Before("gorm:delete").Register("count_summary_deletes", func(tx *gorm.DB) {
+		if tx.Statement.Table == "financial_summaries" {
+			deletes++
+		}
+	}))
+
+	require.NoError(t, service.InvalidateSummariesForDates(testUserID, dates, []string{"monthly", "weekly"}))
+	assert.Equal(t, 3, deletes, "One month and two weeks are invalidated, each once")
+	var count int64
+	db.Model(&models.FinancialSummary{}).Count(&count)
+	assert.Equal(t, int64(0), count)
+}
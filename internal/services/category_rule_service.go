@@ -0,0 +1,406 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/zayyadi/finance-tracker/internal/database"
+	"github.com/zayyadi/finance-tracker/internal/models"
+	"github.com/zayyadi/finance-tracker/internal/types"
+	"gorm.io/gorm"
+)
+
+// defaultCategory is the category of entries that carry none of their own and match no category rule.
+const defaultCategory = "Uncategorized"
+
+// CategoryRuleService manages the rules that categorize incomes and expenses automatically.
+type CategoryRuleService struct {
+	DB *gorm.DB
+}
+
+// NewCategoryRuleService creates a new CategoryRuleService with a GORM database connection.
+func NewCategoryRuleService(db *gorm.DB) *CategoryRuleService {
+	if db == nil {
+		log.Println("Warning: NewCategoryRuleService called with nil DB, attempting to use global GetDB()")
+		db = database.GetDB()
+	}
+	return &CategoryRuleService{DB: db}
+}
+
+// compiledCategoryRule is a rule prepared for matching.
+type compiledCategoryRule struct {
+	rule     models.CategoryRule
+	contains string // NoteContains in lower case
+	regex    *regexp.Regexp
+}
+
+// categorizedEntry holds the fields of an income or expense that category rules look at.
+type categorizedEntry struct {
+	ID             uint
+	Amount         types.Money
+	Currency       string
+	Category       string
+	CategoryRuleID *uint
+	Date           database.CustomDate
+	Note           string
+}
+
+func compileCategoryRule(rule models.CategoryRule) (*compiledCategoryRule, error) {
+	compiled := &compiledCategoryRule{rule: rule, contains: strings.ToLower(rule.NoteContains)}
+	if rule.NoteRegex != "" {
+		regex, err := regexp.Compile(rule.NoteRegex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid category rule: note_regex: %v", err)
+		}
+		compiled.regex = regex
+	}
+	return compiled, nil
+}
+
+// matches reports whether an income or expense meets every condition of the rule.
+func (r *compiledCategoryRule) matches(txnType string, amount types.Money, currency, note string) bool {
+	if r.rule.Type != "both" && r.rule.Type != txnType {
+		return false
+	}
+	if r.contains != "" && !strings.Contains(strings.ToLower(note), r.contains) {
+		return false
+	}
+	if r.regex != nil && !r.regex.MatchString(note) {
+		return false
+	}
+	if r.rule.MinAmount != nil || r.rule.MaxAmount != nil {
+		if r.rule.Currency != "" && r.rule.Currency != currency {
+			return false
+		}
+		if r.rule.MinAmount != nil && amount < *r.rule.MinAmount {
+			return false
+		}
+		if r.rule.MaxAmount != nil && amount > *r.rule.MaxAmount {
+			return false
+		}
+	}
+	return true
+}
+
+// loadCategoryRules returns the user's active rules in the order they are tried.
+func loadCategoryRules(db *gorm.DB, userID uint) ([]*compiledCategoryRule, error) {
+	var rules []models.CategoryRule
+	if err := db.Where("user_id = ? AND active = ?", userID, true).Order("priority, id").Find(&rules).Error; err != nil {
+		log.Printf("Error loading category rules for user %d: %v", userID, err)
+		return nil, fmt.Errorf("could not load category rules: %w", err)
+	}
+	compiled := make([]*compiledCategoryRule, 0, len(rules))
+	for _, rule := range rules {
+		c, err := compileCategoryRule(rule)
+		if err != nil {
+			log.Printf("Skipping category rule %d: %v", rule.ID, err)
+			continue
+		}
+		compiled = append(compiled, c)
+	}
+	return compiled, nil
+}
+
+// matchCategoryRule returns the first of the rules that matches, or nil.
+func matchCategoryRule(rules []*compiledCategoryRule, txnType string, amount types.Money, currency, note string) *models.CategoryRule {
+	for _, r := range rules {
+		if r.matches(txnType, amount, currency, note) {
+			return &r.rule
+		}
+	}
+	return nil
+}
+
+// categorizeByRules picks the category of an income or expense from the user's rules. It returns
+// defaultCategory and a nil rule ID when no rule matches.
+func categorizeByRules(db *gorm.DB, userID uint, txnType string, amount types.Money, currency, note string) (string, *uint, error) {
+	rules, err := loadCategoryRules(db, userID)
+	if err != nil {
+		return "", nil, err
+	}
+	if rule := matchCategoryRule(rules, txnType, amount, currency, note); rule != nil {
+		return rule.Category, &rule.ID, nil
+	}
+	return defaultCategory, nil, nil
+}
+
+// recategorizeOnUpdate works out the category of an income or expense whose amount, currency or note
+// changes. A category set by hand stays; one chosen by a rule, or the fallback category, is chosen
+// again. It returns the updates to make, if any.
+func recategorizeOnUpdate(db *gorm.DB, userID uint, txnType string, current categorizedEntry) (map[string]interface{}, error) {
+	if current.CategoryRuleID == nil && !strings.EqualFold(current.Category, defaultCategory) {
+		return nil, nil
+	}
+	rules, err := loadCategoryRules(db, userID)
+	if err != nil {
+		return nil, err
+	}
+	rule := matchCategoryRule(rules, txnType, current.Amount, current.Currency, current.Note)
+	if rule == nil {
+		return nil, nil
+	}
+	category, err := resolveCategory(db, userID, txnType, rule.Category)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"category": category.Name, "category_id": category.ID, "category_rule_id": rule.ID}, nil
+}
+
+// CreateCategoryRule validates and inserts a new rule.
+func (s *CategoryRuleService) CreateCategoryRule(userID uint, req *models.CategoryRuleRequest) (*models.CategoryRule, error) {
+	if s.DB == nil {
+		return nil, fmt.Errorf("database connection not initialized in CategoryRuleService")
+	}
+	rule := &models.CategoryRule{UserID: userID}
+	if err := applyCategoryRuleRequest(rule, req); err != nil {
+		return nil, err
+	}
+	if err := s.DB.Create(rule).Error; err != nil {
+		log.Printf("Error creating category rule for user %d: %v", userID, err)
+		return nil, fmt.Errorf("could not create category rule: %w", err)
+	}
+	return rule, nil
+}
+
+// GetCategoryRuleByID retrieves a specific rule by its ID, scoped to the given user.
+func (s *CategoryRuleService) GetCategoryRuleByID(userID uint, ruleID uint) (*models.CategoryRule, error) {
+	if s.DB == nil {
+		return nil, fmt.Errorf("database connection not initialized in CategoryRuleService")
+	}
+	var rule models.CategoryRule
+	if err := s.DB.Where("id = ? AND user_id = ?", ruleID, userID).First(&rule).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("category rule not found")
+		}
+		log.Printf("Error retrieving category rule %d for user %d: %v", ruleID, userID, err)
+		return nil, fmt.Errorf("could not retrieve category rule: %w", err)
+	}
+	return &rule, nil
+}
+
+// GetCategoryRules retrieves a user's rules with pagination, in the order they are tried.
+func (s *CategoryRuleService) GetCategoryRules(userID uint, offset int, limit int) ([]models.CategoryRule, error) {
+	if s.DB == nil {
+		return nil, fmt.Errorf("database connection not initialized in CategoryRuleService")
+	}
+	var rules []models.CategoryRule
+	if err := s.DB.Where("user_id = ?", userID).Order("priority, id").Offset(offset).Limit(limit).Find(&rules).Error; err != nil {
+		log.Printf("Error retrieving category rules for user %d: %v", userID, err)
+		return nil, fmt.Errorf("could not retrieve category rules: %w", err)
+	}
+	if rules == nil {
+		return []models.CategoryRule{}, nil
+	}
+	return rules, nil
+}
+
+// UpdateCategoryRule replaces an existing rule owned by the given user. Entries it categorized
+// before keep their category until rules are re-applied.
+func (s *CategoryRuleService) UpdateCategoryRule(userID uint, ruleID uint, req *models.CategoryRuleRequest) (*models.CategoryRule, error) {
+	if s.DB == nil {
+		return nil, fmt.Errorf("database connection not initialized in CategoryRuleService")
+	}
+	rule, err := s.GetCategoryRuleByID(userID, ruleID)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyCategoryRuleRequest(rule, req); err != nil {
+		return nil, err
+	}
+	// Select("*") so cleared conditions and a false active flag are written too.
+	if err := s.DB.Model(rule).Select("*").Omit("id", "created_at", "deleted_at").Updates(rule).Error; err != nil {
+		log.Printf("Error updating category rule %d: %v", ruleID, err)
+		return nil, fmt.Errorf("could not update category rule: %w", err)
+	}
+	return s.GetCategoryRuleByID(userID, ruleID)
+}
+
+// DeleteCategoryRule deletes a rule owned by the given user. Entries it categorized keep their category.
+func (s *CategoryRuleService) DeleteCategoryRule(userID uint, ruleID uint) error {
+	if s.DB == nil {
+		return fmt.Errorf("database connection not initialized in CategoryRuleService")
+	}
+	result := s.DB.Where("id = ? AND user_id = ?", ruleID, userID).Delete(&models.CategoryRule{})
+	if result.Error != nil {
+		log.Printf("Error deleting category rule %d: %v", ruleID, result.Error)
+		return fmt.Errorf("could not delete category rule: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("category rule not found, no rows deleted")
+	}
+	return nil
+}
+
+// TestCategoryRule runs a rule, saved or not, against the user's existing incomes and expenses without
+// changing them. The rule is tested on its own, whatever other rules there are. The result counts all
+// matches and lists the most recent ones, up to limit.
+func (s *CategoryRuleService) TestCategoryRule(userID uint, req *models.CategoryRuleRequest, limit int) (*models.CategoryRuleTestResult, error) {
+	if s.DB == nil {
+		return nil, fmt.Errorf("database connection not initialized in CategoryRuleService")
+	}
+	rule := &models.CategoryRule{UserID: userID}
+	if err := applyCategoryRuleRequest(rule, req); err != nil {
+		return nil, err
+	}
+	compiled, err := compileCategoryRule(*rule)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.CategoryRuleTestResult{Transactions: []models.CategoryRuleMatch{}}
+	for _, txnType := range []string{"income", "expense"} {
+		if rule.Type != "both" && rule.Type != txnType {
+			continue
+		}
+		var entries []categorizedEntry
+		err := s.DB.Model(transactionModel(txnType)).Where("user_id = ?", userID).
+			FindInBatches(&entries, 500, func(tx *gorm.DB, batch int) error {
+				for _, entry := range entries {
+					if !compiled.matches(txnType, entry.Amount, entry.Currency, entry.Note) {
+						continue
+					}
+					match := models.CategoryRuleMatch{
+						Type:        txnType,
+						ID:          entry.ID,
+						Date:        entry.Date.Format("2006-01-02"),
+						Amount:      entry.Amount,
+						Currency:    entry.Currency,
+						Category:    entry.Category,
+						Note:        entry.Note,
+						WouldChange: !strings.EqualFold(entry.Category, rule.Category),
+					}
+					result.Matched++
+					if match.WouldChange {
+						result.WouldChange++
+					}
+					result.Transactions = append(result.Transactions, match)
+				}
+				return nil
+			}).Error
+		if err != nil {
+			log.Printf("Error testing category rule for user %d: %v", userID, err)
+			return nil, fmt.Errorf("could not test category rule: %w", err)
+		}
+	}
+
+	sort.SliceStable(result.Transactions, func(i, j int) bool {
+		a, b := result.Transactions[i], result.Transactions[j]
+		if a.Date != b.Date {
+			return a.Date > b.Date
+		}
+		return a.ID > b.ID
+	})
+	if len(result.Transactions) > limit {
+		result.Transactions = result.Transactions[:limit]
+	}
+	return result, nil
+}
+
+// ApplyCategoryRules runs the user's rules over their existing incomes and expenses. Only entries
+// whose category was chosen by a rule or is the fallback category are changed, unless overwrite is
+// set; entries no rule matches are left as they are.
+func (s *CategoryRuleService) ApplyCategoryRules(userID uint, overwrite bool, dryRun bool) (*models.CategoryRuleApplyResult, error) {
+	if s.DB == nil {
+		return nil, fmt.Errorf("database connection not initialized in CategoryRuleService")
+	}
+	rules, err := loadCategoryRules(s.DB, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.CategoryRuleApplyResult{DryRun: dryRun}
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		for _, txnType := range []string{"income", "expense"} {
+			type change struct {
+				id       uint
+				category string
+				ruleID   uint
+			}
+			var changes []change
+			var entries []categorizedEntry
+			err := tx.Model(transactionModel(txnType)).Where("user_id = ?", userID).
+				FindInBatches(&entries, 500, func(batchTx *gorm.DB, batch int) error {
+					for _, entry := range entries {
+						if !overwrite && entry.CategoryRuleID == nil && !strings.EqualFold(entry.Category, defaultCategory) {
+							continue
+						}
+						result.Examined++
+						rule := matchCategoryRule(rules, txnType, entry.Amount, entry.Currency, entry.Note)
+						if rule == nil || (strings.EqualFold(entry.Category, normalizeCategoryName(rule.Category)) && entry.CategoryRuleID != nil && *entry.CategoryRuleID == rule.ID) {
+							continue
+						}
+						changes = append(changes, change{id: entry.ID, category: rule.Category, ruleID: rule.ID})
+					}
+					return nil
+				}).Error
+			if err != nil {
+				return fmt.Errorf("could not load %ss: %w", txnType, err)
+			}
+			result.Updated += len(changes)
+			if dryRun {
+				continue
+			}
+			categories := make(map[string]*models.Category)
+			for _, c := range changes {
+				category, ok := categories[c.category]
+				if !ok {
+					if category, err = resolveCategory(tx, userID, txnType, c.category); err != nil {
+						return err
+					}
+					categories[c.category] = category
+				}
+				if err := tx.Model(transactionModel(txnType)).Where("id = ? AND user_id = ?", c.id, userID).
+					Updates(map[string]interface{}{"category": category.Name, "category_id": category.ID, "category_rule_id": c.ruleID}).Error; err != nil {
+					return fmt.Errorf("could not recategorize %s %d: %w", txnType, c.id, err)
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("Error applying category rules for user %d: %v", userID, err)
+		return nil, fmt.Errorf("could not apply category rules: %w", err)
+	}
+	return result, nil
+}
+
+// applyCategoryRuleRequest validates a request and copies it onto the rule.
+func applyCategoryRuleRequest(rule *models.CategoryRule, req *models.CategoryRuleRequest) error {
+	if req.NoteContains == "" && req.NoteRegex == "" && req.MinAmount == nil && req.MaxAmount == nil {
+		return fmt.Errorf("invalid category rule: set at least one of note_contains, note_regex, min_amount and max_amount")
+	}
+	if req.MinAmount != nil && req.MaxAmount != nil && *req.MinAmount > *req.MaxAmount {
+		return fmt.Errorf("invalid category rule: min_amount is greater than max_amount")
+	}
+	category := strings.TrimSpace(req.Category)
+	if category == "" {
+		return fmt.Errorf("invalid category rule: category must not be blank")
+	}
+	if req.NoteRegex != "" {
+		if _, err := regexp.Compile(req.NoteRegex); err != nil {
+			return fmt.Errorf("invalid category rule: note_regex: %v", err)
+		}
+	}
+
+	rule.Name = req.Name
+	rule.Priority = req.Priority
+	rule.Type = req.Type
+	if rule.Type == "" {
+		rule.Type = "expense"
+	}
+	rule.NoteContains = req.NoteContains
+	rule.NoteRegex = req.NoteRegex
+	rule.MinAmount = req.MinAmount
+	rule.MaxAmount = req.MaxAmount
+	rule.Currency = strings.ToUpper(req.Currency)
+	rule.Category = category
+	rule.Active = true
+	if req.Active != nil {
+		rule.Active = *req.Active
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zayyadi/finance-tracker/internal/database"
+	"github.com/zayyadi/finance-tracker/internal/models"
+	"github.com/zayyadi/finance-tracker/internal/types"
+)
+
+func moneyPtr(m types.Money) *types.Money { return &m }
+
+func TestCategoryRuleService_CreateUpdateAndImport(t *testing.T) {
+	db := setupImportTestDB(t)
+	service := NewCategoryRuleService(db)
+	expenseService := NewExpenseService(db)
+	day := database.CustomDate{Time: time.Date(2024, time.June, 3, 0, 0, 0, 0, time.UTC)}
+
+	// The small-amount rule is tried first, so a big supermarket shop is still groceries.
+	groceries, err := service.CreateCategoryRule(testUserID, &models.CategoryRuleRequest{Name: "Supermarket", Priority: 2, NoteContains: "supermarket", Category: "Groceries"})
+	require.NoError(t, err)
+	assert.Equal(t, "expense", groceries.Type)
+	assert.True(t, groceries.Active)
+	snacks, err := service.CreateCategoryRule(testUserID, &models.CategoryRuleRequest{Name: "Small", Priority: 1, NoteRegex: `(?i)^(kiosk|supermarket)`, MaxAmount: moneyPtr(500), Currency: "usd", Category: "Snacks"})
+	require.NoError(t, err)
+	_, err = service.CreateCategoryRule(testUserID, &models.CategoryRuleRequest{Name: "Off", NoteContains: "supermarket", Category: "Never", Active: new(bool)})
+	require.NoError(t, err)
+
+	big := &models.Expense{UserID: testUserID, Amount: types.Money(8000), Date: day, Note: "SUPERMARKET LONDON"}
+	require.NoError(t, expenseService.CreateExpense(big))
+	assert.Equal(t, "Groceries", big.Category)
+	require.NotNil(t, big.CategoryRuleID)
+	assert.Equal(t, groceries.ID, *big.CategoryRuleID)
+
+	small := &models.Expense{UserID: testUserID, Amount: types.Money(350), Date: day, Note: "Supermarket express"}
+	require.NoError(t, expenseService.CreateExpense(small))
+	assert.Equal(t, "Snacks", small.Category)
+
+	unmatched := &models.Expense{UserID: testUserID, Amount: types.Money(350), Date: day, Note: "Bookshop"}
+	require.NoError(t, expenseService.CreateExpense(unmatched))
+	assert.Equal(t, "Uncategorized", unmatched.Category)
+	assert.Nil(t, unmatched.CategoryRuleID)
+
+	typed := &models.Expense{UserID: testUserID, Amount: types.Money(350), Category: "Gifts", Date: day, Note: "Supermarket flowers"}
+	require.NoError(t, expenseService.CreateExpense(typed))
+	assert.Equal(t, "Gifts", typed.Category, "A category given on create wins over the rules")
+	assert.Nil(t, typed.CategoryRuleID)
+
+	// Changing the amount of a rule-categorized expense runs the rules again...
+	newAmount := types.Money(9000)
+	updated, err := expenseService.UpdateExpense(testUserID, small.ID, &models.ExpenseUpdateRequest{Amount: &newAmount})
+	require.NoError(t, err)
+	reloaded, err := expenseService.GetExpenseByID(testUserID, updated.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Groceries", reloaded.Category)
+	assert.Equal(t, groceries.ID, *reloaded.CategoryRuleID)
+
+	// ...until the category is set by hand.
+	manual := "Household"
+	_, err = expenseService.UpdateExpense(testUserID, small.ID, &models.ExpenseUpdateRequest{Category: &manual})
+	require.NoError(t, err)
+	smallAmount := types.Money(100)
+	_, err = expenseService.UpdateExpense(testUserID, small.ID, &models.ExpenseUpdateRequest{Amount: &smallAmount})
+	require.NoError(t, err)
+	reloaded, err = expenseService.GetExpenseByID(testUserID, small.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Household", reloaded.Category)
+	assert.Nil(t, reloaded.CategoryRuleID)
+
+	// Imported rows without a category of their own go through the rules before the profile default.
+	importService := NewImportService(db, nil)
+	profile, err := importService.ProfileFromRequest(testUserID, &models.ImportProfileRequest{Name: "Bank", DateColumn: "Date", AmountColumn: "Amount", DescriptionColumn: "Description", CategoryColumn: "Category"})
+	require.NoError(t, err)
+	csv := "Date,Amount,Description,Category\n2024-06-10,-3.20,KIOSK 12,\n2024-06-11,-30.00,Supermarket,Food\n2024-06-12,-7.00,Cinema,\n"
+	result, err := importService.ImportCSV(testUserID, strings.NewReader(csv), profile, true)
+	require.NoError(t, err)
+	require.Len(t, result.Transactions, 3)
+	assert.Equal(t, "Snacks", result.Transactions[0].Category)
+	assert.Equal(t, snacks.ID, *result.Transactions[0].CategoryRuleID)
+	assert.Equal(t, "Food", result.Transactions[1].Category, "The statement's own category is kept")
+	assert.Equal(t, "Uncategorized", result.Transactions[2].Category)
+}
+
+func TestCategoryRuleService_TestAndApply(t *testing.T) {
+	db := setupImportTestDB(t)
+	service := NewCategoryRuleService(db)
+	day := func(d int) database.CustomDate {
+		return database.CustomDate{Time: time.Date(2024, time.June, d, 0, 0, 0, 0, time.UTC)}
+	}
+	// History recorded before any rules existed.
+	rows := []models.Expense{
+		{UserID: testUserID, Amount: types.Money(1599), Currency: "USD", Category: "Uncategorized", Date: day(1), Note: "STREAMING CO"},
+		{UserID: testUserID, Amount: types.Money(1599), Currency: "USD", Category: "Fun", Date: day(2), Note: "Streaming co gift card"},
+		{UserID: testUserID, Amount: types.Money(1599), Currency: "USD", Category: "Subscriptions", Date: day(3), Note: "streaming co"},
+		{UserID: testUserID, Amount: types.Money(2000), Currency: "USD", Category: "Uncategorized", Date: day(4), Note: "Bakery"},
+	}
+	require.NoError(t, db.Create(&rows).Error)
+	require.NoError(t, db.Create(&models.Income{UserID: testUserID, Amount: types.Money(1599), Currency: "USD", Category: "Refunds", Date: day(5), Note: "Streaming co refund"}).Error)
+
+	req := &models.CategoryRuleRequest{Name: "Streaming", NoteContains: "streaming co", Category: "Subscriptions"}
+	tested, err := service.TestCategoryRule(testUserID, req, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 3, tested.Matched, "Only expenses are tested by an expense rule")
+	assert.Equal(t, 2, tested.WouldChange)
+	require.Len(t, tested.Transactions, 2)
+	assert.Equal(t, "2024-06-03", tested.Transactions[0].Date, "The most recent matches are listed")
+	assert.False(t, tested.Transactions[0].WouldChange)
+
+	_, err = service.CreateCategoryRule(testUserID, req)
+	require.NoError(t, err)
+
+	preview, err := service.ApplyCategoryRules(testUserID, false, true)
+	require.NoError(t, err)
+	assert.Equal(t, 2, preview.Examined, "Only uncategorized entries are examined")
+	assert.Equal(t, 1, preview.Updated)
+	var first models.Expense
+	require.NoError(t, db.First(&first, rows[0].ID).Error)
+	assert.Equal(t, "Uncategorized", first.Category, "A dry run changes nothing")
+
+	applied, err := service.ApplyCategoryRules(testUserID, false, false)
+	require.NoError(t, err)
+	assert.Equal(t, 1, applied.Updated)
+	require.NoError(t, db.First(&first, rows[0].ID).Error)
+	assert.Equal(t, "Subscriptions", first.Category)
+	var gift models.Expense
+	require.NoError(t, db.First(&gift, rows[1].ID).Error)
+	assert.Equal(t, "Fun", gift.Category, "Categories chosen by hand are kept")
+
+	overwritten, err := service.ApplyCategoryRules(testUserID, true, false)
+	require.NoError(t, err)
+	assert.Equal(t, 5, overwritten.Examined, "Every income and expense is examined")
+	assert.Equal(t, 2, overwritten.Updated, "The gift card, and the hand-set row that now records its rule")
+	require.NoError(t, db.First(&gift, rows[1].ID).Error)
+	assert.Equal(t, "Subscriptions", gift.Category)
+}
+
+func TestCategoryRuleService_Validation(t *testing.T) {
+	db := setupImportTestDB(t)
+	service := NewCategoryRuleService(db)
+
+	_, err := service.CreateCategoryRule(testUserID, &models.CategoryRuleRequest{Name: "Empty", Category: "Food"})
+	assert.ErrorContains(t, err, "invalid category rule: set at least one")
+	_, err = service.CreateCategoryRule(testUserID, &models.CategoryRuleRequest{Name: "Regex", NoteRegex: "(", Category: "Food"})
+	assert.ErrorContains(t, err, "invalid category rule: note_regex")
+	_, err = service.CreateCategoryRule(testUserID, &models.CategoryRuleRequest{Name: "Range", MinAmount: moneyPtr(500), MaxAmount: moneyPtr(100), Category: "Food"})
+	assert.ErrorContains(t, err, "min_amount is greater than max_amount")
+
+	rule, err := service.CreateCategoryRule(testUserID, &models.CategoryRuleRequest{Name: "Rent", Type: "both", MinAmount: moneyPtr(100000), Category: "Housing"})
+	require.NoError(t, err)
+	updated, err := service.UpdateCategoryRule(testUserID, rule.ID, &models.CategoryRuleRequest{Name: "Rent", NoteContains: "landlord", Category: "Housing", Active: new(bool)})
+	require.NoError(t, err)
+	assert.Nil(t, updated.MinAmount, "An update replaces the whole rule")
+	assert.False(t, updated.Active)
+	assert.Equal(t, "expense", updated.Type)
+
+	require.NoError(t, service.DeleteCategoryRule(testUserID, rule.ID))
+	_, err = service.GetCategoryRuleByID(testUserID, rule.ID)
+	assert.ErrorContains(t, err, "category rule not found")
+}
@@ -0,0 +1,430 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/zayyadi/finance-tracker/internal/database"
+	"github.com/zayyadi/finance-tracker/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CategoryService manages users' income and expense categories.
+type CategoryService struct {
+	DB *gorm.DB
+}
+
+// NewCategoryService creates a new CategoryService with a GORM database connection.
+func NewCategoryService(db *gorm.DB) *CategoryService {
+	if db == nil {
+		log.Println("Warning: NewCategoryService called with nil DB, attempting to use global GetDB()")
+		db = database.GetDB()
+	}
+	return &CategoryService{DB: db}
+}
+
+// normalizeCategoryName trims a category name and collapses runs of whitespace inside it.
+func normalizeCategoryName(name string) string {
+	return strings.Join(strings.Fields(name), " ")
+}
+
+// findCategoryByName returns the user's category of the given kind whose name matches, ignoring case
+// and surrounding whitespace, or nil when there is none.
+func findCategoryByName(db *gorm.DB, userID uint, kind, name string) (*models.Category, error) {
+	// Find rather than First: a missing category is expected here, not an error worth logging.
+	var categories []models.Category
+	err := db.Where("user_id = ? AND kind = ? AND LOWER(name) = LOWER(?)", userID, kind, normalizeCategoryName(name)).Limit(1).Find(&categories).Error
+	if err != nil {
+		return nil, fmt.Errorf("could not look up category %q: %w", name, err)
+	}
+	if len(categories) == 0 {
+		return nil, nil
+	}
+	return &categories[0], nil
+}
+
+// resolveCategory returns the user's category of the given kind with the given name, creating it as
+// a top-level category the first time the name is used. A blank name resolves to defaultCategory.
+func resolveCategory(db *gorm.DB, userID uint, kind, name string) (*models.Category, error) {
+	name = normalizeCategoryName(name)
+	if name == "" {
+		name = defaultCategory
+	}
+	if len(name) > 100 {
+		return nil, fmt.Errorf("invalid category: %q is longer than 100 characters", name)
+	}
+	category, err := findCategoryByName(db, userID, kind, name)
+	if err != nil || category != nil {
+		return category, err
+	}
+	category = &models.Category{UserID: userID, Kind: kind, Name: name}
+	result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(category)
+	if result.Error != nil {
+		return nil, fmt.Errorf("could not create category %q: %w", name, result.Error)
+	}
+	if result.RowsAffected == 0 {
+		// Created by a concurrent request since the lookup.
+		return findCategoryByName(db, userID, kind, name)
+	}
+	return category, nil
+}
+
+// categoryByID returns the user's category with the given ID, which must be of the given kind.
+func categoryByID(db *gorm.DB, userID uint, kind string, categoryID uint) (*models.Category, error) {
+	var category models.Category
+	err := db.Where("id = ? AND user_id = ?", categoryID, userID).First(&category).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("invalid category: category %d not found", categoryID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve category %d: %w", categoryID, err)
+	}
+	if category.Kind != kind {
+		return nil, fmt.Errorf("invalid category: %q is an %s category, not an %s one", category.Name, category.Kind, kind)
+	}
+	return &category, nil
+}
+
+// categoryFor resolves the category of an income or expense: by ID when one is given, otherwise by name.
+func categoryFor(db *gorm.DB, userID uint, kind string, categoryID *uint, name string) (*models.Category, error) {
+	if categoryID != nil && *categoryID != 0 {
+		return categoryByID(db, userID, kind, *categoryID)
+	}
+	return resolveCategory(db, userID, kind, name)
+}
+
+// CreateCategory validates and inserts a new category.
+func (s *CategoryService) CreateCategory(userID uint, req *models.CategoryCreateRequest) (*models.Category, error) {
+	if s.DB == nil {
+		return nil, fmt.Errorf("database connection not initialized in CategoryService")
+	}
+	category := &models.Category{
+		UserID: userID,
+		Kind:   req.Kind,
+		Name:   normalizeCategoryName(req.Name),
+		Color:  strings.ToUpper(req.Color),
+		Icon:   req.Icon,
+	}
+	if category.Name == "" {
+		return nil, fmt.Errorf("invalid category: name must not be blank")
+	}
+	if err := s.checkCategoryNameFree(userID, category.Kind, category.Name, 0); err != nil {
+		return nil, err
+	}
+	if req.ParentID != nil && *req.ParentID != 0 {
+		if err := s.checkCategoryParent(category, *req.ParentID); err != nil {
+			return nil, err
+		}
+		category.ParentID = req.ParentID
+	}
+	if err := s.DB.Create(category).Error; err != nil {
+		log.Printf("Error creating category for user %d: %v", userID, err)
+		return nil, fmt.Errorf("could not create category: %w", err)
+	}
+	return category, nil
+}
+
+// GetCategoryByID retrieves a specific category by its ID, scoped to the given user.
+func (s *CategoryService) GetCategoryByID(userID uint, categoryID uint) (*models.Category, error) {
+	if s.DB == nil {
+		return nil, fmt.Errorf("database connection not initialized in CategoryService")
+	}
+	var category models.Category
+	if err := s.DB.Where("id = ? AND user_id = ?", categoryID, userID).First(&category).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("category not found")
+		}
+		log.Printf("Error retrieving category %d for user %d: %v", categoryID, userID, err)
+		return nil, fmt.Errorf("could not retrieve category: %w", err)
+	}
+	return &category, nil
+}
+
+// GetCategories retrieves a user's categories with pagination, ordered by kind and name and optionally
+// limited to one kind.
+func (s *CategoryService) GetCategories(userID uint, kind string, offset int, limit int) ([]models.Category, error) {
+	if s.DB == nil {
+		return nil, fmt.Errorf("database connection not initialized in CategoryService")
+	}
+	query := s.DB.Where("user_id = ?", userID)
+	if kind != "" {
+		query = query.Where("kind = ?", kind)
+	}
+	var categories []models.Category
+	if err := query.Order("kind, name").Offset(offset).Limit(limit).Find(&categories).Error; err != nil {
+		log.Printf("Error retrieving categories for user %d: %v", userID, err)
+		return nil, fmt.Errorf("could not retrieve categories: %w", err)
+	}
+	if categories == nil {
+		return []models.Category{}, nil
+	}
+	return categories, nil
+}
+
+// GetCategoryTree returns all of a user's categories as a tree: top-level categories, ordered by kind
+// and name, each with its subcategories. kind optionally limits it to income or expense categories.
+func (s *CategoryService) GetCategoryTree(userID uint, kind string) ([]models.CategoryNode, error) {
+	if s.DB == nil {
+		return nil, fmt.Errorf("database connection not initialized in CategoryService")
+	}
+	categories, err := s.GetCategories(userID, kind, 0, -1)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[uint]bool, len(categories))
+	for _, category := range categories {
+		byID[category.ID] = true
+	}
+	children := make(map[uint][]models.Category)
+	var roots []models.Category
+	for _, category := range categories {
+		if category.ParentID != nil && byID[*category.ParentID] {
+			children[*category.ParentID] = append(children[*category.ParentID], category)
+		} else {
+			roots = append(roots, category)
+		}
+	}
+	var build func(categories []models.Category) []models.CategoryNode
+	build = func(categories []models.Category) []models.CategoryNode {
+		nodes := make([]models.CategoryNode, 0, len(categories))
+		for _, category := range categories {
+			nodes = append(nodes, models.CategoryNode{Category: category, Children: build(children[category.ID])})
+		}
+		return nodes
+	}
+	return build(roots), nil
+}
+
+// UpdateCategory updates an existing category owned by the given user. A new name is carried over to
+// the incomes or expenses in the category and to the budgets, recurring transactions and category
+// rules that refer to it by name.
+func (s *CategoryService) UpdateCategory(userID uint, categoryID uint, updateData *models.CategoryUpdateRequest) (*models.Category, error) {
+	if s.DB == nil {
+		return nil, fmt.Errorf("database connection not initialized in CategoryService")
+	}
+	category, err := s.GetCategoryByID(userID, categoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := make(map[string]interface{})
+	oldName := category.Name
+	if updateData.Name != nil {
+		name := normalizeCategoryName(*updateData.Name)
+		if name == "" {
+			return nil, fmt.Errorf("invalid category: name must not be blank")
+		}
+		if name != oldName {
+			if err := s.checkCategoryNameFree(userID, category.Kind, name, categoryID); err != nil {
+				return nil, err
+			}
+			updates["name"] = name
+		}
+	}
+	if updateData.ParentID != nil {
+		if *updateData.ParentID == 0 {
+			updates["parent_id"] = nil
+		} else {
+			if err := s.checkCategoryParent(category, *updateData.ParentID); err != nil {
+				return nil, err
+			}
+			updates["parent_id"] = *updateData.ParentID
+		}
+	}
+	if updateData.Color != nil {
+		updates["color"] = strings.ToUpper(*updateData.Color)
+	}
+	if updateData.Icon != nil {
+		updates["icon"] = *updateData.Icon
+	}
+	if len(updates) == 0 {
+		return category, nil
+	}
+
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(category).Updates(updates).Error; err != nil {
+			return err
+		}
+		name, renamed := updates["name"].(string)
+		if !renamed {
+			return nil
+		}
+		// Deleted entries are renamed too, so they come back under the new name if restored.
+		if err := tx.Unscoped().Model(transactionModel(category.Kind)).Where("user_id = ? AND category_id = ?", userID, categoryID).
+			Update("category", name).Error; err != nil {
+			return err
+		}
+		if category.Kind == "expense" {
+			if err := tx.Model(&models.ExpenseSplit{}).Where("category_id = ?", categoryID).
+				Update("category", name).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&models.Budget{}).Where("user_id = ? AND LOWER(category) = LOWER(?)", userID, oldName).
+				Update("category", name).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Model(&models.RecurringTransaction{}).Where("user_id = ? AND type = ? AND LOWER(category) = LOWER(?)", userID, category.Kind, oldName).
+			Update("category", name).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.CategoryRule{}).Where("user_id = ? AND type = ? AND LOWER(category) = LOWER(?)", userID, category.Kind, oldName).
+			Update("category", name).Error
+	})
+	if err != nil {
+		log.Printf("Error updating category %d: %v", categoryID, err)
+		return nil, fmt.Errorf("could not update category: %w", err)
+	}
+	return s.GetCategoryByID(userID, categoryID)
+}
+
+// DeleteCategory deletes a category that has no subcategories and no incomes or expenses. Categories
+// are removed permanently so the name can be reused.
+func (s *CategoryService) DeleteCategory(userID uint, categoryID uint) error {
+	if s.DB == nil {
+		return fmt.Errorf("database connection not initialized in CategoryService")
+	}
+	category, err := s.GetCategoryByID(userID, categoryID)
+	if err != nil {
+		if strings.Contains(err.Error(), "category not found") {
+			return fmt.Errorf("category not found, no rows deleted")
+		}
+		return err
+	}
+
+	var children int64
+	if err := s.DB.Model(&models.Category{}).Where("user_id = ? AND parent_id = ?", userID, categoryID).Count(&children).Error; err != nil {
+		return fmt.Errorf("could not check subcategories: %w", err)
+	}
+	if children > 0 {
+		return fmt.Errorf("category %q still has %d subcategory(ies)", category.Name, children)
+	}
+	var entries int64
+	if err := s.DB.Model(transactionModel(category.Kind)).Where("user_id = ? AND category_id = ?", userID, categoryID).Count(&entries).Error; err != nil {
+		return fmt.Errorf("could not check the category's %ss: %w", category.Kind, err)
+	}
+	if entries > 0 {
+		return fmt.Errorf("category %q is still used by %d %s record(s)", category.Name, entries, category.Kind)
+	}
+	if category.Kind == "expense" {
+		var lines int64
+		if err := s.DB.Model(&models.ExpenseSplit{}).Joins("JOIN expenses ON expenses.id = expense_splits.expense_id").
+			Where("expense_splits.category_id = ? AND expenses.deleted_at IS NULL", categoryID).Count(&lines).Error; err != nil {
+			return fmt.Errorf("could not check the category's split lines: %w", err)
+		}
+		if lines > 0 {
+			return fmt.Errorf("category %q is still used by %d split line(s)", category.Name, lines)
+		}
+	}
+
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		// Deleted entries keep the name but let go of the category.
+		if err := tx.Unscoped().Model(transactionModel(category.Kind)).Where("user_id = ? AND category_id = ?", userID, categoryID).
+			Update("category_id", nil).Error; err != nil {
+			return err
+		}
+		if category.Kind == "expense" {
+			if err := tx.Model(&models.ExpenseSplit{}).Where("category_id = ?", categoryID).Update("category_id", nil).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Unscoped().Delete(category).Error
+	})
+	if err != nil {
+		log.Printf("Error deleting category %d: %v", categoryID, err)
+		return fmt.Errorf("could not delete category: %w", err)
+	}
+	return nil
+}
+
+// checkCategoryNameFree returns an error if another of the user's categories of the kind already has
+// the name, ignoring case.
+func (s *CategoryService) checkCategoryNameFree(userID uint, kind, name string, exceptID uint) error {
+	var count int64
+	if err := s.DB.Model(&models.Category{}).Where("user_id = ? AND kind = ? AND LOWER(name) = LOWER(?) AND id <> ?", userID, kind, name, exceptID).
+		Count(&count).Error; err != nil {
+		return fmt.Errorf("could not check category name: %w", err)
+	}
+	if count > 0 {
+		return fmt.Errorf("a category named %q already exists", name)
+	}
+	return nil
+}
+
+// checkCategoryParent returns an error unless parentID is another of the user's categories of the
+// same kind that is not nested under the category itself.
+func (s *CategoryService) checkCategoryParent(category *models.Category, parentID uint) error {
+	var parent models.Category
+	if err := s.DB.Where("id = ? AND user_id = ?", parentID, category.UserID).First(&parent).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("invalid category: parent category %d not found", parentID)
+		}
+		return fmt.Errorf("could not retrieve parent category: %w", err)
+	}
+	if parent.Kind != category.Kind {
+		return fmt.Errorf("invalid category: an %s category cannot be nested under an %s category", category.Kind, parent.Kind)
+	}
+	// Walk up from the new parent; reaching the category itself would close a loop.
+	seen := make(map[uint]bool)
+	for current := &parent; ; {
+		if category.ID != 0 && current.ID == category.ID {
+			return fmt.Errorf("invalid category: a category cannot be nested under itself or one of its subcategories")
+		}
+		if current.ParentID == nil || seen[current.ID] {
+			return nil
+		}
+		seen[current.ID] = true
+		var next models.Category
+		if err := s.DB.Where("id = ? AND user_id = ?", *current.ParentID, category.UserID).First(&next).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return fmt.Errorf("could not retrieve parent category: %w", err)
+		}
+		current = &next
+	}
+}
+
+// categoryRollup maps category names of one kind to the user's categories, and each category to its
+// top-level ancestor.
+type categoryRollup struct {
+	byName map[string]*models.Category // Keyed by lower-case name
+	byID   map[uint]*models.Category
+}
+
+// loadCategoryRollup loads all of a user's categories of the given kind.
+func loadCategoryRollup(db *gorm.DB, userID uint, kind string) (*categoryRollup, error) {
+	var categories []models.Category
+	if err := db.Where("user_id = ? AND kind = ?", userID, kind).Find(&categories).Error; err != nil {
+		return nil, fmt.Errorf("could not load categories: %w", err)
+	}
+	rollup := &categoryRollup{byName: make(map[string]*models.Category, len(categories)), byID: make(map[uint]*models.Category, len(categories))}
+	for i := range categories {
+		rollup.byName[strings.ToLower(categories[i].Name)] = &categories[i]
+		rollup.byID[categories[i].ID] = &categories[i]
+	}
+	return rollup, nil
+}
+
+// lookup returns the category with the given name, or nil.
+func (r *categoryRollup) lookup(name string) *models.Category {
+	return r.byName[strings.ToLower(normalizeCategoryName(name))]
+}
+
+// root returns the top-level category that category is nested under, or category itself.
+func (r *categoryRollup) root(category *models.Category) *models.Category {
+	seen := make(map[uint]bool)
+	for category.ParentID != nil && !seen[category.ID] {
+		parent, ok := r.byID[*category.ParentID]
+		if !ok {
+			break
+		}
+		seen[category.ID] = true
+		category = parent
+	}
+	return category
+}
@@ -0,0 +1,131 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zayyadi/finance-tracker/internal/database"
+	"github.com/zayyadi/finance-tracker/internal/models"
+	"github.com/zayyadi/finance-tracker/internal/types"
+)
+
+func TestCategoryService_ResolvesSpellings(t *testing.T) {
+	db := setupAccountTestDB(t)
+	expenseService := NewExpenseService(db)
+	incomeService := NewIncomeService(db)
+	day := database.CustomDate{Time: time.Date(2024, time.June, 3, 0, 0, 0, 0, time.UTC)}
+
+	first := &models.Expense{UserID: testUserID, Amount: types.Money(1000), Category: "Food", Date: day}
+	require.NoError(t, expenseService.CreateExpense(first))
+	require.NotNil(t, first.CategoryID)
+	second := &models.Expense{UserID: testUserID, Amount: types.Money(2000), Category: "  food ", Date: day}
+	require.NoError(t, expenseService.CreateExpense(second))
+	assert.Equal(t, "Food", second.Category, "The existing spelling is used")
+	assert.Equal(t, *first.CategoryID, *second.CategoryID)
+
+	income := &models.Income{UserID: testUserID, Amount: types.Money(1000), Category: "food", Date: day}
+	require.NoError(t, incomeService.CreateIncome(income))
+	assert.NotEqual(t, *first.CategoryID, *income.CategoryID, "Income categories are separate from expense ones")
+
+	// A category can also be given by ID, which must be one of the user's categories of the right kind.
+	byID := &models.Expense{UserID: testUserID, Amount: types.Money(500), CategoryID: first.CategoryID, Date: day}
+	require.NoError(t, expenseService.CreateExpense(byID))
+	assert.Equal(t, "Food", byID.Category)
+	err := expenseService.CreateExpense(&models.Expense{UserID: testUserID, Amount: types.Money(500), CategoryID: income.CategoryID, Date: day})
+	assert.ErrorContains(t, err, "invalid category")
+
+	// Updating by name or ID moves the expense to that category.
+	rent := "rent"
+	updated, err := expenseService.UpdateExpense(testUserID, second.ID, &models.ExpenseUpdateRequest{Category: &rent})
+	require.NoError(t, err)
+	reloaded, err := expenseService.GetExpenseByID(testUserID, updated.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "rent", reloaded.Category)
+	assert.NotEqual(t, *first.CategoryID, *reloaded.CategoryID)
+	_, err = expenseService.UpdateExpense(testUserID, second.ID, &models.ExpenseUpdateRequest{CategoryID: first.CategoryID})
+	require.NoError(t, err)
+	reloaded, err = expenseService.GetExpenseByID(testUserID, second.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Food", reloaded.Category)
+	assert.Equal(t, *first.CategoryID, *reloaded.CategoryID)
+}
+
+func TestCategoryService_Hierarchy(t *testing.T) {
+	db := setupAccountTestDB(t)
+	require.NoError(t, db.AutoMigrate(&models.Budget{}))
+	service := NewCategoryService(db)
+	expenseService := NewExpenseService(db)
+
+	food, err := service.CreateCategory(testUserID, &models.CategoryCreateRequest{Name: "Food", Kind: "expense", Color: "#ff8800"})
+	require.NoError(t, err)
+	assert.Equal(t, "#FF8800", food.Color)
+	groceries, err := service.CreateCategory(testUserID, &models.CategoryCreateRequest{Name: "Groceries", Kind: "expense", ParentID: &food.ID})
+	require.NoError(t, err)
+	_, err = service.CreateCategory(testUserID, &models.CategoryCreateRequest{Name: "Restaurants", Kind: "expense", ParentID: &food.ID})
+	require.NoError(t, err)
+	salary, err := service.CreateCategory(testUserID, &models.CategoryCreateRequest{Name: "Salary", Kind: "income"})
+	require.NoError(t, err)
+
+	_, err = service.CreateCategory(testUserID, &models.CategoryCreateRequest{Name: "FOOD", Kind: "expense"})
+	assert.ErrorContains(t, err, "already exists")
+	_, err = service.CreateCategory(testUserID, &models.CategoryCreateRequest{Name: "Bonus", Kind: "income", ParentID: &food.ID})
+	assert.ErrorContains(t, err, "invalid category: an income category cannot be nested under an expense category")
+	_, err = service.UpdateCategory(testUserID, food.ID, &models.CategoryUpdateRequest{ParentID: &groceries.ID})
+	assert.ErrorContains(t, err, "cannot be nested under itself")
+
+	tree, err := service.GetCategoryTree(testUserID, "expense")
+	require.NoError(t, err)
+	require.Len(t, tree, 1)
+	assert.Equal(t, "Food", tree[0].Name)
+	require.Len(t, tree[0].Children, 2)
+	assert.Equal(t, "Groceries", tree[0].Children[0].Name)
+
+	// Subcategories roll up into their parent in the expense breakdown.
+	day := database.CustomDate{Time: time.Date(2024, time.June, 3, 0, 0, 0, 0, time.UTC)}
+	require.NoError(t, expenseService.CreateExpense(&models.Expense{UserID: testUserID, Amount: types.Money(3000), Category: "groceries", Date: day}))
+	require.NoError(t, expenseService.CreateExpense(&models.Expense{UserID: testUserID, Amount: types.Money(2000), Category: "Restaurants", Date: day}))
+	require.NoError(t, expenseService.CreateExpense(&models.Expense{UserID: testUserID, Amount: types.Money(4000), Category: "Fuel", Date: day}))
+	analytics := NewAnalyticsService(db)
+	stats, err := analytics.GetExpenseBreakdownByCategory(testUserID, day.Time, false)
+	require.NoError(t, err)
+	require.Len(t, stats, 3)
+	assert.Equal(t, "Fuel", stats[0].Category)
+	assert.Equal(t, "Groceries", stats[1].Category)
+	stats, err = analytics.GetExpenseBreakdownByCategory(testUserID, day.Time, true)
+	require.NoError(t, err)
+	require.Len(t, stats, 2)
+	assert.Equal(t, "Food", stats[0].Category)
+	assert.Equal(t, types.Money(5000), stats[0].TotalAmount)
+	assert.Equal(t, food.ID, *stats[0].CategoryID)
+	assert.Equal(t, "#FF8800", stats[0].Color)
+
+	// Renaming carries over to the expenses, budgets and rules that use the category.
+	budgetService := NewBudgetService(db)
+	require.NoError(t, budgetService.CreateBudget(&models.Budget{UserID: testUserID, Category: "GROCERIES", Amount: types.Money(10000)}))
+	_, err = NewCategoryRuleService(db).CreateCategoryRule(testUserID, &models.CategoryRuleRequest{Name: "Shop", NoteContains: "shop", Category: "Groceries"})
+	require.NoError(t, err)
+	name := "Supermarket"
+	renamed, err := service.UpdateCategory(testUserID, groceries.ID, &models.CategoryUpdateRequest{Name: &name})
+	require.NoError(t, err)
+	assert.Equal(t, "Supermarket", renamed.Name)
+	var expense models.Expense
+	require.NoError(t, db.Where("category_id = ?", groceries.ID).First(&expense).Error)
+	assert.Equal(t, "Supermarket", expense.Category)
+	var budget models.Budget
+	require.NoError(t, db.First(&budget).Error)
+	assert.Equal(t, "Supermarket", budget.Category)
+	var rule models.CategoryRule
+	require.NoError(t, db.First(&rule).Error)
+	assert.Equal(t, "Supermarket", rule.Category)
+
+	// Categories still in use, or with subcategories, cannot be deleted.
+	assert.ErrorContains(t, service.DeleteCategory(testUserID, food.ID), "still has 2 subcategory(ies)")
+	assert.ErrorContains(t, service.DeleteCategory(testUserID, groceries.ID), "still used by 1 expense record(s)")
+	require.NoError(t, service.DeleteCategory(testUserID, salary.ID))
+	_, err = service.GetCategoryByID(testUserID, salary.ID)
+	assert.ErrorContains(t, err, "category not found")
+	_, err = service.CreateCategory(testUserID, &models.CategoryCreateRequest{Name: "Salary", Kind: "income"})
+	assert.NoError(t, err, "The name of a deleted category can be reused")
+}
@@ -0,0 +1,358 @@
+package services
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zayyadi/finance-tracker/internal/database"
+	"github.com/zayyadi/finance-tracker/internal/models"
+	"github.com/zayyadi/finance-tracker/internal/types"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CurrencyService manages a user's base currency and exchange rates, and converts amounts
+// into the base currency for summaries, analytics and reports.
+type CurrencyService struct {
+	DB *gorm.DB
+}
+
+// NewCurrencyService creates a new CurrencyService with a GORM database connection.
+func NewCurrencyService(db *gorm.DB) *CurrencyService {
+	if db == nil {
+		log.Println("Warning: NewCurrencyService called with nil DB, attempting to use global GetDB()")
+		db = database.GetDB()
+	}
+	return &CurrencyService{DB: db}
+}
+
+// GetBaseCurrency returns the user's reporting currency.
+func (s *CurrencyService) GetBaseCurrency(userID uint) (string, error) {
+	if s.DB == nil {
+		return "", fmt.Errorf("database connection not initialized in CurrencyService")
+	}
+	return baseCurrencyForUser(s.DB, userID)
+}
+
+// SetBaseCurrency changes the user's reporting currency. Stored summaries were converted to the
+// old currency, so they are discarded and rebuilt on demand.
+func (s *CurrencyService) SetBaseCurrency(userID uint, currency string) error {
+	if s.DB == nil {
+		return fmt.Errorf("database connection not initialized in CurrencyService")
+	}
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.User{}).Where("id = ?", userID).Update("base_currency", currency)
+		if result.Error != nil {
+			log.Printf("Error updating base currency for user %d: %v", userID, result.Error)
+			return fmt.Errorf("could not update base currency: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("user not found")
+		}
+		return deleteAllSummaries(tx, userID)
+	})
+}
+
+// UpsertExchangeRate stores a rate, replacing any existing rate for the same pair and date.
+func (s *CurrencyService) UpsertExchangeRate(rate *models.ExchangeRate) error {
+	if s.DB == nil {
+		return fmt.Errorf("database connection not initialized in CurrencyService")
+	}
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := upsertExchangeRate(tx, rate); err != nil {
+			return err
+		}
+		return deleteAllSummaries(tx, rate.UserID)
+	})
+}
+
+// GetExchangeRates retrieves a user's exchange rates with pagination, newest first.
+// fromCurrency and toCurrency optionally filter by pair.
+func (s *CurrencyService) GetExchangeRates(userID uint, offset, limit int, fromCurrency, toCurrency string) ([]models.ExchangeRate, error) {
+	if s.DB == nil {
+		return nil, fmt.Errorf("database connection not initialized in CurrencyService")
+	}
+	query := s.DB.Where("user_id = ?", userID)
+	if fromCurrency != "" {
+		query = query.Where("from_currency = ?", fromCurrency)
+	}
+	if toCurrency != "" {
+		query = query.Where("to_currency = ?", toCurrency)
+	}
+
+	var rates []models.ExchangeRate
+	if err := query.Order("date desc, from_currency, to_currency").Offset(offset).Limit(limit).Find(&rates).Error; err != nil {
+		log.Printf("Error retrieving exchange rates for user %d: %v", userID, err)
+		return nil, fmt.Errorf("could not retrieve exchange rates: %w", err)
+	}
+	return rates, nil
+}
+
+// ImportExchangeRatesCSV loads rates from CSV with the header "date,from_currency,to_currency,rate"
+// (columns in any order, dates as YYYY-MM-DD). The whole file is imported in one transaction:
+// a single invalid row rejects the import and reports its line number.
+func (s *CurrencyService) ImportExchangeRatesCSV(userID uint, r io.Reader) (int, error) {
+	if s.DB == nil {
+		return 0, fmt.Errorf("database connection not initialized in CurrencyService")
+	}
+
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err != nil {
+		return 0, fmt.Errorf("invalid exchange rate CSV: could not read header: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"date", "from_currency", "to_currency", "rate"} {
+		if _, ok := columns[required]; !ok {
+			return 0, fmt.Errorf("invalid exchange rate CSV: missing %q column", required)
+		}
+	}
+
+	var rates []models.ExchangeRate
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return 0, fmt.Errorf("invalid exchange rate CSV on line %d: %w", line, err)
+		}
+		rate, err := parseExchangeRateRecord(record, columns)
+		if err != nil {
+			return 0, fmt.Errorf("invalid exchange rate CSV on line %d: %w", line, err)
+		}
+		rate.UserID = userID
+		rates = append(rates, rate)
+	}
+
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		for i := range rates {
+			if err := upsertExchangeRate(tx, &rates[i]); err != nil {
+				return err
+			}
+		}
+		return deleteAllSummaries(tx, userID)
+	})
+	if err != nil {
+		log.Printf("Error importing exchange rates for user %d: %v", userID, err)
+		return 0, err
+	}
+	return len(rates), nil
+}
+
+func parseExchangeRateRecord(record []string, columns map[string]int) (models.ExchangeRate, error) {
+	field := func(name string) string {
+		if idx := columns[name]; idx < len(record) {
+			return strings.TrimSpace(record[idx])
+		}
+		return ""
+	}
+
+	date, err := time.Parse("2006-01-02", field("date"))
+	if err != nil {
+		return models.ExchangeRate{}, fmt.Errorf("invalid date %q, use YYYY-MM-DD", field("date"))
+	}
+	from := strings.ToUpper(field("from_currency"))
+	to := strings.ToUpper(field("to_currency"))
+	if !isCurrencyCode(from) || !isCurrencyCode(to) {
+		return models.ExchangeRate{}, fmt.Errorf("invalid currency pair %q/%q", from, to)
+	}
+	if from == to {
+		return models.ExchangeRate{}, fmt.Errorf("from_currency and to_currency must differ")
+	}
+	rate, err := strconv.ParseFloat(field("rate"), 64)
+	if err != nil || rate <= 0 {
+		return models.ExchangeRate{}, fmt.Errorf("invalid rate %q, must be a positive number", field("rate"))
+	}
+	return models.ExchangeRate{
+		FromCurrency: from,
+		ToCurrency:   to,
+		Rate:         rate,
+		Date:         database.CustomDate{Time: date},
+	}, nil
+}
+
+// isCurrencyCode reports whether code looks like an ISO 4217 code (three upper-case letters).
+func isCurrencyCode(code string) bool {
+	if len(code) != 3 {
+		return false
+	}
+	for i := 0; i < len(code); i++ {
+		if code[i] < 'A' || code[i] > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+func upsertExchangeRate(tx *gorm.DB, rate *models.ExchangeRate) error {
+	err := tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "from_currency"}, {Name: "to_currency"}, {Name: "date"}},
+		DoUpdates: clause.AssignmentColumns([]string{"rate", "updated_at"}),
+	}).Create(rate).Error
+	if err != nil {
+		return fmt.Errorf("could not save exchange rate %s/%s on %s: %w", rate.FromCurrency, rate.ToCurrency, rate.Date.Format("2006-01-02"), err)
+	}
+	return nil
+}
+
+// deleteAllSummaries discards every stored summary of a user. Summaries are a cache, so
+// they are removed permanently rather than soft-deleted, which would keep their unique key taken.
+func deleteAllSummaries(tx *gorm.DB, userID uint) error {
+	if err := tx.Unscoped().Where("user_id = ?", userID).Delete(&models.FinancialSummary{}).Error; err != nil {
+		return fmt.Errorf("could not invalidate summaries: %w", err)
+	}
+	return nil
+}
+
+// baseCurrencyForUser looks up the user's reporting currency.
+func baseCurrencyForUser(db *gorm.DB, userID uint) (string, error) {
+	var user models.User
+	if err := db.Select("id", "base_currency").First(&user, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", fmt.Errorf("user not found")
+		}
+		return "", fmt.Errorf("could not retrieve base currency: %w", err)
+	}
+	if user.BaseCurrency == "" {
+		return models.DefaultCurrency, nil
+	}
+	return user.BaseCurrency, nil
+}
+
+// resolveCurrency returns currency, or the user's base currency when it is empty.
+// Services call it when creating records so every amount carries a currency.
+func resolveCurrency(db *gorm.DB, userID uint, currency string) (string, error) {
+	if currency != "" {
+		return currency, nil
+	}
+	return baseCurrencyForUser(db, userID)
+}
+
+// rateKey identifies a cached exchange rate lookup.
+type rateKey struct {
+	from, to string
+	date     string
+}
+
+// CurrencyConverter converts a user's amounts into their base currency. It caches rate
+// lookups, so create one per report or summary computation rather than sharing it.
+type CurrencyConverter struct {
+	db     *gorm.DB
+	userID uint
+	Base   string
+	rates  map[rateKey]float64
+}
+
+// NewConverter returns a converter into the user's current base currency.
+func (s *CurrencyService) NewConverter(userID uint) (*CurrencyConverter, error) {
+	if s.DB == nil {
+		return nil, fmt.Errorf("database connection not initialized in CurrencyService")
+	}
+	base, err := baseCurrencyForUser(s.DB, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &CurrencyConverter{db: s.DB, userID: userID, Base: base, rates: make(map[rateKey]float64)}, nil
+}
+
+// Convert converts an amount in currency on the given date into the base currency, using the
+// most recent rate on or before that date. The inverse pair is used when only it is stored.
+func (c *CurrencyConverter) Convert(amount types.Money, currency string, on time.Time) (types.Money, error) {
+	if currency == "" || currency == c.Base || amount == 0 {
+		return amount, nil
+	}
+	rate, err := c.rate(currency, c.Base, on)
+	if err != nil {
+		return 0, err
+	}
+	return amount.Convert(rate), nil
+}
+
+func (c *CurrencyConverter) rate(from, to string, on time.Time) (float64, error) {
+	key := rateKey{from: from, to: to, date: on.Format("2006-01-02")}
+	if rate, ok := c.rates[key]; ok {
+		return rate, nil
+	}
+
+	var direct, inverse models.ExchangeRate
+	directErr := c.latestRate(from, to, key.date, &direct)
+	inverseErr := c.latestRate(to, from, key.date, &inverse)
+	for _, err := range []error{directErr, inverseErr} {
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Printf("Error looking up exchange rate %s/%s for user %d: %v", from, to, c.userID, err)
+			return 0, fmt.Errorf("could not look up exchange rate: %w", err)
+		}
+	}
+
+	var rate float64
+	switch {
+	case directErr == nil && (inverseErr != nil || !inverse.Date.After(direct.Date.Time)):
+		rate = direct.Rate
+	case inverseErr == nil:
+		rate = 1 / inverse.Rate
+	default:
+		return 0, fmt.Errorf("no exchange rate from %s to %s on or before %s", from, to, key.date)
+	}
+	c.rates[key] = rate
+	return rate, nil
+}
+
+func (c *CurrencyConverter) latestRate(from, to, onOrBefore string, dest *models.ExchangeRate) error {
+	return c.db.Where("user_id = ? AND from_currency = ? AND to_currency = ? AND date <= ?", c.userID, from, to, onOrBefore).
+		Order("date desc").First(dest).Error
+}
+
+// currencyTotal is one row of an amount sum grouped by currency and date.
+type currencyTotal struct {
+	GroupKey string
+	Currency string
+	Date     database.CustomDate
+	Total    types.Money
+}
+
+// SumAmounts sums the "amount" column of the rows selected by query, converting each
+// currency/date group into the base currency. query must already be scoped with Model and Where.
+func (c *CurrencyConverter) SumAmounts(query *gorm.DB) (types.Money, error) {
+	totals, err := c.SumAmountsBy(query, "")
+	if err != nil {
+		return 0, err
+	}
+	return totals[""], nil
+}
+
+// SumAmountsBy is like SumAmounts but returns one converted total per value of groupColumn.
+// An empty groupColumn sums everything under the "" key.
+func (c *CurrencyConverter) SumAmountsBy(query *gorm.DB, groupColumn string) (map[string]types.Money, error) {
+	selectKey := "'' AS group_key"
+	group := "currency, date"
+	if groupColumn != "" {
+		selectKey = groupColumn + " AS group_key"
+		group = groupColumn + ", " + group
+	}
+
+	var rows []currencyTotal
+	if err := query.Select(selectKey + ", currency, date, SUM(amount) AS total").Group(group).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	totals := make(map[string]types.Money)
+	for _, row := range rows {
+		converted, err := c.Convert(row.Total, row.Currency, row.Date.Time)
+		if err != nil {
+			return nil, err
+		}
+		totals[row.GroupKey] += converted
+	}
+	return totals, nil
+}
@@ -0,0 +1,139 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zayyadi/finance-tracker/internal/database"
+	"github.com/zayyadi/finance-tracker/internal/models"
+	"github.com/zayyadi/finance-tracker/internal/types"
+)
+
+func seedExchangeRate(t *testing.T, service *CurrencyService, from, to string, rate float64, date time.Time) {
+	err := service.UpsertExchangeRate(&models.ExchangeRate{UserID: testUserID, FromCurrency: from, ToCurrency: to, Rate: rate, Date: database.CustomDate{Time: date}})
+	require.NoError(t, err, "Failed to seed exchange rate %s/%s", from, to)
+}
+
+func TestCurrencyConverter_UsesLatestRateOnOrBeforeDate(t *testing.T) {
+	db := setupSummaryTestDB(t)
+	service := NewCurrencyService(db)
+	seedExchangeRate(t, service, "EUR", "USD", 1.10, time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC))
+	seedExchangeRate(t, service, "EUR", "USD", 1.20, time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC))
+
+	converter, err := service.NewConverter(testUserID)
+	require.NoError(t, err)
+	assert.Equal(t, "USD", converter.Base)
+
+	january, err := converter.Convert(types.Money(10000), "EUR", time.Date(2024, time.January, 31, 0, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Equal(t, types.Money(11000), january)
+
+	february, err := converter.Convert(types.Money(10000), "EUR", time.Date(2024, time.February, 15, 0, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Equal(t, types.Money(12000), february)
+
+	same, err := converter.Convert(types.Money(10000), "USD", time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Equal(t, types.Money(10000), same, "Amounts already in the base currency are not converted")
+
+	_, err = converter.Convert(types.Money(10000), "EUR", time.Date(2023, time.December, 31, 0, 0, 0, 0, time.UTC))
+	assert.ErrorContains(t, err, "no exchange rate from EUR to USD on or before 2023-12-31")
+}
+
+func TestCurrencyConverter_UsesInverseRate(t *testing.T) {
+	db := setupSummaryTestDB(t)
+	service := NewCurrencyService(db)
+	seedExchangeRate(t, service, "USD", "GBP", 0.8, time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC))
+
+	converter, err := service.NewConverter(testUserID)
+	require.NoError(t, err)
+
+	converted, err := converter.Convert(types.Money(8000), "GBP", time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Equal(t, types.Money(10000), converted)
+}
+
+func TestImportExchangeRatesCSV(t *testing.T) {
+	db := setupSummaryTestDB(t)
+	service := NewCurrencyService(db)
+
+	csvData := "date,from_currency,to_currency,rate\n" +
+		"2024-01-01,eur,USD,1.10\n" +
+		"2024-02-01,EUR,USD,1.20\n"
+	imported, err := service.ImportExchangeRatesCSV(testUserID, strings.NewReader(csvData))
+	require.NoError(t, err)
+	assert.Equal(t, 2, imported)
+
+	// Re-importing a pair and date replaces the stored rate instead of duplicating it.
+	imported, err = service.ImportExchangeRatesCSV(testUserID, strings.NewReader("rate,date,from_currency,to_currency\n1.25,2024-02-01,EUR,USD\n"))
+	require.NoError(t, err)
+	assert.Equal(t, 1, imported)
+
+	rates, err := service.GetExchangeRates(testUserID, 0, 10, "EUR", "USD")
+	require.NoError(t, err)
+	require.Len(t, rates, 2)
+	assert.Equal(t, "2024-02-01", rates[0].Date.Format("2006-01-02"))
+	assert.Equal(t, 1.25, rates[0].Rate)
+	assert.Equal(t, "EUR", rates[1].FromCurrency, "Currency codes are upper-cased")
+}
+
+func TestImportExchangeRatesCSV_RejectsInvalidRows(t *testing.T) {
+	db := setupSummaryTestDB(t)
+	service := NewCurrencyService(db)
+
+	testCases := []struct {
+		name    string
+		csv     string
+		wantErr string
+	}{
+		{"MissingColumn", "date,from_currency,rate\n2024-01-01,EUR,1.1\n", `missing "to_currency" column`},
+		{"BadDate", "date,from_currency,to_currency,rate\n2024-01-01,EUR,USD,1.1\n01/02/2024,EUR,USD,1.1\n", "line 3: invalid date"},
+		{"BadRate", "date,from_currency,to_currency,rate\n2024-01-01,EUR,USD,-1\n", "line 2: invalid rate"},
+		{"SamePair", "date,from_currency,to_currency,rate\n2024-01-01,USD,USD,1\n", "line 2: from_currency and to_currency must differ"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := service.ImportExchangeRatesCSV(testUserID, strings.NewReader(tc.csv))
+			assert.ErrorContains(t, err, "invalid exchange rate CSV")
+			assert.ErrorContains(t, err, tc.wantErr)
+		})
+	}
+
+	var count int64
+	db.Model(&models.ExchangeRate{}).Count(&count)
+	assert.Zero(t, count, "A rejected file must not import any rows")
+}
+
+func TestGetOrCreateFinancialSummary_ConvertsToBaseCurrency(t *testing.T) {
+	db := setupSummaryTestDB(t)
+	currencyService := NewCurrencyService(db)
+	summaryService := NewSummaryService(db)
+	incomeService := NewIncomeService(db)
+	date := time.Date(2024, time.March, 10, 0, 0, 0, 0, time.UTC)
+
+	require.NoError(t, incomeService.CreateIncome(&models.Income{UserID: testUserID, Amount: types.Money(10000), Category: "Salary", Date: database.CustomDate{Time: date}}))
+	require.NoError(t, incomeService.CreateIncome(&models.Income{UserID: testUserID, Amount: types.Money(5000), Currency: "EUR", Category: "Freelance", Date: database.CustomDate{Time: date}}))
+
+	var salary models.Income
+	require.NoError(t, db.Where("category = ?", "Salary").First(&salary).Error)
+	assert.Equal(t, "USD", salary.Currency, "Records without a currency take the user's base currency")
+
+	_, err := summaryService.GetOrCreateFinancialSummary(testUserID, "monthly", date, "overall")
+	assert.ErrorContains(t, err, "no exchange rate from EUR to USD")
+
+	seedExchangeRate(t, currencyService, "EUR", "USD", 1.5, time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC))
+	summary, err := summaryService.GetOrCreateFinancialSummary(testUserID, "monthly", date, "overall")
+	require.NoError(t, err)
+	assert.Equal(t, types.Money(17500), summary.TotalIncome)
+	assert.Equal(t, "USD", summary.Currency)
+
+	// Switching the base currency discards stored summaries and reports in the new currency.
+	require.NoError(t, currencyService.SetBaseCurrency(testUserID, "EUR"))
+	summary, err = summaryService.GetOrCreateFinancialSummary(testUserID, "monthly", date, "overall")
+	require.NoError(t, err)
+	assert.Equal(t, types.Money(11667), summary.TotalIncome)
+	assert.Equal(t, "EUR", summary.Currency)
+}
@@ -4,8 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"log"
-
-	// "time" // Not needed for GORM model timestamps if gorm.Model is used
+	"time"
 
 	"github.com/zayyadi/finance-tracker/internal/database"
 	"github.com/zayyadi/finance-tracker/internal/models"
@@ -31,66 +30,104 @@ func (s *DebtService) CreateDebt(debt *models.Debt) error {
 	if s.DB == nil {
 		return fmt.Errorf("database connection not initialized in DebtService")
 	}
-	result := s.DB.Create(debt)
-	if result.Error != nil {
-		// log.Printf("Error creating debt for user %d: %v", debt.UserID, result.Error) // UserID removed
-		log.Printf("Error creating debt: %v", result.Error)
-		return fmt.Errorf("could not create debt: %w", result.Error)
+	currency, err := resolveCurrency(s.DB, debt.UserID, debt.Currency)
+	if err != nil {
+		return fmt.Errorf("could not create debt: %w", err)
+	}
+	debt.Currency = currency
+	tags, err := normalizeTags(debt.Tags)
+	if err != nil {
+		return fmt.Errorf("could not create debt: %w", err)
+	}
+	debt.Tags = tags
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(debt).Error; err != nil {
+			return err
+		}
+		if len(tags) == 0 {
+			return nil
+		}
+		return setTags(tx, debt.UserID, "debt", debt.ID, tags)
+	})
+	if err != nil {
+		log.Printf("Error creating debt for user %d: %v", debt.UserID, err)
+		return fmt.Errorf("could not create debt: %w", err)
 	}
 	return nil
 }
 
-// GetDebtByID retrieves a specific debt record by its ID.
-func (s *DebtService) GetDebtByID(debtID uint) (*models.Debt, error) {
+// GetDebtByID retrieves a specific debt record by its ID, scoped to the given user.
+func (s *DebtService) GetDebtByID(userID uint, debtID uint) (*models.Debt, error) {
 	if s.DB == nil {
 		return nil, fmt.Errorf("database connection not initialized in DebtService")
 	}
 	var debt models.Debt
-	result := s.DB.Where("id = ?", debtID).First(&debt) // Removed userID
+	result := s.DB.Where("id = ? AND user_id = ?", debtID, userID).First(&debt)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
-			return nil, fmt.Errorf("debt record not found") // Simplified error
+			return nil, fmt.Errorf("debt record not found")
 		}
-		log.Printf("Error retrieving debt %d: %v", debtID, result.Error)
+		log.Printf("Error retrieving debt %d for user %d: %v", debtID, userID, result.Error)
 		return nil, fmt.Errorf("could not retrieve debt: %w", result.Error)
 	}
+	tags, err := loadTags(s.DB, "debt", []uint{debt.ID})
+	if err != nil {
+		return nil, err
+	}
+	debt.Tags = tags[debt.ID]
 	return &debt, nil
 }
 
-// GetDebts retrieves all debt records with pagination and optional status filter.
-func (s *DebtService) GetDebts(offset int, limit int, statusFilter string) ([]models.Debt, error) {
+// GetDebts retrieves a page, counting from 1, of the debt records of a user matching filter, and
+// statusFilter when it is set. They are ordered by due date unless filter sorts them otherwise.
+func (s *DebtService) GetDebts(userID uint, page int, limit int, statusFilter string, filter models.ListFilter) (*models.Page[models.Debt], error) {
 	if s.DB == nil {
 		return nil, fmt.Errorf("database connection not initialized in DebtService")
 	}
 
-	query := s.DB.Model(&models.Debt{}) // Start query on the Debt model
+	query := s.DB.Model(&models.Debt{}).Where("user_id = ?", userID)
 	if statusFilter != "" {
 		query = query.Where("status = ?", statusFilter)
 	}
+	query, err := applyListFilter(query, userID, debtListColumns, filter)
+	if err != nil {
+		return nil, err
+	}
 
-	var debts []models.Debt
-	result := query.Offset(offset).Limit(limit).Order("due_date asc, created_at desc").Find(&debts) // Removed userID
-
-	if result.Error != nil {
-		log.Printf("Error retrieving debts (status: '%s'): %v", statusFilter, result.Error)
-		return nil, fmt.Errorf("could not retrieve debts: %w", result.Error)
+	debts, err := paginate[models.Debt](query, page, limit)
+	if err != nil {
+		log.Printf("Error retrieving debts for user %d (status: '%s'): %v", userID, statusFilter, err)
+		return nil, fmt.Errorf("could not retrieve debts: %w", err)
 	}
-	if debts == nil {
-		return []models.Debt{}, nil
+	ids := make([]uint, len(debts.Items))
+	for i := range debts.Items {
+		ids[i] = debts.Items[i].ID
+	}
+	debtTags, err := loadTags(s.DB, "debt", ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range debts.Items {
+		debts.Items[i].Tags = debtTags[debts.Items[i].ID]
 	}
 	return debts, nil
 }
 
-// UpdateDebt updates an existing debt record.
-func (s *DebtService) UpdateDebt(debtID uint, updateData *models.DebtUpdateRequest) (*models.Debt, error) {
+// UpdateDebt updates an existing debt record owned by the given user.
+// When versions are given, the update is only made while the debt record was last changed at one
+// of them; otherwise a VersionConflictError is returned.
+func (s *DebtService) UpdateDebt(userID uint, debtID uint, updateData *models.DebtUpdateRequest, versions ...time.Time) (*models.Debt, error) {
 	if s.DB == nil {
 		return nil, fmt.Errorf("database connection not initialized in DebtService")
 	}
 
-	existingDebt, err := s.GetDebtByID(debtID) // Removed userID
+	existingDebt, err := s.GetDebtByID(userID, debtID)
 	if err != nil {
 		return nil, err
 	}
+	if err := checkVersion(existingDebt.UpdatedAt, "debt record", versions); err != nil {
+		return nil, err
+	}
 
 	updatesMap := make(map[string]interface{})
 	if updateData.DebtorName != nil {
@@ -102,40 +139,125 @@ func (s *DebtService) UpdateDebt(debtID uint, updateData *models.DebtUpdateReque
 	if updateData.Amount != nil {
 		updatesMap["amount"] = *updateData.Amount
 	}
+	if updateData.Currency != nil {
+		updatesMap["currency"] = *updateData.Currency
+	}
 	if updateData.DueDate != nil {
 		updatesMap["due_date"] = *updateData.DueDate
 	}
 	if updateData.Status != nil && *updateData.Status != "" {
 		updatesMap["status"] = *updateData.Status
 	}
+	var tags []string
+	if updateData.Tags != nil {
+		if tags, err = normalizeTags(*updateData.Tags); err != nil {
+			return nil, fmt.Errorf("could not update debt: %w", err)
+		}
+	}
 
 	if len(updatesMap) == 0 {
-		return existingDebt, nil // No fields to update
+		if updateData.Tags == nil {
+			return existingDebt, nil // No fields to update
+		}
+		// Only the tags changed; the record is still touched so its ETag changes with them.
+		updatesMap["updated_at"] = time.Now()
 	}
 
-	result := s.DB.Model(&existingDebt).Where("id = ?", debtID).Updates(updatesMap) // Removed userID
-	if result.Error != nil {
-		log.Printf("Error updating debt %d: %v", debtID, result.Error)
-		return nil, fmt.Errorf("could not update debt: %w", result.Error)
+	// The tags and the record are written together, so a failed update leaves the old tags in place.
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		if updateData.Tags != nil {
+			if err := setTags(tx, userID, "debt", debtID, tags); err != nil {
+				log.Printf("Error tagging debt %d: %v", debtID, err)
+				return err
+			}
+		}
+		result := whereVersion(tx.Model(&existingDebt).Where("id = ? AND user_id = ?", debtID, userID), existingDebt.UpdatedAt, versions).Updates(updatesMap)
+		if result.Error != nil {
+			log.Printf("Error updating debt %d: %v", debtID, result.Error)
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			if len(versions) > 0 {
+				return versionConflict(tx, &models.Debt{}, "debt record", userID, debtID)
+			}
+			return fmt.Errorf("debt record not found during update (or no changes made)")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not update debt: %w", err)
 	}
-	if result.RowsAffected == 0 {
-		return nil, fmt.Errorf("debt record not found during update (or no changes made)")
+	if updateData.Tags != nil {
+		existingDebt.Tags = tags
 	}
 	return existingDebt, nil
 }
 
-// DeleteDebt deletes a debt record.
-func (s *DebtService) DeleteDebt(debtID uint) error {
+// DeleteDebt deletes a debt record owned by the given user.
+// When versions are given, it is only deleted while it was last changed at one of them; otherwise a
+// VersionConflictError is returned.
+func (s *DebtService) DeleteDebt(userID uint, debtID uint, versions ...time.Time) error {
 	if s.DB == nil {
 		return fmt.Errorf("database connection not initialized in DebtService")
 	}
-	result := s.DB.Where("id = ?", debtID).Delete(&models.Debt{}) // Removed userID
+	query := s.DB.Where("id = ? AND user_id = ?", debtID, userID)
+	if len(versions) > 0 {
+		updatedAt, err := recordVersion(s.DB, &models.Debt{}, "debt record", userID, debtID)
+		if err != nil {
+			return err
+		}
+		if err := checkVersion(updatedAt, "debt record", versions); err != nil {
+			return err
+		}
+		query = whereVersion(query, updatedAt, versions)
+	}
+	result := query.Delete(&models.Debt{})
 	if result.Error != nil {
 		log.Printf("Error deleting debt %d: %v", debtID, result.Error)
 		return fmt.Errorf("could not delete debt: %w", result.Error)
 	}
 	if result.RowsAffected == 0 {
+		if len(versions) > 0 {
+			return versionConflict(s.DB, &models.Debt{}, "debt record", userID, debtID)
+		}
 		return fmt.Errorf("debt record not found, no rows deleted")
 	}
 	return nil
 }
+
+// BulkCreateDebts creates the debts in one transaction: if any of them cannot be created, none is.
+func (s *DebtService) BulkCreateDebts(debts []models.Debt) (*models.BulkResult[models.Debt], error) {
+	if s.DB == nil {
+		return nil, fmt.Errorf("database connection not initialized in DebtService")
+	}
+	return runBulk(s.DB, models.BulkStatusCreated, nil, len(debts), func(tx *gorm.DB, i int) (*models.Debt, []time.Time, error) {
+		if err := (&DebtService{DB: tx}).CreateDebt(&debts[i]); err != nil {
+			return nil, nil, err
+		}
+		return &debts[i], nil, nil
+	})
+}
+
+// BulkUpdateDebts updates debt records owned by the given user in one transaction: if any of them cannot
+// be updated, none is.
+func (s *DebtService) BulkUpdateDebts(userID uint, items []models.DebtBulkUpdateItem) (*models.BulkResult[models.Debt], error) {
+	if s.DB == nil {
+		return nil, fmt.Errorf("database connection not initialized in DebtService")
+	}
+	ids := bulkUpdateIDs(items, func(item models.DebtBulkUpdateItem) uint { return item.ID })
+	return runBulk(s.DB, models.BulkStatusUpdated, ids, len(items), func(tx *gorm.DB, i int) (*models.Debt, []time.Time, error) {
+		updated, err := (&DebtService{DB: tx}).UpdateDebt(userID, items[i].ID, &items[i].DebtUpdateRequest)
+		return updated, nil, err
+	})
+}
+
+// BulkDeleteDebts deletes debt records owned by the given user in one transaction: if any of them cannot
+// be deleted, none is.
+func (s *DebtService) BulkDeleteDebts(userID uint, debtIDs []uint) (*models.BulkResult[models.Debt], error) {
+	if s.DB == nil {
+		return nil, fmt.Errorf("database connection not initialized in DebtService")
+	}
+	return runBulk(s.DB, models.BulkStatusDeleted, debtIDs, len(debtIDs), func(tx *gorm.DB, i int) (*models.Debt, []time.Time, error) {
+		return nil, nil, (&DebtService{DB: tx}).DeleteDebt(userID, debtIDs[i])
+	})
+}
@@ -0,0 +1,425 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/zayyadi/finance-tracker/internal/database"
+	"github.com/zayyadi/finance-tracker/internal/models"
+	"github.com/zayyadi/finance-tracker/internal/types"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// duplicateDateWindow is how many days apart two entries can be and still be flagged as duplicates.
+// A manually entered expense is often dated when it was paid, the bank's copy when it cleared.
+const duplicateDateWindow = 3
+
+// DuplicateService finds incomes and expenses that were recorded twice, e.g. entered by hand and then
+// imported from a statement, and lets the user merge or dismiss them.
+type DuplicateService struct {
+	DB             *gorm.DB
+	summaryService *SummaryService
+}
+
+// NewDuplicateService creates a new DuplicateService. The summary service may be nil, in which case
+// merges do not invalidate cached summaries.
+func NewDuplicateService(db *gorm.DB, summaryService *SummaryService) *DuplicateService {
+	if db == nil {
+		log.Println("Warning: NewDuplicateService called with nil DB, attempting to use global GetDB()")
+		db = database.GetDB()
+	}
+	return &DuplicateService{DB: db, summaryService: summaryService}
+}
+
+// duplicateCandidate holds the fields of an income or expense that duplicate detection looks at.
+type duplicateCandidate struct {
+	ID         uint
+	Amount     types.Money
+	Currency   string
+	AccountID  *uint
+	ExternalID *string
+	Category   string
+	CategoryID *uint
+	Date       database.CustomDate
+	Note       string
+	DeletedAt  gorm.DeletedAt
+}
+
+// duplicateMatch is an existing entry that a new one may duplicate.
+type duplicateMatch struct {
+	id        uint
+	daysApart int
+	matchedOn string
+}
+
+func duplicateCandidateOfIncome(income *models.Income) duplicateCandidate {
+	return duplicateCandidate{ID: income.ID, Amount: income.Amount, Currency: income.Currency, Category: income.Category, Date: income.Date, Note: income.Note}
+}
+
+func duplicateCandidateOfExpense(expense *models.Expense) duplicateCandidate {
+	return duplicateCandidate{ID: expense.ID, Amount: expense.Amount, Currency: expense.Currency, Category: expense.Category, Date: expense.Date, Note: expense.Note}
+}
+
+// transactionModel returns the model of the table holding entries of the given type.
+func transactionModel(txnType string) interface{} {
+	if txnType == "income" {
+		return &models.Income{}
+	}
+	return &models.Expense{}
+}
+
+// findDuplicates returns the user's existing entries of the given type that look like txn. txn itself
+// is excluded when it has already been saved.
+func findDuplicates(db *gorm.DB, userID uint, txnType string, txn duplicateCandidate) ([]duplicateMatch, error) {
+	from := txn.Date.AddDate(0, 0, -duplicateDateWindow).Format("2006-01-02")
+	to := txn.Date.AddDate(0, 0, duplicateDateWindow).Format("2006-01-02")
+	var candidates []duplicateCandidate
+	err := db.Model(transactionModel(txnType)).
+		Where("user_id = ? AND id <> ? AND amount = ? AND currency = ? AND date BETWEEN ? AND ?", userID, txn.ID, txn.Amount, txn.Currency, from, to).
+		Order("date, id").
+		Find(&candidates).Error
+	if err != nil {
+		return nil, fmt.Errorf("could not look for duplicate %ss: %w", txnType, err)
+	}
+	var matches []duplicateMatch
+	for _, candidate := range candidates {
+		if matchedOn := duplicateMatchedOn(txn, candidate); matchedOn != "" {
+			matches = append(matches, duplicateMatch{id: candidate.ID, daysApart: daysApart(txn.Date.Time, candidate.Date.Time), matchedOn: matchedOn})
+		}
+	}
+	return matches, nil
+}
+
+// duplicateMatchedOn reports what two entries with the same amount have in common besides it: a word
+// of their notes, their category, or both. It returns "" when they share neither.
+func duplicateMatchedOn(a, b duplicateCandidate) string {
+	var matched []string
+	aWords := noteFingerprint(a.Note)
+	for word := range noteFingerprint(b.Note) {
+		if aWords[word] {
+			matched = append(matched, "note")
+			break
+		}
+	}
+	if category := categoryFingerprint(a.Category); category != "" && category == categoryFingerprint(b.Category) {
+		matched = append(matched, "category")
+	}
+	return strings.Join(matched, "+")
+}
+
+// noteFingerprint returns the words of a note that identify a payee, in lower case. Words with digits,
+// such as card numbers, references and dates that banks add to descriptions, and very short words
+// are left out, so "SUPERMARKET 4411 LONDON" and "Supermarket" share "supermarket".
+func noteFingerprint(note string) map[string]bool {
+	words := make(map[string]bool)
+	for _, word := range strings.FieldsFunc(strings.ToLower(note), func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }) {
+		if len([]rune(word)) < 3 || strings.IndexFunc(word, unicode.IsDigit) >= 0 {
+			continue
+		}
+		words[word] = true
+	}
+	return words
+}
+
+// categoryFingerprint normalizes a category for comparison. The fallback category says
+// nothing about a transaction, so it never counts as a match.
+func categoryFingerprint(category string) string {
+	category = strings.ToLower(strings.TrimSpace(category))
+	if category == strings.ToLower(defaultCategory) {
+		return ""
+	}
+	return category
+}
+
+// daysApart returns the number of days between two dates, whichever comes first.
+func daysApart(a, b time.Time) int {
+	days := int(a.Sub(b).Hours() / 24)
+	if days < 0 {
+		return -days
+	}
+	return days
+}
+
+// flagDuplicates records a pending duplicate pair for each match of the entry with the given ID. Pairs
+// flagged before, including merged and dismissed ones, are left as they are. It returns how many new
+// pairs were flagged.
+func flagDuplicates(db *gorm.DB, userID uint, txnType string, txnID uint, matches []duplicateMatch) (int, error) {
+	flagged := 0
+	for _, match := range matches {
+		pair := models.DuplicatePair{UserID: userID, Type: txnType, TransactionID: txnID, DuplicateOfID: match.id, DaysApart: match.daysApart, MatchedOn: match.matchedOn, Status: "pending"}
+		if match.id > txnID {
+			pair.TransactionID, pair.DuplicateOfID = match.id, txnID
+		}
+		result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&pair)
+		if result.Error != nil {
+			return flagged, fmt.Errorf("could not flag duplicate %s: %w", txnType, result.Error)
+		}
+		flagged += int(result.RowsAffected)
+	}
+	return flagged, nil
+}
+
+// flagNewTransaction flags a newly created income or expense against the user's existing ones and
+// returns the IDs of those it may duplicate. Detection is advisory, so failures are only logged.
+func flagNewTransaction(db *gorm.DB, userID uint, txnType string, txn duplicateCandidate) []uint {
+	matches, err := findDuplicates(db, userID, txnType, txn)
+	if err != nil {
+		log.Printf("Error checking %s %d for duplicates: %v", txnType, txn.ID, err)
+		return nil
+	}
+	if len(matches) == 0 {
+		return nil
+	}
+	if _, err := flagDuplicates(db, userID, txnType, txn.ID, matches); err != nil {
+		log.Printf("Error flagging duplicates of %s %d: %v", txnType, txn.ID, err)
+	}
+	ids := make([]uint, len(matches))
+	for i, match := range matches {
+		ids[i] = match.id
+	}
+	return ids
+}
+
+// ScanDuplicates looks for duplicates among all of the user's existing incomes and expenses, for
+// history recorded before detection was in place. It returns how many new pairs were flagged.
+func (s *DuplicateService) ScanDuplicates(userID uint) (int, error) {
+	if s.DB == nil {
+		return 0, fmt.Errorf("database connection not initialized in DuplicateService")
+	}
+	flagged := 0
+	for _, txnType := range []string{"income", "expense"} {
+		var entries []duplicateCandidate
+		if err := s.DB.Model(transactionModel(txnType)).Where("user_id = ?", userID).Order("date, id").Find(&entries).Error; err != nil {
+			log.Printf("Error loading %ss of user %d for a duplicate scan: %v", txnType, userID, err)
+			return flagged, fmt.Errorf("could not scan for duplicates: %w", err)
+		}
+		// Entries are in date order, so each only needs comparing with the ones shortly before it.
+		for i, entry := range entries {
+			var matches []duplicateMatch
+			for j := i - 1; j >= 0; j-- {
+				earlier := entries[j]
+				days := daysApart(entry.Date.Time, earlier.Date.Time)
+				if days > duplicateDateWindow {
+					break
+				}
+				if earlier.Amount != entry.Amount || earlier.Currency != entry.Currency {
+					continue
+				}
+				if matchedOn := duplicateMatchedOn(entry, earlier); matchedOn != "" {
+					matches = append(matches, duplicateMatch{id: earlier.ID, daysApart: days, matchedOn: matchedOn})
+				}
+			}
+			n, err := flagDuplicates(s.DB, userID, txnType, entry.ID, matches)
+			flagged += n
+			if err != nil {
+				return flagged, fmt.Errorf("could not scan for duplicates: %w", err)
+			}
+		}
+	}
+	return flagged, nil
+}
+
+// GetDuplicatePairs lists the user's duplicate pairs with the given status, most recently flagged first.
+func (s *DuplicateService) GetDuplicatePairs(userID uint, status string, offset int, limit int) ([]models.DuplicatePairResponse, error) {
+	if s.DB == nil {
+		return nil, fmt.Errorf("database connection not initialized in DuplicateService")
+	}
+	var pairs []models.DuplicatePair
+	result := s.DB.Where("user_id = ? AND status = ?", userID, status).
+		Order("created_at desc, id desc").
+		Offset(offset).
+		Limit(limit).
+		Find(&pairs)
+	if result.Error != nil {
+		log.Printf("Error retrieving duplicate pairs for user %d: %v", userID, result.Error)
+		return nil, fmt.Errorf("could not retrieve duplicate pairs: %w", result.Error)
+	}
+	return s.pairResponses(userID, pairs)
+}
+
+// GetDuplicatePairByID retrieves a duplicate pair and its two entries, scoped to the given user.
+func (s *DuplicateService) GetDuplicatePairByID(userID uint, pairID uint) (*models.DuplicatePairResponse, error) {
+	if s.DB == nil {
+		return nil, fmt.Errorf("database connection not initialized in DuplicateService")
+	}
+	pair, err := s.getPair(s.DB, userID, pairID)
+	if err != nil {
+		return nil, err
+	}
+	responses, err := s.pairResponses(userID, []models.DuplicatePair{*pair})
+	if err != nil {
+		return nil, err
+	}
+	return &responses[0], nil
+}
+
+// DismissDuplicatePair marks a pending pair as not being a duplicate, so it is not flagged again.
+func (s *DuplicateService) DismissDuplicatePair(userID uint, pairID uint) (*models.DuplicatePairResponse, error) {
+	if s.DB == nil {
+		return nil, fmt.Errorf("database connection not initialized in DuplicateService")
+	}
+	pair, err := s.getPair(s.DB, userID, pairID)
+	if err != nil {
+		return nil, err
+	}
+	if pair.Status != "pending" {
+		return nil, fmt.Errorf("duplicate pair is already %s", pair.Status)
+	}
+	now := time.Now().UTC()
+	if err := s.DB.Model(pair).Updates(map[string]interface{}{"status": "dismissed", "resolved_at": now}).Error; err != nil {
+		log.Printf("Error dismissing duplicate pair %d: %v", pairID, err)
+		return nil, fmt.Errorf("could not dismiss duplicate pair: %w", err)
+	}
+	return s.GetDuplicatePairByID(userID, pairID)
+}
+
+// MergeDuplicatePair resolves a pending pair by deleting one of its two entries. The kept entry, the
+// earlier one unless keepID says otherwise, takes over the deleted entry's note, category, account and
+// external ID where it has none of its own, so a later import of the same statement still recognises it.
+func (s *DuplicateService) MergeDuplicatePair(userID uint, pairID uint, keepID *uint) (*models.DuplicatePairResponse, error) {
+	if s.DB == nil {
+		return nil, fmt.Errorf("database connection not initialized in DuplicateService")
+	}
+	var dates []time.Time
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		pair, err := s.getPair(tx, userID, pairID)
+		if err != nil {
+			return err
+		}
+		if pair.Status != "pending" {
+			return fmt.Errorf("duplicate pair is already %s", pair.Status)
+		}
+		keptID, removedID := pair.DuplicateOfID, pair.TransactionID
+		if keepID != nil && *keepID != keptID {
+			if *keepID != removedID {
+				return fmt.Errorf("keep_id must be one of the pair's transaction IDs (%d or %d)", pair.TransactionID, pair.DuplicateOfID)
+			}
+			keptID, removedID = removedID, keptID
+		}
+
+		model := transactionModel(pair.Type)
+		var entries []duplicateCandidate
+		if err := tx.Model(model).Where("user_id = ? AND id IN ?", userID, []uint{keptID, removedID}).Find(&entries).Error; err != nil {
+			return fmt.Errorf("could not retrieve the pair's transactions: %w", err)
+		}
+		var kept, removed *duplicateCandidate
+		for i := range entries {
+			if entries[i].ID == keptID {
+				kept = &entries[i]
+			} else {
+				removed = &entries[i]
+			}
+		}
+		if kept == nil || removed == nil {
+			return fmt.Errorf("a %s of this duplicate pair no longer exists", pair.Type)
+		}
+
+		updates := make(map[string]interface{})
+		if kept.Note == "" && removed.Note != "" {
+			updates["note"] = removed.Note
+		}
+		if categoryFingerprint(kept.Category) == "" && categoryFingerprint(removed.Category) != "" {
+			updates["category"] = removed.Category
+			updates["category_id"] = removed.CategoryID
+		}
+		if kept.AccountID == nil && removed.AccountID != nil {
+			updates["account_id"] = removed.AccountID
+		}
+		if kept.ExternalID == nil && removed.ExternalID != nil {
+			// External IDs are unique per user, deleted rows included, so free it up first.
+			if err := tx.Model(model).Where("id = ?", removedID).Update("external_id", nil).Error; err != nil {
+				return fmt.Errorf("could not move the external ID: %w", err)
+			}
+			updates["external_id"] = removed.ExternalID
+		}
+		if len(updates) > 0 {
+			if err := tx.Model(model).Where("id = ? AND user_id = ?", keptID, userID).Updates(updates).Error; err != nil {
+				return fmt.Errorf("could not update the kept %s: %w", pair.Type, err)
+			}
+		}
+		if err := copyTags(tx, pair.Type, removedID, keptID); err != nil {
+			return err
+		}
+		if err := tx.Where("id = ? AND user_id = ?", removedID, userID).Delete(model).Error; err != nil {
+			return fmt.Errorf("could not delete the duplicate %s: %w", pair.Type, err)
+		}
+
+		now := time.Now().UTC()
+		if err := tx.Model(pair).Updates(map[string]interface{}{"status": "merged", "kept_id": keptID, "resolved_at": now}).Error; err != nil {
+			return fmt.Errorf("could not update duplicate pair: %w", err)
+		}
+		// Other pending pairs involving the deleted entry have nothing left to review.
+		if err := tx.Unscoped().
+			Where("user_id = ? AND type = ? AND status = ? AND id <> ? AND (transaction_id = ? OR duplicate_of_id = ?)", userID, pair.Type, "pending", pair.ID, removedID, removedID).
+			Delete(&models.DuplicatePair{}).Error; err != nil {
+			return fmt.Errorf("could not clear other duplicate pairs: %w", err)
+		}
+		dates = []time.Time{kept.Date.Time, removed.Date.Time}
+		return nil
+	})
+	if err != nil {
+		log.Printf("Error merging duplicate pair %d for user %d: %v", pairID, userID, err)
+		return nil, err
+	}
+
+	invalidateSummariesForDates(s.summaryService, userID, dates)
+	return s.GetDuplicatePairByID(userID, pairID)
+}
+
+// getPair loads a duplicate pair owned by the given user.
+func (s *DuplicateService) getPair(db *gorm.DB, userID uint, pairID uint) (*models.DuplicatePair, error) {
+	var pair models.DuplicatePair
+	result := db.Where("id = ? AND user_id = ?", pairID, userID).First(&pair)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("duplicate pair not found")
+		}
+		log.Printf("Error retrieving duplicate pair %d for user %d: %v", pairID, userID, result.Error)
+		return nil, fmt.Errorf("could not retrieve duplicate pair: %w", result.Error)
+	}
+	return &pair, nil
+}
+
+// pairResponses attaches the two entries, including deleted ones, to each pair.
+func (s *DuplicateService) pairResponses(userID uint, pairs []models.DuplicatePair) ([]models.DuplicatePairResponse, error) {
+	ids := map[string][]uint{}
+	for _, pair := range pairs {
+		ids[pair.Type] = append(ids[pair.Type], pair.TransactionID, pair.DuplicateOfID)
+	}
+	entries := map[string]map[uint]*models.DuplicateTransaction{}
+	for txnType, typeIDs := range ids {
+		var candidates []duplicateCandidate
+		if err := s.DB.Unscoped().Model(transactionModel(txnType)).Where("user_id = ? AND id IN ?", userID, typeIDs).Find(&candidates).Error; err != nil {
+			return nil, fmt.Errorf("could not retrieve duplicate %ss: %w", txnType, err)
+		}
+		entries[txnType] = make(map[uint]*models.DuplicateTransaction, len(candidates))
+		for _, c := range candidates {
+			entries[txnType][c.ID] = &models.DuplicateTransaction{
+				ID:         c.ID,
+				Date:       c.Date.Format("2006-01-02"),
+				Amount:     c.Amount,
+				Currency:   c.Currency,
+				AccountID:  c.AccountID,
+				Category:   c.Category,
+				Note:       c.Note,
+				ExternalID: c.ExternalID,
+				Deleted:    c.DeletedAt.Valid,
+			}
+		}
+	}
+
+	responses := make([]models.DuplicatePairResponse, len(pairs))
+	for i, pair := range pairs {
+		responses[i] = models.DuplicatePairResponse{
+			DuplicatePair: pair,
+			Transaction:   entries[pair.Type][pair.TransactionID],
+			DuplicateOf:   entries[pair.Type][pair.DuplicateOfID],
+		}
+	}
+	return responses, nil
+}
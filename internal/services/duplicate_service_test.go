@@ -0,0 +1,129 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zayyadi/finance-tracker/internal/database"
+	"github.com/zayyadi/finance-tracker/internal/models"
+	"github.com/zayyadi/finance-tracker/internal/types"
+)
+
+func TestDuplicateService_FlagOnCreateAndMerge(t *testing.T) {
+	db := setupImportTestDB(t)
+	service := NewDuplicateService(db, nil)
+	expenseService := NewExpenseService(db)
+	account := createTestAccount(t, NewAccountService(db), "Checking", "USD", 0)
+
+	day := func(d int) database.CustomDate {
+		return database.CustomDate{Time: time.Date(2024, time.June, d, 0, 0, 0, 0, time.UTC)}
+	}
+	manual := &models.Expense{UserID: testUserID, Amount: types.Money(4520), Category: "Groceries", Date: day(3), Note: "Supermarket"}
+	require.NoError(t, expenseService.CreateExpense(manual))
+	assert.Empty(t, manual.PossibleDuplicateOf)
+
+	// Neither a different amount, nor the same amount with nothing else in common, is flagged.
+	other := &models.Expense{UserID: testUserID, Amount: types.Money(4521), Category: "Groceries", Date: day(3), Note: "Supermarket"}
+	require.NoError(t, expenseService.CreateExpense(other))
+	assert.Empty(t, other.PossibleDuplicateOf)
+	unrelated := &models.Expense{UserID: testUserID, Amount: types.Money(4520), Category: "Fuel", Date: day(4), Note: "Petrol station"}
+	require.NoError(t, expenseService.CreateExpense(unrelated))
+	assert.Empty(t, unrelated.PossibleDuplicateOf)
+
+	// The bank's copy of the manual entry, imported two days later, is flagged on its note.
+	csv := "Date,Amount,Description\n2024-06-05,-45.20,SUPERMARKET 4411 LONDON\n"
+	importService := NewImportService(db, nil)
+	profile, err := importService.ProfileFromRequest(testUserID, &models.ImportProfileRequest{Name: "Bank", DateColumn: "Date", AmountColumn: "Amount", DescriptionColumn: "Description", AccountID: &account.ID})
+	require.NoError(t, err)
+	preview, err := importService.ImportCSV(testUserID, strings.NewReader(csv), profile, true)
+	require.NoError(t, err)
+	assert.Equal(t, []uint{manual.ID}, preview.Transactions[0].PossibleDuplicateOf)
+	pending, err := service.GetDuplicatePairs(testUserID, "pending", 0, 10)
+	require.NoError(t, err)
+	assert.Empty(t, pending, "A dry run flags nothing")
+
+	imported, err := importService.ImportCSV(testUserID, strings.NewReader(csv), profile, false)
+	require.NoError(t, err)
+	require.Equal(t, 1, imported.Imported)
+	pending, err = service.GetDuplicatePairs(testUserID, "pending", 0, 10)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	pair := pending[0]
+	assert.Equal(t, "expense", pair.Type)
+	assert.Equal(t, imported.Transactions[0].ID, pair.TransactionID)
+	assert.Equal(t, manual.ID, pair.DuplicateOfID)
+	assert.Equal(t, 2, pair.DaysApart)
+	assert.Equal(t, "note", pair.MatchedOn)
+	assert.Equal(t, "SUPERMARKET 4411 LONDON", pair.Transaction.Note)
+
+	// Merging keeps the manual entry, which takes the imported entry's account.
+	merged, err := service.MergeDuplicatePair(testUserID, pair.ID, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "merged", merged.Status)
+	require.NotNil(t, merged.KeptID)
+	assert.Equal(t, manual.ID, *merged.KeptID)
+	assert.True(t, merged.Transaction.Deleted)
+	assert.Equal(t, &account.ID, merged.DuplicateOf.AccountID)
+	assert.Equal(t, "Groceries", merged.DuplicateOf.Category)
+
+	_, err = service.MergeDuplicatePair(testUserID, pair.ID, nil)
+	assert.ErrorContains(t, err, "already merged")
+	_, err = service.GetDuplicatePairByID(testUserID+1, pair.ID)
+	assert.ErrorContains(t, err, "duplicate pair not found")
+}
+
+func TestDuplicateService_ScanMergeExternalIDAndDismiss(t *testing.T) {
+	db := setupImportTestDB(t)
+	service := NewDuplicateService(db, nil)
+	day := func(d int) database.CustomDate {
+		return database.CustomDate{Time: time.Date(2024, time.June, d, 0, 0, 0, 0, time.UTC)}
+	}
+	bankID := "ACC:T1"
+	// Rows written directly, as history recorded before detection existed.
+	salary := models.Income{UserID: testUserID, Amount: types.Money(300000), Currency: "USD", Category: "Salary", Date: day(28)}
+	salaryCopy := models.Income{UserID: testUserID, Amount: types.Money(300000), Currency: "USD", Category: "Salary", Date: day(30), Note: "ACME PAYROLL", ExternalID: &bankID}
+	refund := models.Income{UserID: testUserID, Amount: types.Money(300000), Currency: "USD", Category: "Salary", Date: day(5)}
+	require.NoError(t, db.Create(&salary).Error)
+	require.NoError(t, db.Create(&salaryCopy).Error)
+	require.NoError(t, db.Create(&refund).Error)
+
+	flagged, err := service.ScanDuplicates(testUserID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, flagged, "Entries more than a few days apart are not duplicates")
+	again, err := service.ScanDuplicates(testUserID)
+	require.NoError(t, err)
+	assert.Zero(t, again, "Pairs are only flagged once")
+
+	pending, err := service.GetDuplicatePairs(testUserID, "pending", 0, 10)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, "category", pending[0].MatchedOn)
+
+	// Keeping the earlier entry moves the bank's ID onto it, so importing the statement again skips it.
+	_, err = service.MergeDuplicatePair(testUserID, pending[0].ID, &refund.ID)
+	assert.ErrorContains(t, err, "keep_id must be")
+	_, err = service.MergeDuplicatePair(testUserID, pending[0].ID, &salary.ID)
+	require.NoError(t, err)
+	var kept models.Income
+	require.NoError(t, db.First(&kept, salary.ID).Error)
+	require.NotNil(t, kept.ExternalID)
+	assert.Equal(t, bankID, *kept.ExternalID)
+	assert.Equal(t, "ACME PAYROLL", kept.Note)
+
+	// A dismissed pair stays dismissed.
+	require.NoError(t, db.Create(&models.Income{UserID: testUserID, Amount: types.Money(300000), Currency: "USD", Category: "Salary", Date: day(29)}).Error)
+	_, err = service.ScanDuplicates(testUserID)
+	require.NoError(t, err)
+	pending, err = service.GetDuplicatePairs(testUserID, "pending", 0, 10)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	dismissed, err := service.DismissDuplicatePair(testUserID, pending[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, "dismissed", dismissed.Status)
+	flagged, err = service.ScanDuplicates(testUserID)
+	require.NoError(t, err)
+	assert.Zero(t, flagged)
+}
@@ -4,10 +4,12 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
 	"github.com/zayyadi/finance-tracker/internal/database"
 	"github.com/zayyadi/finance-tracker/internal/models"
+	"github.com/zayyadi/finance-tracker/internal/types"
 	"gorm.io/gorm"
 )
 
@@ -30,109 +32,251 @@ func (s *ExpenseService) CreateExpense(expense *models.Expense) error {
 	if s.DB == nil {
 		return fmt.Errorf("database connection not initialized in ExpenseService")
 	}
-	result := s.DB.Create(expense)
-	if result.Error != nil {
-		// log.Printf("Error creating expense for user %d: %v", expense.UserID, result.Error) // UserID removed
-		log.Printf("Error creating expense: %v", result.Error)
-		return fmt.Errorf("could not create expense: %w", result.Error)
+	if expense.AccountID != nil && *expense.AccountID == 0 {
+		expense.AccountID = nil
+	}
+	currency, err := resolveAccountCurrency(s.DB, expense.UserID, expense.AccountID, expense.Currency)
+	if err != nil {
+		return fmt.Errorf("could not create expense: %w", err)
+	}
+	expense.Currency = currency
+	if expense.CategoryID != nil && *expense.CategoryID == 0 {
+		expense.CategoryID = nil
+	}
+	if expense.CategoryID == nil && strings.TrimSpace(expense.Category) == "" {
+		category, ruleID, err := categorizeByRules(s.DB, expense.UserID, "expense", expense.Amount, expense.Currency, expense.Note)
+		if err != nil {
+			return fmt.Errorf("could not create expense: %w", err)
+		}
+		expense.Category, expense.CategoryRuleID = category, ruleID
+	}
+	category, err := categoryFor(s.DB, expense.UserID, "expense", expense.CategoryID, expense.Category)
+	if err != nil {
+		return fmt.Errorf("could not create expense: %w", err)
+	}
+	expense.Category, expense.CategoryID = category.Name, &category.ID
+	tags, err := normalizeTags(expense.Tags)
+	if err != nil {
+		return fmt.Errorf("could not create expense: %w", err)
+	}
+	expense.Tags = tags
+	splits, err := prepareSplits(s.DB, expense.UserID, expense.Amount, expense.Splits)
+	if err != nil {
+		return fmt.Errorf("could not create expense: %w", err)
+	}
+	expense.Splits = splits
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(expense).Error; err != nil {
+			return err
+		}
+		if len(tags) > 0 {
+			if err := setTags(tx, expense.UserID, "expense", expense.ID, tags); err != nil {
+				return err
+			}
+		}
+		if len(splits) == 0 {
+			return nil
+		}
+		return saveSplits(tx, expense.ID, splits)
+	})
+	if err != nil {
+		log.Printf("Error creating expense for user %d: %v", expense.UserID, err)
+		return fmt.Errorf("could not create expense: %w", err)
 	}
+	expense.PossibleDuplicateOf = flagNewTransaction(s.DB, expense.UserID, "expense", duplicateCandidateOfExpense(expense))
 	return nil
 }
 
-// GetExpenseByID retrieves a specific expense record by its ID.
-func (s *ExpenseService) GetExpenseByID(expenseID uint) (*models.Expense, error) {
+// GetExpenseByID retrieves a specific expense record by its ID, scoped to the given user.
+func (s *ExpenseService) GetExpenseByID(userID uint, expenseID uint) (*models.Expense, error) {
 	if s.DB == nil {
 		return nil, fmt.Errorf("database connection not initialized in ExpenseService")
 	}
 	var expense models.Expense
-	result := s.DB.Where("id = ?", expenseID).First(&expense) // Removed userID condition
+	result := s.DB.Where("id = ? AND user_id = ?", expenseID, userID).First(&expense)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
-			return nil, fmt.Errorf("expense record not found") // Simplified error
+			return nil, fmt.Errorf("expense record not found")
 		}
-		log.Printf("Error retrieving expense %d: %v", expenseID, result.Error)
+		log.Printf("Error retrieving expense %d for user %d: %v", expenseID, userID, result.Error)
 		return nil, fmt.Errorf("could not retrieve expense: %w", result.Error)
 	}
-	return &expense, nil
+	expenses := []models.Expense{expense}
+	if err := s.attachDetails(expenses); err != nil {
+		return nil, err
+	}
+	return &expenses[0], nil
 }
 
-// GetExpenses retrieves expense records, optionally filtered by date range, with pagination.
-func (s *ExpenseService) GetExpenses(offset int, limit int, startDateStr, endDateStr string) ([]models.Expense, error) {
+// GetExpenses retrieves a page, counting from 1, of the expense records of a user matching filter,
+// newest first unless filter sorts them otherwise.
+func (s *ExpenseService) GetExpenses(userID uint, page int, limit int, filter models.ListFilter) (*models.Page[models.Expense], error) {
 	if s.DB == nil {
 		return nil, fmt.Errorf("database connection not initialized in ExpenseService")
 	}
-	var expenses []models.Expense
-	query := s.DB.Model(&models.Expense{})
-	var parsedStartDate, parsedEndDate time.Time // Declare here to be in scope for logging
-	var err error
-
-	if startDateStr != "" && endDateStr != "" {
-		parsedStartDate, err = time.Parse("2006-01-02", startDateStr)
-		if err != nil {
-			log.Printf("Error parsing start date '%s': %v", startDateStr, err)
-			return nil, fmt.Errorf("invalid start date format: %w", err)
-		}
-		parsedEndDate, err = time.Parse("2006-01-02", endDateStr)
-		if err != nil {
-			log.Printf("Error parsing end date '%s': %v", endDateStr, err)
-			return nil, fmt.Errorf("invalid end date format: %w", err)
-		}
-		query = query.Where("date BETWEEN ? AND ?", parsedStartDate.Format("2006-01-02"), parsedEndDate.Format("2006-01-02"))
+	query, err := applyListFilter(s.DB.Model(&models.Expense{}).Where("user_id = ?", userID), userID, expenseListColumns, filter)
+	if err != nil {
+		return nil, err
 	}
-
-	// Apply ordering and pagination to the original query object
-	result := query.Order("date desc, created_at desc").
-		Offset(offset).
-		Limit(limit).
-		Find(&expenses)
-
-	if result.Error != nil {
-		log.Printf("Error retrieving expenses: %v", result.Error)
-		return nil, fmt.Errorf("could not retrieve expenses: %w", result.Error)
+	expenses, err := paginate[models.Expense](query, page, limit)
+	if err != nil {
+		log.Printf("Error retrieving expenses for user %d: %v", userID, err)
+		return nil, fmt.Errorf("could not retrieve expenses: %w", err)
 	}
-	if expenses == nil {
-		return []models.Expense{}, nil // Return empty slice if no records found
+	if err := s.attachDetails(expenses.Items); err != nil {
+		return nil, err
 	}
 	return expenses, nil
 }
 
-// GetExpensesByDateRange retrieves all expense records within a specific date range.
-func (s *ExpenseService) GetExpensesByDateRange(startDate, endDate time.Time) ([]models.Expense, error) {
+// GetExpensesByDateRange retrieves all expense records for a user within a specific date range.
+func (s *ExpenseService) GetExpensesByDateRange(userID uint, startDate, endDate time.Time) ([]models.Expense, error) {
 	if s.DB == nil {
 		return nil, fmt.Errorf("database connection not initialized in ExpenseService")
 	}
 	var expenses []models.Expense
-	result := s.DB.Where("date BETWEEN ? AND ?", startDate, endDate). // Removed userID condition
-										Order("date desc, created_at desc").
-										Find(&expenses)
+	result := s.DB.Where("user_id = ? AND date BETWEEN ? AND ?", userID, startDate.Format("2006-01-02"), endDate.Format("2006-01-02")).
+		Order("date desc, created_at desc").
+		Find(&expenses)
 
 	if result.Error != nil {
-		log.Printf("Error retrieving expenses by date range: %v", result.Error)
+		log.Printf("Error retrieving expenses by date range for user %d: %v", userID, result.Error)
 		return nil, fmt.Errorf("could not retrieve expenses by date range: %w", result.Error)
 	}
 	if expenses == nil {
 		return []models.Expense{}, nil
 	}
+	if err := s.attachDetails(expenses); err != nil {
+		return nil, err
+	}
 	return expenses, nil
 }
 
-// UpdateExpense updates an existing expense record.
-func (s *ExpenseService) UpdateExpense(expenseID uint, updateData *models.ExpenseUpdateRequest) (*models.Expense, error) {
+// attachDetails fills in the tags and split lines on each of the expenses.
+func (s *ExpenseService) attachDetails(expenses []models.Expense) error {
+	ids := make([]uint, len(expenses))
+	for i := range expenses {
+		ids[i] = expenses[i].ID
+	}
+	tags, err := loadTags(s.DB, "expense", ids)
+	if err != nil {
+		return err
+	}
+	splits, err := loadSplits(s.DB, ids)
+	if err != nil {
+		return err
+	}
+	for i := range expenses {
+		expenses[i].Tags = tags[expenses[i].ID]
+		expenses[i].Splits = splits[expenses[i].ID]
+	}
+	return nil
+}
+
+// UpdateExpense updates an existing expense record owned by the given user.
+// When versions are given, the update is only made while the expense record was last changed at one
+// of them; otherwise a VersionConflictError is returned.
+func (s *ExpenseService) UpdateExpense(userID uint, expenseID uint, updateData *models.ExpenseUpdateRequest, versions ...time.Time) (*models.Expense, error) {
 	if s.DB == nil {
 		return nil, fmt.Errorf("database connection not initialized in ExpenseService")
 	}
 
-	existingExpense, err := s.GetExpenseByID(expenseID) // Removed userID
+	existingExpense, err := s.GetExpenseByID(userID, expenseID)
 	if err != nil {
 		return nil, err
 	}
+	if err := checkVersion(existingExpense.UpdatedAt, "expense record", versions); err != nil {
+		return nil, err
+	}
+
+	var tags []string
+	if updateData.Tags != nil {
+		if tags, err = normalizeTags(*updateData.Tags); err != nil {
+			return nil, fmt.Errorf("could not update expense: %w", err)
+		}
+	}
+	var splits []models.ExpenseSplit
+	if updateData.Splits != nil {
+		amount := existingExpense.Amount
+		if updateData.Amount != nil {
+			amount = *updateData.Amount
+		}
+		if splits, err = prepareSplits(s.DB, userID, amount, ExpenseSplitsFromRequests(*updateData.Splits)); err != nil {
+			return nil, fmt.Errorf("could not update expense: %w", err)
+		}
+	} else if updateData.Amount != nil && *updateData.Amount != existingExpense.Amount && len(existingExpense.Splits) > 0 {
+		return nil, fmt.Errorf("could not update expense: invalid splits: the expense is split, so a new amount needs new split lines")
+	}
 
 	updates := make(map[string]interface{})
 	if updateData.Amount != nil {
 		updates["amount"] = *updateData.Amount
 	}
-	if updateData.Category != nil {
-		updates["category"] = *updateData.Category
+	if updateData.Currency != nil {
+		updates["currency"] = *updateData.Currency
+	}
+	if updateData.AccountID != nil || updateData.Currency != nil {
+		// The record's currency must keep matching its account, whichever of the two changes.
+		accountID := existingExpense.AccountID
+		if updateData.AccountID != nil {
+			accountID = updateData.AccountID
+			if *accountID == 0 {
+				accountID = nil
+			}
+			updates["account_id"] = accountID
+		}
+		currency := existingExpense.Currency
+		if updateData.Currency != nil {
+			currency = *updateData.Currency
+		}
+		if accountID != nil {
+			if _, err := resolveAccountCurrency(s.DB, userID, accountID, currency); err != nil {
+				return nil, err
+			}
+		}
+	}
+	categoryCleared := (updateData.Category != nil && strings.TrimSpace(*updateData.Category) == "") || (updateData.CategoryID != nil && *updateData.CategoryID == 0)
+	if (updateData.Category != nil || updateData.CategoryID != nil) && !categoryCleared {
+		var name string
+		if updateData.Category != nil {
+			name = *updateData.Category
+		}
+		category, err := categoryFor(s.DB, userID, "expense", updateData.CategoryID, name)
+		if err != nil {
+			return nil, fmt.Errorf("could not update expense: %w", err)
+		}
+		updates["category"] = category.Name
+		updates["category_id"] = category.ID
+		updates["category_rule_id"] = nil // Chosen by hand, so rules leave it alone from now on
+	} else if categoryCleared || updateData.Amount != nil || updateData.Currency != nil || updateData.Note != nil {
+		// A cleared category, or one a rule chose from what is changing, is chosen by the rules again.
+		entry := categorizedEntry{Amount: existingExpense.Amount, Currency: existingExpense.Currency, Category: existingExpense.Category, CategoryRuleID: existingExpense.CategoryRuleID, Note: existingExpense.Note}
+		if updateData.Amount != nil {
+			entry.Amount = *updateData.Amount
+		}
+		if updateData.Currency != nil {
+			entry.Currency = *updateData.Currency
+		}
+		if updateData.Note != nil {
+			entry.Note = *updateData.Note
+		}
+		if categoryCleared {
+			fallback, err := resolveCategory(s.DB, userID, "expense", defaultCategory)
+			if err != nil {
+				return nil, fmt.Errorf("could not update expense: %w", err)
+			}
+			entry.Category, entry.CategoryRuleID = fallback.Name, nil
+			updates["category"] = fallback.Name
+			updates["category_id"] = fallback.ID
+			updates["category_rule_id"] = nil
+		}
+		recategorized, err := recategorizeOnUpdate(s.DB, userID, "expense", entry)
+		if err != nil {
+			return nil, fmt.Errorf("could not update expense: %w", err)
+		}
+		for column, value := range recategorized {
+			updates[column] = value
+		}
 	}
 	if updateData.Date != nil {
 		updates["date"] = *updateData.Date
@@ -142,32 +286,210 @@ func (s *ExpenseService) UpdateExpense(expenseID uint, updateData *models.Expens
 	}
 
 	if len(updates) == 0 {
-		return existingExpense, nil
+		if updateData.Tags == nil && updateData.Splits == nil {
+			return existingExpense, nil
+		}
+		// Only the tags or split lines changed; the record is still touched so its ETag changes with them.
+		updates["updated_at"] = time.Now()
 	}
 
-	result := s.DB.Model(&existingExpense).Where("id = ?", expenseID).Updates(updates) // Removed userID condition
-	if result.Error != nil {
-		log.Printf("Error updating expense %d: %v", expenseID, result.Error)
-		return nil, fmt.Errorf("could not update expense: %w", result.Error)
+	// The tags, the split lines and the record are written together, so they keep matching when any of
+	// the writes fails.
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		if updateData.Tags != nil {
+			if err := setTags(tx, userID, "expense", expenseID, tags); err != nil {
+				log.Printf("Error tagging expense %d: %v", expenseID, err)
+				return err
+			}
+		}
+		if updateData.Splits != nil {
+			if err := saveSplits(tx, expenseID, splits); err != nil {
+				log.Printf("Error splitting expense %d: %v", expenseID, err)
+				return err
+			}
+		}
+		result := whereVersion(tx.Model(&existingExpense).Where("id = ? AND user_id = ?", expenseID, userID), existingExpense.UpdatedAt, versions).Updates(updates)
+		if result.Error != nil {
+			log.Printf("Error updating expense %d: %v", expenseID, result.Error)
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			if len(versions) > 0 {
+				return versionConflict(tx, &models.Expense{}, "expense record", userID, expenseID)
+			}
+			return fmt.Errorf("expense record not found during update (or no changes made)")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not update expense: %w", err)
 	}
-	if result.RowsAffected == 0 {
-		return nil, fmt.Errorf("expense record not found during update (or no changes made)")
+	if updateData.Tags != nil {
+		existingExpense.Tags = tags
+	}
+	if updateData.Splits != nil {
+		existingExpense.Splits = splits
 	}
 	return existingExpense, nil
 }
 
-// DeleteExpense deletes an expense record.
-func (s *ExpenseService) DeleteExpense(expenseID uint) error {
+// DeleteExpense deletes an expense record owned by the given user.
+// When versions are given, it is only deleted while it was last changed at one of them; otherwise a
+// VersionConflictError is returned.
+func (s *ExpenseService) DeleteExpense(userID uint, expenseID uint, versions ...time.Time) error {
 	if s.DB == nil {
 		return fmt.Errorf("database connection not initialized in ExpenseService")
 	}
-	result := s.DB.Where("id = ?", expenseID).Delete(&models.Expense{}) // Removed userID condition
+	query := s.DB.Where("id = ? AND user_id = ?", expenseID, userID)
+	if len(versions) > 0 {
+		updatedAt, err := recordVersion(s.DB, &models.Expense{}, "expense record", userID, expenseID)
+		if err != nil {
+			return err
+		}
+		if err := checkVersion(updatedAt, "expense record", versions); err != nil {
+			return err
+		}
+		query = whereVersion(query, updatedAt, versions)
+	}
+	result := query.Delete(&models.Expense{})
 	if result.Error != nil {
 		log.Printf("Error deleting expense %d: %v", expenseID, result.Error)
 		return fmt.Errorf("could not delete expense: %w", result.Error)
 	}
 	if result.RowsAffected == 0 {
+		if len(versions) > 0 {
+			return versionConflict(s.DB, &models.Expense{}, "expense record", userID, expenseID)
+		}
 		return fmt.Errorf("expense record not found, no rows deleted")
 	}
 	return nil
 }
+
+// ExpenseSplitsFromRequests converts the split lines of a create or update request.
+func ExpenseSplitsFromRequests(requests []models.ExpenseSplitRequest) []models.ExpenseSplit {
+	splits := make([]models.ExpenseSplit, len(requests))
+	for i, request := range requests {
+		splits[i] = models.ExpenseSplit{Amount: request.Amount, Category: request.Category, CategoryID: request.CategoryID, Note: request.Note}
+	}
+	return splits
+}
+
+// prepareSplits checks that the split lines of an expense add up to its amount and resolves their
+// categories. An empty list means the expense is not split.
+func prepareSplits(db *gorm.DB, userID uint, amount types.Money, lines []models.ExpenseSplit) ([]models.ExpenseSplit, error) {
+	if len(lines) == 0 {
+		return nil, nil
+	}
+	if len(lines) < 2 {
+		return nil, fmt.Errorf("invalid splits: an expense is split into at least two lines")
+	}
+	var total types.Money
+	for i, line := range lines {
+		if line.Amount <= 0 {
+			return nil, fmt.Errorf("invalid splits: line %d must have a positive amount", i+1)
+		}
+		total += line.Amount
+	}
+	if total != amount {
+		return nil, fmt.Errorf("invalid splits: the lines add up to %s, not the expense amount %s", total, amount)
+	}
+
+	splits := make([]models.ExpenseSplit, len(lines))
+	for i, line := range lines {
+		if line.CategoryID != nil && *line.CategoryID == 0 {
+			line.CategoryID = nil
+		}
+		category, err := categoryFor(db, userID, "expense", line.CategoryID, line.Category)
+		if err != nil {
+			return nil, err
+		}
+		splits[i] = models.ExpenseSplit{Amount: line.Amount, Category: category.Name, CategoryID: &category.ID, Note: line.Note}
+	}
+	return splits, nil
+}
+
+// saveSplits replaces the split lines of an expense. splits must have been prepared with prepareSplits.
+func saveSplits(db *gorm.DB, expenseID uint, splits []models.ExpenseSplit) error {
+	if err := db.Where("expense_id = ?", expenseID).Delete(&models.ExpenseSplit{}).Error; err != nil {
+		return fmt.Errorf("could not clear split lines: %w", err)
+	}
+	if len(splits) == 0 {
+		return nil
+	}
+	for i := range splits {
+		splits[i].ExpenseID = expenseID
+	}
+	if err := db.Create(&splits).Error; err != nil {
+		return fmt.Errorf("could not save split lines: %w", err)
+	}
+	return nil
+}
+
+// loadSplits returns the split lines of each of the given expenses, in the order they were given.
+func loadSplits(db *gorm.DB, expenseIDs []uint) (map[uint][]models.ExpenseSplit, error) {
+	splits := make(map[uint][]models.ExpenseSplit, len(expenseIDs))
+	if len(expenseIDs) == 0 {
+		return splits, nil
+	}
+	var lines []models.ExpenseSplit
+	if err := db.Where("expense_id IN ?", expenseIDs).Order("id").Find(&lines).Error; err != nil {
+		return nil, fmt.Errorf("could not load split lines: %w", err)
+	}
+	for _, line := range lines {
+		splits[line.ExpenseID] = append(splits[line.ExpenseID], line)
+	}
+	return splits, nil
+}
+
+// BulkCreateExpenses creates the expenses in one transaction: if any of them cannot be created, none is.
+func (s *ExpenseService) BulkCreateExpenses(expenses []models.Expense) (*models.BulkResult[models.Expense], error) {
+	if s.DB == nil {
+		return nil, fmt.Errorf("database connection not initialized in ExpenseService")
+	}
+	return runBulk(s.DB, models.BulkStatusCreated, nil, len(expenses), func(tx *gorm.DB, i int) (*models.Expense, []time.Time, error) {
+		if err := (&ExpenseService{DB: tx}).CreateExpense(&expenses[i]); err != nil {
+			return nil, nil, err
+		}
+		return &expenses[i], []time.Time{expenses[i].Date.Time}, nil
+	})
+}
+
+// BulkUpdateExpenses updates expense records owned by the given user in one transaction: if any of them
+// cannot be updated, none is.
+func (s *ExpenseService) BulkUpdateExpenses(userID uint, items []models.ExpenseBulkUpdateItem) (*models.BulkResult[models.Expense], error) {
+	if s.DB == nil {
+		return nil, fmt.Errorf("database connection not initialized in ExpenseService")
+	}
+	ids := bulkUpdateIDs(items, func(item models.ExpenseBulkUpdateItem) uint { return item.ID })
+	return runBulk(s.DB, models.BulkStatusUpdated, ids, len(items), func(tx *gorm.DB, i int) (*models.Expense, []time.Time, error) {
+		service := &ExpenseService{DB: tx}
+		existing, err := service.GetExpenseByID(userID, items[i].ID)
+		if err != nil {
+			return nil, nil, err
+		}
+		updated, err := service.UpdateExpense(userID, items[i].ID, &items[i].ExpenseUpdateRequest)
+		if err != nil {
+			return nil, nil, err
+		}
+		return updated, []time.Time{existing.Date.Time, updated.Date.Time}, nil
+	})
+}
+
+// BulkDeleteExpenses deletes expense records owned by the given user in one transaction: if any of them
+// cannot be deleted, none is.
+func (s *ExpenseService) BulkDeleteExpenses(userID uint, expenseIDs []uint) (*models.BulkResult[models.Expense], error) {
+	if s.DB == nil {
+		return nil, fmt.Errorf("database connection not initialized in ExpenseService")
+	}
+	return runBulk(s.DB, models.BulkStatusDeleted, expenseIDs, len(expenseIDs), func(tx *gorm.DB, i int) (*models.Expense, []time.Time, error) {
+		service := &ExpenseService{DB: tx}
+		existing, err := service.GetExpenseByID(userID, expenseIDs[i])
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := service.DeleteExpense(userID, expenseIDs[i]); err != nil {
+			return nil, nil, err
+		}
+		return nil, []time.Time{existing.Date.Time}, nil
+	})
+}
@@ -1,13 +1,16 @@
 package services
 
 import (
+	"errors"
 	"fmt"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/zayyadi/finance-tracker/internal/database"
 	"github.com/zayyadi/finance-tracker/internal/models"
+	"github.com/zayyadi/finance-tracker/internal/types"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger" // Added for GORM logging
@@ -26,20 +29,21 @@ func setupExpenseTestDB(t *testing.T) *gorm.DB {
 	db.Exec("DROP TABLE IF EXISTS Users") // In case of implicit dependencies
 
 	// Auto-migrate schemas based on GORM structs.
-	// Since UserID is not an active field in models.Expense, the created table will not have user_id.
-	err = db.AutoMigrate(&models.User{}, &models.Expense{})
+	err = db.AutoMigrate(&models.User{}, &models.Expense{}, &models.Category{}, &models.Tag{}, &models.Tagging{}, &models.ExpenseSplit{})
 	assert.NoError(t, err, "Failed to auto-migrate models")
 
 	// Optional: Create a dummy user if needed for any other service interactions not directly tested.
 	// db.Create(&models.User{Username: "testuser", Email: "test@example.com", PasswordHash: "hash"})
 
-
 	return db
 }
 
 // seedExpensesForTest populates the database with expense data for testing GetExpenses.
 func seedExpensesForTest(t *testing.T, db *gorm.DB, expenses []models.Expense) {
 	for _, expense := range expenses {
+		if expense.UserID == 0 {
+			expense.UserID = testUserID
+		}
 		err := db.Create(&expense).Error
 		assert.NoError(t, err, "Failed to seed expense: %+v", expense)
 	}
@@ -51,10 +55,10 @@ func TestGetExpenses_DateRange_NoData(t *testing.T) {
 
 	startDate := "2023-01-01"
 	endDate := "2023-01-31"
-	expenses, err := service.GetExpenses(0, 10, startDate, endDate)
+	expenses, err := service.GetExpenses(testUserID, 1, 10, models.ListFilter{StartDate: startDate, EndDate: endDate})
 
 	assert.NoError(t, err)
-	assert.Empty(t, expenses)
+	assert.Empty(t, expenses.Items)
 }
 
 func TestGetExpenses_DateRange_WithData_InRange(t *testing.T) {
@@ -70,12 +74,12 @@ func TestGetExpenses_DateRange_WithData_InRange(t *testing.T) {
 
 	startDate := "2023-01-01"
 	endDate := "2023-01-31"
-	expenses, err := service.GetExpenses(0, 10, startDate, endDate)
+	expenses, err := service.GetExpenses(testUserID, 1, 10, models.ListFilter{StartDate: startDate, EndDate: endDate})
 
 	assert.NoError(t, err)
-	assert.Len(t, expenses, 2)
-	assert.Equal(t, float64(50), expenses[0].Amount)    // Sorted by date desc
-	assert.Equal(t, float64(100), expenses[1].Amount)
+	assert.Len(t, expenses.Items, 2)
+	assert.Equal(t, types.Money(50), expenses.Items[0].Amount) // Sorted by date desc
+	assert.Equal(t, types.Money(100), expenses.Items[1].Amount)
 }
 
 func TestGetExpenses_DateRange_WithData_OutsideRange(t *testing.T) {
@@ -89,10 +93,10 @@ func TestGetExpenses_DateRange_WithData_OutsideRange(t *testing.T) {
 
 	startDate := "2023-01-01"
 	endDate := "2023-01-31"
-	expenses, err := service.GetExpenses(0, 10, startDate, endDate)
+	expenses, err := service.GetExpenses(testUserID, 1, 10, models.ListFilter{StartDate: startDate, EndDate: endDate})
 
 	assert.NoError(t, err)
-	assert.Empty(t, expenses)
+	assert.Empty(t, expenses.Items)
 }
 
 func TestGetExpenses_NoDateRange(t *testing.T) {
@@ -105,21 +109,21 @@ func TestGetExpenses_NoDateRange(t *testing.T) {
 	}
 	seedExpensesForTest(t, db, expensesToSeed)
 
-	expenses, err := service.GetExpenses(0, 10, "", "") // No date range
+	expenses, err := service.GetExpenses(testUserID, 1, 10, models.ListFilter{}) // No date range
 
 	assert.NoError(t, err)
-	assert.Len(t, expenses, 2) // Should return all, paginated
+	assert.Len(t, expenses.Items, 2) // Should return all, paginated
 }
 
 func TestGetExpenses_InvalidDateStrings(t *testing.T) {
 	db := setupExpenseTestDB(t)
 	service := NewExpenseService(db)
 
-	_, err := service.GetExpenses(0, 10, "not-a-date", "2023-01-31")
+	_, err := service.GetExpenses(testUserID, 1, 10, models.ListFilter{StartDate: "not-a-date", EndDate: "2023-01-31"})
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "invalid start date format")
 
-	_, err = service.GetExpenses(0, 10, "2023-01-01", "also-not-a-date")
+	_, err = service.GetExpenses(testUserID, 1, 10, models.ListFilter{StartDate: "2023-01-01", EndDate: "also-not-a-date"})
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "invalid end date format")
 }
@@ -141,23 +145,196 @@ func TestGetExpenses_PaginationWithDateRange(t *testing.T) {
 	endDate := "2023-03-31"
 
 	// Get first page
-	expensesPage1, err1 := service.GetExpenses(0, 2, startDate, endDate)
+	expensesPage1, err1 := service.GetExpenses(testUserID, 1, 2, models.ListFilter{StartDate: startDate, EndDate: endDate})
 	assert.NoError(t, err1)
-	assert.Len(t, expensesPage1, 2)
-	assert.Equal(t, float64(40), expensesPage1[0].Amount) // March 4 (latest due to Order("date desc"))
-	assert.Equal(t, float64(30), expensesPage1[1].Amount) // March 3
+	assert.Len(t, expensesPage1.Items, 2)
+	assert.Equal(t, types.Money(40), expensesPage1.Items[0].Amount) // March 4 (latest due to Order("date desc"))
+	assert.Equal(t, types.Money(30), expensesPage1.Items[1].Amount) // March 3
+	assert.Equal(t, int64(4), expensesPage1.Total)
+	assert.True(t, expensesPage1.HasMore)
 
 	// Get second page
 	// Enable GORM logging for this specific call
 	originalLogger := db.Logger
 	db.Logger = db.Logger.LogMode(logger.Info)
 
-	expensesPage2, err2 := service.GetExpenses(2, 2, startDate, endDate)
+	expensesPage2, err2 := service.GetExpenses(testUserID, 2, 2, models.ListFilter{StartDate: startDate, EndDate: endDate})
 
 	db.Logger = originalLogger // Restore original logger
 
 	assert.NoError(t, err2)
-	assert.Len(t, expensesPage2, 2)
-	assert.Equal(t, float64(20), expensesPage2[0].Amount) // March 2
-	assert.Equal(t, float64(10), expensesPage2[1].Amount) // March 1
+	assert.Len(t, expensesPage2.Items, 2)
+	assert.Equal(t, types.Money(20), expensesPage2.Items[0].Amount) // March 2
+	assert.Equal(t, types.Money(10), expensesPage2.Items[1].Amount) // March 1
+	assert.False(t, expensesPage2.HasMore)
+}
+
+func TestExpenseService_ScopedToUser(t *testing.T) {
+	db := setupExpenseTestDB(t)
+	service := NewExpenseService(db)
+	otherUserID := testUserID + 1
+
+	seedExpensesForTest(t, db, []models.Expense{
+		{Amount: 10, Category: "Mine", Date: database.CustomDate{Time: time.Date(2023, time.March, 1, 0, 0, 0, 0, time.UTC)}},
+		{UserID: otherUserID, Amount: 99, Category: "Theirs", Date: database.CustomDate{Time: time.Date(2023, time.March, 2, 0, 0, 0, 0, time.UTC)}},
+	})
+
+	expenses, err := service.GetExpenses(testUserID, 1, 10, models.ListFilter{})
+	assert.NoError(t, err)
+	assert.Len(t, expenses.Items, 1)
+	assert.Equal(t, "Mine", expenses.Items[0].Category)
+
+	var theirs models.Expense
+	db.Where("user_id = ?", otherUserID).First(&theirs)
+
+	_, err = service.GetExpenseByID(testUserID, theirs.ID)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "expense record not found")
+
+	note := "hijacked"
+	_, err = service.UpdateExpense(testUserID, theirs.ID, &models.ExpenseUpdateRequest{Note: &note})
+	assert.Error(t, err)
+
+	err = service.DeleteExpense(testUserID, theirs.ID)
+	assert.Error(t, err)
+
+	var count int64
+	db.Model(&models.Expense{}).Where("user_id = ?", otherUserID).Count(&count)
+	assert.Equal(t, int64(1), count, "Other user's expense must not be deleted")
+}
+
+func TestExpenseService_SplitLines(t *testing.T) {
+	db := setupAccountTestDB(t)
+	require.NoError(t, db.AutoMigrate(&models.Budget{}))
+	service := NewExpenseService(db)
+	day := database.CustomDate{Time: time.Date(2024, time.June, 3, 0, 0, 0, 0, time.UTC)}
+
+	// The lines must add up to the expense and there must be at least two.
+	err := service.CreateExpense(&models.Expense{UserID: testUserID, Amount: types.Money(10000), Category: "Shopping", Date: day,
+		Splits: []models.ExpenseSplit{{Amount: types.Money(6000), Category: "Groceries"}, {Amount: types.Money(3000), Category: "Household"}}})
+	assert.ErrorContains(t, err, "invalid splits: the lines add up to 90.00, not the expense amount 100.00")
+	err = service.CreateExpense(&models.Expense{UserID: testUserID, Amount: types.Money(10000), Category: "Shopping", Date: day,
+		Splits: []models.ExpenseSplit{{Amount: types.Money(10000), Category: "Groceries"}}})
+	assert.ErrorContains(t, err, "at least two lines")
+
+	receipt := &models.Expense{UserID: testUserID, Amount: types.Money(10000), Category: "Shopping", Date: day,
+		Splits: []models.ExpenseSplit{{Amount: types.Money(6000), Category: "groceries"}, {Amount: types.Money(2500), Category: "Household"}, {Amount: types.Money(1500), Category: "Alcohol", Note: "wine"}}}
+	require.NoError(t, service.CreateExpense(receipt))
+	require.NoError(t, service.CreateExpense(&models.Expense{UserID: testUserID, Amount: types.Money(4000), Category: "Groceries", Date: day}))
+	reloaded, err := service.GetExpenseByID(testUserID, receipt.ID)
+	require.NoError(t, err)
+	require.Len(t, reloaded.Splits, 3)
+	assert.Equal(t, "groceries", reloaded.Splits[0].Category, "The first spelling creates the category")
+	assert.Equal(t, "wine", reloaded.Splits[2].Note)
+	require.NotNil(t, reloaded.Splits[1].CategoryID)
+
+	// Category totals count the lines, not the expense's own category.
+	stats, err := NewAnalyticsService(db).GetExpenseBreakdownByCategory(testUserID, day.Time, false)
+	require.NoError(t, err)
+	require.Len(t, stats, 3)
+	assert.Equal(t, "groceries", stats[0].Category)
+	assert.Equal(t, types.Money(10000), stats[0].TotalAmount)
+	assert.Equal(t, "Household", stats[1].Category)
+	assert.Equal(t, "Alcohol", stats[2].Category)
+	summary, err := NewSummaryService(db).GetOrCreateFinancialSummary(testUserID, "monthly", day.Time, "overall")
+	require.NoError(t, err)
+	assert.Equal(t, types.Money(14000), summary.TotalExpenses)
+	assert.Equal(t, map[string]types.Money{"groceries": 10000, "Household": 2500, "Alcohol": 1500}, summary.ExpensesByCategory)
+	budgetService := NewBudgetService(db)
+	require.NoError(t, budgetService.CreateBudget(&models.Budget{UserID: testUserID, Category: "Household", Period: "monthly", Amount: types.Money(5000)}))
+	status, err := budgetService.GetBudgetStatus(testUserID, "monthly", day.Time)
+	require.NoError(t, err)
+	require.Len(t, status.Budgets, 1)
+	assert.Equal(t, types.Money(2500), status.Budgets[0].Spent)
+
+	// A split expense keeps its amount unless new lines come with the new amount.
+	newAmount := types.Money(12000)
+	_, err = service.UpdateExpense(testUserID, receipt.ID, &models.ExpenseUpdateRequest{Amount: &newAmount})
+	assert.ErrorContains(t, err, "a new amount needs new split lines")
+	updated, err := service.UpdateExpense(testUserID, receipt.ID, &models.ExpenseUpdateRequest{Amount: &newAmount, Splits: &[]models.ExpenseSplitRequest{
+		{Amount: types.Money(8000), Category: "Groceries"}, {Amount: types.Money(4000), Category: "Household"},
+	}})
+	require.NoError(t, err)
+	assert.Len(t, updated.Splits, 2)
+	assert.ErrorContains(t, NewCategoryService(db).DeleteCategory(testUserID, *updated.Splits[1].CategoryID), "still used by 1 split line(s)")
+
+	// An empty list un-splits the expense.
+	updated, err = service.UpdateExpense(testUserID, receipt.ID, &models.ExpenseUpdateRequest{Splits: &[]models.ExpenseSplitRequest{}})
+	require.NoError(t, err)
+	assert.Empty(t, updated.Splits)
+	stats, err = NewAnalyticsService(db).GetExpenseBreakdownByCategory(testUserID, day.Time, false)
+	require.NoError(t, err)
+	require.Len(t, stats, 2)
+	assert.Equal(t, "Shopping", stats[0].Category)
+	assert.Equal(t, types.Money(12000), stats[0].TotalAmount)
+}
+
+func TestExpenseService_FailedUpdateKeepsTagsAndSplits(t *testing.T) {
+	db := setupAccountTestDB(t)
+	service := NewExpenseService(db)
+	day := database.CustomDate{Time: time.Date(2024, time.June, 3, 0, 0, 0, 0, time.UTC)}
+	receipt := &models.Expense{UserID: testUserID, Amount: types.Money(10000), Category: "Shopping", Date: day, Tags: []string{"old"},
+		Splits: []models.ExpenseSplit{{Amount: types.Money(6000), Category: "Groceries"}, {Amount: types.Money(4000), Category: "Household"}}}
+	require.NoError(t, service.CreateExpense(receipt))
+
+	// Every update of an expense fails from here on.
+	require.NoError(t, db.Callback().Update().Before("gorm:update").Register("test:fail_updates", func(tx *gorm.DB) {
+		if tx.Statement.Table == "expenses" {
+			_ = tx.AddError(errors.New("disk full"))
+		}
+	}))
+	newAmount := types.Money(12000)
+	_, err := service.UpdateExpense(testUserID, receipt.ID, &models.ExpenseUpdateRequest{Amount: &newAmount, Tags: &[]string{"new"}, Splits: &[]models.ExpenseSplitRequest{
+		{Amount: types.Money(8000), Category: "Groceries"}, {Amount: types.Money(4000), Category: "Household"},
+	}})
+	assert.ErrorContains(t, err, "disk full")
+
+	reloaded, err := service.GetExpenseByID(testUserID, receipt.ID)
+	require.NoError(t, err)
+	assert.Equal(t, types.Money(10000), reloaded.Amount)
+	assert.Equal(t, []string{"old"}, reloaded.Tags, "A failed update leaves the tags alone")
+	require.Len(t, reloaded.Splits, 2, "A failed update leaves the split lines alone")
+	assert.Equal(t, types.Money(6000), reloaded.Splits[0].Amount)
+}
+
+func TestExpenseService_VersionedChangesLoseToConcurrentWrites(t *testing.T) {
+	db := setupAccountTestDB(t)
+	service := NewExpenseService(db)
+	day := database.CustomDate{Time: time.Date(2024, time.June, 3, 0, 0, 0, 0, time.UTC)}
+	lunch := &models.Expense{UserID: testUserID, Amount: types.Money(1500), Category: "Food", Date: day, Note: "lunch", Tags: []string{"old"}}
+	require.NoError(t, service.CreateExpense(lunch))
+	loaded, err := service.GetExpenseByID(testUserID, lunch.ID)
+	require.NoError(t, err)
+	version := loaded.UpdatedAt
+
+	// When armed, another request saves the expense right after the service has loaded it.
+	armed := false
+	require.NoError(t, db.Callback().Query().After("gorm:query").Register("test:concurrent_write", func(tx *gorm.DB) {
+		if armed && tx.Statement.Table == "expenses" {
+			armed = false
+			require.NoError(t, db.Exec("UPDATE expenses SET note = ?, updated_at = ? WHERE id = ?", "theirs", time.Now().Add(time.Second), lunch.ID).Error)
+		}
+	}))
+
+	armed = true
+	note := "ours"
+	_, err = service.UpdateExpense(testUserID, lunch.ID, &models.ExpenseUpdateRequest{Note: &note, Tags: &[]string{"new"}}, version)
+	var conflict *VersionConflictError
+	require.ErrorAs(t, err, &conflict)
+	reloaded, err := service.GetExpenseByID(testUserID, lunch.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "theirs", reloaded.Note, "The change made first is kept")
+	assert.Equal(t, []string{"old"}, reloaded.Tags)
+	assert.Equal(t, reloaded.UpdatedAt.UnixMicro(), conflict.UpdatedAt.UnixMicro(), "The conflict carries the current version")
+
+	_, err = service.UpdateExpense(testUserID, lunch.ID, &models.ExpenseUpdateRequest{Note: &note}, version)
+	assert.ErrorAs(t, err, &conflict, "A stale version is refused")
+
+	armed = true
+	assert.ErrorAs(t, service.DeleteExpense(testUserID, lunch.ID, reloaded.UpdatedAt), &conflict)
+	current, err := service.GetExpenseByID(testUserID, lunch.ID)
+	require.NoError(t, err, "A delete that loses the race deletes nothing")
+
+	require.NoError(t, service.DeleteExpense(testUserID, lunch.ID, version, current.UpdatedAt), "Any of the versions may match")
+	assert.ErrorContains(t, service.DeleteExpense(testUserID, lunch.ID, current.UpdatedAt), "expense record not found")
 }
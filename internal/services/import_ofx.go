@@ -0,0 +1,304 @@
+package services
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/zayyadi/finance-tracker/internal/models"
+	"github.com/zayyadi/finance-tracker/internal/types"
+)
+
+// ofxFieldTags are the transaction fields that hold a value rather than other elements.
+var ofxFieldTags = map[string]bool{
+	"TRNTYPE": true, "DTPOSTED": true, "DTUSER": true, "DTAVAIL": true, "TRNAMT": true, "FITID": true,
+	"CORRECTFITID": true, "CORRECTACTION": true, "SRVRTID": true, "CHECKNUM": true, "REFNUM": true,
+	"SIC": true, "PAYEEID": true, "NAME": true, "EXTDNAME": true, "MEMO": true, "INV401KSOURCE": true,
+}
+
+// ofxStatement is what an OFX file says about one account: its transactions and balance.
+type ofxStatement struct {
+	accountID     string
+	currency      string
+	startDate     time.Time
+	endDate       time.Time
+	ledgerBalance *types.Money
+	ledgerDate    time.Time
+}
+
+// ImportOFX imports an OFX or QFX bank statement, in either the SGML (1.x) or XML (2.x) flavour.
+// Each transaction's FITID is kept as its external ID, so importing an overlapping statement again
+// skips the transactions already imported. The result also carries the statement's ledger balance
+// and, when accountID is given, our own balance of that account on the same date for comparison.
+func (s *ImportService) ImportOFX(userID uint, r io.Reader, accountID *uint, dryRun bool) (*models.ImportResult, error) {
+	if s.DB == nil {
+		return nil, fmt.Errorf("database connection not initialized in ImportService")
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("invalid OFX import: could not read file: %w", err)
+	}
+	statement, rows, rowErrors, err := parseOFX(string(data))
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.saveImport(userID, importOptions{accountID: accountID, currency: statement.currency}, rows, rowErrors, dryRun)
+	if err != nil {
+		return nil, err
+	}
+	result.Statement = &models.StatementBalance{
+		AccountNumber: maskAccountNumber(statement.accountID),
+		Currency:      statement.currency,
+		LedgerBalance: statement.ledgerBalance,
+	}
+	if !statement.startDate.IsZero() {
+		result.Statement.StartDate = statement.startDate.Format("2006-01-02")
+	}
+	if !statement.endDate.IsZero() {
+		result.Statement.EndDate = statement.endDate.Format("2006-01-02")
+	}
+	if statement.ledgerBalance != nil && !statement.ledgerDate.IsZero() {
+		result.Statement.LedgerBalanceDate = statement.ledgerDate.Format("2006-01-02")
+		if accountID != nil && *accountID != 0 {
+			if err := s.compareLedgerBalance(userID, *accountID, statement, result); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return result, nil
+}
+
+// compareLedgerBalance fills in our balance of the account on the statement's ledger balance date.
+// On a dry run the rows that would be imported are included, so the preview shows the balance the
+// import would lead to.
+func (s *ImportService) compareLedgerBalance(userID uint, accountID uint, statement *ofxStatement, result *models.ImportResult) error {
+	accountService := NewAccountService(s.DB)
+	account, err := accountService.GetAccountByID(userID, accountID)
+	if err != nil {
+		return err
+	}
+	balance, err := accountService.accountBalanceOn(userID, account, statement.ledgerDate)
+	if err != nil {
+		return err
+	}
+	if result.DryRun {
+		asOf := statement.ledgerDate.Format("2006-01-02")
+		for _, txn := range result.Transactions {
+			if txn.Date > asOf {
+				continue
+			}
+			if txn.Type == "income" {
+				balance += txn.Amount
+			} else {
+				balance -= txn.Amount
+			}
+		}
+	}
+	difference := *statement.ledgerBalance - balance
+	result.Statement.AccountBalance = &balance
+	result.Statement.Difference = &difference
+	return nil
+}
+
+// parseOFX reads the statement transactions and balance from an OFX document. SGML OFX leaves
+// elements such as <TRNAMT>-12.00 unclosed while XML OFX closes them, so the tokenizer treats any
+// tag followed by text as a field and only tracks nesting for tags that contain other tags.
+func parseOFX(data string) (*ofxStatement, []statementRow, []models.ImportRowError, error) {
+	start := strings.Index(strings.ToUpper(data), "<OFX>")
+	if start < 0 {
+		return nil, nil, nil, fmt.Errorf("invalid OFX import: no <OFX> element found")
+	}
+	line := 1 + strings.Count(data[:start], "\n")
+	data = data[start:]
+
+	statement := &ofxStatement{}
+	statements := 0
+	var rows []statementRow
+	var rowErrors []models.ImportRowError
+	var stack []string
+	var txn map[string]string
+	txnLine := 0
+
+	for pos := 0; pos < len(data); {
+		open := strings.IndexByte(data[pos:], '<')
+		if open < 0 {
+			break
+		}
+		line += strings.Count(data[pos:pos+open], "\n")
+		pos += open
+		end := strings.IndexByte(data[pos:], '>')
+		if end < 0 {
+			return nil, nil, nil, fmt.Errorf("invalid OFX import: unterminated tag on line %d", line)
+		}
+		tag := strings.ToUpper(strings.TrimSpace(data[pos+1 : pos+end]))
+		tagLine := line
+		pos += end + 1
+
+		if tag == "" || tag[0] == '?' || tag[0] == '!' {
+			continue
+		}
+		if tag[0] == '/' {
+			name := tag[1:]
+			if name == "STMTTRN" && txn != nil {
+				row, err := ofxTransactionRow(txn, statement.accountID)
+				if err != nil {
+					rowErrors = append(rowErrors, models.ImportRowError{Line: txnLine, Error: err.Error()})
+				} else {
+					row.line = txnLine
+					rows = append(rows, row)
+				}
+				txn = nil
+			}
+			// Pop back to the matching aggregate; closing tags of SGML fields have none.
+			for i := len(stack) - 1; i >= 0; i-- {
+				if stack[i] == name {
+					stack = stack[:i]
+					break
+				}
+			}
+			continue
+		}
+
+		next := strings.IndexByte(data[pos:], '<')
+		if next < 0 {
+			next = len(data) - pos
+		}
+		value := strings.TrimSpace(data[pos : pos+next])
+		if value == "" && ofxFieldTags[tag] {
+			continue // An empty field, which some banks write as a bare <MEMO> in SGML files
+		}
+		if value == "" {
+			// An aggregate such as <STMTTRN> or <LEDGERBAL>.
+			stack = append(stack, tag)
+			switch tag {
+			case "STMTTRN":
+				txn = make(map[string]string)
+				txnLine = tagLine
+			case "STMTRS", "CCSTMTRS":
+				statements++
+			}
+			continue
+		}
+		value = html.UnescapeString(value)
+
+		parent := ""
+		if len(stack) > 0 {
+			parent = stack[len(stack)-1]
+		}
+		switch parent {
+		case "STMTTRN":
+			if txn != nil {
+				txn[tag] = value
+			}
+		case "LEDGERBAL":
+			switch tag {
+			case "BALAMT":
+				balance, err := parseOFXAmount(value)
+				if err != nil {
+					return nil, nil, nil, fmt.Errorf("invalid OFX import: ledger balance: %w", err)
+				}
+				statement.ledgerBalance = &balance
+			case "DTASOF":
+				statement.ledgerDate, _ = parseOFXDate(value)
+			}
+		case "BANKACCTFROM", "CCACCTFROM":
+			if tag == "ACCTID" {
+				statement.accountID = value
+			}
+		case "BANKTRANLIST":
+			switch tag {
+			case "DTSTART":
+				statement.startDate, _ = parseOFXDate(value)
+			case "DTEND":
+				statement.endDate, _ = parseOFXDate(value)
+			}
+		case "STMTRS", "CCSTMTRS":
+			if tag == "CURDEF" {
+				statement.currency = strings.ToUpper(value)
+			}
+		}
+	}
+
+	if statements == 0 {
+		return nil, nil, nil, fmt.Errorf("invalid OFX import: no bank or credit card statement found")
+	}
+	if statements > 1 {
+		return nil, nil, nil, fmt.Errorf("invalid OFX import: the file holds %d statements; import one account at a time", statements)
+	}
+	if statement.currency != "" && !isCurrencyCode(statement.currency) {
+		return nil, nil, nil, fmt.Errorf("invalid OFX import: unknown currency %q", statement.currency)
+	}
+	return statement, rows, rowErrors, nil
+}
+
+// ofxTransactionRow converts the fields of one <STMTTRN> into a statement row.
+func ofxTransactionRow(fields map[string]string, accountID string) (statementRow, error) {
+	var row statementRow
+	date, err := parseOFXDate(fields["DTPOSTED"])
+	if err != nil {
+		return row, err
+	}
+	row.date = date
+
+	amount, err := parseOFXAmount(fields["TRNAMT"])
+	if err != nil {
+		return row, err
+	}
+	if amount.IsZero() {
+		return row, fmt.Errorf("amount is missing or zero")
+	}
+	row.amount = amount
+
+	row.note = fields["NAME"]
+	if memo := fields["MEMO"]; memo != "" && memo != row.note {
+		if row.note != "" {
+			row.note += " - "
+		}
+		row.note += memo
+	}
+
+	// FITIDs are only unique within an account, so qualify them with the account number.
+	if fitID := fields["FITID"]; fitID != "" {
+		row.externalID = fitID
+		if accountID != "" {
+			row.externalID = accountID + ":" + fitID
+		}
+	}
+	return row, nil
+}
+
+// parseOFXDate parses an OFX date such as 20240615, 20240615120000 or 20240615120000.000[-5:EST];
+// only the calendar date is kept.
+func parseOFXDate(value string) (time.Time, error) {
+	if len(value) < 8 {
+		return time.Time{}, fmt.Errorf("invalid date %q", value)
+	}
+	date, err := time.Parse("20060102", value[:8])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q", value)
+	}
+	return date, nil
+}
+
+// parseOFXAmount parses a signed OFX amount. Some banks write a decimal comma instead of a point.
+func parseOFXAmount(value string) (types.Money, error) {
+	if value == "" {
+		return 0, fmt.Errorf("amount is missing")
+	}
+	separator := "."
+	if strings.Contains(value, ",") && !strings.Contains(value, ".") {
+		separator = ","
+	}
+	return parseStatementAmount(value, separator)
+}
+
+// maskAccountNumber hides all but the last four characters of an account number.
+func maskAccountNumber(number string) string {
+	if len(number) <= 4 {
+		return number
+	}
+	return strings.Repeat("*", len(number)-4) + number[len(number)-4:]
+}
@@ -0,0 +1,278 @@
+package services
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zayyadi/finance-tracker/internal/models"
+	"github.com/zayyadi/finance-tracker/internal/types"
+)
+
+// qifTransferCategory is the category of QIF transactions that move money to or from another account.
+const qifTransferCategory = "Transfer"
+
+// qifRecord collects the fields of one QIF transaction, up to its closing "^" line.
+type qifRecord struct {
+	line     int
+	date     string
+	amount   string
+	payee    string
+	memo     string
+	category string
+	splits   []qifSplit
+}
+
+// qifSplit is one split line of a QIF transaction: its S (category), E (memo) and $ (amount) fields.
+type qifSplit struct {
+	category string
+	memo     string
+	amount   string
+}
+
+// ImportQIF imports a QIF export from desktop finance software. Transactions in !Type:Bank, !Type:CCard
+// and !Type:Cash sections are imported; other sections, such as investments or category lists, are
+// skipped. A split payment becomes one expense with a split line per split. QIF dates carry no
+// format of their own, so dateFormat says whether they are MM/DD/YYYY (the default) or DD/MM/YYYY.
+func (s *ImportService) ImportQIF(userID uint, r io.Reader, accountID *uint, dateFormat string, dryRun bool) (*models.ImportResult, error) {
+	if s.DB == nil {
+		return nil, fmt.Errorf("database connection not initialized in ImportService")
+	}
+	if dateFormat == "" {
+		dateFormat = "MM/DD/YYYY"
+	}
+	if dateFormat != "MM/DD/YYYY" && dateFormat != "DD/MM/YYYY" {
+		return nil, fmt.Errorf("invalid QIF import: date_format must be MM/DD/YYYY or DD/MM/YYYY")
+	}
+	rows, rowErrors, err := parseQIF(r, dateFormat == "DD/MM/YYYY")
+	if err != nil {
+		return nil, err
+	}
+	return s.saveImport(userID, importOptions{accountID: accountID}, rows, rowErrors, dryRun)
+}
+
+// parseQIF reads the transactions of a QIF file. Each line starts with a one-letter field code and a
+// "^" line ends a transaction; "!" lines start a new section.
+func parseQIF(r io.Reader, dayFirst bool) ([]statementRow, []models.ImportRowError, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var rows []statementRow
+	var rowErrors []models.ImportRowError
+	importing := false
+	sections := 0
+	var record *qifRecord
+
+	flush := func() {
+		if record == nil {
+			return
+		}
+		recordRows, err := qifRecordRows(record, dayFirst)
+		if err != nil {
+			rowErrors = append(rowErrors, models.ImportRowError{Line: record.line, Error: err.Error()})
+		} else {
+			rows = append(rows, recordRows...)
+		}
+		record = nil
+	}
+
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimRight(scanner.Text(), " \t\r")
+		if line == 1 {
+			text = strings.TrimPrefix(text, "\ufeff") // Some exporters start the file with a byte order mark
+		}
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		if text[0] == '!' {
+			flush()
+			header := strings.ToLower(strings.TrimSpace(text))
+			if strings.HasPrefix(header, "!type:") {
+				switch strings.TrimSpace(strings.TrimPrefix(header, "!type:")) {
+				case "bank", "ccard", "cash":
+					importing = true
+					sections++
+				default:
+					importing = false
+				}
+			} else if header == "!account" {
+				// An account header block, ended by "^", precedes the account's transactions.
+				importing = false
+			}
+			continue
+		}
+		if !importing {
+			continue
+		}
+		if text[0] == '^' {
+			flush()
+			continue
+		}
+
+		if record == nil {
+			record = &qifRecord{line: line}
+		}
+		code, value := text[0], strings.TrimSpace(text[1:])
+		switch code {
+		case 'D':
+			record.date = value
+		case 'T', 'U':
+			record.amount = value
+		case 'P':
+			record.payee = value
+		case 'M':
+			record.memo = value
+		case 'L':
+			record.category = value
+		case 'S':
+			record.splits = append(record.splits, qifSplit{category: value})
+		case 'E':
+			if n := len(record.splits); n > 0 {
+				record.splits[n-1].memo = value
+			}
+		case '$':
+			if n := len(record.splits); n > 0 {
+				record.splits[n-1].amount = value
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, fmt.Errorf("invalid QIF import: could not read file: %w", err)
+	}
+	flush() // A final transaction without its "^"
+
+	if sections == 0 {
+		return nil, nil, fmt.Errorf("invalid QIF import: no !Type:Bank, !Type:CCard or !Type:Cash section found")
+	}
+	return rows, rowErrors, nil
+}
+
+// qifRecordRows converts a QIF transaction into a statement row. A split payment keeps its split lines,
+// to be saved as the lines of one expense; incomes have no split lines, so a split deposit is imported
+// as one income in the transaction's own category.
+func qifRecordRows(record *qifRecord, dayFirst bool) ([]statementRow, error) {
+	date, err := parseQIFDate(record.date, dayFirst)
+	if err != nil {
+		return nil, err
+	}
+	total, err := parseStatementAmount(record.amount, ".")
+	if err != nil {
+		return nil, err
+	}
+	note := record.payee
+	if record.memo != "" && record.memo != note {
+		if note != "" {
+			note += " - "
+		}
+		note += record.memo
+	}
+
+	if len(record.splits) == 0 {
+		if total.IsZero() {
+			return nil, fmt.Errorf("amount is missing or zero")
+		}
+		return []statementRow{{line: record.line, date: date, amount: total, category: qifCategory(record.category), note: note}}, nil
+	}
+
+	var lines []statementRow
+	var sum types.Money
+	for _, split := range record.splits {
+		amount, err := parseStatementAmount(split.amount, ".")
+		if err != nil {
+			return nil, fmt.Errorf("split %q: %w", split.category, err)
+		}
+		sum += amount
+		if amount.IsZero() {
+			continue
+		}
+		splitNote := note
+		if split.memo != "" {
+			splitNote = record.payee
+			if splitNote != "" {
+				splitNote += " - "
+			}
+			splitNote += split.memo
+		}
+		lines = append(lines, statementRow{amount: amount, category: qifCategory(split.category), note: splitNote})
+	}
+	if record.amount == "" {
+		total = sum
+	} else if sum != total {
+		return nil, fmt.Errorf("split amounts add up to %s, not the transaction total %s", sum, total)
+	}
+	if len(lines) == 0 || total.IsZero() {
+		return nil, fmt.Errorf("amount is missing or zero")
+	}
+
+	row := statementRow{line: record.line, date: date, amount: total, category: qifCategory(record.category), note: note}
+	switch {
+	case len(lines) == 1:
+		row.category, row.note = lines[0].category, lines[0].note
+	case total.IsNegative():
+		for _, line := range lines {
+			if !line.amount.IsNegative() {
+				return nil, fmt.Errorf("split %q is money in, but a payment can only be split into money out", line.category)
+			}
+			row.splits = append(row.splits, models.ExpenseSplit{Amount: line.amount.Abs(), Category: line.category, Note: line.note})
+		}
+	}
+	return []statementRow{row}, nil
+}
+
+// qifCategory turns a QIF category into ours. A category in brackets, such as [Savings], names the
+// other account of a transfer; a class after a slash, as in Food/Business, is dropped.
+func qifCategory(category string) string {
+	if strings.HasPrefix(category, "[") {
+		return qifTransferCategory
+	}
+	if i := strings.Index(category, "/"); i >= 0 {
+		category = category[:i]
+	}
+	return strings.TrimSpace(category)
+}
+
+// parseQIFDate parses the date styles QIF exporters write, such as 6/15/2024, 06/15/24, 6/15'24 and
+// 6-15-2024. An apostrophe before a two-digit year means the 2000s.
+func parseQIFDate(value string, dayFirst bool) (time.Time, error) {
+	orig := value
+	value = strings.ReplaceAll(value, " ", "")
+	century := 0
+	if i := strings.Index(value, "'"); i >= 0 {
+		century = 2000
+		value = value[:i] + "/" + value[i+1:]
+	}
+	parts := strings.FieldsFunc(value, func(r rune) bool { return r == '/' || r == '-' || r == '.' })
+	if len(parts) != 3 {
+		return time.Time{}, fmt.Errorf("invalid date %q", orig)
+	}
+	numbers := make([]int, 3)
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid date %q", orig)
+		}
+		numbers[i] = n
+	}
+	month, day, year := numbers[0], numbers[1], numbers[2]
+	if dayFirst {
+		month, day = day, month
+	}
+	if len(parts[2]) <= 2 {
+		switch {
+		case century != 0:
+			year += century
+		case year < 70:
+			year += 2000
+		default:
+			year += 1900
+		}
+	}
+	date := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	if month < 1 || month > 12 || date.Day() != day {
+		return time.Time{}, fmt.Errorf("invalid date %q", orig)
+	}
+	return date, nil
+}
@@ -0,0 +1,671 @@
+package services
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/zayyadi/finance-tracker/internal/database"
+	"github.com/zayyadi/finance-tracker/internal/models"
+	"github.com/zayyadi/finance-tracker/internal/types"
+	"gorm.io/gorm"
+)
+
+// ImportService imports bank statements as income and expenses, and manages the saved column
+// mappings used for CSV statements.
+type ImportService struct {
+	DB             *gorm.DB
+	summaryService *SummaryService
+}
+
+// NewImportService creates a new ImportService. summaryService is used to invalidate summaries
+// for imported rows; it may be nil.
+func NewImportService(db *gorm.DB, summaryService *SummaryService) *ImportService {
+	if db == nil {
+		log.Println("Warning: NewImportService called with nil DB, attempting to use global GetDB()")
+		db = database.GetDB()
+	}
+	return &ImportService{DB: db, summaryService: summaryService}
+}
+
+// statementRow is one transaction parsed from a statement, before it is saved. Amount is signed:
+// positive for money in (income), negative for money out (expense).
+type statementRow struct {
+	line       int
+	date       time.Time
+	amount     types.Money
+	category   string
+	note       string
+	externalID string                // The bank's own transaction ID, when the format has one
+	splits     []models.ExpenseSplit // The split lines of a split expense, with positive amounts
+}
+
+// importOptions controls how parsed statement rows are saved.
+type importOptions struct {
+	accountID       *uint
+	currency        string
+	incomeCategory  string
+	expenseCategory string
+}
+
+// CreateImportProfile validates and inserts a new CSV mapping profile.
+func (s *ImportService) CreateImportProfile(userID uint, req *models.ImportProfileRequest) (*models.ImportProfile, error) {
+	if s.DB == nil {
+		return nil, fmt.Errorf("database connection not initialized in ImportService")
+	}
+	profile := &models.ImportProfile{UserID: userID}
+	if err := applyImportProfileRequest(profile, req); err != nil {
+		return nil, err
+	}
+	if err := s.checkProfileNameFree(userID, profile.Name, 0); err != nil {
+		return nil, err
+	}
+	if err := s.DB.Create(profile).Error; err != nil {
+		log.Printf("Error creating import profile for user %d: %v", userID, err)
+		return nil, fmt.Errorf("could not create import profile: %w", err)
+	}
+	return profile, nil
+}
+
+// GetImportProfileByID retrieves a specific import profile by its ID, scoped to the given user.
+func (s *ImportService) GetImportProfileByID(userID uint, profileID uint) (*models.ImportProfile, error) {
+	if s.DB == nil {
+		return nil, fmt.Errorf("database connection not initialized in ImportService")
+	}
+	var profile models.ImportProfile
+	if err := s.DB.Where("id = ? AND user_id = ?", profileID, userID).First(&profile).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("import profile not found")
+		}
+		log.Printf("Error retrieving import profile %d for user %d: %v", profileID, userID, err)
+		return nil, fmt.Errorf("could not retrieve import profile: %w", err)
+	}
+	return &profile, nil
+}
+
+// GetImportProfiles retrieves a user's import profiles with pagination, ordered by name.
+func (s *ImportService) GetImportProfiles(userID uint, offset int, limit int) ([]models.ImportProfile, error) {
+	if s.DB == nil {
+		return nil, fmt.Errorf("database connection not initialized in ImportService")
+	}
+	var profiles []models.ImportProfile
+	if err := s.DB.Where("user_id = ?", userID).Order("name, id").Offset(offset).Limit(limit).Find(&profiles).Error; err != nil {
+		log.Printf("Error retrieving import profiles for user %d: %v", userID, err)
+		return nil, fmt.Errorf("could not retrieve import profiles: %w", err)
+	}
+	if profiles == nil {
+		return []models.ImportProfile{}, nil
+	}
+	return profiles, nil
+}
+
+// UpdateImportProfile replaces the mapping of an existing profile owned by the given user.
+func (s *ImportService) UpdateImportProfile(userID uint, profileID uint, req *models.ImportProfileRequest) (*models.ImportProfile, error) {
+	if s.DB == nil {
+		return nil, fmt.Errorf("database connection not initialized in ImportService")
+	}
+	profile, err := s.GetImportProfileByID(userID, profileID)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyImportProfileRequest(profile, req); err != nil {
+		return nil, err
+	}
+	if err := s.checkProfileNameFree(userID, profile.Name, profileID); err != nil {
+		return nil, err
+	}
+	// Select("*") so cleared columns and false flags are written too.
+	if err := s.DB.Model(profile).Select("*").Omit("id", "created_at", "deleted_at").Updates(profile).Error; err != nil {
+		log.Printf("Error updating import profile %d: %v", profileID, err)
+		return nil, fmt.Errorf("could not update import profile: %w", err)
+	}
+	return s.GetImportProfileByID(userID, profileID)
+}
+
+// DeleteImportProfile deletes an import profile. Profiles are removed permanently so the name can be reused.
+func (s *ImportService) DeleteImportProfile(userID uint, profileID uint) error {
+	if s.DB == nil {
+		return fmt.Errorf("database connection not initialized in ImportService")
+	}
+	result := s.DB.Unscoped().Where("id = ? AND user_id = ?", profileID, userID).Delete(&models.ImportProfile{})
+	if result.Error != nil {
+		log.Printf("Error deleting import profile %d: %v", profileID, result.Error)
+		return fmt.Errorf("could not delete import profile: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("import profile not found, no rows deleted")
+	}
+	return nil
+}
+
+// checkProfileNameFree returns an error if another of the user's profiles already has the name.
+func (s *ImportService) checkProfileNameFree(userID uint, name string, exceptID uint) error {
+	var count int64
+	if err := s.DB.Model(&models.ImportProfile{}).Where("user_id = ? AND name = ? AND id <> ?", userID, name, exceptID).Count(&count).Error; err != nil {
+		return fmt.Errorf("could not check import profile name: %w", err)
+	}
+	if count > 0 {
+		return fmt.Errorf("an import profile named %q already exists", name)
+	}
+	return nil
+}
+
+// ProfileFromRequest validates a one-off column mapping without saving it, for imports that do not
+// use a saved profile.
+func (s *ImportService) ProfileFromRequest(userID uint, req *models.ImportProfileRequest) (*models.ImportProfile, error) {
+	profile := &models.ImportProfile{UserID: userID}
+	if err := applyImportProfileRequest(profile, req); err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
+
+// ImportCSV imports a bank statement in CSV format using the column mapping of profile, which need
+// not be saved. Rows that cannot be parsed are reported and skipped; the rest are created in one
+// transaction. With dryRun set nothing is written and the result previews what would be imported.
+func (s *ImportService) ImportCSV(userID uint, r io.Reader, profile *models.ImportProfile, dryRun bool) (*models.ImportResult, error) {
+	if s.DB == nil {
+		return nil, fmt.Errorf("database connection not initialized in ImportService")
+	}
+	rows, rowErrors, err := parseStatementCSV(r, profile)
+	if err != nil {
+		return nil, err
+	}
+	opts := importOptions{
+		accountID:       profile.AccountID,
+		currency:        profile.Currency,
+		incomeCategory:  profile.IncomeCategory,
+		expenseCategory: profile.ExpenseCategory,
+	}
+	return s.saveImport(userID, opts, rows, rowErrors, dryRun)
+}
+
+// saveImport creates an income or expense for each parsed row in a single transaction and reports
+// the outcome. Rows whose external ID was imported before are skipped and listed as duplicates.
+// Nothing is written on a dry run.
+func (s *ImportService) saveImport(userID uint, opts importOptions, rows []statementRow, rowErrors []models.ImportRowError, dryRun bool) (*models.ImportResult, error) {
+	if opts.accountID != nil && *opts.accountID == 0 {
+		opts.accountID = nil
+	}
+	currency, err := resolveAccountCurrency(s.DB, userID, opts.accountID, opts.currency)
+	if err != nil {
+		return nil, fmt.Errorf("could not import statement: %w", err)
+	}
+	if opts.incomeCategory == "" {
+		opts.incomeCategory = defaultCategory
+	}
+	if opts.expenseCategory == "" {
+		opts.expenseCategory = defaultCategory
+	}
+	seen, err := s.importedExternalIDs(userID, rows)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := loadCategoryRules(s.DB, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.ImportResult{
+		DryRun:       dryRun,
+		Transactions: make([]models.ImportedTransaction, 0, len(rows)),
+		Duplicates:   []models.ImportedTransaction{},
+		Errors:       rowErrors,
+	}
+	if result.Errors == nil {
+		result.Errors = []models.ImportRowError{}
+	}
+	var pending []statementRow
+	var matches [][]duplicateMatch // Possible duplicates among the user's existing entries, per pending row
+	for _, row := range rows {
+		txn := models.ImportedTransaction{
+			Line:       row.line,
+			Type:       "income",
+			Date:       row.date.Format("2006-01-02"),
+			Amount:     row.amount,
+			Currency:   currency,
+			Category:   row.category,
+			Note:       row.note,
+			ExternalID: row.externalID,
+			Splits:     row.splits,
+		}
+		if row.amount.IsNegative() {
+			txn.Type = "expense"
+			txn.Amount = row.amount.Abs()
+		}
+		if txn.Category == "" {
+			if rule := matchCategoryRule(rules, txn.Type, txn.Amount, currency, txn.Note); rule != nil {
+				txn.Category, txn.CategoryRuleID = rule.Category, &rule.ID
+			} else if txn.Type == "expense" {
+				txn.Category = opts.expenseCategory
+			} else {
+				txn.Category = opts.incomeCategory
+			}
+		}
+		// Use the spelling of an existing category; new ones are created when the rows are saved.
+		if category, err := findCategoryByName(s.DB, userID, txn.Type, txn.Category); err != nil {
+			return nil, err
+		} else if category != nil {
+			txn.Category, txn.CategoryID = category.Name, &category.ID
+		}
+		if row.externalID != "" {
+			if seen[row.externalID] {
+				result.Duplicates = append(result.Duplicates, txn)
+				continue
+			}
+			seen[row.externalID] = true
+		}
+		txnMatches, err := findDuplicates(s.DB, userID, txn.Type, duplicateCandidate{Amount: txn.Amount, Currency: currency, Category: txn.Category, Date: database.CustomDate{Time: row.date}, Note: txn.Note})
+		if err != nil {
+			return nil, err
+		}
+		for _, match := range txnMatches {
+			txn.PossibleDuplicateOf = append(txn.PossibleDuplicateOf, match.id)
+		}
+		result.Transactions = append(result.Transactions, txn)
+		pending = append(pending, row)
+		matches = append(matches, txnMatches)
+	}
+	if dryRun || len(pending) == 0 {
+		return result, nil
+	}
+
+	err = withAuditSource(s.DB, auditSourceImport).Transaction(func(tx *gorm.DB) error {
+		for i := range result.Transactions {
+			txn := &result.Transactions[i]
+			if txn.CategoryID == nil {
+				category, err := resolveCategory(tx, userID, txn.Type, txn.Category)
+				if err != nil {
+					return fmt.Errorf("could not create the category of line %d: %w", txn.Line, err)
+				}
+				txn.Category, txn.CategoryID = category.Name, &category.ID
+			}
+			date := database.CustomDate{Time: pending[i].date}
+			var externalID *string
+			if txn.ExternalID != "" {
+				externalID = &txn.ExternalID
+			}
+			if txn.Type == "income" {
+				income := &models.Income{UserID: userID, Amount: txn.Amount, Currency: currency, AccountID: opts.accountID, ExternalID: externalID, Category: txn.Category, CategoryID: txn.CategoryID, CategoryRuleID: txn.CategoryRuleID, Date: date, Note: txn.Note}
+				if err := tx.Create(income).Error; err != nil {
+					return fmt.Errorf("could not create income for line %d: %w", txn.Line, err)
+				}
+				txn.ID = income.ID
+			} else {
+				expense := &models.Expense{UserID: userID, Amount: txn.Amount, Currency: currency, AccountID: opts.accountID, ExternalID: externalID, Category: txn.Category, CategoryID: txn.CategoryID, CategoryRuleID: txn.CategoryRuleID, Date: date, Note: txn.Note}
+				if err := tx.Create(expense).Error; err != nil {
+					return fmt.Errorf("could not create expense for line %d: %w", txn.Line, err)
+				}
+				txn.ID = expense.ID
+				if len(txn.Splits) > 0 {
+					splits, err := prepareSplits(tx, userID, txn.Amount, txn.Splits)
+					if err != nil {
+						return fmt.Errorf("could not split expense for line %d: %w", txn.Line, err)
+					}
+					if err := saveSplits(tx, expense.ID, splits); err != nil {
+						return fmt.Errorf("could not split expense for line %d: %w", txn.Line, err)
+					}
+					txn.Splits = splits
+				}
+			}
+			if _, err := flagDuplicates(tx, userID, txn.Type, txn.ID, matches[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("Error importing statement for user %d: %v", userID, err)
+		return nil, err
+	}
+	result.Imported = len(result.Transactions)
+
+	dates := make([]time.Time, len(pending))
+	for i, row := range pending {
+		dates[i] = row.date
+	}
+	invalidateSummariesForDates(s.summaryService, userID, dates)
+	return result, nil
+}
+
+// importedExternalIDs returns which of the rows' external IDs the user has imported before, including
+// rows deleted since: a deleted transaction is not brought back by importing the statement again.
+func (s *ImportService) importedExternalIDs(userID uint, rows []statementRow) (map[string]bool, error) {
+	seen := make(map[string]bool)
+	var ids []string
+	for _, row := range rows {
+		if row.externalID != "" {
+			ids = append(ids, row.externalID)
+		}
+	}
+	if len(ids) == 0 {
+		return seen, nil
+	}
+	for _, model := range []interface{}{&models.Income{}, &models.Expense{}} {
+		var existing []string
+		if err := s.DB.Unscoped().Model(model).Where("user_id = ? AND external_id IN ?", userID, ids).Pluck("external_id", &existing).Error; err != nil {
+			return nil, fmt.Errorf("could not check for previously imported transactions: %w", err)
+		}
+		for _, id := range existing {
+			seen[id] = true
+		}
+	}
+	return seen, nil
+}
+
+// applyImportProfileRequest validates req and copies it onto profile, filling in defaults.
+func applyImportProfileRequest(profile *models.ImportProfile, req *models.ImportProfileRequest) error {
+	p := models.ImportProfile{
+		Model:             profile.Model,
+		UserID:            profile.UserID,
+		Name:              strings.TrimSpace(req.Name),
+		Delimiter:         req.Delimiter,
+		HasHeader:         req.HasHeader == nil || *req.HasHeader,
+		SkipRows:          req.SkipRows,
+		DateColumn:        strings.TrimSpace(req.DateColumn),
+		DateFormat:        req.DateFormat,
+		AmountColumn:      strings.TrimSpace(req.AmountColumn),
+		AmountSign:        req.AmountSign,
+		DebitColumn:       strings.TrimSpace(req.DebitColumn),
+		CreditColumn:      strings.TrimSpace(req.CreditColumn),
+		DecimalSeparator:  req.DecimalSeparator,
+		DescriptionColumn: strings.TrimSpace(req.DescriptionColumn),
+		CategoryColumn:    strings.TrimSpace(req.CategoryColumn),
+		IncomeCategory:    req.IncomeCategory,
+		ExpenseCategory:   req.ExpenseCategory,
+		AccountID:         req.AccountID,
+		Currency:          req.Currency,
+	}
+	if p.DateColumn == "" {
+		return fmt.Errorf("invalid import profile: date_column is required")
+	}
+	if p.Delimiter == "" {
+		p.Delimiter = ","
+	}
+	if p.Delimiter == "\"" || p.Delimiter == "\n" || p.Delimiter == "\r" {
+		return fmt.Errorf("invalid import profile: %q cannot be used as the delimiter", p.Delimiter)
+	}
+	if p.DateFormat == "" {
+		p.DateFormat = "YYYY-MM-DD"
+	}
+	if !validDateFormat(p.DateFormat) {
+		return fmt.Errorf("invalid import profile: date_format %q must contain a year, month and day, e.g. DD/MM/YYYY", p.DateFormat)
+	}
+	if p.AmountSign == "" {
+		p.AmountSign = "income_positive"
+	}
+	if p.DecimalSeparator == "" {
+		p.DecimalSeparator = "."
+	}
+	if p.DecimalSeparator != "." && p.DecimalSeparator != "," {
+		return fmt.Errorf("invalid import profile: decimal_separator must be \".\" or \",\"")
+	}
+	if p.AmountColumn == "" && p.DebitColumn == "" && p.CreditColumn == "" {
+		return fmt.Errorf("invalid import profile: amount_column, or debit_column and credit_column, is required")
+	}
+	if p.AmountColumn != "" && (p.DebitColumn != "" || p.CreditColumn != "") {
+		return fmt.Errorf("invalid import profile: use either amount_column or debit_column/credit_column, not both")
+	}
+	if p.IncomeCategory == "" {
+		p.IncomeCategory = defaultCategory
+	}
+	if p.ExpenseCategory == "" {
+		p.ExpenseCategory = defaultCategory
+	}
+	if p.AccountID != nil && *p.AccountID == 0 {
+		p.AccountID = nil
+	}
+	if !p.HasHeader {
+		for _, column := range profileColumnRefs(&p) {
+			if n, err := strconv.Atoi(column); err != nil || n < 1 {
+				return fmt.Errorf("invalid import profile: without a header row, columns are 1-based positions, not %q", column)
+			}
+		}
+	}
+	*profile = p
+	return nil
+}
+
+// dateLayout translates a date format such as DD/MM/YYYY into a Go time layout.
+func dateLayout(format string) string {
+	return strings.NewReplacer("YYYY", "2006", "YY", "06", "MM", "01", "DD", "02").Replace(format)
+}
+
+// validDateFormat reports whether a date format round-trips a date with distinct year, month and day.
+func validDateFormat(format string) bool {
+	layout := dateLayout(format)
+	sample := time.Date(2031, time.November, 23, 0, 0, 0, 0, time.UTC)
+	parsed, err := time.Parse(layout, sample.Format(layout))
+	return err == nil && parsed.Equal(sample)
+}
+
+// parseStatementCSV reads the rows of a CSV statement according to profile. A malformed file or
+// mapping fails the whole import; a malformed row is reported with its line number and skipped.
+func parseStatementCSV(r io.Reader, profile *models.ImportProfile) ([]statementRow, []models.ImportRowError, error) {
+	reader := csv.NewReader(r)
+	reader.Comma = []rune(profile.Delimiter)[0]
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	reader.LazyQuotes = true
+
+	for i := 0; i < profile.SkipRows; i++ {
+		if _, err := reader.Read(); err != nil {
+			return nil, nil, fmt.Errorf("invalid CSV import: could not skip row %d: %w", i+1, err)
+		}
+	}
+
+	var header []string
+	if profile.HasHeader {
+		record, err := reader.Read()
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid CSV import: could not read header: %w", err)
+		}
+		header = record
+	}
+	columns, err := resolveColumns(profile, header)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var rows []statementRow
+	var rowErrors []models.ImportRowError
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		line, _ := reader.FieldPos(0)
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				rowErrors = append(rowErrors, models.ImportRowError{Line: parseErr.StartLine, Error: parseErr.Err.Error()})
+				continue
+			}
+			return nil, nil, fmt.Errorf("invalid CSV import: %w", err)
+		}
+		if blankRecord(record) {
+			continue
+		}
+		row, err := parseStatementRecord(record, columns, profile)
+		if err != nil {
+			rowErrors = append(rowErrors, models.ImportRowError{Line: line, Error: err.Error()})
+			continue
+		}
+		row.line = line
+		rows = append(rows, row)
+	}
+	return rows, rowErrors, nil
+}
+
+// statementColumns holds the position of each mapped column in a record; -1 when not mapped.
+type statementColumns struct {
+	date, amount, debit, credit, description, category int
+}
+
+// profileColumnRefs lists the profile's mapped column references.
+func profileColumnRefs(p *models.ImportProfile) []string {
+	var refs []string
+	for _, ref := range []string{p.DateColumn, p.AmountColumn, p.DebitColumn, p.CreditColumn, p.DescriptionColumn, p.CategoryColumn} {
+		if ref != "" {
+			refs = append(refs, ref)
+		}
+	}
+	return refs
+}
+
+// resolveColumns finds the position of each column the profile maps, by header name (ignoring case)
+// or, without a header, by 1-based position.
+func resolveColumns(profile *models.ImportProfile, header []string) (statementColumns, error) {
+	positions := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))) // Some banks start the file with a byte order mark
+		if _, seen := positions[name]; !seen {
+			positions[name] = i
+		}
+	}
+	find := func(ref string) (int, error) {
+		if ref == "" {
+			return -1, nil
+		}
+		if !profile.HasHeader {
+			n, err := strconv.Atoi(ref)
+			if err != nil || n < 1 {
+				return -1, fmt.Errorf("invalid CSV import: column %q is not a 1-based position", ref)
+			}
+			return n - 1, nil
+		}
+		if i, ok := positions[strings.ToLower(ref)]; ok {
+			return i, nil
+		}
+		return -1, fmt.Errorf("invalid CSV import: column %q not found in header", ref)
+	}
+
+	var columns statementColumns
+	var err error
+	for _, c := range []struct {
+		ref  string
+		dest *int
+	}{
+		{profile.DateColumn, &columns.date},
+		{profile.AmountColumn, &columns.amount},
+		{profile.DebitColumn, &columns.debit},
+		{profile.CreditColumn, &columns.credit},
+		{profile.DescriptionColumn, &columns.description},
+		{profile.CategoryColumn, &columns.category},
+	} {
+		if *c.dest, err = find(c.ref); err != nil {
+			return columns, err
+		}
+	}
+	if columns.date < 0 {
+		return columns, fmt.Errorf("invalid CSV import: date_column is required")
+	}
+	if columns.amount < 0 && columns.debit < 0 && columns.credit < 0 {
+		return columns, fmt.Errorf("invalid CSV import: amount_column, or debit_column and credit_column, is required")
+	}
+	return columns, nil
+}
+
+// parseStatementRecord converts one CSV record into a statement row.
+func parseStatementRecord(record []string, columns statementColumns, profile *models.ImportProfile) (statementRow, error) {
+	field := func(i int) string {
+		if i < 0 || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	var row statementRow
+	dateStr := field(columns.date)
+	if dateStr == "" {
+		return row, fmt.Errorf("date is empty")
+	}
+	date, err := time.Parse(dateLayout(profile.DateFormat), dateStr)
+	if err != nil {
+		return row, fmt.Errorf("invalid date %q, expected format %s", dateStr, profile.DateFormat)
+	}
+	row.date = date
+
+	if columns.amount >= 0 {
+		amount, err := parseStatementAmount(field(columns.amount), profile.DecimalSeparator)
+		if err != nil {
+			return row, err
+		}
+		if profile.AmountSign == "expense_positive" {
+			amount = -amount
+		}
+		row.amount = amount
+	} else {
+		debitStr, creditStr := field(columns.debit), field(columns.credit)
+		var debit, credit types.Money
+		if debitStr != "" {
+			if debit, err = parseStatementAmount(debitStr, profile.DecimalSeparator); err != nil {
+				return row, err
+			}
+		}
+		if creditStr != "" {
+			if credit, err = parseStatementAmount(creditStr, profile.DecimalSeparator); err != nil {
+				return row, err
+			}
+		}
+		if !debit.IsZero() && !credit.IsZero() {
+			return row, fmt.Errorf("both debit and credit amounts are set")
+		}
+		row.amount = credit.Abs() - debit.Abs()
+	}
+	if row.amount.IsZero() {
+		return row, fmt.Errorf("amount is missing or zero")
+	}
+
+	row.note = field(columns.description)
+	row.category = field(columns.category)
+	return row, nil
+}
+
+// parseStatementAmount parses an amount as banks print it: with an optional currency symbol,
+// thousands separators, and parentheses or a leading minus for negatives.
+func parseStatementAmount(s string, decimalSeparator string) (types.Money, error) {
+	orig := s
+	s = strings.Map(func(r rune) rune {
+		if unicode.Is(unicode.Sc, r) || unicode.IsSpace(r) || r == '\'' {
+			return -1
+		}
+		return r
+	}, s)
+	if s == "" {
+		return 0, nil
+	}
+	negative := false
+	if strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")") {
+		negative = true
+		s = s[1 : len(s)-1]
+	}
+	if decimalSeparator == "," {
+		s = strings.ReplaceAll(s, ".", "")
+		s = strings.ReplaceAll(s, ",", ".")
+	}
+	amount, err := types.ParseMoney(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q", orig)
+	}
+	if negative {
+		amount = -amount
+	}
+	return amount, nil
+}
+
+// blankRecord reports whether every field of a record is empty, as in a trailing blank row.
+func blankRecord(record []string) bool {
+	for _, field := range record {
+		if strings.TrimSpace(field) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,398 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zayyadi/finance-tracker/internal/models"
+	"github.com/zayyadi/finance-tracker/internal/types"
+	"gorm.io/gorm"
+)
+
+func setupImportTestDB(t *testing.T) *gorm.DB {
+	db := setupAccountTestDB(t)
+	require.NoError(t, db.AutoMigrate(&models.ImportProfile{}))
+	return db
+}
+
+func TestImportService_CSVSignedAmounts(t *testing.T) {
+	db := setupImportTestDB(t)
+	summaryService := NewSummaryService(db)
+	service := NewImportService(db, summaryService)
+	account := createTestAccount(t, NewAccountService(db), "Checking", "USD", 0)
+
+	profile, err := service.CreateImportProfile(testUserID, &models.ImportProfileRequest{
+		Name:              "My Bank",
+		DateColumn:        "Booking Date",
+		DateFormat:        "DD/MM/YYYY",
+		AmountColumn:      "Amount",
+		DescriptionColumn: "Description",
+		AccountID:         &account.ID,
+	})
+	require.NoError(t, err)
+	assert.True(t, profile.HasHeader)
+	assert.Equal(t, ",", profile.Delimiter)
+
+	// Warm the summary cache so a missing invalidation would show.
+	_, err = summaryService.GetOrCreateFinancialSummary(testUserID, "monthly", time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC), "overall")
+	require.NoError(t, err)
+
+	statement := "Booking Date,Description,Amount\n" +
+		"01/06/2024,ACME PAYROLL,\"2,500.00\"\n" +
+		"03/06/2024,Corner Shop,-12.40\n" +
+		"\n" +
+		"2024-06-04,Bad date,-1.00\n" +
+		"05/06/2024,Nothing,0\n" +
+		"06/06/2024,Refund,($3.10)\n"
+
+	preview, err := service.ImportCSV(testUserID, strings.NewReader(statement), profile, true)
+	require.NoError(t, err)
+	assert.True(t, preview.DryRun)
+	assert.Equal(t, 0, preview.Imported)
+	require.Len(t, preview.Transactions, 3)
+	require.Len(t, preview.Errors, 2)
+	assert.Equal(t, 5, preview.Errors[0].Line)
+	assert.Contains(t, preview.Errors[0].Error, "invalid date")
+	assert.Equal(t, 6, preview.Errors[1].Line)
+	var count int64
+	require.NoError(t, db.Model(&models.Expense{}).Count(&count).Error)
+	assert.Equal(t, int64(0), count, "A dry run writes nothing")
+
+	result, err := service.ImportCSV(testUserID, strings.NewReader(statement), profile, false)
+	require.NoError(t, err)
+	assert.Equal(t, 3, result.Imported)
+	salary := result.Transactions[0]
+	assert.Equal(t, "income", salary.Type)
+	assert.Equal(t, types.Money(250000), salary.Amount)
+	assert.Equal(t, "2024-06-01", salary.Date)
+	assert.Equal(t, "Uncategorized", salary.Category)
+	assert.NotZero(t, salary.ID)
+	shop := result.Transactions[1]
+	assert.Equal(t, "expense", shop.Type)
+	assert.Equal(t, types.Money(1240), shop.Amount)
+	assert.Equal(t, "Corner Shop", shop.Note)
+	assert.Equal(t, "expense", result.Transactions[2].Type, "Parentheses mean a negative amount")
+
+	var stored models.Expense
+	require.NoError(t, db.First(&stored, shop.ID).Error)
+	require.NotNil(t, stored.AccountID)
+	assert.Equal(t, account.ID, *stored.AccountID)
+
+	summary, err := summaryService.GetOrCreateFinancialSummary(testUserID, "monthly", time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC), "overall")
+	require.NoError(t, err)
+	assert.Equal(t, types.Money(250000), summary.TotalIncome)
+	assert.Equal(t, types.Money(1550), summary.TotalExpenses)
+}
+
+func TestImportService_CSVDebitCreditColumns(t *testing.T) {
+	db := setupImportTestDB(t)
+	service := NewImportService(db, nil)
+
+	hasHeader := false
+	profile, err := service.ProfileFromRequest(testUserID, &models.ImportProfileRequest{
+		Delimiter:        ";",
+		HasHeader:        &hasHeader,
+		SkipRows:         1,
+		DateColumn:       "1",
+		DateFormat:       "DD.MM.YYYY",
+		DebitColumn:      "3",
+		CreditColumn:     "4",
+		DecimalSeparator: ",",
+		CategoryColumn:   "2",
+		ExpenseCategory:  "Misc",
+	})
+	require.NoError(t, err)
+
+	statement := "Account 123456;;;\n" +
+		"02.06.2024;Groceries;1.234,56;\n" +
+		"03.06.2024;;9,99;\n" +
+		"04.06.2024;Salary;;3000,00\n" +
+		"05.06.2024;Oops;1,00;2,00\n"
+
+	result, err := service.ImportCSV(testUserID, strings.NewReader(statement), profile, false)
+	require.NoError(t, err)
+	assert.Equal(t, 3, result.Imported)
+	assert.Equal(t, types.Money(123456), result.Transactions[0].Amount)
+	assert.Equal(t, "Groceries", result.Transactions[0].Category)
+	assert.Equal(t, "Misc", result.Transactions[1].Category, "Rows without a category use the profile default")
+	assert.Equal(t, "income", result.Transactions[2].Type)
+	assert.Equal(t, types.Money(300000), result.Transactions[2].Amount)
+	require.Len(t, result.Errors, 1)
+	assert.Equal(t, 5, result.Errors[0].Line)
+	assert.Contains(t, result.Errors[0].Error, "both debit and credit")
+}
+
+func TestImportService_ProfileValidation(t *testing.T) {
+	db := setupImportTestDB(t)
+	service := NewImportService(db, nil)
+
+	_, err := service.CreateImportProfile(testUserID, &models.ImportProfileRequest{Name: "No amount", DateColumn: "Date"})
+	assert.ErrorContains(t, err, "invalid import profile")
+	_, err = service.CreateImportProfile(testUserID, &models.ImportProfileRequest{Name: "Bad format", DateColumn: "Date", AmountColumn: "Amount", DateFormat: "DD/MM"})
+	assert.ErrorContains(t, err, "date_format")
+
+	_, err = service.CreateImportProfile(testUserID, &models.ImportProfileRequest{Name: "Bank", DateColumn: "Date", AmountColumn: "Amount"})
+	require.NoError(t, err)
+	_, err = service.CreateImportProfile(testUserID, &models.ImportProfileRequest{Name: "Bank", DateColumn: "Date", AmountColumn: "Amount"})
+	assert.ErrorContains(t, err, "already exists")
+
+	profile, err := service.ProfileFromRequest(testUserID, &models.ImportProfileRequest{DateColumn: "Date", AmountColumn: "Value"})
+	require.NoError(t, err)
+	_, err = service.ImportCSV(testUserID, strings.NewReader("Date,Amount\n2024-06-01,1.00\n"), profile, true)
+	assert.ErrorContains(t, err, `column "Value" not found in header`)
+}
+
+const testOFXSGML = `OFXHEADER:100
+DATA:OFXSGML
+VERSION:102
+ENCODING:USASCII
+
+<OFX>
+<SIGNONMSGSRSV1><SONRS><STATUS><CODE>0<SEVERITY>INFO</STATUS><DTSERVER>20240701</SONRS></SIGNONMSGSRSV1>
+<BANKMSGSRSV1><STMTTRNRS><TRNUID>1<STMTRS>
+<CURDEF>USD
+<BANKACCTFROM><BANKID>121000248<ACCTID>1234567890<ACCTTYPE>CHECKING</BANKACCTFROM>
+<BANKTRANLIST>
+<DTSTART>20240601<DTEND>20240630
+<STMTTRN>
+<TRNTYPE>CREDIT
+<DTPOSTED>20240601120000.000[-5:EST]
+<TRNAMT>2500.00
+<FITID>T1001
+<NAME>ACME PAYROLL
+</STMTTRN>
+<STMTTRN>
+<TRNTYPE>DEBIT
+<DTPOSTED>20240603
+<TRNAMT>-42.10
+<FITID>T1002
+<NAME>GROCER &amp; CO
+<MEMO>
+</STMTTRN>
+<STMTTRN>
+<TRNTYPE>DEBIT
+<DTPOSTED>2024
+<TRNAMT>-1.00
+<FITID>T1003
+</STMTTRN>
+</BANKTRANLIST>
+<LEDGERBAL><BALAMT>2457.90<DTASOF>20240630</LEDGERBAL>
+</STMTRS></STMTTRNRS></BANKMSGSRSV1>
+</OFX>
+`
+
+const testOFXXML = `<?xml version="1.0" encoding="UTF-8"?>
+<?OFX OFXHEADER="200" VERSION="220" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"?>
+<OFX>
+  <CREDITCARDMSGSRSV1>
+    <CCSTMTTRNRS>
+      <CCSTMTRS>
+        <CURDEF>USD</CURDEF>
+        <CCACCTFROM><ACCTID>4111111111111111</ACCTID></CCACCTFROM>
+        <BANKTRANLIST>
+          <STMTTRN>
+            <TRNTYPE>DEBIT</TRNTYPE>
+            <DTPOSTED>20240605</DTPOSTED>
+            <TRNAMT>-15.99</TRNAMT>
+            <FITID>C-1</FITID>
+            <NAME>STREAMING</NAME>
+            <MEMO>Monthly plan</MEMO>
+          </STMTTRN>
+        </BANKTRANLIST>
+        <LEDGERBAL><BALAMT>-15.99</BALAMT><DTASOF>20240630</DTASOF></LEDGERBAL>
+      </CCSTMTRS>
+    </CCSTMTTRNRS>
+  </CREDITCARDMSGSRSV1>
+</OFX>
+`
+
+func TestImportService_OFXSGML(t *testing.T) {
+	db := setupImportTestDB(t)
+	service := NewImportService(db, nil)
+	account := createTestAccount(t, NewAccountService(db), "Checking", "USD", 0)
+
+	preview, err := service.ImportOFX(testUserID, strings.NewReader(testOFXSGML), &account.ID, true)
+	require.NoError(t, err)
+	require.Len(t, preview.Transactions, 2)
+	require.Len(t, preview.Errors, 1)
+	assert.Equal(t, 28, preview.Errors[0].Line, "Errors point at the <STMTTRN> of the bad transaction")
+	assert.Equal(t, "1234567890:T1001", preview.Transactions[0].ExternalID)
+	assert.Equal(t, "GROCER & CO", preview.Transactions[1].Note)
+	require.NotNil(t, preview.Statement)
+	assert.Equal(t, "******7890", preview.Statement.AccountNumber)
+	assert.Equal(t, "2024-06-01", preview.Statement.StartDate)
+	assert.Equal(t, types.Money(245790), *preview.Statement.LedgerBalance)
+	assert.Equal(t, types.Money(245790), *preview.Statement.AccountBalance, "A dry run compares the balance the import would lead to")
+	assert.Equal(t, types.Money(0), *preview.Statement.Difference)
+
+	result, err := service.ImportOFX(testUserID, strings.NewReader(testOFXSGML), &account.ID, false)
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Imported)
+	assert.Equal(t, types.Money(0), *result.Statement.Difference)
+
+	// Importing the same statement again skips every transaction, even one deleted in the meantime.
+	require.NoError(t, NewExpenseService(db).DeleteExpense(testUserID, result.Transactions[1].ID))
+	again, err := service.ImportOFX(testUserID, strings.NewReader(testOFXSGML), &account.ID, false)
+	require.NoError(t, err)
+	assert.Equal(t, 0, again.Imported)
+	assert.Len(t, again.Duplicates, 2)
+	assert.Equal(t, types.Money(-4210), *again.Statement.Difference, "Without the deleted expense our balance is higher than the bank's")
+}
+
+func TestImportService_OFXXML(t *testing.T) {
+	db := setupImportTestDB(t)
+	service := NewImportService(db, nil)
+
+	result, err := service.ImportOFX(testUserID, strings.NewReader(testOFXXML), nil, false)
+	require.NoError(t, err)
+	require.Equal(t, 1, result.Imported)
+	txn := result.Transactions[0]
+	assert.Equal(t, "expense", txn.Type)
+	assert.Equal(t, types.Money(1599), txn.Amount)
+	assert.Equal(t, "STREAMING - Monthly plan", txn.Note)
+	assert.Equal(t, "USD", txn.Currency)
+	assert.Nil(t, result.Statement.AccountBalance, "Without an account there is nothing to compare with")
+
+	_, err = service.ImportOFX(testUserID, strings.NewReader("OFXHEADER:100\n"), nil, true)
+	assert.ErrorContains(t, err, "invalid OFX import")
+}
+
+const testQIF = `!Account
+NChecking
+TBank
+^
+!Type:Bank
+D6/ 3'24
+T-1,250.00
+PLandlord
+MJune rent
+LHousing:Rent
+^
+D06/05/2024
+T-84.30
+PSupermarket
+SFood:Groceries
+$-60.00
+SHousehold/Home
+EDetergent
+$-24.30
+^
+D6/28'24
+T3,200.00
+PACME Corp
+LSalary
+^
+D6/30'24
+T-500.00
+PMove to savings
+L[Savings]
+^
+D13/45/2024
+T-1.00
+^
+!Type:Invst
+D6/10'24
+NBuy
+T100.00
+^
+`
+
+func TestImportService_QIF(t *testing.T) {
+	db := setupImportTestDB(t)
+	service := NewImportService(db, nil)
+	account := createTestAccount(t, NewAccountService(db), "Checking", "USD", 0)
+
+	preview, err := service.ImportQIF(testUserID, strings.NewReader(testQIF), &account.ID, "", true)
+	require.NoError(t, err)
+	require.Len(t, preview.Transactions, 4, "The split transaction is one expense; the investment section is skipped")
+	require.Len(t, preview.Errors, 1)
+	assert.Equal(t, 31, preview.Errors[0].Line, "Errors point at the first line of the bad transaction")
+
+	rent := preview.Transactions[0]
+	assert.Equal(t, "expense", rent.Type)
+	assert.Equal(t, "2024-06-03", rent.Date)
+	assert.Equal(t, types.Money(125000), rent.Amount)
+	assert.Equal(t, "Housing:Rent", rent.Category)
+	assert.Equal(t, "Landlord - June rent", rent.Note)
+
+	groceries := preview.Transactions[1]
+	assert.Equal(t, types.Money(8430), groceries.Amount, "A split payment is one expense for the whole receipt")
+	assert.Equal(t, "Supermarket", groceries.Note)
+	require.Len(t, groceries.Splits, 2)
+	assert.Equal(t, types.Money(6000), groceries.Splits[0].Amount)
+	assert.Equal(t, "Food:Groceries", groceries.Splits[0].Category)
+	assert.Equal(t, "Supermarket", groceries.Splits[0].Note)
+	assert.Equal(t, types.Money(2430), groceries.Splits[1].Amount)
+	assert.Equal(t, "Household", groceries.Splits[1].Category, "The class after the slash is dropped")
+	assert.Equal(t, "Supermarket - Detergent", groceries.Splits[1].Note)
+	assert.Equal(t, "income", preview.Transactions[2].Type)
+	assert.Equal(t, "Transfer", preview.Transactions[3].Category)
+
+	var count int64
+	require.NoError(t, db.Model(&models.Expense{}).Count(&count).Error)
+	assert.Zero(t, count, "A dry run saves nothing")
+
+	result, err := service.ImportQIF(testUserID, strings.NewReader(testQIF), &account.ID, "", false)
+	require.NoError(t, err)
+	assert.Equal(t, 4, result.Imported)
+	var splits []models.ExpenseSplit
+	require.NoError(t, db.Where("expense_id = ?", result.Transactions[1].ID).Order("id").Find(&splits).Error)
+	require.Len(t, splits, 2)
+	assert.Equal(t, "Food:Groceries", splits[0].Category)
+	assert.NotNil(t, splits[0].CategoryID)
+	assert.Equal(t, types.Money(2430), splits[1].Amount)
+	balance, err := NewAccountService(db).accountBalance(testUserID, account)
+	require.NoError(t, err)
+	assert.Equal(t, types.Money(320000-125000-8430-50000), balance)
+}
+
+func TestImportService_QIFDatesAndErrors(t *testing.T) {
+	for _, tc := range []struct {
+		value    string
+		dayFirst bool
+		want     string
+	}{
+		{"6/15/2024", false, "2024-06-15"},
+		{"06/15/98", false, "1998-06-15"},
+		{"6/15'05", false, "2005-06-15"},
+		{"15/06/2024", true, "2024-06-15"},
+		{"15.6.24", true, "2024-06-15"},
+	} {
+		date, err := parseQIFDate(tc.value, tc.dayFirst)
+		require.NoError(t, err, tc.value)
+		assert.Equal(t, tc.want, date.Format("2006-01-02"), tc.value)
+	}
+	_, err := parseQIFDate("2/30/2024", false)
+	assert.Error(t, err)
+
+	db := setupImportTestDB(t)
+	service := NewImportService(db, nil)
+
+	unbalanced := "!Type:CCard\nD1/2/2024\nT-10.00\nSFood\n$-4.00\nSFun\n$-5.00\n^\n"
+	result, err := service.ImportQIF(testUserID, strings.NewReader(unbalanced), nil, "", true)
+	require.NoError(t, err)
+	require.Len(t, result.Errors, 1)
+	assert.Contains(t, result.Errors[0].Error, "split amounts add up to -9.00")
+
+	refund := "!Type:Bank\nD1/2/2024\nT-10.00\nSFood\n$-12.00\nSRefund\n$2.00\n^\n"
+	result, err = service.ImportQIF(testUserID, strings.NewReader(refund), nil, "", true)
+	require.NoError(t, err)
+	require.Len(t, result.Errors, 1)
+	assert.Contains(t, result.Errors[0].Error, "can only be split into money out")
+
+	deposit := "!Type:Bank\nD1/2/2024\nT150.00\nLIncome\nSSalary\n$100.00\nSBonus\n$50.00\n^\n"
+	result, err = service.ImportQIF(testUserID, strings.NewReader(deposit), nil, "", true)
+	require.NoError(t, err)
+	require.Len(t, result.Transactions, 1, "A split deposit is one income")
+	assert.Equal(t, types.Money(15000), result.Transactions[0].Amount)
+	assert.Equal(t, "Income", result.Transactions[0].Category)
+	assert.Empty(t, result.Transactions[0].Splits)
+
+	_, err = service.ImportQIF(testUserID, strings.NewReader("!Type:Cat\nNFood\n^\n"), nil, "", true)
+	assert.ErrorContains(t, err, "invalid QIF import")
+	_, err = service.ImportQIF(testUserID, strings.NewReader(unbalanced), nil, "YYYY-MM-DD", true)
+	assert.ErrorContains(t, err, "invalid QIF import")
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
 	"github.com/zayyadi/finance-tracker/internal/database"
@@ -29,89 +30,225 @@ func (s *IncomeService) CreateIncome(income *models.Income) error {
 	if s.DB == nil {
 		return fmt.Errorf("database connection not initialized in IncomeService")
 	}
-	result := s.DB.Create(income)
-	if result.Error != nil {
-		// log.Printf("Error creating income for user %d: %v", income.UserID, result.Error) // UserID removed
-		log.Printf("Error creating income: %v", result.Error)
-		return fmt.Errorf("could not create income: %w", result.Error)
+	if income.AccountID != nil && *income.AccountID == 0 {
+		income.AccountID = nil
+	}
+	currency, err := resolveAccountCurrency(s.DB, income.UserID, income.AccountID, income.Currency)
+	if err != nil {
+		return fmt.Errorf("could not create income: %w", err)
+	}
+	income.Currency = currency
+	if income.CategoryID != nil && *income.CategoryID == 0 {
+		income.CategoryID = nil
+	}
+	if income.CategoryID == nil && strings.TrimSpace(income.Category) == "" {
+		category, ruleID, err := categorizeByRules(s.DB, income.UserID, "income", income.Amount, income.Currency, income.Note)
+		if err != nil {
+			return fmt.Errorf("could not create income: %w", err)
+		}
+		income.Category, income.CategoryRuleID = category, ruleID
+	}
+	category, err := categoryFor(s.DB, income.UserID, "income", income.CategoryID, income.Category)
+	if err != nil {
+		return fmt.Errorf("could not create income: %w", err)
+	}
+	income.Category, income.CategoryID = category.Name, &category.ID
+	tags, err := normalizeTags(income.Tags)
+	if err != nil {
+		return fmt.Errorf("could not create income: %w", err)
+	}
+	income.Tags = tags
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(income).Error; err != nil {
+			return err
+		}
+		if len(tags) == 0 {
+			return nil
+		}
+		return setTags(tx, income.UserID, "income", income.ID, tags)
+	})
+	if err != nil {
+		log.Printf("Error creating income for user %d: %v", income.UserID, err)
+		return fmt.Errorf("could not create income: %w", err)
 	}
+	income.PossibleDuplicateOf = flagNewTransaction(s.DB, income.UserID, "income", duplicateCandidateOfIncome(income))
 	return nil
 }
 
-// GetIncomeByID retrieves a specific income record by its ID.
-func (s *IncomeService) GetIncomeByID(incomeID uint) (*models.Income, error) {
+// GetIncomeByID retrieves a specific income record by its ID, scoped to the given user.
+func (s *IncomeService) GetIncomeByID(userID uint, incomeID uint) (*models.Income, error) {
 	if s.DB == nil {
 		return nil, fmt.Errorf("database connection not initialized in IncomeService")
 	}
 	var income models.Income
-	result := s.DB.Where("id = ?", incomeID).First(&income) // Removed userID condition
+	result := s.DB.Where("id = ? AND user_id = ?", incomeID, userID).First(&income)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
-			return nil, fmt.Errorf("income record not found") // Simplified error message
+			return nil, fmt.Errorf("income record not found")
 		}
-		log.Printf("Error retrieving income %d: %v", incomeID, result.Error)
+		log.Printf("Error retrieving income %d for user %d: %v", incomeID, userID, result.Error)
 		return nil, fmt.Errorf("could not retrieve income: %w", result.Error)
 	}
+	tags, err := loadTags(s.DB, "income", []uint{income.ID})
+	if err != nil {
+		return nil, err
+	}
+	income.Tags = tags[income.ID]
 	return &income, nil
 }
 
-// GetIncomes retrieves all income records with pagination.
-func (s *IncomeService) GetIncomes(offset int, limit int) ([]models.Income, error) {
+// GetIncomes retrieves a page, counting from 1, of the income records of a user matching filter, newest
+// first unless filter sorts them otherwise.
+func (s *IncomeService) GetIncomes(userID uint, page int, limit int, filter models.ListFilter) (*models.Page[models.Income], error) {
 	if s.DB == nil {
 		return nil, fmt.Errorf("database connection not initialized in IncomeService")
 	}
-	var incomes []models.Income
-	result := s.DB.Offset(offset).Limit(limit). // Removed userID condition
-							Order("date desc, created_at desc").
-							Find(&incomes)
-
-	if result.Error != nil {
-		log.Printf("Error retrieving incomes: %v", result.Error)
-		return nil, fmt.Errorf("could not retrieve incomes: %w", result.Error)
+	query, err := applyListFilter(s.DB.Model(&models.Income{}).Where("user_id = ?", userID), userID, incomeListColumns, filter)
+	if err != nil {
+		return nil, err
 	}
-	if incomes == nil {
-		return []models.Income{}, nil
+	incomes, err := paginate[models.Income](query, page, limit)
+	if err != nil {
+		log.Printf("Error retrieving incomes for user %d: %v", userID, err)
+		return nil, fmt.Errorf("could not retrieve incomes: %w", err)
+	}
+	if err := s.attachTags(incomes.Items); err != nil {
+		return nil, err
 	}
 	return incomes, nil
 }
 
-// GetIncomesByDateRange retrieves all income records within a specific date range.
-func (s *IncomeService) GetIncomesByDateRange(startDate, endDate time.Time) ([]models.Income, error) {
+// GetIncomesByDateRange retrieves all income records for a user within a specific date range.
+func (s *IncomeService) GetIncomesByDateRange(userID uint, startDate, endDate time.Time) ([]models.Income, error) {
 	if s.DB == nil {
 		return nil, fmt.Errorf("database connection not initialized in IncomeService")
 	}
 	var incomes []models.Income
-	result := s.DB.Where("date BETWEEN ? AND ?", startDate, endDate). // Removed userID condition
-										Order("date desc, created_at desc").
-										Find(&incomes)
+	result := s.DB.Where("user_id = ? AND date BETWEEN ? AND ?", userID, startDate.Format("2006-01-02"), endDate.Format("2006-01-02")).
+		Order("date desc, created_at desc").
+		Find(&incomes)
 
 	if result.Error != nil {
-		log.Printf("Error retrieving incomes by date range: %v", result.Error)
+		log.Printf("Error retrieving incomes by date range for user %d: %v", userID, result.Error)
 		return nil, fmt.Errorf("could not retrieve incomes by date range: %w", result.Error)
 	}
 	if incomes == nil {
 		return []models.Income{}, nil
 	}
+	if err := s.attachTags(incomes); err != nil {
+		return nil, err
+	}
 	return incomes, nil
 }
 
-// UpdateIncome updates an existing income record.
-func (s *IncomeService) UpdateIncome(incomeID uint, updateData *models.IncomeUpdateRequest) (*models.Income, error) {
+// attachTags fills in the tags on each of the incomes.
+func (s *IncomeService) attachTags(incomes []models.Income) error {
+	ids := make([]uint, len(incomes))
+	for i := range incomes {
+		ids[i] = incomes[i].ID
+	}
+	tags, err := loadTags(s.DB, "income", ids)
+	if err != nil {
+		return err
+	}
+	for i := range incomes {
+		incomes[i].Tags = tags[incomes[i].ID]
+	}
+	return nil
+}
+
+// UpdateIncome updates an existing income record owned by the given user.
+// When versions are given, the update is only made while the income record was last changed at one
+// of them; otherwise a VersionConflictError is returned.
+func (s *IncomeService) UpdateIncome(userID uint, incomeID uint, updateData *models.IncomeUpdateRequest, versions ...time.Time) (*models.Income, error) {
 	if s.DB == nil {
 		return nil, fmt.Errorf("database connection not initialized in IncomeService")
 	}
 
-	existingIncome, err := s.GetIncomeByID(incomeID) // Removed userID
+	existingIncome, err := s.GetIncomeByID(userID, incomeID)
 	if err != nil {
 		return nil, err
 	}
+	if err := checkVersion(existingIncome.UpdatedAt, "income record", versions); err != nil {
+		return nil, err
+	}
+
+	var tags []string
+	if updateData.Tags != nil {
+		if tags, err = normalizeTags(*updateData.Tags); err != nil {
+			return nil, fmt.Errorf("could not update income: %w", err)
+		}
+	}
 
 	updates := make(map[string]interface{})
 	if updateData.Amount != nil {
 		updates["amount"] = *updateData.Amount
 	}
-	if updateData.Category != nil {
-		updates["category"] = *updateData.Category
+	if updateData.Currency != nil {
+		updates["currency"] = *updateData.Currency
+	}
+	if updateData.AccountID != nil || updateData.Currency != nil {
+		// The record's currency must keep matching its account, whichever of the two changes.
+		accountID := existingIncome.AccountID
+		if updateData.AccountID != nil {
+			accountID = updateData.AccountID
+			if *accountID == 0 {
+				accountID = nil
+			}
+			updates["account_id"] = accountID
+		}
+		currency := existingIncome.Currency
+		if updateData.Currency != nil {
+			currency = *updateData.Currency
+		}
+		if accountID != nil {
+			if _, err := resolveAccountCurrency(s.DB, userID, accountID, currency); err != nil {
+				return nil, err
+			}
+		}
+	}
+	categoryCleared := (updateData.Category != nil && strings.TrimSpace(*updateData.Category) == "") || (updateData.CategoryID != nil && *updateData.CategoryID == 0)
+	if (updateData.Category != nil || updateData.CategoryID != nil) && !categoryCleared {
+		var name string
+		if updateData.Category != nil {
+			name = *updateData.Category
+		}
+		category, err := categoryFor(s.DB, userID, "income", updateData.CategoryID, name)
+		if err != nil {
+			return nil, fmt.Errorf("could not update income: %w", err)
+		}
+		updates["category"] = category.Name
+		updates["category_id"] = category.ID
+		updates["category_rule_id"] = nil // Chosen by hand, so rules leave it alone from now on
+	} else if categoryCleared || updateData.Amount != nil || updateData.Currency != nil || updateData.Note != nil {
+		// A cleared category, or one a rule chose from what is changing, is chosen by the rules again.
+		entry := categorizedEntry{Amount: existingIncome.Amount, Currency: existingIncome.Currency, Category: existingIncome.Category, CategoryRuleID: existingIncome.CategoryRuleID, Note: existingIncome.Note}
+		if updateData.Amount != nil {
+			entry.Amount = *updateData.Amount
+		}
+		if updateData.Currency != nil {
+			entry.Currency = *updateData.Currency
+		}
+		if updateData.Note != nil {
+			entry.Note = *updateData.Note
+		}
+		if categoryCleared {
+			fallback, err := resolveCategory(s.DB, userID, "income", defaultCategory)
+			if err != nil {
+				return nil, fmt.Errorf("could not update income: %w", err)
+			}
+			entry.Category, entry.CategoryRuleID = fallback.Name, nil
+			updates["category"] = fallback.Name
+			updates["category_id"] = fallback.ID
+			updates["category_rule_id"] = nil
+		}
+		recategorized, err := recategorizeOnUpdate(s.DB, userID, "income", entry)
+		if err != nil {
+			return nil, fmt.Errorf("could not update income: %w", err)
+		}
+		for column, value := range recategorized {
+			updates[column] = value
+		}
 	}
 	if updateData.Date != nil {
 		updates["date"] = *updateData.Date
@@ -123,33 +260,124 @@ func (s *IncomeService) UpdateIncome(incomeID uint, updateData *models.IncomeUpd
 	if len(updates) == 0 {
 		// No actual fields to update, just return the existing record
 		// or you could return an error indicating no update data was provided.
-		return existingIncome, nil
+		if updateData.Tags == nil {
+			return existingIncome, nil
+		}
+		// Only the tags changed; the record is still touched so its ETag changes with them.
+		updates["updated_at"] = time.Now()
 	}
 
-	result := s.DB.Model(&existingIncome).Where("id = ?", incomeID).Updates(updates) // Removed userID condition
-	if result.Error != nil {
-		log.Printf("Error updating income %d: %v", incomeID, result.Error)
-		return nil, fmt.Errorf("could not update income: %w", result.Error)
+	// The tags and the record are written together, so a failed update leaves the old tags in place.
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		if updateData.Tags != nil {
+			if err := setTags(tx, userID, "income", incomeID, tags); err != nil {
+				log.Printf("Error tagging income %d: %v", incomeID, err)
+				return err
+			}
+		}
+		result := whereVersion(tx.Model(&existingIncome).Where("id = ? AND user_id = ?", incomeID, userID), existingIncome.UpdatedAt, versions).Updates(updates)
+		if result.Error != nil {
+			log.Printf("Error updating income %d: %v", incomeID, result.Error)
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			if len(versions) > 0 {
+				return versionConflict(tx, &models.Income{}, "income record", userID, incomeID)
+			}
+			return fmt.Errorf("income record not found during update (or no changes made)")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not update income: %w", err)
 	}
-	if result.RowsAffected == 0 {
-		return nil, fmt.Errorf("income record not found during update (or no changes made)")
+	if updateData.Tags != nil {
+		existingIncome.Tags = tags
 	}
-
 	return existingIncome, nil
 }
 
-// DeleteIncome deletes an income record.
-func (s *IncomeService) DeleteIncome(incomeID uint) error {
+// DeleteIncome deletes an income record owned by the given user.
+// When versions are given, it is only deleted while it was last changed at one of them; otherwise a
+// VersionConflictError is returned.
+func (s *IncomeService) DeleteIncome(userID uint, incomeID uint, versions ...time.Time) error {
 	if s.DB == nil {
 		return fmt.Errorf("database connection not initialized in IncomeService")
 	}
-	result := s.DB.Where("id = ?", incomeID).Delete(&models.Income{}) // Removed userID condition
+	query := s.DB.Where("id = ? AND user_id = ?", incomeID, userID)
+	if len(versions) > 0 {
+		updatedAt, err := recordVersion(s.DB, &models.Income{}, "income record", userID, incomeID)
+		if err != nil {
+			return err
+		}
+		if err := checkVersion(updatedAt, "income record", versions); err != nil {
+			return err
+		}
+		query = whereVersion(query, updatedAt, versions)
+	}
+	result := query.Delete(&models.Income{})
 	if result.Error != nil {
 		log.Printf("Error deleting income %d: %v", incomeID, result.Error)
 		return fmt.Errorf("could not delete income: %w", result.Error)
 	}
 	if result.RowsAffected == 0 {
+		if len(versions) > 0 {
+			return versionConflict(s.DB, &models.Income{}, "income record", userID, incomeID)
+		}
 		return fmt.Errorf("income record not found, no rows deleted")
 	}
 	return nil
 }
+
+// BulkCreateIncomes creates the incomes in one transaction: if any of them cannot be created, none is.
+func (s *IncomeService) BulkCreateIncomes(incomes []models.Income) (*models.BulkResult[models.Income], error) {
+	if s.DB == nil {
+		return nil, fmt.Errorf("database connection not initialized in IncomeService")
+	}
+	return runBulk(s.DB, models.BulkStatusCreated, nil, len(incomes), func(tx *gorm.DB, i int) (*models.Income, []time.Time, error) {
+		if err := (&IncomeService{DB: tx}).CreateIncome(&incomes[i]); err != nil {
+			return nil, nil, err
+		}
+		return &incomes[i], []time.Time{incomes[i].Date.Time}, nil
+	})
+}
+
+// BulkUpdateIncomes updates income records owned by the given user in one transaction: if any of them
+// cannot be updated, none is.
+func (s *IncomeService) BulkUpdateIncomes(userID uint, items []models.IncomeBulkUpdateItem) (*models.BulkResult[models.Income], error) {
+	if s.DB == nil {
+		return nil, fmt.Errorf("database connection not initialized in IncomeService")
+	}
+	ids := bulkUpdateIDs(items, func(item models.IncomeBulkUpdateItem) uint { return item.ID })
+	return runBulk(s.DB, models.BulkStatusUpdated, ids, len(items), func(tx *gorm.DB, i int) (*models.Income, []time.Time, error) {
+		service := &IncomeService{DB: tx}
+		existing, err := service.GetIncomeByID(userID, items[i].ID)
+		if err != nil {
+			return nil, nil, err
+		}
+		updated, err := service.UpdateIncome(userID, items[i].ID, &items[i].IncomeUpdateRequest)
+		if err != nil {
+			return nil, nil, err
+		}
+		return updated, []time.Time{existing.Date.Time, updated.Date.Time}, nil
+	})
+}
+
+// BulkDeleteIncomes deletes income records owned by the given user in one transaction: if any of them
+// cannot be deleted, none is.
+func (s *IncomeService) BulkDeleteIncomes(userID uint, incomeIDs []uint) (*models.BulkResult[models.Income], error) {
+	if s.DB == nil {
+		return nil, fmt.Errorf("database connection not initialized in IncomeService")
+	}
+	return runBulk(s.DB, models.BulkStatusDeleted, incomeIDs, len(incomeIDs), func(tx *gorm.DB, i int) (*models.Income, []time.Time, error) {
+		service := &IncomeService{DB: tx}
+		existing, err := service.GetIncomeByID(userID, incomeIDs[i])
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := service.DeleteIncome(userID, incomeIDs[i]); err != nil {
+			return nil, nil, err
+		}
+		return nil, []time.Time{existing.Date.Time}, nil
+	})
+}
@@ -0,0 +1,144 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/zayyadi/finance-tracker/internal/models"
+	"github.com/zayyadi/finance-tracker/internal/types"
+	"gorm.io/gorm"
+)
+
+// listColumns maps the list filters and sort fields of a record type onto its table. A filter whose
+// column is empty is not supported for the type.
+type listColumns struct {
+	recordType string
+	date       string
+	amount     string
+	category   string
+	note       string
+	splits     bool              // Whether the category filter also matches the split lines of expenses
+	sortFields map[string]string // Sort field, as named in the JSON of the records, to column
+	sortField  string            // Default sort field
+	sortOrder  string            // Default order of the default sort field
+}
+
+var incomeListColumns = listColumns{
+	recordType: "income", date: "date", amount: "amount", category: "category", note: "note",
+	sortFields: map[string]string{"date": "date", "amount": "amount", "category": "category", "created_at": "created_at"},
+	sortField:  "date", sortOrder: "desc",
+}
+
+var expenseListColumns = listColumns{
+	recordType: "expense", date: "date", amount: "amount", category: "category", note: "note", splits: true,
+	sortFields: map[string]string{"date": "date", "amount": "amount", "category": "category", "created_at": "created_at"},
+	sortField:  "date", sortOrder: "desc",
+}
+
+var debtListColumns = listColumns{
+	recordType: "debt", date: "due_date", amount: "amount", note: "description",
+	sortFields: map[string]string{"due_date": "due_date", "amount": "amount", "debtor_name": "debtor_name", "status": "status", "created_at": "created_at"},
+	sortField:  "due_date", sortOrder: "asc",
+}
+
+var savingsListColumns = listColumns{
+	recordType: "savings", date: "target_date", amount: "goal_amount", note: "notes",
+	sortFields: map[string]string{"target_date": "target_date", "goal_amount": "goal_amount", "current_amount": "current_amount", "goal_name": "goal_name", "created_at": "created_at"},
+	sortField:  "target_date", sortOrder: "asc",
+}
+
+// parseDateBound parses the start or end date of a date range filter.
+func parseDateBound(param, which string) (string, error) {
+	date, err := time.Parse("2006-01-02", param)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s date format: %w", which, err)
+	}
+	return date.Format("2006-01-02"), nil
+}
+
+// parseAmountBound parses the min_amount or max_amount filter.
+func parseAmountBound(param, name string) (types.Money, error) {
+	amount, err := types.ParseMoney(param)
+	if err != nil || amount.IsNegative() {
+		return 0, fmt.Errorf("invalid filter: %s must be a non-negative amount, got %q", name, param)
+	}
+	return amount, nil
+}
+
+// applyListFilter limits query, on the table described by columns, to the user's records matching
+// filter and orders it. Ties are broken by creation, newest first when the order is descending.
+func applyListFilter(query *gorm.DB, userID uint, columns listColumns, filter models.ListFilter) (*gorm.DB, error) {
+	if filter.StartDate != "" {
+		start, err := parseDateBound(filter.StartDate, "start")
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where(columns.date+" >= ?", start)
+	}
+	if filter.EndDate != "" {
+		end, err := parseDateBound(filter.EndDate, "end")
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where(columns.date+" <= ?", end)
+	}
+	if len(filter.Categories) > 0 {
+		if columns.category == "" {
+			return nil, fmt.Errorf("invalid filter: %s records have no category", columns.recordType)
+		}
+		lowered := make([]string, len(filter.Categories))
+		for i, category := range filter.Categories {
+			lowered[i] = strings.ToLower(strings.TrimSpace(category))
+		}
+		if columns.splits {
+			query = query.Where("(LOWER("+columns.category+") IN ? OR id IN (SELECT expense_id FROM expense_splits WHERE LOWER(category) IN ?))", lowered, lowered)
+		} else {
+			query = query.Where("LOWER("+columns.category+") IN ?", lowered)
+		}
+	}
+	var minAmount, maxAmount types.Money
+	var err error
+	if filter.MinAmount != "" {
+		if minAmount, err = parseAmountBound(filter.MinAmount, "min_amount"); err != nil {
+			return nil, err
+		}
+		query = query.Where(columns.amount+" >= ?", minAmount)
+	}
+	if filter.MaxAmount != "" {
+		if maxAmount, err = parseAmountBound(filter.MaxAmount, "max_amount"); err != nil {
+			return nil, err
+		}
+		if filter.MinAmount != "" && minAmount > maxAmount {
+			return nil, fmt.Errorf("invalid filter: min_amount is larger than max_amount")
+		}
+		query = query.Where(columns.amount+" <= ?", maxAmount)
+	}
+	if note := strings.TrimSpace(filter.Note); note != "" {
+		query = query.Where("LOWER("+columns.note+`) LIKE ? ESCAPE '\'`, "%"+escapeLike(strings.ToLower(note))+"%")
+	}
+	query = whereTagged(query, userID, columns.recordType, filter.Tags)
+
+	field, order := columns.sortField, columns.sortOrder
+	if filter.Sort != "" {
+		field, order = filter.Sort, "asc"
+	}
+	column, ok := columns.sortFields[field]
+	if !ok {
+		fields := make([]string, 0, len(columns.sortFields))
+		for name := range columns.sortFields {
+			fields = append(fields, name)
+		}
+		sort.Strings(fields)
+		return nil, fmt.Errorf("invalid filter: %s records cannot be sorted by %q; use one of %s", columns.recordType, field, strings.Join(fields, ", "))
+	}
+	switch filter.Order {
+	case "":
+	case "asc", "desc":
+		order = filter.Order
+	default:
+		return nil, fmt.Errorf("invalid filter: order must be asc or desc")
+	}
+	return query.Order(column + " " + order + ", created_at " + order + ", id " + order), nil
+}
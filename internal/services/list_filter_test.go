@@ -0,0 +1,81 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zayyadi/finance-tracker/internal/database"
+	"github.com/zayyadi/finance-tracker/internal/models"
+	"github.com/zayyadi/finance-tracker/internal/types"
+)
+
+func TestListFilter_FiltersAndSorts(t *testing.T) {
+	db := setupAccountTestDB(t)
+	require.NoError(t, db.AutoMigrate(&models.Debt{}, &models.Savings{}))
+	expenseService := NewExpenseService(db)
+	day := func(d int) database.CustomDate {
+		return database.CustomDate{Time: time.Date(2024, time.May, d, 0, 0, 0, 0, time.UTC)}
+	}
+	for _, expense := range []*models.Expense{
+		{UserID: testUserID, Amount: types.Money(1200), Category: "Food", Date: day(2), Note: "Bakery"},
+		{UserID: testUserID, Amount: types.Money(8000), Category: "Travel", Date: day(5), Note: "Train to the coast"},
+		{UserID: testUserID, Amount: types.Money(3000), Category: "Home", Date: day(9), Note: "Light bulbs"},
+		{UserID: testUserID, Amount: types.Money(10000), Category: "Shopping", Date: day(12), Splits: []models.ExpenseSplit{
+			{Amount: types.Money(6000), Category: "Food"},
+			{Amount: types.Money(4000), Category: "Home"},
+		}},
+	} {
+		require.NoError(t, expenseService.CreateExpense(expense))
+	}
+	amounts := func(filter models.ListFilter) []types.Money {
+		expenses, err := expenseService.GetExpenses(testUserID, 1, 10, filter)
+		require.NoError(t, err)
+		result := make([]types.Money, len(expenses.Items))
+		for i, expense := range expenses.Items {
+			result[i] = expense.Amount
+		}
+		return result
+	}
+
+	assert.Equal(t, []types.Money{10000, 3000, 8000, 1200}, amounts(models.ListFilter{}), "Newest first by default")
+	assert.Equal(t, []types.Money{10000, 3000}, amounts(models.ListFilter{StartDate: "2024-05-06"}), "Date ranges may be open-ended")
+	assert.Equal(t, []types.Money{8000, 1200}, amounts(models.ListFilter{EndDate: "2024-05-06"}))
+	assert.Equal(t, []types.Money{10000, 3000, 1200}, amounts(models.ListFilter{Categories: []string{"food", "HOME"}}), "Split lines count towards their categories")
+	assert.Equal(t, []types.Money{3000, 8000}, amounts(models.ListFilter{MinAmount: "30", MaxAmount: "80.00"}))
+	assert.Equal(t, []types.Money{8000}, amounts(models.ListFilter{Note: "COAST"}))
+	assert.Equal(t, []types.Money{1200, 3000, 8000, 10000}, amounts(models.ListFilter{Sort: "amount"}))
+	assert.Equal(t, []types.Money{10000, 8000, 3000, 1200}, amounts(models.ListFilter{Sort: "amount", Order: "desc"}))
+	assert.Equal(t, []types.Money{1200, 8000, 3000, 10000}, amounts(models.ListFilter{Order: "asc"}), "The order applies to the default sort field")
+
+	_, err := expenseService.GetExpenses(testUserID, 1, 10, models.ListFilter{Sort: "colour"})
+	assert.ErrorContains(t, err, "invalid filter")
+	_, err = expenseService.GetExpenses(testUserID, 1, 10, models.ListFilter{MinAmount: "-5"})
+	assert.ErrorContains(t, err, "invalid filter")
+
+	// Debts and savings goals filter on their own columns.
+	debtService := NewDebtService(db)
+	require.NoError(t, debtService.CreateDebt(&models.Debt{UserID: testUserID, DebtorName: "Ana", Description: "Concert tickets", Amount: types.Money(9000), DueDate: day(20), Status: "Pending"}))
+	require.NoError(t, debtService.CreateDebt(&models.Debt{UserID: testUserID, DebtorName: "Ben", Amount: types.Money(2000), DueDate: day(10), Status: "Pending"}))
+	debts, err := debtService.GetDebts(testUserID, 1, 10, "", models.ListFilter{Sort: "debtor_name", Order: "desc"})
+	require.NoError(t, err)
+	require.Len(t, debts.Items, 2)
+	assert.Equal(t, "Ben", debts.Items[0].DebtorName)
+	debts, err = debtService.GetDebts(testUserID, 1, 10, "", models.ListFilter{Note: "concert", StartDate: "2024-05-15"})
+	require.NoError(t, err)
+	require.Len(t, debts.Items, 1)
+	assert.Equal(t, "Ana", debts.Items[0].DebtorName)
+	_, err = debtService.GetDebts(testUserID, 1, 10, "", models.ListFilter{Categories: []string{"Food"}})
+	assert.ErrorContains(t, err, "invalid filter")
+
+	savingsService := NewSavingsService(db)
+	require.NoError(t, savingsService.CreateSavings(&models.Savings{UserID: testUserID, GoalName: "Bike", GoalAmount: types.Money(60000)}))
+	require.NoError(t, savingsService.CreateSavings(&models.Savings{UserID: testUserID, GoalName: "Laptop", GoalAmount: types.Money(150000)}))
+	goals, err := savingsService.GetSavings(testUserID, 1, 10, models.ListFilter{MinAmount: "1000"})
+	require.NoError(t, err)
+	require.Len(t, goals.Items, 1)
+	assert.Equal(t, "Laptop", goals.Items[0].GoalName)
+	_, err = savingsService.GetSavings(testUserID, 1, 10, models.ListFilter{Sort: "amount"})
+	assert.ErrorContains(t, err, "goal_amount")
+}
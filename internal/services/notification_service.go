@@ -36,8 +36,7 @@ func (s *NotificationService) CheckDueDatesAndGoals() {
 
 	// Check for upcoming debts
 	var upcomingDebts []models.Debt
-	// Removed UserID from query
-	err := s.DB.Where("status = ? AND due_date BETWEEN ? AND ?", "Pending", now, sevenDaysFromNow).
+	err := s.DB.Where("status = ? AND due_date BETWEEN ? AND ?", "Pending", now.Format("2006-01-02"), sevenDaysFromNow.Format("2006-01-02")).
 		Find(&upcomingDebts).Error
 	if err != nil {
 		log.Printf("NotificationService: Error fetching upcoming debts: %v", err)
@@ -45,9 +44,8 @@ func (s *NotificationService) CheckDueDatesAndGoals() {
 		if len(upcomingDebts) > 0 {
 			log.Printf("NotificationService: Found %d upcoming debt(s).", len(upcomingDebts))
 			for _, debt := range upcomingDebts {
-				// Removed UserID from log message
-				log.Printf("Reminder: Debt for '%s' of amount %.2f is due on %s.",
-					debt.DebtorName, debt.Amount, debt.DueDate.Format("2006-01-02"))
+				log.Printf("Reminder (user %d): Debt for '%s' of amount %s %s is due on %s.",
+					debt.UserID, debt.DebtorName, debt.Amount, debt.Currency, debt.DueDate.Format("2006-01-02"))
 			}
 		} else {
 			log.Println("NotificationService: No upcoming debts found in the next 7 days.")
@@ -56,8 +54,7 @@ func (s *NotificationService) CheckDueDatesAndGoals() {
 
 	// Check for approaching savings goals
 	var upcomingSavings []models.Savings
-	// Removed UserID from query
-	err = s.DB.Where("target_date IS NOT NULL AND target_date BETWEEN ? AND ? AND current_amount < goal_amount", now, sevenDaysFromNow).
+	err = s.DB.Where("target_date IS NOT NULL AND target_date BETWEEN ? AND ? AND current_amount < goal_amount", now.Format("2006-01-02"), sevenDaysFromNow.Format("2006-01-02")).
 		Find(&upcomingSavings).Error
 	if err != nil {
 		log.Printf("NotificationService: Error fetching upcoming savings goals: %v", err)
@@ -65,9 +62,8 @@ func (s *NotificationService) CheckDueDatesAndGoals() {
 		if len(upcomingSavings) > 0 {
 			log.Printf("NotificationService: Found %d approaching savings goal(s).", len(upcomingSavings))
 			for _, sg := range upcomingSavings {
-				// Removed UserID from log message
-				log.Printf("Reminder: Savings goal '%s' (Target: %.2f, Current: %.2f) is approaching its target date %s.",
-					sg.GoalName, sg.GoalAmount, sg.CurrentAmount, sg.TargetDate.Format("2006-01-02"))
+				log.Printf("Reminder (user %d): Savings goal '%s' (Target: %s %s, Current: %s) is approaching its target date %s.",
+					sg.UserID, sg.GoalName, sg.GoalAmount, sg.Currency, sg.CurrentAmount, sg.TargetDate.Format("2006-01-02"))
 			}
 		} else {
 			log.Println("NotificationService: No savings goals approaching target date in the next 7 days or they are already met.")
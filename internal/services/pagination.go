@@ -0,0 +1,37 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/zayyadi/finance-tracker/internal/models"
+	"gorm.io/gorm"
+)
+
+// paginate loads one page, counting from 1, of the rows selected by query and counts the rows on all
+// pages. limit is capped at models.MaxPageLimit. query must be scoped with Model, Where and Order.
+func paginate[T any](query *gorm.DB, page, limit int) (*models.Page[T], error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 10
+	}
+	if limit > models.MaxPageLimit {
+		limit = models.MaxPageLimit
+	}
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("could not count records: %w", err)
+	}
+	items := []T{}
+	if err := query.Offset((page - 1) * limit).Limit(limit).Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return &models.Page[T]{
+		Items:   items,
+		Total:   total,
+		Page:    page,
+		Limit:   limit,
+		HasMore: int64(page)*int64(limit) < total,
+	}, nil
+}
@@ -0,0 +1,348 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/zayyadi/finance-tracker/internal/database"
+	"github.com/zayyadi/finance-tracker/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// maxOccurrencesPerRun bounds how many rows one template may generate in a single run, so a
+// template started far in the past catches up over several runs instead of stalling one.
+const maxOccurrencesPerRun = 400
+
+// RecurringService manages recurring transaction templates and materializes their occurrences.
+type RecurringService struct {
+	DB             *gorm.DB
+	summaryService *SummaryService
+}
+
+// NewRecurringService creates a new RecurringService. summaryService is used to invalidate
+// summaries for generated rows; it may be nil.
+func NewRecurringService(db *gorm.DB, summaryService *SummaryService) *RecurringService {
+	if db == nil {
+		log.Println("Warning: NewRecurringService called with nil DB, attempting to use global GetDB()")
+		db = database.GetDB()
+	}
+	return &RecurringService{DB: db, summaryService: summaryService}
+}
+
+// CreateRecurring validates and inserts a recurring template. Its first occurrence is the first date
+// on or after the start date that matches the schedule.
+func (s *RecurringService) CreateRecurring(userID uint, req *models.RecurringCreateRequest) (*models.RecurringTransaction, error) {
+	if s.DB == nil {
+		return nil, fmt.Errorf("database connection not initialized in RecurringService")
+	}
+	if req.DayOfMonth != nil && req.Frequency != "monthly" && req.Frequency != "yearly" {
+		return nil, fmt.Errorf("day_of_month only applies to monthly and yearly schedules")
+	}
+	if req.EndDate != nil && req.EndDate.Before(req.StartDate.Time) {
+		return nil, fmt.Errorf("end_date must not be before start_date")
+	}
+
+	accountID := req.AccountID
+	if accountID != nil && *accountID == 0 {
+		accountID = nil
+	}
+	currency, err := resolveAccountCurrency(s.DB, userID, accountID, req.Currency)
+	if err != nil {
+		return nil, fmt.Errorf("could not create recurring transaction: %w", err)
+	}
+
+	interval := req.Interval
+	if interval == 0 {
+		interval = 1
+	}
+	recurring := &models.RecurringTransaction{
+		UserID:     userID,
+		Type:       req.Type,
+		Amount:     req.Amount,
+		Currency:   currency,
+		AccountID:  accountID,
+		Category:   req.Category,
+		Note:       req.Note,
+		Frequency:  req.Frequency,
+		Interval:   interval,
+		DayOfMonth: req.DayOfMonth,
+		StartDate:  database.CustomDate{Time: dateOnly(req.StartDate.Time)},
+		EndDate:    req.EndDate,
+		Active:     true,
+	}
+	recurring.NextRunDate = database.CustomDate{Time: firstOccurrence(recurring)}
+	if recurring.EndDate != nil && recurring.NextRunDate.After(recurring.EndDate.Time) {
+		return nil, fmt.Errorf("the schedule has no occurrence between start_date and end_date")
+	}
+
+	if err := s.DB.Create(recurring).Error; err != nil {
+		log.Printf("Error creating recurring transaction for user %d: %v", userID, err)
+		return nil, fmt.Errorf("could not create recurring transaction: %w", err)
+	}
+	return recurring, nil
+}
+
+// GetRecurringByID retrieves a specific recurring template by its ID, scoped to the given user.
+func (s *RecurringService) GetRecurringByID(userID uint, recurringID uint) (*models.RecurringTransaction, error) {
+	if s.DB == nil {
+		return nil, fmt.Errorf("database connection not initialized in RecurringService")
+	}
+	var recurring models.RecurringTransaction
+	if err := s.DB.Where("id = ? AND user_id = ?", recurringID, userID).First(&recurring).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("recurring transaction not found")
+		}
+		log.Printf("Error retrieving recurring transaction %d for user %d: %v", recurringID, userID, err)
+		return nil, fmt.Errorf("could not retrieve recurring transaction: %w", err)
+	}
+	return &recurring, nil
+}
+
+// GetRecurring retrieves a user's recurring templates with pagination, soonest occurrence first.
+func (s *RecurringService) GetRecurring(userID uint, offset int, limit int) ([]models.RecurringTransaction, error) {
+	if s.DB == nil {
+		return nil, fmt.Errorf("database connection not initialized in RecurringService")
+	}
+	var recurring []models.RecurringTransaction
+	if err := s.DB.Where("user_id = ?", userID).Order("active desc, next_run_date, id").Offset(offset).Limit(limit).Find(&recurring).Error; err != nil {
+		log.Printf("Error retrieving recurring transactions for user %d: %v", userID, err)
+		return nil, fmt.Errorf("could not retrieve recurring transactions: %w", err)
+	}
+	if recurring == nil {
+		return []models.RecurringTransaction{}, nil
+	}
+	return recurring, nil
+}
+
+// UpdateRecurring updates an existing recurring template owned by the given user. Re-activating a paused
+// template moves its next run date to its first occurrence from today on, so the occurrences that fell
+// due while it was paused are not generated.
+func (s *RecurringService) UpdateRecurring(userID uint, recurringID uint, updateData *models.RecurringUpdateRequest) (*models.RecurringTransaction, error) {
+	if s.DB == nil {
+		return nil, fmt.Errorf("database connection not initialized in RecurringService")
+	}
+	existing, err := s.GetRecurringByID(userID, recurringID)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := make(map[string]interface{})
+	if updateData.Amount != nil {
+		updates["amount"] = *updateData.Amount
+	}
+	if updateData.Category != nil {
+		updates["category"] = *updateData.Category
+	}
+	if updateData.Note != nil {
+		updates["note"] = *updateData.Note
+	}
+	if updateData.EndDate != nil {
+		if updateData.EndDate.Before(existing.StartDate.Time) {
+			return nil, fmt.Errorf("end_date must not be before start_date")
+		}
+		updates["end_date"] = *updateData.EndDate
+	}
+	if updateData.Active != nil {
+		updates["active"] = *updateData.Active
+		if *updateData.Active && !existing.Active {
+			// A resumed template carries on from today: what fell due while it was paused is skipped.
+			updates["next_run_date"] = database.CustomDate{Time: occurrenceOnOrAfter(existing, dateOnly(time.Now().UTC()))}
+		}
+	}
+	if len(updates) == 0 {
+		return existing, nil
+	}
+
+	if err := s.DB.Model(existing).Where("id = ? AND user_id = ?", recurringID, userID).Updates(updates).Error; err != nil {
+		log.Printf("Error updating recurring transaction %d: %v", recurringID, err)
+		return nil, fmt.Errorf("could not update recurring transaction: %w", err)
+	}
+	return s.GetRecurringByID(userID, recurringID)
+}
+
+// DeleteRecurring deletes a recurring template. Rows it already generated are kept.
+func (s *RecurringService) DeleteRecurring(userID uint, recurringID uint) error {
+	if s.DB == nil {
+		return fmt.Errorf("database connection not initialized in RecurringService")
+	}
+	result := s.DB.Where("id = ? AND user_id = ?", recurringID, userID).Delete(&models.RecurringTransaction{})
+	if result.Error != nil {
+		log.Printf("Error deleting recurring transaction %d: %v", recurringID, result.Error)
+		return fmt.Errorf("could not delete recurring transaction: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("recurring transaction not found, no rows deleted")
+	}
+	return nil
+}
+
+// MaterializeDue generates the Income and Expense rows of every active template whose occurrences
+// fall on or before asOf, for all users, and returns how many rows were created. It is safe to run
+// repeatedly or concurrently: each occurrence is unique per template and date, so one that already
+// exists is skipped rather than duplicated.
+func (s *RecurringService) MaterializeDue(asOf time.Time) (int, error) {
+	if s.DB == nil {
+		return 0, fmt.Errorf("database connection not initialized in RecurringService")
+	}
+	asOf = dateOnly(asOf)
+
+	var due []models.RecurringTransaction
+	if err := s.DB.Where("active = ? AND next_run_date <= ?", true, asOf.Format("2006-01-02")).Find(&due).Error; err != nil {
+		return 0, fmt.Errorf("could not retrieve due recurring transactions: %w", err)
+	}
+
+	created := 0
+	var firstErr error
+	for i := range due {
+		dates, err := s.materializeTemplate(&due[i], asOf)
+		if err != nil {
+			log.Printf("Error materializing recurring transaction %d for user %d: %v", due[i].ID, due[i].UserID, err)
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		created += len(dates)
+		invalidateSummariesForDates(s.summaryService, due[i].UserID, dates)
+	}
+	return created, firstErr
+}
+
+// materializeTemplate creates the template's due occurrences and advances its next run date in one
+// transaction. It returns the dates of the rows actually created.
+func (s *RecurringService) materializeTemplate(recurring *models.RecurringTransaction, asOf time.Time) ([]time.Time, error) {
+	var created []time.Time
+	err := withAuditSource(s.DB, auditSourceRecurring).Transaction(func(tx *gorm.DB) error {
+		next := dateOnly(recurring.NextRunDate.Time)
+		active := true
+		for n := 0; n < maxOccurrencesPerRun && !next.After(asOf); n++ {
+			if recurring.EndDate != nil && next.After(dateOnly(recurring.EndDate.Time)) {
+				break
+			}
+			inserted, err := insertOccurrence(tx, recurring, next)
+			if err != nil {
+				return err
+			}
+			if inserted {
+				created = append(created, next)
+			}
+			next = nextOccurrence(recurring, next)
+		}
+		if recurring.EndDate != nil && next.After(dateOnly(recurring.EndDate.Time)) {
+			active = false
+		}
+
+		// Guard on the old next_run_date so a concurrent run that got here first is not rewound.
+		return tx.Model(&models.RecurringTransaction{}).
+			Where("id = ? AND next_run_date = ?", recurring.ID, recurring.NextRunDate).
+			Updates(map[string]interface{}{"next_run_date": database.CustomDate{Time: next}, "active": active}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// insertOccurrence creates the income or expense row for one occurrence, reporting false when it
+// already exists.
+func insertOccurrence(tx *gorm.DB, recurring *models.RecurringTransaction, date time.Time) (bool, error) {
+	recurringID := recurring.ID
+	if recurring.Type != "income" && recurring.Type != "expense" {
+		return false, fmt.Errorf("invalid recurring transaction type %q", recurring.Type)
+	}
+	category, err := resolveCategory(tx, recurring.UserID, recurring.Type, recurring.Category)
+	if err != nil {
+		return false, err
+	}
+	var row interface{}
+	if recurring.Type == "income" {
+		row = &models.Income{UserID: recurring.UserID, Amount: recurring.Amount, Currency: recurring.Currency, AccountID: recurring.AccountID, RecurringID: &recurringID, Category: category.Name, CategoryID: &category.ID, Date: database.CustomDate{Time: date}, Note: recurring.Note}
+	} else {
+		row = &models.Expense{UserID: recurring.UserID, Amount: recurring.Amount, Currency: recurring.Currency, AccountID: recurring.AccountID, RecurringID: &recurringID, Category: category.Name, CategoryID: &category.ID, Date: database.CustomDate{Time: date}, Note: recurring.Note}
+	}
+	result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(row)
+	if result.Error != nil {
+		return false, fmt.Errorf("could not create %s for %s: %w", recurring.Type, date.Format("2006-01-02"), result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// firstOccurrence returns the first scheduled date on or after the template's start date.
+func firstOccurrence(recurring *models.RecurringTransaction) time.Time {
+	start := dateOnly(recurring.StartDate.Time)
+	switch recurring.Frequency {
+	case "monthly":
+		candidate := dayInMonth(start.Year(), start.Month(), anchorDay(recurring))
+		if candidate.Before(start) {
+			next := start.AddDate(0, 0, 1-start.Day()).AddDate(0, 1, 0)
+			candidate = dayInMonth(next.Year(), next.Month(), anchorDay(recurring))
+		}
+		return candidate
+	case "yearly":
+		candidate := dayInMonth(start.Year(), start.Month(), anchorDay(recurring))
+		if candidate.Before(start) {
+			candidate = dayInMonth(start.Year()+1, start.Month(), anchorDay(recurring))
+		}
+		return candidate
+	default:
+		return start
+	}
+}
+
+// occurrenceOnOrAfter returns the template's first scheduled date on or after day, counting on from its
+// next run date.
+func occurrenceOnOrAfter(recurring *models.RecurringTransaction, day time.Time) time.Time {
+	next := dateOnly(recurring.NextRunDate.Time)
+	for next.Before(day) {
+		next = nextOccurrence(recurring, next)
+	}
+	return next
+}
+
+// nextOccurrence returns the scheduled date following current.
+func nextOccurrence(recurring *models.RecurringTransaction, current time.Time) time.Time {
+	interval := recurring.Interval
+	if interval < 1 {
+		interval = 1
+	}
+	switch recurring.Frequency {
+	case "daily":
+		return current.AddDate(0, 0, interval)
+	case "weekly":
+		return current.AddDate(0, 0, 7*interval)
+	case "monthly":
+		// Step from the first of the month so a clamped day (e.g. the 28th of February) does
+		// not drift: the anchor day is applied again in the target month.
+		month := time.Date(current.Year(), current.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, interval, 0)
+		return dayInMonth(month.Year(), month.Month(), anchorDay(recurring))
+	case "yearly":
+		return dayInMonth(current.Year()+interval, recurring.StartDate.Month(), anchorDay(recurring))
+	default:
+		return current.AddDate(0, 0, interval)
+	}
+}
+
+// anchorDay is the day of month occurrences fall on: DayOfMonth if set, else the start date's day.
+func anchorDay(recurring *models.RecurringTransaction) int {
+	if recurring.DayOfMonth != nil {
+		return *recurring.DayOfMonth
+	}
+	return recurring.StartDate.Day()
+}
+
+// dayInMonth returns the given day of a month, clamped to the month's last day; -1 means the last day.
+func dayInMonth(year int, month time.Month, day int) time.Time {
+	lastDay := time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	if day == -1 || day > lastDay {
+		day = lastDay
+	}
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// dateOnly truncates t to midnight UTC of its calendar date.
+func dateOnly(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,165 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zayyadi/finance-tracker/internal/database"
+	"github.com/zayyadi/finance-tracker/internal/models"
+	"github.com/zayyadi/finance-tracker/internal/types"
+)
+
+func recurringDate(year int, month time.Month, day int) database.CustomDate {
+	return database.CustomDate{Time: time.Date(year, month, day, 0, 0, 0, 0, time.UTC)}
+}
+
+func TestRecurringService_MonthlyClampsToMonthEnd(t *testing.T) {
+	db := setupAccountTestDB(t)
+	service := NewRecurringService(db, NewSummaryService(db))
+
+	recurring, err := service.CreateRecurring(testUserID, &models.RecurringCreateRequest{
+		Type: "expense", Amount: types.Money(120000), Category: "Rent", Frequency: "monthly",
+		StartDate: recurringDate(2024, time.January, 31),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "2024-01-31", recurring.NextRunDate.Format("2006-01-02"))
+	assert.Equal(t, "USD", recurring.Currency)
+
+	created, err := service.MaterializeDue(time.Date(2024, time.April, 30, 12, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, 4, created)
+
+	var expenses []models.Expense
+	require.NoError(t, db.Where("recurring_id = ?", recurring.ID).Order("date").Find(&expenses).Error)
+	var dates []string
+	for _, expense := range expenses {
+		dates = append(dates, expense.Date.Format("2006-01-02"))
+	}
+	assert.Equal(t, []string{"2024-01-31", "2024-02-29", "2024-03-31", "2024-04-30"}, dates, "The 31st clamps to shorter months without drifting")
+
+	stored, err := service.GetRecurringByID(testUserID, recurring.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "2024-05-31", stored.NextRunDate.Format("2006-01-02"))
+}
+
+func TestRecurringService_MaterializeIsIdempotent(t *testing.T) {
+	db := setupAccountTestDB(t)
+	service := NewRecurringService(db, nil)
+
+	recurring, err := service.CreateRecurring(testUserID, &models.RecurringCreateRequest{
+		Type: "income", Amount: types.Money(500000), Category: "Salary", Frequency: "weekly", Interval: 2,
+		StartDate: recurringDate(2024, time.May, 3),
+	})
+	require.NoError(t, err)
+
+	asOf := time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC)
+	created, err := service.MaterializeDue(asOf)
+	require.NoError(t, err)
+	assert.Equal(t, 3, created, "May 3, May 17 and May 31")
+
+	created, err = service.MaterializeDue(asOf)
+	require.NoError(t, err)
+	assert.Equal(t, 0, created, "A second run generates nothing new")
+
+	// Even if the next run date is rewound (e.g. a run that crashed before saving it), existing
+	// occurrences are skipped rather than duplicated.
+	require.NoError(t, db.Model(&models.RecurringTransaction{}).Where("id = ?", recurring.ID).Update("next_run_date", recurringDate(2024, time.May, 3)).Error)
+	created, err = service.MaterializeDue(asOf)
+	require.NoError(t, err)
+	assert.Equal(t, 0, created)
+
+	var count int64
+	require.NoError(t, db.Model(&models.Income{}).Where("recurring_id = ?", recurring.ID).Count(&count).Error)
+	assert.Equal(t, int64(3), count)
+}
+
+func TestRecurringService_EndDateDeactivatesTemplate(t *testing.T) {
+	db := setupAccountTestDB(t)
+	service := NewRecurringService(db, nil)
+
+	endDate := recurringDate(2024, time.May, 3)
+	recurring, err := service.CreateRecurring(testUserID, &models.RecurringCreateRequest{
+		Type: "expense", Amount: types.Money(1500), Category: "Parking", Frequency: "daily",
+		StartDate: recurringDate(2024, time.May, 1), EndDate: &endDate,
+	})
+	require.NoError(t, err)
+
+	created, err := service.MaterializeDue(time.Date(2024, time.May, 10, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, 3, created)
+
+	stored, err := service.GetRecurringByID(testUserID, recurring.ID)
+	require.NoError(t, err)
+	assert.False(t, stored.Active, "The template is deactivated once its end date has passed")
+
+	_, err = service.CreateRecurring(testUserID, &models.RecurringCreateRequest{
+		Type: "expense", Amount: types.Money(1500), Category: "Parking", Frequency: "monthly",
+		StartDate: recurringDate(2024, time.May, 2), EndDate: &endDate,
+		DayOfMonth: func() *int { d := 10; return &d }(),
+	})
+	assert.ErrorContains(t, err, "no occurrence between start_date and end_date")
+}
+
+func TestRecurringService_ResumeSkipsPausedOccurrences(t *testing.T) {
+	db := setupAccountTestDB(t)
+	service := NewRecurringService(db, nil)
+
+	recurring, err := service.CreateRecurring(testUserID, &models.RecurringCreateRequest{
+		Type: "expense", Amount: types.Money(1500), Category: "Parking", Frequency: "daily",
+		StartDate: recurringDate(2024, time.May, 1),
+	})
+	require.NoError(t, err)
+	created, err := service.MaterializeDue(time.Date(2024, time.May, 3, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, 3, created)
+
+	paused, resumed := false, true
+	_, err = service.UpdateRecurring(testUserID, recurring.ID, &models.RecurringUpdateRequest{Active: &paused})
+	require.NoError(t, err)
+	stored, err := service.UpdateRecurring(testUserID, recurring.ID, &models.RecurringUpdateRequest{Active: &resumed})
+	require.NoError(t, err)
+	today := dateOnly(time.Now().UTC())
+	assert.Equal(t, today.Format("2006-01-02"), stored.NextRunDate.Format("2006-01-02"), "A resumed template carries on from today")
+
+	created, err = service.MaterializeDue(today)
+	require.NoError(t, err)
+	assert.Equal(t, 1, created, "Occurrences that fell due while the template was paused are skipped")
+
+	stored, err = service.UpdateRecurring(testUserID, recurring.ID, &models.RecurringUpdateRequest{Active: &resumed})
+	require.NoError(t, err)
+	assert.Equal(t, today.AddDate(0, 0, 1).Format("2006-01-02"), stored.NextRunDate.Format("2006-01-02"), "Setting an active template active leaves its schedule alone")
+}
+
+func TestRecurringService_GeneratedRowsUpdateSummaryAndAccount(t *testing.T) {
+	db := setupAccountTestDB(t)
+	summaryService := NewSummaryService(db)
+	accountService := NewAccountService(db)
+	service := NewRecurringService(db, summaryService)
+	account := createTestAccount(t, accountService, "Checking", "USD", 0)
+
+	// Compute (and store) the summary before the rows exist, so a stale summary would show zero.
+	summary, err := summaryService.GetOrCreateFinancialSummary(testUserID, "monthly", time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC), "overall")
+	require.NoError(t, err)
+	assert.Equal(t, types.Money(0), summary.TotalExpenses)
+
+	_, err = service.CreateRecurring(testUserID, &models.RecurringCreateRequest{
+		Type: "expense", Amount: types.Money(999), AccountID: &account.ID, Category: "Streaming", Frequency: "monthly",
+		StartDate: recurringDate(2024, time.May, 15),
+	})
+	require.NoError(t, err)
+	_, err = service.MaterializeDue(time.Date(2024, time.May, 20, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+
+	summary, err = summaryService.GetOrCreateFinancialSummary(testUserID, "monthly", time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC), "overall")
+	require.NoError(t, err)
+	assert.Equal(t, types.Money(999), summary.TotalExpenses)
+
+	balance, err := accountService.GetAccountWithBalance(testUserID, account.ID)
+	require.NoError(t, err)
+	assert.Equal(t, types.Money(-999), balance.Balance)
+
+	err = accountService.DeleteAccount(testUserID, account.ID)
+	assert.ErrorContains(t, err, "still has transactions")
+}
@@ -5,11 +5,13 @@ import (
 	"encoding/csv"
 	"fmt"
 	"log"
-	"strconv"
+	"sort"
+	"strings"
 	"time"
 
 	// For strings.Builder if used instead of bytes.Buffer for CSV
 	"github.com/jung-kurt/gofpdf/v2"
+	"github.com/zayyadi/finance-tracker/internal/types"
 )
 
 // ReportService provides methods for generating financial reports.
@@ -26,40 +28,52 @@ func NewReportService(is *IncomeService, es *ExpenseService) *ReportService {
 	}
 }
 
-// GenerateTransactionsCSV generates a CSV string of all income and expense transactions within a date range.
-func (s *ReportService) GenerateTransactionsCSV(startDate, endDate time.Time) (string, error) {
+// GenerateTransactionsCSV generates a CSV string of a user's income and expense transactions within a date range.
+func (s *ReportService) GenerateTransactionsCSV(userID uint, startDate, endDate time.Time) (string, error) {
 	if s.incomeService == nil || s.expenseService == nil {
 		return "", fmt.Errorf("report service is not properly initialized with income/expense services")
 	}
 
-	incomes, err := s.incomeService.GetIncomesByDateRange(startDate, endDate) // UserID removed
+	incomes, err := s.incomeService.GetIncomesByDateRange(userID, startDate, endDate)
 	if err != nil {
 		return "", fmt.Errorf("error fetching income data: %w", err)
 	}
 
-	expenses, err := s.expenseService.GetExpensesByDateRange(startDate, endDate) // UserID removed
+	expenses, err := s.expenseService.GetExpensesByDateRange(userID, startDate, endDate)
 	if err != nil {
 		return "", fmt.Errorf("error fetching expense data: %w", err)
 	}
 
+	converter, err := NewCurrencyService(s.incomeService.DB).NewConverter(userID)
+	if err != nil {
+		return "", fmt.Errorf("error preparing currency conversion: %w", err)
+	}
+
 	var b bytes.Buffer
 	writer := csv.NewWriter(&b)
 	defer writer.Flush()
 
-	// Write header row
-	header := []string{"Type", "Date", "Category", "Amount", "Note"}
+	// Write header row. Amount and Currency are as recorded; the base-currency column holds the converted amount.
+	header := []string{"Type", "Date", "Category", "Amount", "Currency", fmt.Sprintf("Amount (%s)", converter.Base), "Note", "Tags"}
 	if err := writer.Write(header); err != nil {
 		return "", fmt.Errorf("error writing CSV header: %w", err)
 	}
 
 	// Write income records
 	for _, income := range incomes {
+		converted, err := converter.Convert(income.Amount, income.Currency, income.Date.Time)
+		if err != nil {
+			return "", err
+		}
 		record := []string{
 			"Income",
 			income.Date.Format("2006-01-02"),
 			income.Category,
-			strconv.FormatFloat(income.Amount, 'f', 2, 64),
+			income.Amount.String(),
+			income.Currency,
+			converted.String(),
 			income.Note,
+			strings.Join(income.Tags, ", "),
 		}
 		if err := writer.Write(record); err != nil {
 			return "", fmt.Errorf("error writing income record to CSV: %w", err)
@@ -68,12 +82,19 @@ func (s *ReportService) GenerateTransactionsCSV(startDate, endDate time.Time) (s
 
 	// Write expense records
 	for _, expense := range expenses {
+		converted, err := converter.Convert(expense.Amount, expense.Currency, expense.Date.Time)
+		if err != nil {
+			return "", err
+		}
 		record := []string{
 			"Expense",
 			expense.Date.Format("2006-01-02"),
 			expense.Category,
-			strconv.FormatFloat(expense.Amount, 'f', 2, 64),
+			expense.Amount.String(),
+			expense.Currency,
+			converted.String(),
 			expense.Note,
+			strings.Join(expense.Tags, ", "),
 		}
 		if err := writer.Write(record); err != nil {
 			return "", fmt.Errorf("error writing expense record to CSV: %w", err)
@@ -88,32 +109,63 @@ func (s *ReportService) GenerateTransactionsCSV(startDate, endDate time.Time) (s
 	return b.String(), nil
 }
 
-// GenerateTransactionsPDF generates a PDF report of transactions.
-func (s *ReportService) GenerateTransactionsPDF(startDate, endDate time.Time) (*bytes.Buffer, error) {
+// GenerateTransactionsPDF generates a PDF report of a user's transactions.
+func (s *ReportService) GenerateTransactionsPDF(userID uint, startDate, endDate time.Time) (*bytes.Buffer, error) {
 	if s.incomeService == nil || s.expenseService == nil {
 		return nil, fmt.Errorf("report service is not properly initialized with income/expense services")
 	}
 
-	incomes, err := s.incomeService.GetIncomesByDateRange(startDate, endDate) // UserID removed
+	incomes, err := s.incomeService.GetIncomesByDateRange(userID, startDate, endDate)
 	if err != nil {
 		return nil, fmt.Errorf("error fetching income data for PDF: %w", err)
 	}
 
-	expenses, err := s.expenseService.GetExpensesByDateRange(startDate, endDate) // UserID removed
+	expenses, err := s.expenseService.GetExpensesByDateRange(userID, startDate, endDate)
 	if err != nil {
 		return nil, fmt.Errorf("error fetching expense data for PDF: %w", err)
 	}
 
-	// Calculate summary directly
-	var totalIncome float64
+	converter, err := NewCurrencyService(s.incomeService.DB).NewConverter(userID)
+	if err != nil {
+		return nil, fmt.Errorf("error preparing currency conversion: %w", err)
+	}
+
+	// Calculate summary directly, in the base currency. Records count towards each of their tags.
+	var totalIncome types.Money
+	incomeByTag := make(map[string]types.Money)
 	for _, item := range incomes {
-		totalIncome += item.Amount
+		converted, err := converter.Convert(item.Amount, item.Currency, item.Date.Time)
+		if err != nil {
+			return nil, err
+		}
+		totalIncome += converted
+		for _, tag := range item.Tags {
+			incomeByTag[tag] += converted
+		}
 	}
-	var totalExpenses float64
+	var totalExpenses types.Money
+	expensesByTag := make(map[string]types.Money)
 	for _, item := range expenses {
-		totalExpenses += item.Amount
+		converted, err := converter.Convert(item.Amount, item.Currency, item.Date.Time)
+		if err != nil {
+			return nil, err
+		}
+		totalExpenses += converted
+		for _, tag := range item.Tags {
+			expensesByTag[tag] += converted
+		}
 	}
 	netBalance := totalIncome - totalExpenses
+	var tags []string
+	for tag := range incomeByTag {
+		tags = append(tags, tag)
+	}
+	for tag := range expensesByTag {
+		if _, ok := incomeByTag[tag]; !ok {
+			tags = append(tags, tag)
+		}
+	}
+	sort.Strings(tags)
 
 	pdf := gofpdf.New("P", "mm", "A4", "")
 	pdf.AddPage()
@@ -123,8 +175,6 @@ func (s *ReportService) GenerateTransactionsPDF(startDate, endDate time.Time) (*
 	pdf.Cell(40, 10, "Financial Report")
 	pdf.Ln(10)
 	pdf.SetFont("Arial", "", 12)
-	// pdf.Cell(40, 10, fmt.Sprintf("User ID: %d", userID)) // UserID removed from PDF content
-	// pdf.Ln(5)
 	pdf.Cell(40, 10, fmt.Sprintf("Period: %s to %s", startDate.Format("2006-01-02"), endDate.Format("2006-01-02")))
 	pdf.Ln(10)
 
@@ -133,11 +183,11 @@ func (s *ReportService) GenerateTransactionsPDF(startDate, endDate time.Time) (*
 	pdf.Cell(40, 10, "Summary")
 	pdf.Ln(8)
 	pdf.SetFont("Arial", "", 12)
-	pdf.Cell(40, 8, fmt.Sprintf("Total Income: %.2f", totalIncome))
+	pdf.Cell(40, 8, fmt.Sprintf("Total Income: %s %s", totalIncome, converter.Base))
 	pdf.Ln(6)
-	pdf.Cell(40, 8, fmt.Sprintf("Total Expenses: %.2f", totalExpenses))
+	pdf.Cell(40, 8, fmt.Sprintf("Total Expenses: %s %s", totalExpenses, converter.Base))
 	pdf.Ln(6)
-	pdf.Cell(40, 8, fmt.Sprintf("Net Balance: %.2f", netBalance))
+	pdf.Cell(40, 8, fmt.Sprintf("Net Balance: %s %s", netBalance, converter.Base))
 	pdf.Ln(10)
 
 	// Table rendering helper
@@ -168,7 +218,7 @@ func (s *ReportService) GenerateTransactionsPDF(startDate, endDate time.Time) (*
 				// Handle potential overflow for 'Note' column if it's too long
 				// For simplicity, we're not doing MultiCell here yet.
 				// A more robust solution would check content length and use MultiCell or truncate.
-				if headers[i] == "Note" && len(item) > int(colWidth/2) { // Heuristic for when to truncate
+				if (headers[i] == "Note" || headers[i] == "Tags") && len(item) > int(colWidth/2) { // Heuristic for when to truncate
 					item = item[:int(colWidth/2)-3] + "..."
 				}
 				pdf.CellFormat(colWidth, 6, item, "1", 0, "L", fill, 0, "")
@@ -179,28 +229,40 @@ func (s *ReportService) GenerateTransactionsPDF(startDate, endDate time.Time) (*
 		pdf.Ln(10)
 	}
 
+	// Totals per tag
+	if len(tags) > 0 {
+		tagHeaders := []string{"Tag", fmt.Sprintf("Income (%s)", converter.Base), fmt.Sprintf("Expenses (%s)", converter.Base)}
+		var tagData [][]string
+		for _, tag := range tags {
+			tagData = append(tagData, []string{tag, incomeByTag[tag].String(), expensesByTag[tag].String()})
+		}
+		renderTable("By Tag", tagHeaders, tagData)
+	}
+
 	// Income Transactions
-	incomeHeaders := []string{"Date", "Category", "Amount", "Note"}
+	incomeHeaders := []string{"Date", "Category", "Amount", "Note", "Tags"}
 	var incomeData [][]string
 	for _, item := range incomes {
 		incomeData = append(incomeData, []string{
 			item.Date.Format("2006-01-02"),
 			item.Category,
-			strconv.FormatFloat(item.Amount, 'f', 2, 64),
+			item.Amount.String() + " " + item.Currency,
 			item.Note,
+			strings.Join(item.Tags, ", "),
 		})
 	}
 	renderTable("Income Transactions", incomeHeaders, incomeData)
 
 	// Expense Transactions
-	expenseHeaders := []string{"Date", "Category", "Amount", "Note"}
+	expenseHeaders := []string{"Date", "Category", "Amount", "Note", "Tags"}
 	var expenseData [][]string
 	for _, item := range expenses {
 		expenseData = append(expenseData, []string{
 			item.Date.Format("2006-01-02"),
 			item.Category,
-			strconv.FormatFloat(item.Amount, 'f', 2, 64),
+			item.Amount.String() + " " + item.Currency,
 			item.Note,
+			strings.Join(item.Tags, ", "),
 		})
 	}
 	renderTable("Expense Transactions", expenseHeaders, expenseData)
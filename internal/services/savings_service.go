@@ -4,8 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"log"
-
-	// "time" // Not needed directly if GORM handles timestamps in gorm.Model
+	"time"
 
 	"github.com/zayyadi/finance-tracker/internal/database"
 	"github.com/zayyadi/finance-tracker/internal/models"
@@ -31,62 +30,104 @@ func (s *SavingsService) CreateSavings(savings *models.Savings) error {
 	if s.DB == nil {
 		return fmt.Errorf("database connection not initialized in SavingsService")
 	}
-	result := s.DB.Create(savings)
-	if result.Error != nil {
-		// log.Printf("Error creating savings goal for user %d: %v", savings.UserID, result.Error) // UserID removed
-		log.Printf("Error creating savings goal: %v", result.Error)
-		return fmt.Errorf("could not create savings goal: %w", result.Error)
+	currency, err := resolveCurrency(s.DB, savings.UserID, savings.Currency)
+	if err != nil {
+		return fmt.Errorf("could not create savings goal: %w", err)
+	}
+	savings.Currency = currency
+	tags, err := normalizeTags(savings.Tags)
+	if err != nil {
+		return fmt.Errorf("could not create savings goal: %w", err)
+	}
+	savings.Tags = tags
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(savings).Error; err != nil {
+			return err
+		}
+		if len(tags) == 0 {
+			return nil
+		}
+		return setTags(tx, savings.UserID, "savings", savings.ID, tags)
+	})
+	if err != nil {
+		log.Printf("Error creating savings goal for user %d: %v", savings.UserID, err)
+		return fmt.Errorf("could not create savings goal: %w", err)
 	}
 	return nil
 }
 
-// GetSavingsByID retrieves a specific savings goal by its ID.
-func (s *SavingsService) GetSavingsByID(savingsID uint) (*models.Savings, error) {
+// GetSavingsByID retrieves a specific savings goal by its ID, scoped to the given user.
+func (s *SavingsService) GetSavingsByID(userID uint, savingsID uint) (*models.Savings, error) {
 	if s.DB == nil {
 		return nil, fmt.Errorf("database connection not initialized in SavingsService")
 	}
 	var savings models.Savings
-	result := s.DB.Where("id = ?", savingsID).First(&savings) // Removed userID condition
+	result := s.DB.Where("id = ? AND user_id = ?", savingsID, userID).First(&savings)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
-			return nil, fmt.Errorf("savings goal not found") // Simplified error
+			return nil, fmt.Errorf("savings goal not found")
 		}
-		log.Printf("Error retrieving savings goal %d: %v", savingsID, result.Error)
+		log.Printf("Error retrieving savings goal %d for user %d: %v", savingsID, userID, result.Error)
 		return nil, fmt.Errorf("could not retrieve savings goal: %w", result.Error)
 	}
+	tags, err := loadTags(s.DB, "savings", []uint{savings.ID})
+	if err != nil {
+		return nil, err
+	}
+	savings.Tags = tags[savings.ID]
 	return &savings, nil
 }
 
-// GetSavings retrieves all savings goals with pagination.
-func (s *SavingsService) GetSavings(offset int, limit int) ([]models.Savings, error) {
+// GetSavings retrieves a page, counting from 1, of the savings goals of a user matching filter, by
+// target date unless filter sorts them otherwise.
+func (s *SavingsService) GetSavings(userID uint, page int, limit int, filter models.ListFilter) (*models.Page[models.Savings], error) {
 	if s.DB == nil {
 		return nil, fmt.Errorf("database connection not initialized in SavingsService")
 	}
-	var savingsList []models.Savings
-	result := s.DB.Offset(offset).Limit(limit). // Removed userID condition
-							Order("target_date asc, created_at desc").
-							Find(&savingsList)
-
-	if result.Error != nil {
-		log.Printf("Error retrieving savings goals: %v", result.Error)
-		return nil, fmt.Errorf("could not retrieve savings goals: %w", result.Error)
+	query, err := applyListFilter(s.DB.Model(&models.Savings{}).Where("user_id = ?", userID), userID, savingsListColumns, filter)
+	if err != nil {
+		return nil, err
+	}
+	savingsList, err := paginate[models.Savings](query, page, limit)
+	if err != nil {
+		log.Printf("Error retrieving savings goals for user %d: %v", userID, err)
+		return nil, fmt.Errorf("could not retrieve savings goals: %w", err)
+	}
+	ids := make([]uint, len(savingsList.Items))
+	for i := range savingsList.Items {
+		ids[i] = savingsList.Items[i].ID
+	}
+	savingsTags, err := loadTags(s.DB, "savings", ids)
+	if err != nil {
+		return nil, err
 	}
-	if savingsList == nil {
-		return []models.Savings{}, nil
+	for i := range savingsList.Items {
+		savingsList.Items[i].Tags = savingsTags[savingsList.Items[i].ID]
 	}
 	return savingsList, nil
 }
 
-// UpdateSavings updates an existing savings goal.
-func (s *SavingsService) UpdateSavings(savingsID uint, updateData *models.SavingsUpdateRequest) (*models.Savings, error) {
+// UpdateSavings updates an existing savings goal owned by the given user.
+// When versions are given, the update is only made while the savings goal was last changed at one
+// of them; otherwise a VersionConflictError is returned.
+func (s *SavingsService) UpdateSavings(userID uint, savingsID uint, updateData *models.SavingsUpdateRequest, versions ...time.Time) (*models.Savings, error) {
 	if s.DB == nil {
 		return nil, fmt.Errorf("database connection not initialized in SavingsService")
 	}
 
-	existingSavings, err := s.GetSavingsByID(savingsID) // Removed userID
+	existingSavings, err := s.GetSavingsByID(userID, savingsID)
 	if err != nil {
 		return nil, err
 	}
+	if err := checkVersion(existingSavings.UpdatedAt, "savings goal", versions); err != nil {
+		return nil, err
+	}
+	var tags []string
+	if updateData.Tags != nil {
+		if tags, err = normalizeTags(*updateData.Tags); err != nil {
+			return nil, fmt.Errorf("could not update savings goal: %w", err)
+		}
+	}
 
 	// and to correctly handle zero values if not using pointers for all fields in updateData.
 	// For GORM, Model(&existingSavings).Updates(updateData) where updateData is a struct with pointer fields
@@ -101,6 +142,9 @@ func (s *SavingsService) UpdateSavings(savingsID uint, updateData *models.Saving
 	if updateData.GoalAmount != nil {
 		updatesMap["goal_amount"] = *updateData.GoalAmount
 	}
+	if updateData.Currency != nil {
+		updatesMap["currency"] = *updateData.Currency
+	}
 	if updateData.CurrentAmount != nil {
 		updatesMap["current_amount"] = *updateData.CurrentAmount
 	}
@@ -150,13 +194,35 @@ func (s *SavingsService) UpdateSavings(savingsID uint, updateData *models.Saving
 		// This is okay, we want to proceed to update them to NULL.
 	}
 
+	// UpdateColumns leaves updated_at alone, but it versions the record for If-Match, so set it here.
+	updatesMap["updated_at"] = time.Now()
+
 	// Use UpdateColumns to ensure that nil values in the map explicitly set DB fields to NULL.
 	// Updates might ignore nil values in maps depending on GORM version and configuration.
-	result := s.DB.Model(&existingSavings).Where("id = ?", savingsID).UpdateColumns(updatesMap)
-
-	if result.Error != nil {
-		log.Printf("Error updating savings goal %d: %v", savingsID, result.Error)
-		return nil, fmt.Errorf("could not update savings goal: %w", result.Error)
+	// The tags and the record are written together, so a failed update leaves the old tags in place.
+	var result *gorm.DB
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		if updateData.Tags != nil {
+			if err := setTags(tx, userID, "savings", savingsID, tags); err != nil {
+				log.Printf("Error tagging savings goal %d: %v", savingsID, err)
+				return err
+			}
+		}
+		result = whereVersion(tx.Model(&existingSavings).Where("id = ? AND user_id = ?", savingsID, userID), existingSavings.UpdatedAt, versions).UpdateColumns(updatesMap)
+		if result.Error != nil {
+			log.Printf("Error updating savings goal %d: %v", savingsID, result.Error)
+			return result.Error
+		}
+		if result.RowsAffected == 0 && len(versions) > 0 {
+			return versionConflict(tx, &models.Savings{}, "savings goal", userID, savingsID)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not update savings goal: %w", err)
+	}
+	if updateData.Tags != nil {
+		existingSavings.Tags = tags
 	}
 	if result.RowsAffected == 0 {
 		// It's possible no rows were affected because the data in updatesMap matched existing data.
@@ -171,27 +237,81 @@ func (s *SavingsService) UpdateSavings(savingsID uint, updateData *models.Saving
 	// especially to correctly reflect fields set to NULL and avoid issues with GORM potentially
 	// not clearing fields in an already populated struct.
 	var freshlyFetchedSavings models.Savings
-	err = s.DB.First(&freshlyFetchedSavings, savingsID).Error
+	err = s.DB.Where("user_id = ?", userID).First(&freshlyFetchedSavings, savingsID).Error
 	if err != nil {
 		log.Printf("Error re-fetching savings goal %d after update: %v", savingsID, err)
 		return nil, fmt.Errorf("could not re-fetch savings goal after update: %w", err)
 	}
+	freshlyFetchedSavings.Tags = existingSavings.Tags
 
 	return &freshlyFetchedSavings, nil
 }
 
-// DeleteSavings deletes a savings goal.
-func (s *SavingsService) DeleteSavings(savingsID uint) error {
+// DeleteSavings deletes a savings goal owned by the given user.
+// When versions are given, it is only deleted while it was last changed at one of them; otherwise a
+// VersionConflictError is returned.
+func (s *SavingsService) DeleteSavings(userID uint, savingsID uint, versions ...time.Time) error {
 	if s.DB == nil {
 		return fmt.Errorf("database connection not initialized in SavingsService")
 	}
-	result := s.DB.Where("id = ?", savingsID).Delete(&models.Savings{}) // Removed userID
+	query := s.DB.Where("id = ? AND user_id = ?", savingsID, userID)
+	if len(versions) > 0 {
+		updatedAt, err := recordVersion(s.DB, &models.Savings{}, "savings goal", userID, savingsID)
+		if err != nil {
+			return err
+		}
+		if err := checkVersion(updatedAt, "savings goal", versions); err != nil {
+			return err
+		}
+		query = whereVersion(query, updatedAt, versions)
+	}
+	result := query.Delete(&models.Savings{})
 	if result.Error != nil {
 		log.Printf("Error deleting savings goal %d: %v", savingsID, result.Error)
 		return fmt.Errorf("could not delete savings goal: %w", result.Error)
 	}
 	if result.RowsAffected == 0 {
+		if len(versions) > 0 {
+			return versionConflict(s.DB, &models.Savings{}, "savings goal", userID, savingsID)
+		}
 		return fmt.Errorf("savings goal not found, no rows deleted")
 	}
 	return nil
 }
+
+// BulkCreateSavings creates the savings goals in one transaction: if any of them cannot be created, none is.
+func (s *SavingsService) BulkCreateSavings(savingsList []models.Savings) (*models.BulkResult[models.Savings], error) {
+	if s.DB == nil {
+		return nil, fmt.Errorf("database connection not initialized in SavingsService")
+	}
+	return runBulk(s.DB, models.BulkStatusCreated, nil, len(savingsList), func(tx *gorm.DB, i int) (*models.Savings, []time.Time, error) {
+		if err := (&SavingsService{DB: tx}).CreateSavings(&savingsList[i]); err != nil {
+			return nil, nil, err
+		}
+		return &savingsList[i], nil, nil
+	})
+}
+
+// BulkUpdateSavings updates savings goals owned by the given user in one transaction: if any of them
+// cannot be updated, none is.
+func (s *SavingsService) BulkUpdateSavings(userID uint, items []models.SavingsBulkUpdateItem) (*models.BulkResult[models.Savings], error) {
+	if s.DB == nil {
+		return nil, fmt.Errorf("database connection not initialized in SavingsService")
+	}
+	ids := bulkUpdateIDs(items, func(item models.SavingsBulkUpdateItem) uint { return item.ID })
+	return runBulk(s.DB, models.BulkStatusUpdated, ids, len(items), func(tx *gorm.DB, i int) (*models.Savings, []time.Time, error) {
+		updated, err := (&SavingsService{DB: tx}).UpdateSavings(userID, items[i].ID, &items[i].SavingsUpdateRequest)
+		return updated, nil, err
+	})
+}
+
+// BulkDeleteSavings deletes savings goals owned by the given user in one transaction: if any of them
+// cannot be deleted, none is.
+func (s *SavingsService) BulkDeleteSavings(userID uint, savingsIDs []uint) (*models.BulkResult[models.Savings], error) {
+	if s.DB == nil {
+		return nil, fmt.Errorf("database connection not initialized in SavingsService")
+	}
+	return runBulk(s.DB, models.BulkStatusDeleted, savingsIDs, len(savingsIDs), func(tx *gorm.DB, i int) (*models.Savings, []time.Time, error) {
+		return nil, nil, (&SavingsService{DB: tx}).DeleteSavings(userID, savingsIDs[i])
+	})
+}
@@ -0,0 +1,143 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/zayyadi/finance-tracker/internal/database"
+	"github.com/zayyadi/finance-tracker/internal/models"
+	"gorm.io/gorm"
+)
+
+// searchSource describes the columns searched and returned for one record type.
+type searchSource struct {
+	recordType string
+	table      string
+	date       string
+	amount     string
+	title      string
+	text       string
+}
+
+// searchSources lists the searchable record types. On PostgreSQL the title and text columns are
+// covered by the full-text indexes of migration 000020_search_indexes.
+var searchSources = []searchSource{
+	{recordType: "income", table: "incomes", date: "date", amount: "amount", title: "category", text: "note"},
+	{recordType: "expense", table: "expenses", date: "date", amount: "amount", title: "category", text: "note"},
+	{recordType: "debt", table: "debts", date: "due_date", amount: "amount", title: "debtor_name", text: "description"},
+	{recordType: "savings", table: "savings", date: "target_date", amount: "goal_amount", title: "goal_name", text: "notes"},
+}
+
+// SearchService searches the text of a user's incomes, expenses, debts and savings goals.
+type SearchService struct {
+	DB *gorm.DB
+}
+
+// NewSearchService creates a new SearchService with a GORM database connection.
+func NewSearchService(db *gorm.DB) *SearchService {
+	if db == nil {
+		log.Println("Warning: NewSearchService called with nil DB, attempting to use global GetDB()")
+		db = database.GetDB()
+	}
+	return &SearchService{DB: db}
+}
+
+// Search finds the user's records whose text matches query, best matches first. recordType limits the
+// search to one type of record; empty searches them all.
+//
+// On PostgreSQL the query is a web-search style full-text query ("plumber -kitchen", "\"new tyres\"")
+// matched against English word stems. On SQLite every word of the query must appear in the record,
+// and records with the words in their title rank first.
+func (s *SearchService) Search(userID uint, query string, recordType string, offset, limit int) ([]models.SearchResult, error) {
+	if s.DB == nil {
+		return nil, fmt.Errorf("database connection not initialized in SearchService")
+	}
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, fmt.Errorf("invalid search: the query must not be empty")
+	}
+	if len(query) > 200 {
+		return nil, fmt.Errorf("invalid search: the query is limited to 200 characters")
+	}
+	sources := searchSources
+	if recordType != "" {
+		sources = nil
+		for _, source := range searchSources {
+			if source.recordType == recordType {
+				sources = append(sources, source)
+			}
+		}
+		if sources == nil {
+			return nil, fmt.Errorf("invalid search: unknown type %q, expected income, expense, debt or savings", recordType)
+		}
+	}
+
+	args := map[string]interface{}{"user": userID, "query": query, "limit": limit, "offset": offset}
+	var selects []string
+	if s.DB.Dialector.Name() == database.DriverPostgres {
+		for _, source := range sources {
+			selects = append(selects, fullTextSelect(source))
+		}
+	} else {
+		var terms []string
+		for i, word := range strings.Fields(strings.ToLower(strings.ReplaceAll(query, `"`, ""))) {
+			term := fmt.Sprintf("term%d", i)
+			args[term] = "%" + escapeLike(word) + "%"
+			terms = append(terms, term)
+		}
+		if len(terms) == 0 {
+			return []models.SearchResult{}, nil
+		}
+		for _, source := range sources {
+			selects = append(selects, likeSelect(source, terms))
+		}
+	}
+
+	var results []models.SearchResult
+	err := s.DB.Raw(`SELECT * FROM (`+strings.Join(selects, " UNION ALL ")+`) AS results
+		ORDER BY rank DESC, date IS NULL, date DESC, type, id DESC LIMIT @limit OFFSET @offset`, args).
+		Scan(&results).Error
+	if err != nil {
+		log.Printf("Error searching records for user %d: %v", userID, err)
+		return nil, fmt.Errorf("could not search records: %w", err)
+	}
+	if results == nil {
+		return []models.SearchResult{}, nil
+	}
+	return results, nil
+}
+
+// searchColumns returns the columns every search select returns, in order.
+func searchColumns(source searchSource) string {
+	return fmt.Sprintf("'%s' AS type, id, %s AS date, %s AS amount, currency, %s AS title, %s AS text",
+		source.recordType, source.date, source.amount, source.title, source.text)
+}
+
+// fullTextSelect returns a PostgreSQL select of the records of source matching the @query parameter.
+// The document expression is the one indexed by migration 000020_search_indexes.
+func fullTextSelect(source searchSource) string {
+	document := fmt.Sprintf("to_tsvector('english', coalesce(%s, '') || ' ' || coalesce(%s, ''))", source.title, source.text)
+	return fmt.Sprintf(`SELECT %s, ts_rank(%s, websearch_to_tsquery('english', @query)) AS rank
+		FROM %s WHERE user_id = @user AND deleted_at IS NULL AND %s @@ websearch_to_tsquery('english', @query)`,
+		searchColumns(source), document, source.table, document)
+}
+
+// likeSelect returns a select of the records of source containing every one of the LIKE patterns in
+// the named parameters terms. Each term found in the title adds one to the rank.
+func likeSelect(source searchSource, terms []string) string {
+	document := fmt.Sprintf("lower(coalesce(%s, '') || ' ' || coalesce(%s, ''))", source.title, source.text)
+	conditions := make([]string, len(terms))
+	ranks := make([]string, len(terms))
+	for i, term := range terms {
+		conditions[i] = fmt.Sprintf(`%s LIKE @%s ESCAPE '\'`, document, term)
+		ranks[i] = fmt.Sprintf(`CASE WHEN lower(%s) LIKE @%s ESCAPE '\' THEN 1.0 ELSE 0.0 END`, source.title, term)
+	}
+	return fmt.Sprintf(`SELECT %s, %s AS rank FROM %s WHERE user_id = @user AND deleted_at IS NULL AND %s`,
+		searchColumns(source), strings.Join(ranks, " + "), source.table, strings.Join(conditions, " AND "))
+}
+
+// escapeLike escapes the LIKE wildcards in s, for use with ESCAPE '\'.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
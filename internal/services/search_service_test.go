@@ -0,0 +1,72 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zayyadi/finance-tracker/internal/database"
+	"github.com/zayyadi/finance-tracker/internal/models"
+	"github.com/zayyadi/finance-tracker/internal/types"
+)
+
+func TestSearchService_SearchesAllRecordTypes(t *testing.T) {
+	db := setupAccountTestDB(t)
+	require.NoError(t, db.AutoMigrate(&models.Debt{}, &models.Savings{}))
+	service := NewSearchService(db)
+	spring := database.CustomDate{Time: time.Date(2024, time.April, 12, 0, 0, 0, 0, time.UTC)}
+	summer := database.CustomDate{Time: time.Date(2024, time.July, 2, 0, 0, 0, 0, time.UTC)}
+
+	leak := &models.Expense{UserID: testUserID, Amount: types.Money(18000), Category: "Home", Date: spring, Note: "Plumber fixed the kitchen leak"}
+	require.NoError(t, NewExpenseService(db).CreateExpense(leak))
+	require.NoError(t, NewExpenseService(db).CreateExpense(&models.Expense{UserID: testUserID, Amount: types.Money(900), Category: "Food", Date: summer, Note: "Lunch"}))
+	require.NoError(t, db.Create(&models.Expense{UserID: testUserID + 1, Amount: types.Money(900), Currency: "USD", Category: "Home", Date: summer, Note: "Plumber"}).Error)
+	debt := &models.Debt{UserID: testUserID, DebtorName: "Plumber Joe", Description: "Deposit for the bathroom", Amount: types.Money(5000), DueDate: summer, Status: "Pending"}
+	require.NoError(t, NewDebtService(db).CreateDebt(debt))
+	goal := &models.Savings{UserID: testUserID, GoalName: "New kitchen", GoalAmount: types.Money(500000), Notes: "100% of the bonus"}
+	require.NoError(t, NewSavingsService(db).CreateSavings(goal))
+
+	results, err := service.Search(testUserID, "plumber", "", 0, 10)
+	require.NoError(t, err)
+	require.Len(t, results, 2, "Other users' records are not searched")
+	assert.Equal(t, "debt", results[0].Type, "A match in the title ranks first")
+	assert.Equal(t, debt.ID, results[0].ID)
+	assert.Equal(t, "Plumber Joe", results[0].Title)
+	assert.Equal(t, "expense", results[1].Type)
+	assert.Equal(t, leak.ID, results[1].ID)
+	assert.Equal(t, types.Money(18000), results[1].Amount)
+	require.NotNil(t, results[1].Date)
+	assert.Equal(t, spring.Time, results[1].Date.Time)
+
+	// Every word must match, in any order and case.
+	results, err = service.Search(testUserID, "KITCHEN plumber", "", 0, 10)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, leak.ID, results[0].ID)
+	results, err = service.Search(testUserID, "kitchen", "savings", 0, 10)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, goal.ID, results[0].ID)
+	results, err = service.Search(testUserID, "100%", "", 0, 10)
+	require.NoError(t, err)
+	assert.Len(t, results, 1, "LIKE wildcards in the query are matched literally")
+
+	results, err = service.Search(testUserID, "kitchen", "", 1, 1)
+	require.NoError(t, err)
+	assert.Len(t, results, 1)
+	results, err = service.Search(testUserID, "kitchen", "", 2, 10)
+	require.NoError(t, err)
+	assert.Empty(t, results)
+
+	// Deleted records are not found.
+	require.NoError(t, NewDebtService(db).DeleteDebt(testUserID, debt.ID))
+	results, err = service.Search(testUserID, "joe", "", 0, 10)
+	require.NoError(t, err)
+	assert.Empty(t, results)
+
+	_, err = service.Search(testUserID, "  ", "", 0, 10)
+	assert.ErrorContains(t, err, "invalid search")
+	_, err = service.Search(testUserID, "plumber", "transfer", 0, 10)
+	assert.ErrorContains(t, err, "invalid search")
+}
@@ -1,15 +1,14 @@
 package services
 
 import (
-	"database/sql"
 	"errors"
 	"fmt"
 	"log"
-	"strings"
 	"time"
 
 	"github.com/zayyadi/finance-tracker/internal/database"
 	"github.com/zayyadi/finance-tracker/internal/models"
+	"github.com/zayyadi/finance-tracker/internal/types"
 
 	"gorm.io/gorm"
 )
@@ -28,10 +27,31 @@ func NewSummaryService(db *gorm.DB) *SummaryService {
 	return &SummaryService{DB: db}
 }
 
-// GetOrCreateFinancialSummary fetches an existing summary or generates a new one based on viewType.
+// GetOrCreateFinancialSummary fetches an existing summary for a user or generates a new one based on viewType.
 // viewType can be "overall", "income", "expenses". Other types are not yet implemented.
 // Overall summaries are fetched from/stored in DB. View-specific summaries are calculated on the fly.
-func (s *SummaryService) GetOrCreateFinancialSummary(summaryType string, targetDate time.Time, viewType string) (*models.FinancialSummary, error) {
+// Overall and expenses summaries also break the expenses down by category, counting split expenses
+// towards the categories of their lines.
+func (s *SummaryService) GetOrCreateFinancialSummary(userID uint, summaryType string, targetDate time.Time, viewType string) (*models.FinancialSummary, error) {
+	summary, err := s.getOrCreateTotals(userID, summaryType, targetDate, viewType)
+	if err != nil || viewType == "income" {
+		return summary, err
+	}
+	converter, err := NewCurrencyService(s.DB).NewConverter(userID)
+	if err != nil {
+		return nil, fmt.Errorf("error preparing currency conversion: %w", err)
+	}
+	byCategory, err := expenseTotalsByCategory(s.DB, converter, userID, summary.PeriodStartDate, summary.PeriodEndDate)
+	if err != nil {
+		log.Printf("Error calculating expenses by category for user %d, %s summary from %s: %v", userID, summaryType, summary.PeriodStartDate.Format("2006-01-02"), err)
+		return nil, fmt.Errorf("error calculating expenses by category: %w", err)
+	}
+	summary.ExpensesByCategory = byCategory
+	return summary, nil
+}
+
+// getOrCreateTotals does the work of GetOrCreateFinancialSummary, apart from the category breakdown.
+func (s *SummaryService) getOrCreateTotals(userID uint, summaryType string, targetDate time.Time, viewType string) (*models.FinancialSummary, error) {
 	if s.DB == nil {
 		return nil, fmt.Errorf("database connection not initialized in SummaryService")
 	}
@@ -45,62 +65,75 @@ func (s *SummaryService) GetOrCreateFinancialSummary(summaryType string, targetD
 		return nil, fmt.Errorf("error calculating period dates: %w", err)
 	}
 
+	// Totals are reported in the user's base currency.
+	converter, err := NewCurrencyService(s.DB).NewConverter(userID)
+	if err != nil {
+		return nil, fmt.Errorf("error preparing currency conversion: %w", err)
+	}
+
 	// Handle "overall" view - fetch from DB or calculate and store
 	if viewType == "overall" {
-		summary, err := s.fetchSummaryFromDB(summaryType, periodStartDate)
-		if err == nil && summary != nil {
+		summary, err := s.fetchSummaryFromDB(userID, summaryType, periodStartDate)
+		if err == nil && summary != nil && summary.Currency == converter.Base {
 			return summary, nil // Found existing overall summary
 		}
 		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
-			log.Printf("Error fetching existing summary (overall), type %s, date %s: %v", summaryType, periodStartDate.Format("2006-01-02"), err)
+			log.Printf("Error fetching existing summary (overall) for user %d, type %s, date %s: %v", userID, summaryType, periodStartDate.Format("2006-01-02"), err)
 			return nil, fmt.Errorf("error retrieving existing overall summary: %w", err)
 		}
+		if summary != nil {
+			// Stored in a previous base currency; recompute it.
+			if err := s.DB.Unscoped().Delete(summary).Error; err != nil {
+				return nil, fmt.Errorf("error discarding stale overall summary: %w", err)
+			}
+		}
 
 		// Existing overall summary not found, calculate it
-		totalIncome, errIncome := s.calculateTotalForPeriodGORM(0, periodStartDate, periodEndDate, &models.Income{})
+		totalIncome, errIncome := s.calculateTotalForPeriodGORM(converter, userID, periodStartDate, periodEndDate, &models.Income{})
 		if errIncome != nil {
 			return nil, fmt.Errorf("error calculating total income for overall summary: %w", errIncome)
 		}
-		totalExpenses, errExpenses := s.calculateTotalForPeriodGORM(0, periodStartDate, periodEndDate, &models.Expense{})
+		totalExpenses, errExpenses := s.calculateTotalForPeriodGORM(converter, userID, periodStartDate, periodEndDate, &models.Expense{})
 		if errExpenses != nil {
 			return nil, fmt.Errorf("error calculating total expenses for overall summary: %w", errExpenses)
 		}
 		netBalance := totalIncome - totalExpenses
 
 		newSummary := &models.FinancialSummary{
+			UserID:          userID,
 			SummaryType:     summaryType,
 			PeriodStartDate: periodStartDate,
 			PeriodEndDate:   periodEndDate,
 			TotalIncome:     totalIncome,
 			TotalExpenses:   totalExpenses,
 			NetBalance:      netBalance,
+			Currency:        converter.Base,
 		}
 		// Attempt to store the new overall summary
 		storedSummary, storeErr := s.storeSummaryInDB(newSummary)
 		if storeErr != nil {
 			// Handle potential race condition where another request created the summary in the meantime
-			if strings.Contains(storeErr.Error(), "duplicate key value violates unique constraint") &&
-				strings.Contains(storeErr.Error(), "idx_type_period") { // Ensure this index name is correct
-				log.Printf("Unique constraint violation for overall summary type %s, date %s during store. Re-fetching.", summaryType, periodStartDate.Format("2006-01-02"))
-				return s.fetchSummaryFromDB(summaryType, periodStartDate)
+			if database.IsUniqueViolation(storeErr) {
+				log.Printf("Unique constraint violation for overall summary of user %d, type %s, date %s during store. Re-fetching.", userID, summaryType, periodStartDate.Format("2006-01-02"))
+				return s.fetchSummaryFromDB(userID, summaryType, periodStartDate)
 			}
-			log.Printf("Error storing new overall summary type %s, date %s: %v", summaryType, periodStartDate.Format("2006-01-02"), storeErr)
+			log.Printf("Error storing new overall summary for user %d, type %s, date %s: %v", userID, summaryType, periodStartDate.Format("2006-01-02"), storeErr)
 			return nil, fmt.Errorf("error storing new overall summary: %w", storeErr)
 		}
 		return storedSummary, nil
 	}
 
 	// Handle view-specific calculations (not stored in DB)
-	var totalIncome float64
-	var totalExpenses float64
+	var totalIncome types.Money
+	var totalExpenses types.Money
 	var calcErr error
 
 	if viewType == "income" {
-		totalIncome, calcErr = s.calculateTotalForPeriodGORM(0, periodStartDate, periodEndDate, &models.Income{})
+		totalIncome, calcErr = s.calculateTotalForPeriodGORM(converter, userID, periodStartDate, periodEndDate, &models.Income{})
 		totalExpenses = 0 // Expenses are zero for income-only view
 	} else if viewType == "expenses" {
 		totalIncome = 0 // Income is zero for expenses-only view
-		totalExpenses, calcErr = s.calculateTotalForPeriodGORM(0, periodStartDate, periodEndDate, &models.Expense{})
+		totalExpenses, calcErr = s.calculateTotalForPeriodGORM(converter, userID, periodStartDate, periodEndDate, &models.Expense{})
 	} else if viewType == "savings" || viewType == "debts" {
 		// Placeholder for future implementation
 		return nil, fmt.Errorf("viewType '%s' not yet implemented", viewType)
@@ -115,19 +148,21 @@ func (s *SummaryService) GetOrCreateFinancialSummary(summaryType string, targetD
 	netBalance := totalIncome - totalExpenses
 	// Create a non-persistent FinancialSummary object for the specific view
 	viewSummary := &models.FinancialSummary{
+		UserID:          userID,
 		SummaryType:     summaryType,
 		PeriodStartDate: periodStartDate,
 		PeriodEndDate:   periodEndDate,
 		TotalIncome:     totalIncome,
 		TotalExpenses:   totalExpenses,
 		NetBalance:      netBalance,
+		Currency:        converter.Base,
 	}
 	return viewSummary, nil
 }
 
-func (s *SummaryService) fetchSummaryFromDB(summaryType string, periodStartDate time.Time) (*models.FinancialSummary, error) {
+func (s *SummaryService) fetchSummaryFromDB(userID uint, summaryType string, periodStartDate time.Time) (*models.FinancialSummary, error) {
 	var summary models.FinancialSummary
-	result := s.DB.Where("summary_type = ? AND period_start_date = ?", summaryType, periodStartDate).First(&summary)
+	result := s.DB.Where("user_id = ? AND summary_type = ? AND period_start_date = ?", userID, summaryType, periodStartDate).First(&summary)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 			return nil, gorm.ErrRecordNotFound
@@ -145,18 +180,15 @@ func (s *SummaryService) storeSummaryInDB(summary *models.FinancialSummary) (*mo
 	return summary, nil
 }
 
-func (s *SummaryService) calculateTotalForPeriodGORM(userID uint, startDate, endDate time.Time, modelInstance interface{}) (float64, error) {
-	var total sql.NullFloat64
-	result := s.DB.Model(modelInstance).
-		Where("date BETWEEN ? AND ?", startDate, endDate).
-		Select("COALESCE(SUM(amount), 0)").
-		Scan(&total)
-
-	if result.Error != nil {
-		log.Printf("Error calculating total between %s and %s for model %T: %v", startDate, endDate, modelInstance, result.Error)
-		return 0, result.Error
+// calculateTotalForPeriodGORM sums amounts in integer minor units, converting each currency into the base currency.
+func (s *SummaryService) calculateTotalForPeriodGORM(converter *CurrencyConverter, userID uint, startDate, endDate time.Time, modelInstance interface{}) (types.Money, error) {
+	total, err := converter.SumAmounts(s.DB.Model(modelInstance).
+		Where("user_id = ? AND date BETWEEN ? AND ?", userID, startDate.Format("2006-01-02"), endDate.Format("2006-01-02")))
+	if err != nil {
+		log.Printf("Error calculating total for user %d between %s and %s for model %T: %v", userID, startDate, endDate, modelInstance, err)
+		return 0, err
 	}
-	return total.Float64, nil
+	return total, nil
 }
 
 func CalculatePeriodDates(targetDate time.Time, summaryType string) (time.Time, time.Time, error) {
@@ -187,34 +219,59 @@ func CalculatePeriodDates(targetDate time.Time, summaryType string) (time.Time,
 	return startDate, endDate, nil
 }
 
-// InvalidateSummariesForDate deletes summary records for specified period types based on the itemDate.
-func (s *SummaryService) InvalidateSummariesForDate(itemDate time.Time, summaryPeriodTypes []string) error {
+// InvalidateSummariesForDate deletes a user's summary records for specified period types based on the itemDate.
+func (s *SummaryService) InvalidateSummariesForDate(userID uint, itemDate time.Time, summaryPeriodTypes []string) error {
+	return s.InvalidateSummariesForDates(userID, []time.Time{itemDate}, summaryPeriodTypes)
+}
+
+// InvalidateSummariesForDates deletes a user's summary records for specified period types covering any of
+// the dates. Each period is deleted once, however many of the dates fall in it.
+func (s *SummaryService) InvalidateSummariesForDates(userID uint, itemDates []time.Time, summaryPeriodTypes []string) error {
 	if s.DB == nil {
 		return fmt.Errorf("database connection not initialized in SummaryService for invalidation")
 	}
 
 	var firstError error
 	for _, periodType := range summaryPeriodTypes {
-		periodStartDate, _, err := CalculatePeriodDates(itemDate, periodType)
-		if err != nil {
-			log.Printf("Error calculating period dates for invalidation (type: %s, itemDate: %s): %v", periodType, itemDate.Format("2006-01-02"), err)
-			if firstError == nil { // Store the first error encountered
-				firstError = fmt.Errorf("failed to calculate period for %s: %w", periodType, err)
+		invalidated := make(map[time.Time]bool)
+		for _, itemDate := range itemDates {
+			periodStartDate, _, err := CalculatePeriodDates(itemDate, periodType)
+			if err != nil {
+				log.Printf("Error calculating period dates for invalidation (type: %s, itemDate: %s): %v", periodType, itemDate.Format("2006-01-02"), err)
+				if firstError == nil { // Store the first error encountered
+					firstError = fmt.Errorf("failed to calculate period for %s: %w", periodType, err)
+				}
+				continue // Try to invalidate other periods even if one fails
 			}
-			continue // Try to invalidate other periods even if one fails
-		}
+			if invalidated[periodStartDate] {
+				continue
+			}
+			invalidated[periodStartDate] = true
 
-		result := s.DB.Where("summary_type = ? AND period_start_date = ?", periodType, periodStartDate).Delete(&models.FinancialSummary{})
-		if result.Error != nil {
-			// Log error, but don't necessarily stop.
-			log.Printf("Error deleting summary for invalidation (type: %s, period_start_date: %s): %v", periodType, periodStartDate.Format("2006-01-02"), result.Error)
-			if firstError == nil {
-				firstError = fmt.Errorf("failed to delete summary for %s (period starting %s): %w", periodType, periodStartDate.Format("2006-01-02"), result.Error)
+			// Summaries are a cache: delete permanently so the unique period key is free for the recomputed row.
+			result := s.DB.Unscoped().Where("user_id = ? AND summary_type = ? AND period_start_date = ?", userID, periodType, periodStartDate).Delete(&models.FinancialSummary{})
+			if result.Error != nil {
+				// Log error, but don't necessarily stop.
+				log.Printf("Error deleting summary for invalidation (type: %s, period_start_date: %s): %v", periodType, periodStartDate.Format("2006-01-02"), result.Error)
+				if firstError == nil {
+					firstError = fmt.Errorf("failed to delete summary for %s (period starting %s): %w", periodType, periodStartDate.Format("2006-01-02"), result.Error)
+				}
+			} else if result.RowsAffected > 0 {
+				log.Printf("Invalidated summary: user %d, type %s, period_start_date %s (triggered by item on %s)",
+					userID, periodType, periodStartDate.Format("2006-01-02"), itemDate.Format("2006-01-02"))
 			}
-		} else if result.RowsAffected > 0 {
-			log.Printf("Invalidated summary: type %s, period_start_date %s (triggered by item on %s)",
-				periodType, periodStartDate.Format("2006-01-02"), itemDate.Format("2006-01-02"))
 		}
 	}
 	return firstError // Return the first error encountered, or nil if all successful
 }
+
+// invalidateSummariesForDates drops the stored summaries covering rows created in bulk on the given
+// dates, as the income and expense handlers do after a single create. summaryService may be nil.
+func invalidateSummariesForDates(summaryService *SummaryService, userID uint, dates []time.Time) {
+	if summaryService == nil || len(dates) == 0 {
+		return
+	}
+	if err := summaryService.InvalidateSummariesForDates(userID, dates, []string{"monthly", "weekly", "yearly"}); err != nil {
+		log.Printf("Error invalidating summaries: %v", err)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/zayyadi/finance-tracker/internal/database"
 	"github.com/zayyadi/finance-tracker/internal/models"
+	"github.com/zayyadi/finance-tracker/internal/types"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
@@ -29,35 +30,34 @@ func setupSummaryTestDB(t *testing.T) *gorm.DB {
 	db.Exec("DROP TABLE IF EXISTS Users")
 
 	// Auto-migrate schemas based on GORM structs.
-	err = db.AutoMigrate(&models.User{}, &models.Income{}, &models.Expense{}, &models.FinancialSummary{})
+	err = db.AutoMigrate(&models.User{}, &models.Income{}, &models.Expense{}, &models.FinancialSummary{}, &models.ExchangeRate{}, &models.Category{}, &models.Tag{}, &models.Tagging{}, &models.ExpenseSplit{})
 	assert.NoError(t, err, "Failed to auto-migrate models")
-
-	// Optional: Create a dummy user if needed
-	// db.Create(&models.User{Username: "testuser", Email: "test@example.com", PasswordHash: "hash"})
+	seedTestUser(t, db)
 
 	return db
 }
 
 // seedDataForSummaryTest populates income and expense data for a given period.
-func seedDataForSummaryTest(t *testing.T, db *gorm.DB, dateForIncome time.Time, incomeAmount float64, dateForExpense time.Time, expenseAmount float64) {
+func seedDataForSummaryTest(t *testing.T, db *gorm.DB, dateForIncome time.Time, incomeAmount types.Money, dateForExpense time.Time, expenseAmount types.Money) {
 	if incomeAmount > 0 {
-		income := models.Income{Amount: incomeAmount, Category: "Test Income", Date: database.CustomDate{Time: dateForIncome}}
+		income := models.Income{UserID: testUserID, Amount: incomeAmount, Category: "Test Income", Date: database.CustomDate{Time: dateForIncome}}
 		err := db.Create(&income).Error
 		assert.NoError(t, err, "Failed to seed income: %+v", income)
 	}
 	if expenseAmount > 0 {
-		expense := models.Expense{Amount: expenseAmount, Category: "Test Expense", Date: database.CustomDate{Time: dateForExpense}}
+		expense := models.Expense{UserID: testUserID, Amount: expenseAmount, Category: "Test Expense", Date: database.CustomDate{Time: dateForExpense}}
 		err := db.Create(&expense).Error
 		assert.NoError(t, err, "Failed to seed expense: %+v", expense)
 	}
 }
 
 // seedFinancialSummary creates and stores a FinancialSummary record for testing.
-func seedFinancialSummary(t *testing.T, db *gorm.DB, summaryType string, forDateForPeriodCalc time.Time, totalIncome float64, totalExpenses float64) models.FinancialSummary {
+func seedFinancialSummary(t *testing.T, db *gorm.DB, summaryType string, forDateForPeriodCalc time.Time, totalIncome types.Money, totalExpenses types.Money) models.FinancialSummary {
 	periodStartDate, periodEndDate, err := CalculatePeriodDates(forDateForPeriodCalc, summaryType)
 	assert.NoError(t, err, "Failed to calculate period dates for seeding summary")
 
 	summary := models.FinancialSummary{
+		UserID:          testUserID,
 		SummaryType:     summaryType,
 		PeriodStartDate: periodStartDate,
 		PeriodEndDate:   periodEndDate,
@@ -70,17 +70,16 @@ func seedFinancialSummary(t *testing.T, db *gorm.DB, summaryType string, forDate
 	return summary
 }
 
-
 func TestCalculatePeriodDates(t *testing.T) {
 	loc, _ := time.LoadLocation("UTC") // Use a consistent timezone for tests
 
 	testCases := []struct {
-		name            string
-		targetDate      time.Time
-		summaryType     string
-		expectedStart   time.Time
-		expectedEnd     time.Time
-		expectError     bool
+		name             string
+		targetDate       time.Time
+		summaryType      string
+		expectedStart    time.Time
+		expectedEnd      time.Time
+		expectError      bool
 		expectedErrorMsg string
 	}{
 		// Monthly tests
@@ -111,7 +110,7 @@ func TestCalculatePeriodDates(t *testing.T) {
 
 		// Weekly tests (assuming Monday is the start of the week)
 		{
-			name:          "Weekly_MidWeek_Wednesday", // Wednesday
+			name:          "Weekly_MidWeek_Wednesday",                          // Wednesday
 			targetDate:    time.Date(2023, time.November, 15, 0, 0, 0, 0, loc), // 2023-11-15 is a Wednesday
 			summaryType:   "weekly",
 			expectedStart: time.Date(2023, time.November, 13, 0, 0, 0, 0, loc), // Monday
@@ -134,7 +133,7 @@ func TestCalculatePeriodDates(t *testing.T) {
 			expectedEnd:   time.Date(2023, time.November, 19, 0, 0, 0, 0, loc), // Sunday
 			expectError:   false,
 		},
-		 {
+		{
 			name:          "Weekly_AcrossMonthBoundary",
 			targetDate:    time.Date(2023, time.October, 30, 0, 0, 0, 0, loc), // Monday Oct 30
 			summaryType:   "weekly",
@@ -143,7 +142,6 @@ func TestCalculatePeriodDates(t *testing.T) {
 			expectError:   false,
 		},
 
-
 		// Yearly tests
 		{
 			name:          "Yearly_AnyDate",
@@ -156,10 +154,10 @@ func TestCalculatePeriodDates(t *testing.T) {
 
 		// Error cases
 		{
-			name:            "InvalidSummaryType",
-			targetDate:      time.Now(),
-			summaryType:     "daily", // Assuming 'daily' is not supported
-			expectError:     true,
+			name:             "InvalidSummaryType",
+			targetDate:       time.Now(),
+			summaryType:      "daily", // Assuming 'daily' is not supported
+			expectError:      true,
 			expectedErrorMsg: "invalid summary type: daily",
 		},
 	}
@@ -197,22 +195,22 @@ func TestGetOrCreateFinancialSummary_ViewOverall_Monthly_NoExisting(t *testing.T
 	targetDate := time.Date(2023, time.April, 10, 0, 0, 0, 0, time.UTC)
 	periodStart := time.Date(2023, time.April, 1, 0, 0, 0, 0, time.UTC)
 
-	seedDataForSummaryTest(t, db, periodStart.AddDate(0,0,5), 1000, periodStart.AddDate(0,0,10), 300)
-	seedDataForSummaryTest(t, db, periodStart.AddDate(0,0,15), 500, periodStart.AddDate(0,0,20), 200) // Income: 1500, Expense: 500
+	seedDataForSummaryTest(t, db, periodStart.AddDate(0, 0, 5), 1000, periodStart.AddDate(0, 0, 10), 300)
+	seedDataForSummaryTest(t, db, periodStart.AddDate(0, 0, 15), 500, periodStart.AddDate(0, 0, 20), 200) // Income: 1500, Expense: 500
 
-	summary, err := service.GetOrCreateFinancialSummary("monthly", targetDate, "overall")
+	summary, err := service.GetOrCreateFinancialSummary(testUserID, "monthly", targetDate, "overall")
 	assert.NoError(t, err)
 	assert.NotNil(t, summary)
-	assert.Equal(t, 1500.0, summary.TotalIncome)
-	assert.Equal(t, 500.0, summary.TotalExpenses)
-	assert.Equal(t, 1000.0, summary.NetBalance)
+	assert.Equal(t, types.Money(1500), summary.TotalIncome)
+	assert.Equal(t, types.Money(500), summary.TotalExpenses)
+	assert.Equal(t, types.Money(1000), summary.NetBalance)
 	assert.Equal(t, periodStart, summary.PeriodStartDate)
 
 	// Verify it was stored in DB
 	var dbSummary models.FinancialSummary
 	result := db.Where("summary_type = ? AND period_start_date = ?", "monthly", periodStart).First(&dbSummary)
 	assert.NoError(t, result.Error)
-	assert.Equal(t, 1500.0, dbSummary.TotalIncome)
+	assert.Equal(t, types.Money(1500), dbSummary.TotalIncome)
 }
 
 func TestGetOrCreateFinancialSummary_ViewOverall_Monthly_Existing(t *testing.T) {
@@ -220,10 +218,11 @@ func TestGetOrCreateFinancialSummary_ViewOverall_Monthly_Existing(t *testing.T)
 	service := NewSummaryService(db)
 	targetDate := time.Date(2023, time.May, 10, 0, 0, 0, 0, time.UTC)
 	periodStart := time.Date(2023, time.May, 1, 0, 0, 0, 0, time.UTC)
-	periodEnd := time.Date(2023, time.May, 31, 0,0,0,0,time.UTC)
+	periodEnd := time.Date(2023, time.May, 31, 0, 0, 0, 0, time.UTC)
 
 	// Pre-store a summary
 	preStoredSummary := models.FinancialSummary{
+		UserID:          testUserID,
 		SummaryType:     "monthly",
 		PeriodStartDate: periodStart,
 		PeriodEndDate:   periodEnd,
@@ -234,15 +233,14 @@ func TestGetOrCreateFinancialSummary_ViewOverall_Monthly_Existing(t *testing.T)
 	db.Create(&preStoredSummary)
 
 	// Seed some data anyway, to ensure the service prefers the stored one for "overall"
-	seedDataForSummaryTest(t, db, periodStart.AddDate(0,0,5), 100, periodStart.AddDate(0,0,10), 50)
+	seedDataForSummaryTest(t, db, periodStart.AddDate(0, 0, 5), 100, periodStart.AddDate(0, 0, 10), 50)
 
-
-	summary, err := service.GetOrCreateFinancialSummary("monthly", targetDate, "overall")
+	summary, err := service.GetOrCreateFinancialSummary(testUserID, "monthly", targetDate, "overall")
 	assert.NoError(t, err)
 	assert.NotNil(t, summary)
-	assert.Equal(t, 2000.0, summary.TotalIncome, "Should return pre-stored income")
-	assert.Equal(t, 800.0, summary.TotalExpenses, "Should return pre-stored expenses")
-	assert.Equal(t, 1200.0, summary.NetBalance, "Should return pre-stored net balance")
+	assert.Equal(t, types.Money(2000), summary.TotalIncome, "Should return pre-stored income")
+	assert.Equal(t, types.Money(800), summary.TotalExpenses, "Should return pre-stored expenses")
+	assert.Equal(t, types.Money(1200), summary.NetBalance, "Should return pre-stored net balance")
 }
 
 func TestGetOrCreateFinancialSummary_ViewIncome_Monthly(t *testing.T) {
@@ -251,14 +249,14 @@ func TestGetOrCreateFinancialSummary_ViewIncome_Monthly(t *testing.T) {
 	targetDate := time.Date(2023, time.June, 10, 0, 0, 0, 0, time.UTC)
 	periodStart := time.Date(2023, time.June, 1, 0, 0, 0, 0, time.UTC)
 
-	seedDataForSummaryTest(t, db, periodStart.AddDate(0,0,5), 1200, periodStart.AddDate(0,0,10), 300)
+	seedDataForSummaryTest(t, db, periodStart.AddDate(0, 0, 5), 1200, periodStart.AddDate(0, 0, 10), 300)
 
-	summary, err := service.GetOrCreateFinancialSummary("monthly", targetDate, "income")
+	summary, err := service.GetOrCreateFinancialSummary(testUserID, "monthly", targetDate, "income")
 	assert.NoError(t, err)
 	assert.NotNil(t, summary)
-	assert.Equal(t, 1200.0, summary.TotalIncome)
-	assert.Equal(t, 0.0, summary.TotalExpenses)
-	assert.Equal(t, 1200.0, summary.NetBalance)
+	assert.Equal(t, types.Money(1200), summary.TotalIncome)
+	assert.Equal(t, types.Money(0), summary.TotalExpenses)
+	assert.Equal(t, types.Money(1200), summary.NetBalance)
 
 	// Verify it was NOT stored as a new specific record (overall might be there if called before)
 	var dbSummaries []models.FinancialSummary
@@ -276,14 +274,14 @@ func TestGetOrCreateFinancialSummary_ViewExpenses_Monthly(t *testing.T) {
 	targetDate := time.Date(2023, time.July, 10, 0, 0, 0, 0, time.UTC)
 	periodStart := time.Date(2023, time.July, 1, 0, 0, 0, 0, time.UTC)
 
-	seedDataForSummaryTest(t, db, periodStart.AddDate(0,0,5), 1500, periodStart.AddDate(0,0,10), 450)
+	seedDataForSummaryTest(t, db, periodStart.AddDate(0, 0, 5), 1500, periodStart.AddDate(0, 0, 10), 450)
 
-	summary, err := service.GetOrCreateFinancialSummary("monthly", targetDate, "expenses")
+	summary, err := service.GetOrCreateFinancialSummary(testUserID, "monthly", targetDate, "expenses")
 	assert.NoError(t, err)
 	assert.NotNil(t, summary)
-	assert.Equal(t, 0.0, summary.TotalIncome)
-	assert.Equal(t, 450.0, summary.TotalExpenses)
-	assert.Equal(t, -450.0, summary.NetBalance)
+	assert.Equal(t, types.Money(0), summary.TotalIncome)
+	assert.Equal(t, types.Money(450), summary.TotalExpenses)
+	assert.Equal(t, types.Money(-450), summary.NetBalance)
 
 	var dbSummaries []models.FinancialSummary
 	db.Where("summary_type = ? AND period_start_date = ?", "monthly", periodStart).Find(&dbSummaries)
@@ -299,28 +297,27 @@ func TestGetOrCreateFinancialSummary_ViewIncome_Weekly(t *testing.T) {
 	// Test for a week: Monday 2023-Oct-02 to Sunday 2023-Oct-08
 	targetDateInWeek := time.Date(2023, time.October, 4, 0, 0, 0, 0, time.UTC) // A Wednesday
 
-	seedDataForSummaryTest(t, db, time.Date(2023, time.October, 3, 0,0,0,0,time.UTC), 200, time.Date(2023, time.October, 5,0,0,0,0,time.UTC), 50) // In week
-	seedDataForSummaryTest(t, db, time.Date(2023, time.September, 30,0,0,0,0,time.UTC), 1000, time.Date(2023, time.October, 10,0,0,0,0,time.UTC), 500) // Outside week
+	seedDataForSummaryTest(t, db, time.Date(2023, time.October, 3, 0, 0, 0, 0, time.UTC), 200, time.Date(2023, time.October, 5, 0, 0, 0, 0, time.UTC), 50)       // In week
+	seedDataForSummaryTest(t, db, time.Date(2023, time.September, 30, 0, 0, 0, 0, time.UTC), 1000, time.Date(2023, time.October, 10, 0, 0, 0, 0, time.UTC), 500) // Outside week
 
-	summary, err := service.GetOrCreateFinancialSummary("weekly", targetDateInWeek, "income")
+	summary, err := service.GetOrCreateFinancialSummary(testUserID, "weekly", targetDateInWeek, "income")
 	assert.NoError(t, err)
 	assert.NotNil(t, summary)
-	assert.Equal(t, 200.0, summary.TotalIncome)
-	assert.Equal(t, 0.0, summary.TotalExpenses)
-	assert.Equal(t, 200.0, summary.NetBalance)
+	assert.Equal(t, types.Money(200), summary.TotalIncome)
+	assert.Equal(t, types.Money(0), summary.TotalExpenses)
+	assert.Equal(t, types.Money(200), summary.NetBalance)
 }
 
-
 func TestGetOrCreateFinancialSummary_ViewNotImplemented(t *testing.T) {
 	db := setupSummaryTestDB(t)
 	service := NewSummaryService(db)
 	targetDate := time.Now()
 
-	_, errSavings := service.GetOrCreateFinancialSummary("monthly", targetDate, "savings")
+	_, errSavings := service.GetOrCreateFinancialSummary(testUserID, "monthly", targetDate, "savings")
 	assert.Error(t, errSavings)
 	assert.Contains(t, errSavings.Error(), "viewType 'savings' not yet implemented")
 
-	_, errDebts := service.GetOrCreateFinancialSummary("monthly", targetDate, "debts")
+	_, errDebts := service.GetOrCreateFinancialSummary(testUserID, "monthly", targetDate, "debts")
 	assert.Error(t, errDebts)
 	assert.Contains(t, errDebts.Error(), "viewType 'debts' not yet implemented")
 }
@@ -330,7 +327,7 @@ func TestGetOrCreateFinancialSummary_ViewInvalid(t *testing.T) {
 	service := NewSummaryService(db)
 	targetDate := time.Now()
 
-	_, err := service.GetOrCreateFinancialSummary("monthly", targetDate, "invalid_view")
+	_, err := service.GetOrCreateFinancialSummary(testUserID, "monthly", targetDate, "invalid_view")
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "invalid viewType 'invalid_view'")
 }
@@ -358,8 +355,7 @@ func TestInvalidateSummariesForDate(t *testing.T) {
 		otherDate := itemDate.AddDate(0, -2, 0) // Two months before
 		seedFinancialSummary(t, db, "monthly", otherDate, 100, 50)
 
-
-		err := service.InvalidateSummariesForDate(itemDate, summaryTypesToInvalidate)
+		err := service.InvalidateSummariesForDate(testUserID, itemDate, summaryTypesToInvalidate)
 		assert.NoError(t, err)
 
 		// Verify targeted summaries are deleted
@@ -383,9 +379,9 @@ func TestInvalidateSummariesForDate(t *testing.T) {
 		db := setupSummaryTestDB(t)
 		service := NewSummaryService(db)
 
-		nonExistentItemDate := time.Date(2020, time.January, 1, 0,0,0,0, time.UTC)
+		nonExistentItemDate := time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
 
-		err := service.InvalidateSummariesForDate(nonExistentItemDate, summaryTypesToInvalidate)
+		err := service.InvalidateSummariesForDate(testUserID, nonExistentItemDate, summaryTypesToInvalidate)
 		assert.NoError(t, err, "Should not error if no summaries found to delete")
 
 		var count int64
@@ -399,7 +395,7 @@ func TestInvalidateSummariesForDate(t *testing.T) {
 
 		seededSummary := seedFinancialSummary(t, db, "monthly", itemDate, 100, 50)
 
-		err := service.InvalidateSummariesForDate(itemDate, []string{})
+		err := service.InvalidateSummariesForDate(testUserID, itemDate, []string{})
 		assert.NoError(t, err)
 
 		var count int64
@@ -415,7 +411,7 @@ func TestInvalidateSummariesForDate(t *testing.T) {
 		seedFinancialSummary(t, db, "weekly", itemDate, 200, 20)
 		seedFinancialSummary(t, db, "yearly", itemDate, 300, 30)
 
-		err := service.InvalidateSummariesForDate(itemDate, []string{"monthly"})
+		err := service.InvalidateSummariesForDate(testUserID, itemDate, []string{"monthly"})
 		assert.NoError(t, err)
 
 		var count int64
@@ -433,9 +429,25 @@ func TestInvalidateSummariesForDate(t *testing.T) {
 		db := setupSummaryTestDB(t)
 		service := NewSummaryService(db)
 
-		err := service.InvalidateSummariesForDate(itemDate, []string{"invalid-type"})
+		err := service.InvalidateSummariesForDate(testUserID, itemDate, []string{"invalid-type"})
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "failed to calculate period for invalid-type")
 		assert.Contains(t, err.Error(), "invalid summary type: invalid-type")
 	})
 }
+
+func TestGetOrCreateFinancialSummary_IncludesPeriodBoundaries(t *testing.T) {
+	db := setupSummaryTestDB(t)
+	service := NewSummaryService(db)
+	periodStart := time.Date(2023, time.August, 1, 0, 0, 0, 0, time.UTC)
+	periodEnd := time.Date(2023, time.August, 31, 0, 0, 0, 0, time.UTC)
+
+	// Records on the first and last day of the month belong to that month on every engine.
+	seedDataForSummaryTest(t, db, periodStart, 700, periodEnd, 250)
+
+	summary, err := service.GetOrCreateFinancialSummary(testUserID, "monthly", periodStart.AddDate(0, 0, 14), "overall")
+	assert.NoError(t, err)
+	assert.NotNil(t, summary)
+	assert.Equal(t, types.Money(700), summary.TotalIncome)
+	assert.Equal(t, types.Money(250), summary.TotalExpenses)
+}
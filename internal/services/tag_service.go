@@ -0,0 +1,275 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/zayyadi/finance-tracker/internal/database"
+	"github.com/zayyadi/finance-tracker/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// taggedTables maps the record types that can be tagged to their tables.
+var taggedTables = map[string]string{
+	"income":  "incomes",
+	"expense": "expenses",
+	"debt":    "debts",
+	"savings": "savings",
+}
+
+// TagService manages users' tags.
+type TagService struct {
+	DB *gorm.DB
+}
+
+// NewTagService creates a new TagService with a GORM database connection.
+func NewTagService(db *gorm.DB) *TagService {
+	if db == nil {
+		log.Println("Warning: NewTagService called with nil DB, attempting to use global GetDB()")
+		db = database.GetDB()
+	}
+	return &TagService{DB: db}
+}
+
+// normalizeTag lower-cases a tag name and joins its words with hyphens, so "Vacation 2026" and
+// "vacation-2026" are the same tag.
+func normalizeTag(name string) (string, error) {
+	tag := strings.ToLower(strings.Join(strings.Fields(name), "-"))
+	switch {
+	case tag == "":
+		return "", fmt.Errorf("invalid tag: tags must not be blank")
+	case strings.Contains(tag, ","):
+		return "", fmt.Errorf("invalid tag %q: tags cannot contain commas", name)
+	case len(tag) > 50:
+		return "", fmt.Errorf("invalid tag %q: tags are at most 50 characters", name)
+	}
+	return tag, nil
+}
+
+// normalizeTags normalizes a list of tag names, dropping repeats.
+func normalizeTags(names []string) ([]string, error) {
+	tags := make([]string, 0, len(names))
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		tag, err := normalizeTag(name)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+	sort.Strings(tags)
+	return tags, nil
+}
+
+// ParseTagFilter splits a comma-separated "tags" query parameter into normalized tag names.
+func ParseTagFilter(param string) ([]string, error) {
+	if strings.TrimSpace(param) == "" {
+		return nil, nil
+	}
+	var names []string
+	for _, name := range strings.Split(param, ",") {
+		if strings.TrimSpace(name) != "" {
+			names = append(names, name)
+		}
+	}
+	return normalizeTags(names)
+}
+
+// setTags replaces the tags on a record, creating tags the user has not used before. names must be
+// normalized.
+func setTags(db *gorm.DB, userID uint, recordType string, recordID uint, names []string) error {
+	if err := db.Where("record_type = ? AND record_id = ?", recordType, recordID).Delete(&models.Tagging{}).Error; err != nil {
+		return fmt.Errorf("could not clear tags: %w", err)
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	tags := make([]models.Tag, len(names))
+	for i, name := range names {
+		tags[i] = models.Tag{UserID: userID, Name: name}
+	}
+	if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&tags).Error; err != nil {
+		return fmt.Errorf("could not create tags: %w", err)
+	}
+	// Look the tags up again: IDs are not filled in for tags that already existed.
+	if err := db.Where("user_id = ? AND name IN ?", userID, names).Find(&tags).Error; err != nil {
+		return fmt.Errorf("could not load tags: %w", err)
+	}
+	taggings := make([]models.Tagging, len(tags))
+	for i, tag := range tags {
+		taggings[i] = models.Tagging{TagID: tag.ID, RecordType: recordType, RecordID: recordID}
+	}
+	if err := db.Create(&taggings).Error; err != nil {
+		return fmt.Errorf("could not tag %s %d: %w", recordType, recordID, err)
+	}
+	return nil
+}
+
+// copyTags adds the tags on one record to another of the same type.
+func copyTags(db *gorm.DB, recordType string, fromID, toID uint) error {
+	err := db.Exec(`INSERT INTO taggings (created_at, tag_id, record_type, record_id)
+		SELECT ?, tag_id, record_type, ? FROM taggings
+		WHERE record_type = ? AND record_id = ?
+		AND tag_id NOT IN (SELECT tag_id FROM taggings WHERE record_type = ? AND record_id = ?)`,
+		time.Now().UTC(), toID, recordType, fromID, recordType, toID).Error
+	if err != nil {
+		return fmt.Errorf("could not copy tags: %w", err)
+	}
+	return nil
+}
+
+// loadTags returns the tag names on each of the given records, sorted.
+func loadTags(db *gorm.DB, recordType string, recordIDs []uint) (map[uint][]string, error) {
+	tags := make(map[uint][]string, len(recordIDs))
+	if len(recordIDs) == 0 {
+		return tags, nil
+	}
+	var rows []struct {
+		RecordID uint
+		Name     string
+	}
+	err := db.Model(&models.Tagging{}).Select("taggings.record_id, tags.name").
+		Joins("JOIN tags ON tags.id = taggings.tag_id").
+		Where("taggings.record_type = ? AND taggings.record_id IN ?", recordType, recordIDs).
+		Order("tags.name").Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("could not load tags: %w", err)
+	}
+	for _, row := range rows {
+		tags[row.RecordID] = append(tags[row.RecordID], row.Name)
+	}
+	return tags, nil
+}
+
+// whereTagged limits query, on the table of recordType, to the user's records that carry every one of
+// the given normalized tags.
+func whereTagged(query *gorm.DB, userID uint, recordType string, tags []string) *gorm.DB {
+	if len(tags) == 0 {
+		return query
+	}
+	return query.Where(taggedTables[recordType]+".id IN (?)", query.Session(&gorm.Session{NewDB: true}).
+		Model(&models.Tagging{}).Select("taggings.record_id").
+		Joins("JOIN tags ON tags.id = taggings.tag_id").
+		Where("taggings.record_type = ? AND tags.user_id = ? AND tags.name IN ?", recordType, userID, tags).
+		Group("taggings.record_id").
+		Having("COUNT(DISTINCT tags.id) = ?", len(tags)))
+}
+
+// GetTags lists the user's tags in name order, with how many records carry each.
+func (s *TagService) GetTags(userID uint) ([]models.TagUsage, error) {
+	if s.DB == nil {
+		return nil, fmt.Errorf("database connection not initialized in TagService")
+	}
+	var tags []models.TagUsage
+	err := s.DB.Model(&models.Tag{}).Select("tags.id, tags.name, COUNT(taggings.id) AS count").
+		Joins("LEFT JOIN taggings ON taggings.tag_id = tags.id").
+		Where("tags.user_id = ?", userID).
+		Group("tags.id, tags.name").Order("tags.name").Scan(&tags).Error
+	if err != nil {
+		log.Printf("Error retrieving tags for user %d: %v", userID, err)
+		return nil, fmt.Errorf("could not retrieve tags: %w", err)
+	}
+	if tags == nil {
+		return []models.TagUsage{}, nil
+	}
+	return tags, nil
+}
+
+// GetTagByID retrieves a specific tag by its ID, scoped to the given user.
+func (s *TagService) GetTagByID(userID uint, tagID uint) (*models.Tag, error) {
+	if s.DB == nil {
+		return nil, fmt.Errorf("database connection not initialized in TagService")
+	}
+	var tag models.Tag
+	if err := s.DB.Where("id = ? AND user_id = ?", tagID, userID).First(&tag).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("tag not found")
+		}
+		log.Printf("Error retrieving tag %d for user %d: %v", tagID, userID, err)
+		return nil, fmt.Errorf("could not retrieve tag: %w", err)
+	}
+	return &tag, nil
+}
+
+// RenameTag renames a tag everywhere it is used. Renaming it to the name of another of the user's
+// tags merges the two.
+func (s *TagService) RenameTag(userID uint, tagID uint, name string) (*models.Tag, error) {
+	if s.DB == nil {
+		return nil, fmt.Errorf("database connection not initialized in TagService")
+	}
+	tag, err := s.GetTagByID(userID, tagID)
+	if err != nil {
+		return nil, err
+	}
+	name, err = normalizeTag(name)
+	if err != nil {
+		return nil, err
+	}
+	if name == tag.Name {
+		return tag, nil
+	}
+
+	var existing models.Tag
+	err = s.DB.Where("user_id = ? AND name = ?", userID, name).First(&existing).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		if err := s.DB.Model(tag).Update("name", name).Error; err != nil {
+			log.Printf("Error renaming tag %d: %v", tagID, err)
+			return nil, fmt.Errorf("could not rename tag: %w", err)
+		}
+		return tag, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not rename tag: %w", err)
+	}
+
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		// Move the taggings over, except on records that already carry the other tag.
+		if err := tx.Model(&models.Tagging{}).Where("tag_id = ?", tagID).
+			Where("NOT EXISTS (SELECT 1 FROM taggings other WHERE other.tag_id = ? AND other.record_type = taggings.record_type AND other.record_id = taggings.record_id)", existing.ID).
+			Update("tag_id", existing.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("tag_id = ?", tagID).Delete(&models.Tagging{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(tag).Error
+	})
+	if err != nil {
+		log.Printf("Error merging tag %d into %d: %v", tagID, existing.ID, err)
+		return nil, fmt.Errorf("could not rename tag: %w", err)
+	}
+	return &existing, nil
+}
+
+// DeleteTag removes a tag from every record that carries it and deletes it.
+func (s *TagService) DeleteTag(userID uint, tagID uint) error {
+	if s.DB == nil {
+		return fmt.Errorf("database connection not initialized in TagService")
+	}
+	tag, err := s.GetTagByID(userID, tagID)
+	if err != nil {
+		if strings.Contains(err.Error(), "tag not found") {
+			return fmt.Errorf("tag not found, no rows deleted")
+		}
+		return err
+	}
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("tag_id = ?", tagID).Delete(&models.Tagging{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(tag).Error
+	})
+	if err != nil {
+		log.Printf("Error deleting tag %d: %v", tagID, err)
+		return fmt.Errorf("could not delete tag: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zayyadi/finance-tracker/internal/database"
+	"github.com/zayyadi/finance-tracker/internal/models"
+	"github.com/zayyadi/finance-tracker/internal/types"
+	"gorm.io/gorm"
+)
+
+func TestTagService_TagsRecords(t *testing.T) {
+	db := setupAccountTestDB(t)
+	require.NoError(t, db.AutoMigrate(&models.Debt{}, &models.Savings{}))
+	expenseService := NewExpenseService(db)
+	incomeService := NewIncomeService(db)
+	service := NewTagService(db)
+	day := database.CustomDate{Time: time.Date(2024, time.June, 3, 0, 0, 0, 0, time.UTC)}
+
+	flight := &models.Expense{UserID: testUserID, Amount: types.Money(40000), Category: "Travel", Date: day, Tags: []string{"Vacation 2024", "reimbursable"}}
+	require.NoError(t, expenseService.CreateExpense(flight))
+	assert.Equal(t, []string{"reimbursable", "vacation-2024"}, flight.Tags, "Tags are normalized and sorted")
+	hotel := &models.Expense{UserID: testUserID, Amount: types.Money(30000), Category: "Travel", Date: day, Tags: []string{"vacation-2024", "VACATION-2024"}}
+	require.NoError(t, expenseService.CreateExpense(hotel))
+	assert.Equal(t, []string{"vacation-2024"}, hotel.Tags)
+	require.NoError(t, expenseService.CreateExpense(&models.Expense{UserID: testUserID, Amount: types.Money(1000), Category: "Food", Date: day}))
+	refund := &models.Income{UserID: testUserID, Amount: types.Money(40000), Category: "Refunds", Date: day, Tags: []string{"reimbursable"}}
+	require.NoError(t, incomeService.CreateIncome(refund))
+	err := expenseService.CreateExpense(&models.Expense{UserID: testUserID, Amount: types.Money(1000), Category: "Food", Date: day, Tags: []string{"  "}})
+	assert.ErrorContains(t, err, "invalid tag")
+
+	// Records must carry every tag in the filter.
+	expenses, err := expenseService.GetExpenses(testUserID, 1, 10, models.ListFilter{Tags: []string{"vacation-2024"}})
+	require.NoError(t, err)
+	assert.Len(t, expenses.Items, 2)
+	expenses, err = expenseService.GetExpenses(testUserID, 1, 10, models.ListFilter{Tags: []string{"vacation-2024", "reimbursable"}})
+	require.NoError(t, err)
+	require.Len(t, expenses.Items, 1)
+	assert.Equal(t, flight.ID, expenses.Items[0].ID)
+	assert.Equal(t, []string{"reimbursable", "vacation-2024"}, expenses.Items[0].Tags)
+	incomes, err := incomeService.GetIncomes(testUserID, 1, 10, models.ListFilter{Tags: []string{"vacation-2024"}})
+	require.NoError(t, err)
+	assert.Empty(t, incomes.Items)
+
+	// Debts and savings goals can be tagged too.
+	debtService := NewDebtService(db)
+	debt := &models.Debt{UserID: testUserID, DebtorName: "Sam", Amount: types.Money(5000), DueDate: day, Status: "Pending", Tags: []string{"vacation-2024"}}
+	require.NoError(t, debtService.CreateDebt(debt))
+	debts, err := debtService.GetDebts(testUserID, 1, 10, "", models.ListFilter{Tags: []string{"vacation-2024"}})
+	require.NoError(t, err)
+	require.Len(t, debts.Items, 1)
+	assert.Equal(t, []string{"vacation-2024"}, debts.Items[0].Tags)
+	savingsService := NewSavingsService(db)
+	goal := &models.Savings{UserID: testUserID, GoalName: "Trip", GoalAmount: types.Money(100000)}
+	require.NoError(t, savingsService.CreateSavings(goal))
+	updatedGoal, err := savingsService.UpdateSavings(testUserID, goal.ID, &models.SavingsUpdateRequest{Tags: &[]string{"Vacation 2024"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"vacation-2024"}, updatedGoal.Tags)
+
+	// Updating tags replaces them; an empty list removes them all.
+	_, err = expenseService.UpdateExpense(testUserID, hotel.ID, &models.ExpenseUpdateRequest{Tags: &[]string{"work"}})
+	require.NoError(t, err)
+	reloaded, err := expenseService.GetExpenseByID(testUserID, hotel.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"work"}, reloaded.Tags)
+	_, err = expenseService.UpdateExpense(testUserID, hotel.ID, &models.ExpenseUpdateRequest{Tags: &[]string{}})
+	require.NoError(t, err)
+	reloaded, err = expenseService.GetExpenseByID(testUserID, hotel.ID)
+	require.NoError(t, err)
+	assert.Empty(t, reloaded.Tags)
+
+	tags, err := service.GetTags(testUserID)
+	require.NoError(t, err)
+	require.Len(t, tags, 3)
+	assert.Equal(t, "reimbursable", tags[0].Name)
+	assert.Equal(t, int64(2), tags[0].Count)
+	assert.Equal(t, "vacation-2024", tags[1].Name)
+	assert.Equal(t, int64(3), tags[1].Count)
+	assert.Equal(t, int64(0), tags[2].Count, "work is no longer used")
+
+	// Tags appear in analytics and reports.
+	stats, err := NewAnalyticsService(db).GetTagBreakdown(testUserID, day.Time)
+	require.NoError(t, err)
+	require.Len(t, stats, 2)
+	assert.Equal(t, models.TagStat{Tag: "reimbursable", TotalIncome: types.Money(40000), TotalExpenses: types.Money(40000), Currency: "USD"}, stats[0])
+	assert.Equal(t, models.TagStat{Tag: "vacation-2024", TotalExpenses: types.Money(40000), Currency: "USD"}, stats[1])
+	csv, err := NewReportService(incomeService, expenseService).GenerateTransactionsCSV(testUserID, day.Time, day.Time)
+	require.NoError(t, err)
+	assert.True(t, strings.HasSuffix(strings.SplitN(csv, "\n", 2)[0], ",Tags"))
+	assert.Contains(t, csv, `"reimbursable, vacation-2024"`)
+
+	// Renaming a tag to an existing one merges them.
+	merged, err := service.RenameTag(testUserID, tags[0].ID, "Vacation 2024")
+	require.NoError(t, err)
+	assert.Equal(t, tags[1].ID, merged.ID)
+	reloaded, err = expenseService.GetExpenseByID(testUserID, flight.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"vacation-2024"}, reloaded.Tags)
+	refundReloaded, err := incomeService.GetIncomeByID(testUserID, refund.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"vacation-2024"}, refundReloaded.Tags)
+
+	require.NoError(t, service.DeleteTag(testUserID, merged.ID))
+	reloaded, err = expenseService.GetExpenseByID(testUserID, flight.ID)
+	require.NoError(t, err)
+	assert.Empty(t, reloaded.Tags)
+	assert.ErrorContains(t, service.DeleteTag(testUserID, merged.ID), "tag not found")
+}
+
+func TestTagService_FailedUpdateKeepsTags(t *testing.T) {
+	db := setupAccountTestDB(t)
+	require.NoError(t, db.AutoMigrate(&models.Debt{}, &models.Savings{}))
+	day := database.CustomDate{Time: time.Date(2024, time.June, 3, 0, 0, 0, 0, time.UTC)}
+	income := &models.Income{UserID: testUserID, Amount: types.Money(1000), Category: "Salary", Date: day, Tags: []string{"old"}}
+	require.NoError(t, NewIncomeService(db).CreateIncome(income))
+	debt := &models.Debt{UserID: testUserID, DebtorName: "Sam", Amount: types.Money(5000), Currency: "USD", DueDate: day, Status: "Pending", Tags: []string{"old"}}
+	require.NoError(t, NewDebtService(db).CreateDebt(debt))
+	savings := &models.Savings{UserID: testUserID, GoalName: "Bike", GoalAmount: types.Money(50000), Currency: "USD", Tags: []string{"old"}}
+	require.NoError(t, NewSavingsService(db).CreateSavings(savings))
+
+	// Every update of a record fails from here on.
+	require.NoError(t, db.Callback().Update().Before("gorm:update").Register("test:fail_updates", func(tx *gorm.DB) {
+		_ = tx.AddError(errors.New("disk full"))
+	}))
+	tags := []string{"new"}
+	note := "changed"
+	_, err := NewIncomeService(db).UpdateIncome(testUserID, income.ID, &models.IncomeUpdateRequest{Note: &note, Tags: &tags})
+	assert.ErrorContains(t, err, "disk full")
+	_, err = NewDebtService(db).UpdateDebt(testUserID, debt.ID, &models.DebtUpdateRequest{Description: &note, Tags: &tags})
+	assert.ErrorContains(t, err, "disk full")
+	_, err = NewSavingsService(db).UpdateSavings(testUserID, savings.ID, &models.SavingsUpdateRequest{Notes: &note, Tags: &tags})
+	assert.ErrorContains(t, err, "disk full")
+
+	for recordType, id := range map[string]uint{"income": income.ID, "debt": debt.ID, "savings": savings.ID} {
+		recordTags, err := loadTags(db, recordType, []uint{id})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"old"}, recordTags[id], "A failed %s update leaves its tags alone", recordType)
+	}
+}
@@ -0,0 +1,232 @@
+package services
+
+import (
+	"encoding/base64"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zayyadi/finance-tracker/internal/database"
+	"github.com/zayyadi/finance-tracker/internal/models"
+	"gorm.io/gorm"
+)
+
+// transactionColumns are the columns of the combined ledger, selected from the incomes and expenses
+// tables. %s is the expression for the signed amount.
+const transactionColumns = "'%s' AS type, id, date, created_at, category, category_id, note, %s AS amount, currency, account_id"
+
+// TransactionService lists incomes and expenses together as one ledger.
+type TransactionService struct {
+	DB *gorm.DB
+}
+
+// NewTransactionService creates a new TransactionService with a GORM database connection.
+func NewTransactionService(db *gorm.DB) *TransactionService {
+	if db == nil {
+		log.Println("Warning: NewTransactionService called with nil DB, attempting to use global GetDB()")
+		db = database.GetDB()
+	}
+	return &TransactionService{DB: db}
+}
+
+// transactionCursor is the position of a transaction in the ledger's order: by date, then by creation
+// time, type and ID.
+type transactionCursor struct {
+	date       string
+	createdAt  time.Time
+	recordType string
+	id         uint
+}
+
+// encode returns the cursor as an opaque URL-safe string.
+func (c transactionCursor) encode() string {
+	raw := fmt.Sprintf("%s|%d|%s|%d", c.date, c.createdAt.UnixNano(), c.recordType, c.id)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// parseTransactionCursor decodes a cursor returned by encode.
+func parseTransactionCursor(s string) (transactionCursor, error) {
+	invalid := fmt.Errorf("invalid cursor: pass the next_cursor of a previous page")
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return transactionCursor{}, invalid
+	}
+	parts := strings.Split(string(raw), "|")
+	if len(parts) != 4 || (parts[2] != "income" && parts[2] != "expense") {
+		return transactionCursor{}, invalid
+	}
+	if _, err := time.Parse("2006-01-02", parts[0]); err != nil {
+		return transactionCursor{}, invalid
+	}
+	nanos, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return transactionCursor{}, invalid
+	}
+	id, err := strconv.ParseUint(parts[3], 10, 32)
+	if err != nil {
+		return transactionCursor{}, invalid
+	}
+	return transactionCursor{date: parts[0], createdAt: time.Unix(0, nanos), recordType: parts[2], id: uint(id)}, nil
+}
+
+// cursorOf returns the position of a transaction.
+func cursorOf(txn models.Transaction) transactionCursor {
+	return transactionCursor{date: txn.Date.Format("2006-01-02"), createdAt: txn.CreatedAt, recordType: txn.Type, id: txn.ID}
+}
+
+// whereBeyond limits query to the transactions after cursor in the ledger's order, or before it when
+// before is set.
+func whereBeyond(query *gorm.DB, cursor transactionCursor, before bool) *gorm.DB {
+	op := ">"
+	if before {
+		op = "<"
+	}
+	return query.Where("date "+op+" ? OR (date = ? AND (created_at "+op+" ? OR (created_at = ? AND (type "+op+" ? OR (type = ? AND id "+op+" ?)))))",
+		cursor.date, cursor.date, cursor.createdAt, cursor.createdAt, cursor.recordType, cursor.recordType, cursor.id)
+}
+
+// GetTransactions returns a page of the user's incomes and expenses as one ledger, oldest first
+// unless filter.Order is "desc", starting after cursor (from the start when empty). Each
+// transaction carries the running balance, in the base currency, of all the transactions matching
+// filter up to and including it. limit is capped at models.MaxPageLimit.
+func (s *TransactionService) GetTransactions(userID uint, filter models.TransactionFilter, cursor string, limit int) (*models.TransactionPage, error) {
+	if s.DB == nil {
+		return nil, fmt.Errorf("database connection not initialized in TransactionService")
+	}
+	if limit > models.MaxPageLimit {
+		limit = models.MaxPageLimit
+	}
+	descending := false
+	switch filter.Order {
+	case "", "asc":
+	case "desc":
+		descending = true
+	default:
+		return nil, fmt.Errorf("invalid filter: order must be asc or desc")
+	}
+
+	ledger, err := s.ledgerQuery(userID, filter)
+	if err != nil {
+		return nil, err
+	}
+	query := ledger
+	if cursor != "" {
+		position, err := parseTransactionCursor(cursor)
+		if err != nil {
+			return nil, err
+		}
+		query = whereBeyond(query, position, descending)
+	}
+	order := "date, created_at, type, id"
+	if descending {
+		order = "date desc, created_at desc, type desc, id desc"
+	}
+
+	var transactions []models.Transaction
+	if err := query.Order(order).Limit(limit + 1).Scan(&transactions).Error; err != nil {
+		log.Printf("Error retrieving transactions for user %d: %v", userID, err)
+		return nil, fmt.Errorf("could not retrieve transactions: %w", err)
+	}
+
+	converter, err := NewCurrencyService(s.DB).NewConverter(userID)
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve transactions: %w", err)
+	}
+	page := &models.TransactionPage{Transactions: []models.Transaction{}, Currency: converter.Base}
+	if len(transactions) > limit {
+		transactions = transactions[:limit]
+		page.NextCursor = cursorOf(transactions[limit-1]).encode()
+	}
+	if len(transactions) == 0 {
+		return page, nil
+	}
+
+	// Start from the total of everything before the page's oldest transaction, and add the page up
+	// in date order.
+	oldest, step := 0, 1
+	if descending {
+		oldest, step = len(transactions)-1, -1
+	}
+	balance, err := converter.SumAmounts(whereBeyond(ledger, cursorOf(transactions[oldest]), true))
+	if err != nil {
+		return nil, fmt.Errorf("could not calculate running balance: %w", err)
+	}
+	for i := oldest; i >= 0 && i < len(transactions); i += step {
+		converted, err := converter.Convert(transactions[i].Amount, transactions[i].Currency, transactions[i].Date.Time)
+		if err != nil {
+			return nil, fmt.Errorf("could not calculate running balance: %w", err)
+		}
+		balance += converted
+		transactions[i].Balance = balance
+	}
+	page.Transactions = transactions
+	return page, nil
+}
+
+// ledgerQuery returns a query over the user's incomes and expenses matching filter, as one table of
+// transactions.
+func (s *TransactionService) ledgerQuery(userID uint, filter models.TransactionFilter) (*gorm.DB, error) {
+	type condition struct {
+		sql  string
+		args []interface{}
+	}
+	var conditions []condition
+	if filter.StartDate != "" {
+		start, err := parseDateBound(filter.StartDate, "start")
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, condition{"date >= ?", []interface{}{start}})
+	}
+	if filter.EndDate != "" {
+		end, err := parseDateBound(filter.EndDate, "end")
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, condition{"date <= ?", []interface{}{end}})
+	}
+	if category := strings.TrimSpace(filter.Category); category != "" {
+		conditions = append(conditions, condition{"LOWER(category) = ?", []interface{}{strings.ToLower(category)}})
+	}
+	for _, bound := range []struct{ param, name, op string }{
+		{filter.MinAmount, "min_amount", ">="},
+		{filter.MaxAmount, "max_amount", "<="},
+	} {
+		if bound.param == "" {
+			continue
+		}
+		amount, err := parseAmountBound(bound.param, bound.name)
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, condition{"amount " + bound.op + " ?", []interface{}{amount}})
+	}
+	if text := strings.TrimSpace(filter.Query); text != "" {
+		pattern := "%" + escapeLike(strings.ToLower(text)) + "%"
+		conditions = append(conditions, condition{`(LOWER(note) LIKE ? ESCAPE '\' OR LOWER(category) LIKE ? ESCAPE '\')`, []interface{}{pattern, pattern}})
+	}
+
+	scope := func(model interface{}, recordType, amount string) *gorm.DB {
+		query := s.DB.Model(model).Select(fmt.Sprintf(transactionColumns, recordType, amount)).Where("user_id = ?", userID)
+		for _, c := range conditions {
+			query = query.Where(c.sql, c.args...)
+		}
+		return query
+	}
+	incomes := scope(&models.Income{}, "income", "amount")
+	expenses := scope(&models.Expense{}, "expense", "-amount")
+
+	// The query is reused for the page and for the running balance, so each use starts a new statement.
+	reusable := &gorm.Session{}
+	switch filter.Type {
+	case "":
+		return s.DB.Table("(? UNION ALL ?) AS transactions", incomes, expenses).Session(reusable), nil
+	case "income":
+		return s.DB.Table("(?) AS transactions", incomes).Session(reusable), nil
+	case "expense":
+		return s.DB.Table("(?) AS transactions", expenses).Session(reusable), nil
+	}
+	return nil, fmt.Errorf("invalid filter: type must be income or expense")
+}
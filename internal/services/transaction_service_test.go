@@ -0,0 +1,94 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zayyadi/finance-tracker/internal/database"
+	"github.com/zayyadi/finance-tracker/internal/models"
+	"github.com/zayyadi/finance-tracker/internal/types"
+)
+
+func TestTransactionService_Ledger(t *testing.T) {
+	db := setupAccountTestDB(t)
+	service := NewTransactionService(db)
+	day := func(d int) database.CustomDate {
+		return database.CustomDate{Time: time.Date(2024, time.March, d, 0, 0, 0, 0, time.UTC)}
+	}
+	require.NoError(t, NewIncomeService(db).CreateIncome(&models.Income{UserID: testUserID, Amount: types.Money(300000), Category: "Salary", Date: day(1)}))
+	rent := &models.Expense{UserID: testUserID, Amount: types.Money(120000), Category: "Rent", Date: day(1), Note: "March rent"}
+	require.NoError(t, NewExpenseService(db).CreateExpense(rent))
+	require.NoError(t, NewExpenseService(db).CreateExpense(&models.Expense{UserID: testUserID, Amount: types.Money(4500), Category: "Food", Date: day(3), Note: "Groceries"}))
+	require.NoError(t, db.Create(&models.ExchangeRate{UserID: testUserID, FromCurrency: "EUR", ToCurrency: "USD", Rate: 2, Date: day(1)}).Error)
+	require.NoError(t, NewExpenseService(db).CreateExpense(&models.Expense{UserID: testUserID, Amount: types.Money(1000), Currency: "EUR", Category: "Food", Date: day(2), Note: "Market"}))
+	require.NoError(t, NewIncomeService(db).CreateIncome(&models.Income{UserID: testUserID, Amount: types.Money(5000), Category: "Refunds", Date: day(5)}))
+
+	page, err := service.GetTransactions(testUserID, models.TransactionFilter{}, "", 10)
+	require.NoError(t, err)
+	assert.Equal(t, "USD", page.Currency)
+	assert.Empty(t, page.NextCursor)
+	require.Len(t, page.Transactions, 5)
+	var summary []string
+	for _, txn := range page.Transactions {
+		summary = append(summary, txn.Type+" "+txn.Amount.String()+" "+txn.Currency+" = "+txn.Balance.String())
+	}
+	assert.Equal(t, []string{
+		"income 3000.00 USD = 3000.00",
+		"expense -1200.00 USD = 1800.00",
+		"expense -10.00 EUR = 1780.00",
+		"expense -45.00 USD = 1735.00",
+		"income 50.00 USD = 1785.00",
+	}, summary, "Same-day entries keep the order they were recorded in; balances are in the base currency")
+
+	// Walking the pages in either direction gives the same balances.
+	var walked []models.Transaction
+	cursor := ""
+	for {
+		page, err := service.GetTransactions(testUserID, models.TransactionFilter{Order: "desc"}, cursor, 2)
+		require.NoError(t, err)
+		walked = append(walked, page.Transactions...)
+		if page.NextCursor == "" {
+			break
+		}
+		cursor = page.NextCursor
+	}
+	require.Len(t, walked, 5)
+	assert.Equal(t, "Refunds", walked[0].Category)
+	assert.Equal(t, types.Money(178500), walked[0].Balance)
+	assert.Equal(t, rent.ID, walked[3].ID)
+	assert.Equal(t, types.Money(180000), walked[3].Balance)
+
+	// Filters apply before the running balance.
+	page, err = service.GetTransactions(testUserID, models.TransactionFilter{Type: "expense", Category: "food", StartDate: "2024-03-02"}, "", 10)
+	require.NoError(t, err)
+	require.Len(t, page.Transactions, 2)
+	assert.Equal(t, types.Money(-6500), page.Transactions[1].Balance)
+	page, err = service.GetTransactions(testUserID, models.TransactionFilter{MinAmount: "45", MaxAmount: "1200", Query: "RENT"}, "", 10)
+	require.NoError(t, err)
+	require.Len(t, page.Transactions, 1)
+	assert.Equal(t, rent.ID, page.Transactions[0].ID)
+
+	_, err = service.GetTransactions(testUserID, models.TransactionFilter{MinAmount: "lots"}, "", 10)
+	assert.ErrorContains(t, err, "invalid filter")
+	_, err = service.GetTransactions(testUserID, models.TransactionFilter{EndDate: "March"}, "", 10)
+	assert.ErrorContains(t, err, "invalid end date format")
+	_, err = service.GetTransactions(testUserID, models.TransactionFilter{}, "not-a-cursor", 10)
+	assert.ErrorContains(t, err, "invalid cursor")
+}
+
+func TestTransactionService_LimitIsCapped(t *testing.T) {
+	db := setupAccountTestDB(t)
+	date := database.CustomDate{Time: time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)}
+	expenses := make([]models.Expense, models.MaxPageLimit+1)
+	for i := range expenses {
+		expenses[i] = models.Expense{UserID: testUserID, Amount: types.Money(100), Category: "Food", Date: date}
+	}
+	require.NoError(t, db.Create(&expenses).Error)
+
+	page, err := NewTransactionService(db).GetTransactions(testUserID, models.TransactionFilter{}, "", 1000000)
+	require.NoError(t, err)
+	assert.Len(t, page.Transactions, models.MaxPageLimit)
+	assert.NotEmpty(t, page.NextCursor)
+}
@@ -0,0 +1,287 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/zayyadi/finance-tracker/internal/database"
+	"github.com/zayyadi/finance-tracker/internal/models"
+	"gorm.io/gorm"
+)
+
+// DefaultTrashRetention is how long deleted records are kept in the trash unless configured otherwise.
+const DefaultTrashRetention = 30 * 24 * time.Hour
+
+// TrashService lists, restores and purges soft-deleted incomes, expenses, debts and savings goals.
+type TrashService struct {
+	DB             *gorm.DB
+	Retention      time.Duration // How long PurgeExpired keeps deleted records; 0 keeps them until purged by hand
+	summaryService *SummaryService
+}
+
+// NewTrashService creates a new TrashService. summaryService is used to invalidate summaries covering
+// restored incomes and expenses, and may be nil.
+func NewTrashService(db *gorm.DB, summaryService *SummaryService) *TrashService {
+	if db == nil {
+		log.Println("Warning: NewTrashService called with nil DB, attempting to use global GetDB()")
+		db = database.GetDB()
+	}
+	return &TrashService{DB: db, Retention: DefaultTrashRetention, summaryService: summaryService}
+}
+
+// trashSource returns the columns of the records of recordType, which are those of search results.
+func trashSource(recordType string) (searchSource, error) {
+	for _, source := range searchSources {
+		if source.recordType == recordType {
+			return source, nil
+		}
+	}
+	return searchSource{}, fmt.Errorf("invalid trash type %q, expected income, expense, debt or savings", recordType)
+}
+
+// trashedModel returns the model of the records of recordType.
+func trashedModel(recordType string) interface{} {
+	switch recordType {
+	case "income":
+		return &models.Income{}
+	case "expense":
+		return &models.Expense{}
+	case "debt":
+		return &models.Debt{}
+	}
+	return &models.Savings{}
+}
+
+// GetTrash retrieves a page, counting from 1, of the user's deleted records, most recently deleted first.
+// recordType limits the list to one type of record; empty lists them all.
+func (s *TrashService) GetTrash(userID uint, recordType string, page int, limit int) (*models.Page[models.TrashItem], error) {
+	if s.DB == nil {
+		return nil, fmt.Errorf("database connection not initialized in TrashService")
+	}
+	sources := searchSources
+	if recordType != "" {
+		source, err := trashSource(recordType)
+		if err != nil {
+			return nil, err
+		}
+		sources = []searchSource{source}
+	}
+
+	selects := make([]string, len(sources))
+	args := make([]interface{}, len(sources))
+	for i, source := range sources {
+		selects[i] = "?"
+		args[i] = s.DB.Table(source.table).Select(searchColumns(source)+", deleted_at").Where("user_id = ? AND deleted_at IS NOT NULL", userID)
+	}
+	query := s.DB.Table("("+strings.Join(selects, " UNION ALL ")+") AS trash", args...).Order("deleted_at DESC, type, id DESC")
+	items, err := paginate[models.TrashItem](query, page, limit)
+	if err != nil {
+		log.Printf("Error retrieving trash for user %d: %v", userID, err)
+		return nil, fmt.Errorf("could not retrieve trash: %w", err)
+	}
+	if s.Retention > 0 {
+		for i := range items.Items {
+			purgeAt := items.Items[i].DeletedAt.Add(s.Retention)
+			items.Items[i].PurgeAt = &purgeAt
+		}
+	}
+	return items, nil
+}
+
+// RestoreRecord brings back a deleted record of recordType owned by the given user. Its tags, split lines
+// and attachments were kept while it was in the trash, so they come back with it. A category deleted in
+// the meantime is created again from the name the record kept.
+func (s *TrashService) RestoreRecord(userID uint, recordType string, recordID uint) error {
+	if s.DB == nil {
+		return fmt.Errorf("database connection not initialized in TrashService")
+	}
+	source, err := trashSource(recordType)
+	if err != nil {
+		return err
+	}
+	var record struct{ Date *database.CustomDate }
+	err = s.DB.Table(source.table).Select(source.date+" AS date").Where("id = ? AND user_id = ? AND deleted_at IS NOT NULL", recordID, userID).
+		Take(&record).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%s record not found in trash", recordType)
+		}
+		log.Printf("Error retrieving deleted %s %d for user %d: %v", recordType, recordID, userID, err)
+		return fmt.Errorf("could not restore %s: %w", recordType, err)
+	}
+
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Unscoped().Model(trashedModel(recordType)).Where("id = ? AND user_id = ? AND deleted_at IS NOT NULL", recordID, userID).
+			Update("deleted_at", nil)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("%s record not found in trash", recordType)
+		}
+		if recordType == "income" || recordType == "expense" {
+			return restoreCategories(tx, userID, recordType, recordID)
+		}
+		return nil
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "not found in trash") {
+			return err
+		}
+		log.Printf("Error restoring %s %d: %v", recordType, recordID, err)
+		return fmt.Errorf("could not restore %s: %w", recordType, err)
+	}
+
+	if (recordType == "income" || recordType == "expense") && record.Date != nil {
+		invalidateSummariesForDates(s.summaryService, userID, []time.Time{record.Date.Time})
+	}
+	return nil
+}
+
+// restoreCategories points a restored income or expense, and its split lines, back at the categories
+// they are named after. Deleting a category lets go of the deleted entries in it, so it is looked up by
+// name, or created again when it is gone.
+func restoreCategories(tx *gorm.DB, userID uint, kind string, recordID uint) error {
+	var entry struct {
+		Category   string
+		CategoryID *uint
+	}
+	if err := tx.Model(transactionModel(kind)).Select("category, category_id").Where("id = ?", recordID).Take(&entry).Error; err != nil {
+		return err
+	}
+	if entry.CategoryID == nil {
+		category, err := resolveCategory(tx, userID, kind, entry.Category)
+		if err != nil {
+			return err
+		}
+		if err := tx.Model(transactionModel(kind)).Where("id = ?", recordID).
+			Updates(map[string]interface{}{"category": category.Name, "category_id": category.ID}).Error; err != nil {
+			return err
+		}
+	}
+	if kind != "expense" {
+		return nil
+	}
+
+	var lines []models.ExpenseSplit
+	if err := tx.Where("expense_id = ? AND category_id IS NULL", recordID).Find(&lines).Error; err != nil {
+		return err
+	}
+	for _, line := range lines {
+		category, err := resolveCategory(tx, userID, kind, line.Category)
+		if err != nil {
+			return err
+		}
+		if err := tx.Model(&models.ExpenseSplit{}).Where("id = ?", line.ID).
+			Updates(map[string]interface{}{"category": category.Name, "category_id": category.ID}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PurgeRecord removes a deleted record of recordType owned by the given user for good. Only records in
+// the trash can be purged.
+func (s *TrashService) PurgeRecord(userID uint, recordType string, recordID uint) error {
+	if s.DB == nil {
+		return fmt.Errorf("database connection not initialized in TrashService")
+	}
+	source, err := trashSource(recordType)
+	if err != nil {
+		return err
+	}
+	purged, err := s.purge(s.DB, source, func(query *gorm.DB) *gorm.DB {
+		return query.Where("id = ? AND user_id = ?", recordID, userID)
+	})
+	if err != nil {
+		return err
+	}
+	if purged == 0 {
+		return fmt.Errorf("%s record not found in trash", recordType)
+	}
+	return nil
+}
+
+// EmptyTrash removes all of the user's deleted records for good. It returns the number of records removed.
+func (s *TrashService) EmptyTrash(userID uint) (int64, error) {
+	if s.DB == nil {
+		return 0, fmt.Errorf("database connection not initialized in TrashService")
+	}
+	var total int64
+	for _, source := range searchSources {
+		purged, err := s.purge(s.DB, source, func(query *gorm.DB) *gorm.DB {
+			return query.Where("user_id = ?", userID)
+		})
+		if err != nil {
+			return total, err
+		}
+		total += purged
+	}
+	return total, nil
+}
+
+// PurgeExpired removes the records of all users that have been in the trash for longer than the
+// retention period. It returns the number of records removed.
+func (s *TrashService) PurgeExpired() (int64, error) {
+	if s.DB == nil {
+		return 0, fmt.Errorf("database connection not initialized in TrashService")
+	}
+	if s.Retention <= 0 {
+		return 0, nil
+	}
+	cutoff := time.Now().UTC().Add(-s.Retention)
+	var total int64
+	for _, source := range searchSources {
+		purged, err := s.purge(withAuditSource(s.DB, auditSourceRetention), source, func(query *gorm.DB) *gorm.DB {
+			return query.Where("deleted_at < ?", cutoff)
+		})
+		if err != nil {
+			return total, err
+		}
+		total += purged
+	}
+	return total, nil
+}
+
+// purge deletes for good the records of source in the trash that scope selects, along with their tags,
+// split lines and duplicate pairs, using db. Their attachments are removed by the orphaned attachment sweep.
+func (s *TrashService) purge(db *gorm.DB, source searchSource, scope func(*gorm.DB) *gorm.DB) (int64, error) {
+	var purged int64
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var ids []uint
+		if err := scope(tx.Table(source.table).Where("deleted_at IS NOT NULL")).Pluck("id", &ids).Error; err != nil {
+			return fmt.Errorf("could not find deleted %s records: %w", source.recordType, err)
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+		if err := tx.Where("record_type = ? AND record_id IN ?", source.recordType, ids).Delete(&models.Tagging{}).Error; err != nil {
+			return fmt.Errorf("could not remove tags: %w", err)
+		}
+		if source.recordType == "expense" {
+			if err := tx.Where("expense_id IN ?", ids).Delete(&models.ExpenseSplit{}).Error; err != nil {
+				return fmt.Errorf("could not remove split lines: %w", err)
+			}
+		}
+		if source.recordType == "income" || source.recordType == "expense" {
+			if err := tx.Unscoped().Where("type = ? AND (transaction_id IN ? OR duplicate_of_id IN ?)", source.recordType, ids, ids).
+				Delete(&models.DuplicatePair{}).Error; err != nil {
+				return fmt.Errorf("could not remove duplicate pairs: %w", err)
+			}
+		}
+		result := tx.Unscoped().Where("id IN ?", ids).Delete(trashedModel(source.recordType))
+		if result.Error != nil {
+			return fmt.Errorf("could not purge %s records: %w", source.recordType, result.Error)
+		}
+		purged = result.RowsAffected
+		return nil
+	})
+	if err != nil {
+		log.Printf("Error purging deleted %s records: %v", source.recordType, err)
+		return 0, err
+	}
+	return purged, nil
+}
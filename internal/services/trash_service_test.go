@@ -0,0 +1,161 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zayyadi/finance-tracker/internal/database"
+	"github.com/zayyadi/finance-tracker/internal/models"
+	"github.com/zayyadi/finance-tracker/internal/types"
+	"gorm.io/gorm"
+)
+
+func setupTrashTestDB(t *testing.T) *gorm.DB {
+	db := setupAccountTestDB(t)
+	require.NoError(t, db.AutoMigrate(&models.Debt{}, &models.Savings{}))
+	return db
+}
+
+func TestTrashService_ListRestoreAndPurge(t *testing.T) {
+	db := setupTrashTestDB(t)
+	service := NewTrashService(db, NewSummaryService(db))
+	expenseService := NewExpenseService(db)
+	date := database.CustomDate{Time: time.Date(2024, time.May, 3, 0, 0, 0, 0, time.UTC)}
+
+	expense := models.Expense{UserID: testUserID, Amount: types.NewMoneyFromMinor(1500), Category: "Food", Date: date, Note: "lunch", Tags: []string{"work"}}
+	require.NoError(t, expenseService.CreateExpense(&expense))
+	kept := models.Expense{UserID: testUserID, Amount: types.NewMoneyFromMinor(700), Category: "Food", Date: date}
+	require.NoError(t, expenseService.CreateExpense(&kept))
+	debt := models.Debt{UserID: testUserID, DebtorName: "Sam", Amount: types.NewMoneyFromMinor(5000), Currency: "USD", DueDate: date, Status: "Pending"}
+	require.NoError(t, db.Create(&debt).Error)
+	require.NoError(t, expenseService.DeleteExpense(testUserID, expense.ID))
+	require.NoError(t, NewDebtService(db).DeleteDebt(testUserID, debt.ID))
+
+	trash, err := service.GetTrash(testUserID, "", 1, 10)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), trash.Total, "Only deleted records are in the trash")
+	byType := map[string]models.TrashItem{}
+	for _, item := range trash.Items {
+		byType[item.Type] = item
+	}
+	assert.Equal(t, expense.ID, byType["expense"].ID)
+	assert.Equal(t, "Food", byType["expense"].Title)
+	assert.Equal(t, "lunch", byType["expense"].Text)
+	assert.Equal(t, "Sam", byType["debt"].Title)
+	require.NotNil(t, byType["expense"].PurgeAt)
+	assert.WithinDuration(t, byType["expense"].DeletedAt.Add(DefaultTrashRetention), *byType["expense"].PurgeAt, time.Second)
+
+	trash, err = service.GetTrash(testUserID, "debt", 1, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), trash.Total)
+	_, err = service.GetTrash(testUserID, "transfer", 1, 10)
+	assert.ErrorContains(t, err, "invalid trash type")
+
+	// Restoring brings the record back with its tags, and drops the summaries covering it.
+	seedFinancialSummary(t, db, "monthly", date.Time, 0, 700)
+	require.NoError(t, service.RestoreRecord(testUserID, "expense", expense.ID))
+	restored, err := expenseService.GetExpenseByID(testUserID, expense.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"work"}, restored.Tags)
+	var summaries int64
+	db.Model(&models.FinancialSummary{}).Count(&summaries)
+	assert.Equal(t, int64(0), summaries)
+	assert.ErrorContains(t, service.RestoreRecord(testUserID, "expense", expense.ID), "expense record not found in trash")
+	assert.ErrorContains(t, service.RestoreRecord(testUserID, "expense", kept.ID), "not found in trash", "Live records are not in the trash")
+
+	// Purging removes the record and what hangs off it for good.
+	assert.ErrorContains(t, service.PurgeRecord(testUserID, "expense", expense.ID), "not found in trash", "Only deleted records can be purged")
+	require.NoError(t, expenseService.DeleteExpense(testUserID, expense.ID))
+	require.NoError(t, service.PurgeRecord(testUserID, "expense", expense.ID))
+	var count int64
+	db.Unscoped().Model(&models.Expense{}).Where("id = ?", expense.ID).Count(&count)
+	assert.Equal(t, int64(0), count)
+	db.Model(&models.Tagging{}).Where("record_type = ? AND record_id = ?", "expense", expense.ID).Count(&count)
+	assert.Equal(t, int64(0), count)
+
+	purged, err := service.EmptyTrash(testUserID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), purged)
+	trash, err = service.GetTrash(testUserID, "", 1, 10)
+	require.NoError(t, err)
+	assert.Empty(t, trash.Items)
+	_, err = expenseService.GetExpenseByID(testUserID, kept.ID)
+	assert.NoError(t, err, "Live records are left alone")
+}
+
+func TestTrashService_RestoreAfterCategoryDeleted(t *testing.T) {
+	db := setupTrashTestDB(t)
+	service := NewTrashService(db, nil)
+	expenseService := NewExpenseService(db)
+	categoryService := NewCategoryService(db)
+	date := database.CustomDate{Time: time.Date(2024, time.May, 3, 0, 0, 0, 0, time.UTC)}
+
+	expense := models.Expense{UserID: testUserID, Amount: types.NewMoneyFromMinor(1500), Category: "Snacks", Date: date, Splits: []models.ExpenseSplit{
+		{Amount: types.NewMoneyFromMinor(1000), Category: "Snacks"},
+		{Amount: types.NewMoneyFromMinor(500), Category: "Drinks"},
+	}}
+	require.NoError(t, expenseService.CreateExpense(&expense))
+	income := models.Income{UserID: testUserID, Amount: types.NewMoneyFromMinor(9000), Category: "Tips", Date: date}
+	require.NoError(t, NewIncomeService(db).CreateIncome(&income))
+	require.NoError(t, expenseService.DeleteExpense(testUserID, expense.ID))
+	require.NoError(t, NewIncomeService(db).DeleteIncome(testUserID, income.ID))
+	for _, split := range expense.Splits {
+		require.NoError(t, categoryService.DeleteCategory(testUserID, *split.CategoryID))
+	}
+	require.NoError(t, categoryService.DeleteCategory(testUserID, *income.CategoryID))
+
+	require.NoError(t, service.RestoreRecord(testUserID, "expense", expense.ID))
+	restored, err := expenseService.GetExpenseByID(testUserID, expense.ID)
+	require.NoError(t, err)
+	require.NotNil(t, restored.CategoryID, "The restored expense gets its category back")
+	category, err := categoryService.GetCategoryByID(testUserID, *restored.CategoryID)
+	require.NoError(t, err)
+	assert.Equal(t, "Snacks", category.Name)
+	require.Len(t, restored.Splits, 2)
+	for _, split := range restored.Splits {
+		require.NotNil(t, split.CategoryID, split.Category)
+		category, err := categoryService.GetCategoryByID(testUserID, *split.CategoryID)
+		require.NoError(t, err)
+		assert.Equal(t, split.Category, category.Name)
+	}
+
+	require.NoError(t, service.RestoreRecord(testUserID, "income", income.ID))
+	restoredIncome, err := NewIncomeService(db).GetIncomeByID(testUserID, income.ID)
+	require.NoError(t, err)
+	require.NotNil(t, restoredIncome.CategoryID)
+	category, err = categoryService.GetCategoryByID(testUserID, *restoredIncome.CategoryID)
+	require.NoError(t, err)
+	assert.Equal(t, "income", category.Kind)
+	assert.Equal(t, "Tips", category.Name)
+}
+
+func TestTrashService_PurgeExpired(t *testing.T) {
+	db := setupTrashTestDB(t)
+	service := NewTrashService(db, nil)
+	date := database.CustomDate{Time: time.Date(2024, time.May, 3, 0, 0, 0, 0, time.UTC)}
+
+	old := models.Savings{UserID: testUserID, GoalName: "Bike", GoalAmount: types.NewMoneyFromMinor(50000), Currency: "USD"}
+	recent := models.Income{UserID: testUserID, Amount: types.NewMoneyFromMinor(1000), Currency: "USD", Category: "Salary", Date: date}
+	require.NoError(t, db.Create(&old).Error)
+	require.NoError(t, db.Create(&recent).Error)
+	require.NoError(t, db.Unscoped().Model(&old).Update("deleted_at", time.Now().UTC().Add(-31*24*time.Hour)).Error)
+	require.NoError(t, db.Delete(&recent).Error)
+
+	purged, err := service.PurgeExpired()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), purged)
+	trash, err := service.GetTrash(testUserID, "", 1, 10)
+	require.NoError(t, err)
+	require.Len(t, trash.Items, 1)
+	assert.Equal(t, "income", trash.Items[0].Type)
+
+	service.Retention = 0
+	purged, err = service.PurgeExpired()
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), purged, "A retention of 0 keeps deleted records")
+	trash, err = service.GetTrash(testUserID, "", 1, 10)
+	require.NoError(t, err)
+	assert.Nil(t, trash.Items[0].PurgeAt)
+}
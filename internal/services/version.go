@@ -0,0 +1,70 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// VersionConflictError is returned when a change is made against a version of a record it no longer
+// has: another change got there first.
+type VersionConflictError struct {
+	Record    string    // What was changed, e.g. "expense record"
+	UpdatedAt time.Time // When it was last changed
+}
+
+func (e *VersionConflictError) Error() string {
+	return fmt.Sprintf("precondition failed: the %s has been changed since it was fetched", e.Record)
+}
+
+// checkVersion returns a VersionConflictError unless a record described as record (e.g. "expense record"),
+// last changed at updatedAt, is at one of versions. Any version goes when none are given. Times are
+// compared to the microsecond, the precision PostgreSQL stores them at.
+func checkVersion(updatedAt time.Time, record string, versions []time.Time) error {
+	if len(versions) == 0 {
+		return nil
+	}
+	for _, version := range versions {
+		if version.UnixMicro() == updatedAt.UnixMicro() {
+			return nil
+		}
+	}
+	return &VersionConflictError{Record: record, UpdatedAt: updatedAt}
+}
+
+// whereVersion limits an update or delete to a record still last changed at updatedAt, as it was loaded,
+// when versions are given. A change committed since then makes the statement change no rows instead of
+// being overwritten.
+func whereVersion(query *gorm.DB, updatedAt time.Time, versions []time.Time) *gorm.DB {
+	if len(versions) == 0 {
+		return query
+	}
+	return query.Where("updated_at = ?", updatedAt)
+}
+
+// recordVersion returns when the user's record, a model described as record, was last changed.
+func recordVersion(db *gorm.DB, model interface{}, record string, userID uint, id uint) (time.Time, error) {
+	var current struct{ UpdatedAt time.Time }
+	err := db.Model(model).Select("updated_at").Where("id = ? AND user_id = ?", id, userID).Take(&current).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return time.Time{}, fmt.Errorf("%s not found", record)
+	}
+	if err != nil {
+		log.Printf("Error checking version of %s %d: %v", record, id, err)
+		return time.Time{}, fmt.Errorf("could not check version of %s: %w", record, err)
+	}
+	return current.UpdatedAt, nil
+}
+
+// versionConflict explains why a conditional update or delete of the user's record changed no rows: a
+// VersionConflictError when it is still there, or that it is not.
+func versionConflict(db *gorm.DB, model interface{}, record string, userID uint, id uint) error {
+	updatedAt, err := recordVersion(db, model, record, userID, id)
+	if err != nil {
+		return err
+	}
+	return &VersionConflictError{Record: record, UpdatedAt: updatedAt}
+}
@@ -0,0 +1,101 @@
+// Package storage keeps uploaded files, such as receipts attached to expenses, outside the database.
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ErrNotFound is returned when no file is stored under a key.
+var ErrNotFound = errors.New("stored file not found")
+
+// Storage stores files under slash-separated keys such as "12/3f9a...". Implementations must be safe
+// for concurrent use.
+type Storage interface {
+	// Save stores the contents of r under key, replacing any file already there, and returns the
+	// number of bytes written.
+	Save(key string, r io.Reader) (int64, error)
+	// Open returns the file stored under key, or ErrNotFound.
+	Open(key string) (io.ReadCloser, error)
+	// Delete removes the file stored under key. Deleting a missing file is not an error.
+	Delete(key string) error
+}
+
+// Local stores files in a directory on the local filesystem.
+type Local struct {
+	root string
+}
+
+// NewLocal creates a Local storage rooted at dir, creating the directory if needed.
+func NewLocal(dir string) (*Local, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("could not create storage directory %s: %w", dir, err)
+	}
+	return &Local{root: dir}, nil
+}
+
+// filePath maps a key to a path under the root, rejecting keys that would escape it.
+func (l *Local) filePath(key string) (string, error) {
+	if key == "" || path.IsAbs(key) || path.Clean(key) != key || key == ".." || strings.HasPrefix(key, "../") || strings.Contains(key, "\\") {
+		return "", fmt.Errorf("invalid storage key %q", key)
+	}
+	return filepath.Join(l.root, filepath.FromSlash(key)), nil
+}
+
+// Save writes the file to a temporary name first, so a failed upload never leaves a partial file
+// under the key.
+func (l *Local) Save(key string, r io.Reader) (int64, error) {
+	name, err := l.filePath(key)
+	if err != nil {
+		return 0, err
+	}
+	if err := os.MkdirAll(filepath.Dir(name), 0o750); err != nil {
+		return 0, fmt.Errorf("could not create storage directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(name), ".upload-*")
+	if err != nil {
+		return 0, fmt.Errorf("could not create file: %w", err)
+	}
+	written, err := io.Copy(tmp, r)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), name)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return 0, fmt.Errorf("could not write file: %w", err)
+	}
+	return written, nil
+}
+
+// Open opens the file stored under key.
+func (l *Local) Open(key string) (io.ReadCloser, error) {
+	name, err := l.filePath(key)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(name)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return file, err
+}
+
+// Delete removes the file stored under key.
+func (l *Local) Delete(key string) error {
+	name, err := l.filePath(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(name); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("could not delete file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package storage
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocal_SaveOpenDelete(t *testing.T) {
+	store, err := NewLocal(t.TempDir())
+	require.NoError(t, err)
+
+	written, err := store.Save("7/receipt", strings.NewReader("hello"))
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), written)
+
+	file, err := store.Open("7/receipt")
+	require.NoError(t, err)
+	content, err := io.ReadAll(file)
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+	assert.Equal(t, "hello", string(content))
+
+	require.NoError(t, store.Delete("7/receipt"))
+	_, err = store.Open("7/receipt")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.NoError(t, store.Delete("7/receipt"), "Deleting a missing file is not an error")
+}
+
+func TestLocal_RejectsKeysOutsideRoot(t *testing.T) {
+	store, err := NewLocal(t.TempDir())
+	require.NoError(t, err)
+	for _, key := range []string{"", "../secret", "/etc/passwd", "a/../../b", "a\\..\\b"} {
+		_, err := store.Save(key, strings.NewReader("x"))
+		assert.Error(t, err, key)
+	}
+}
@@ -0,0 +1,209 @@
+package types
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Money is an exact monetary amount stored as an integer number of minor units (cents).
+// It marshals to JSON as a decimal number with two places (e.g. 12.34) and is stored in
+// the database as a BIGINT of minor units, so sums are exact on every engine.
+type Money int64
+
+// minorUnitsPerMajor is the number of minor units in one major unit (100 cents per unit).
+const minorUnitsPerMajor = 100
+
+// moneyDecimals is the number of decimal places a Money value carries.
+const moneyDecimals = 2
+
+// NewMoneyFromMinor returns the amount for a given number of minor units.
+func NewMoneyFromMinor(minor int64) Money {
+	return Money(minor)
+}
+
+// NewMoneyFromFloat converts a float amount, rounding half away from zero to the nearest minor unit.
+// Use it only at boundaries where a float is unavoidable; ParseMoney is exact.
+func NewMoneyFromFloat(f float64) Money {
+	return Money(math.Round(f * minorUnitsPerMajor))
+}
+
+// ParseMoney parses a decimal string such as "12.34", "-5", "+0.5" or "1,234.50".
+// Digits beyond the second decimal place must be zeros; anything else is rejected
+// rather than silently rounded.
+func ParseMoney(s string) (Money, error) {
+	orig := s
+	s = strings.TrimSpace(s)
+	s = strings.ReplaceAll(s, ",", "") // Thousands separators
+	if s == "" {
+		return 0, fmt.Errorf("invalid money amount: empty string")
+	}
+
+	negative := false
+	switch s[0] {
+	case '-':
+		negative = true
+		s = s[1:]
+	case '+':
+		s = s[1:]
+	}
+
+	whole, frac, hasPoint := strings.Cut(s, ".")
+	if whole == "" && (!hasPoint || frac == "") {
+		return 0, fmt.Errorf("invalid money amount %q", orig)
+	}
+	if !isDigits(whole) || !isDigits(frac) {
+		return 0, fmt.Errorf("invalid money amount %q", orig)
+	}
+	if len(frac) > moneyDecimals {
+		if strings.Trim(frac[moneyDecimals:], "0") != "" {
+			return 0, fmt.Errorf("invalid money amount %q: more than %d decimal places", orig, moneyDecimals)
+		}
+		frac = frac[:moneyDecimals]
+	}
+	frac += strings.Repeat("0", moneyDecimals-len(frac))
+
+	if whole == "" {
+		whole = "0"
+	}
+	cents, _ := strconv.ParseInt(frac, 10, 64)
+	units, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil || units > (math.MaxInt64-cents)/minorUnitsPerMajor {
+		return 0, fmt.Errorf("invalid money amount %q: out of range", orig)
+	}
+
+	minor := units*minorUnitsPerMajor + cents
+	if negative {
+		minor = -minor
+	}
+	return Money(minor), nil
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// MinorUnits returns the amount as an integer number of minor units.
+func (m Money) MinorUnits() int64 {
+	return int64(m)
+}
+
+// Float64 returns the amount as a float, for display-only uses such as charts.
+func (m Money) Float64() float64 {
+	return float64(m) / minorUnitsPerMajor
+}
+
+// IsZero reports whether the amount is zero.
+func (m Money) IsZero() bool {
+	return m == 0
+}
+
+// IsNegative reports whether the amount is below zero.
+func (m Money) IsNegative() bool {
+	return m < 0
+}
+
+// Abs returns the absolute value of the amount.
+func (m Money) Abs() Money {
+	if m < 0 {
+		return -m
+	}
+	return m
+}
+
+// Convert multiplies the amount by an exchange rate, rounding half away from zero to the nearest minor unit.
+func (m Money) Convert(rate float64) Money {
+	return Money(math.Round(float64(m) * rate))
+}
+
+// String formats the amount with exactly two decimal places, e.g. "-1234.50".
+func (m Money) String() string {
+	minor := int64(m)
+	sign := ""
+	if minor < 0 {
+		sign = "-"
+		minor = -minor
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, minor/minorUnitsPerMajor, minor%minorUnitsPerMajor)
+}
+
+// MarshalJSON implements the json.Marshaler interface, emitting a JSON number like 12.34.
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+// It accepts a JSON number (12.34) or a string ("12.34") and parses it exactly, without going through float64.
+func (m *Money) UnmarshalJSON(b []byte) error {
+	s := string(b)
+	if s == "null" {
+		return nil
+	}
+	if len(s) > 1 && s[0] == '"' && s[len(s)-1] == '"' {
+		s = s[1 : len(s)-1]
+	}
+	if strings.ContainsAny(s, "eE") {
+		// Exponent notation is valid JSON; accept it when it lands on a whole minor unit.
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return fmt.Errorf("invalid money amount %q: %w", s, err)
+		}
+		parsed := NewMoneyFromFloat(f)
+		if parsed.Float64() != f {
+			return fmt.Errorf("invalid money amount %q: more than %d decimal places", s, moneyDecimals)
+		}
+		*m = parsed
+		return nil
+	}
+	parsed, err := ParseMoney(s)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// Value implements the driver.Valuer interface, storing the amount as integer minor units.
+func (m Money) Value() (driver.Value, error) {
+	return int64(m), nil
+}
+
+// Scan implements the sql.Scanner interface. The database holds integer minor units;
+// aggregates such as SUM may come back as int64, float64, or a numeric string depending on the driver.
+func (m *Money) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*m = 0
+	case int64:
+		*m = Money(v)
+	case float64:
+		*m = Money(math.Round(v))
+	case []byte:
+		return m.scanString(string(v))
+	case string:
+		return m.scanString(v)
+	default:
+		return fmt.Errorf("cannot scan Money: unsupported type %T", value)
+	}
+	return nil
+}
+
+func (m *Money) scanString(s string) error {
+	if minor, err := strconv.ParseInt(s, 10, 64); err == nil {
+		*m = Money(minor)
+		return nil
+	}
+	f, err := strconv.ParseFloat(s, 64) // e.g. PostgreSQL NUMERIC results such as "1234.0"
+	if err != nil {
+		return fmt.Errorf("cannot scan Money from %q: %w", s, err)
+	}
+	*m = Money(math.Round(f))
+	return nil
+}
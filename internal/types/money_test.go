@@ -0,0 +1,93 @@
+package types
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMoney(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected Money
+	}{
+		{"12.34", 1234},
+		{"-5", -500},
+		{"+0.5", 50},
+		{".75", 75},
+		{"7.", 700},
+		{"1,234.50", 123450},
+		{"10.1000", 1010},
+		{" 3.10 ", 310},
+		{"92233720368547758.07", 9223372036854775807},
+	}
+	for _, tc := range testCases {
+		got, err := ParseMoney(tc.input)
+		assert.NoError(t, err, "input %q", tc.input)
+		assert.Equal(t, tc.expected, got, "input %q", tc.input)
+	}
+
+	for _, invalid := range []string{"", "-", ".", "abc", "1.234", "1.2.3", "1e3", "92233720368547758.08"} {
+		_, err := ParseMoney(invalid)
+		assert.Error(t, err, "input %q should be rejected", invalid)
+	}
+}
+
+func TestMoney_String(t *testing.T) {
+	assert.Equal(t, "0.00", Money(0).String())
+	assert.Equal(t, "0.05", Money(5).String())
+	assert.Equal(t, "-0.05", Money(-5).String())
+	assert.Equal(t, "1234.50", Money(123450).String())
+}
+
+func TestMoney_JSON(t *testing.T) {
+	var payload struct {
+		Amount  Money  `json:"amount"`
+		Pointer *Money `json:"pointer"`
+	}
+	err := json.Unmarshal([]byte(`{"amount": 0.1, "pointer": "19.99"}`), &payload)
+	assert.NoError(t, err)
+	assert.Equal(t, Money(10), payload.Amount)
+	assert.Equal(t, Money(1999), *payload.Pointer)
+
+	out, err := json.Marshal(payload)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"amount": 0.10, "pointer": 19.99}`, string(out))
+
+	assert.NoError(t, json.Unmarshal([]byte(`{"amount": 1.5e2}`), &payload))
+	assert.Equal(t, Money(15000), payload.Amount)
+
+	assert.Error(t, json.Unmarshal([]byte(`{"amount": 0.001}`), &payload), "sub-cent amounts must be rejected")
+}
+
+func TestMoney_SumIsExact(t *testing.T) {
+	// 0.10 added ten times is not 1.0 in float64, but is exactly 1.00 as Money.
+	var floatTotal float64
+	var total Money
+	for i := 0; i < 10; i++ {
+		floatTotal += 0.1
+		total += NewMoneyFromFloat(0.1)
+	}
+	assert.NotEqual(t, 1.0, floatTotal)
+	assert.Equal(t, "1.00", total.String())
+}
+
+func TestMoney_Scan(t *testing.T) {
+	var m Money
+	assert.NoError(t, m.Scan(int64(1234)))
+	assert.Equal(t, Money(1234), m)
+	assert.NoError(t, m.Scan(float64(99)))
+	assert.Equal(t, Money(99), m)
+	assert.NoError(t, m.Scan([]byte("5000")))
+	assert.Equal(t, Money(5000), m)
+	assert.NoError(t, m.Scan("42.0"))
+	assert.Equal(t, Money(42), m)
+	assert.NoError(t, m.Scan(nil))
+	assert.Equal(t, Money(0), m)
+	assert.Error(t, m.Scan(true))
+
+	v, err := Money(1234).Value()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1234), v)
+}
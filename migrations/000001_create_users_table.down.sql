@@ -1 +1 @@
-DROP TABLE IF EXISTS Users;
+DROP TABLE IF EXISTS users;
@@ -1 +1 @@
-DROP TABLE IF EXISTS Income;
+DROP TABLE IF EXISTS incomes;
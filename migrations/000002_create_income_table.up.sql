@@ -1,12 +1,14 @@
-CREATE TABLE IF NOT EXISTS Income (
-    id SERIAL PRIMARY KEY,
-    user_id INTEGER NOT NULL REFERENCES Users(id) ON DELETE CASCADE,
-    amount DECIMAL(10, 2) NOT NULL,
-    category VARCHAR(100) NOT NULL,
+CREATE TABLE IF NOT EXISTS incomes (
+    id BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    amount DECIMAL NOT NULL DEFAULT 0,
+    category TEXT NOT NULL,
     date DATE NOT NULL,
-    note TEXT,
-    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
-    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+    note TEXT
 );
-CREATE INDEX IF NOT EXISTS idx_income_user_id ON Income(user_id);
-CREATE INDEX IF NOT EXISTS idx_income_date ON Income(date);
+CREATE INDEX IF NOT EXISTS idx_incomes_user_id ON incomes(user_id);
+CREATE INDEX IF NOT EXISTS idx_incomes_date ON incomes(date);
+CREATE INDEX IF NOT EXISTS idx_incomes_deleted_at ON incomes(deleted_at);
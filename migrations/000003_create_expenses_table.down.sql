@@ -1 +1 @@
-DROP TABLE IF EXISTS Expenses;
+DROP TABLE IF EXISTS expenses;
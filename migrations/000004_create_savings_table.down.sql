@@ -1 +1 @@
-DROP TABLE IF EXISTS Savings;
+DROP TABLE IF EXISTS savings;
@@ -1,13 +1,15 @@
-CREATE TABLE IF NOT EXISTS Savings (
-    id SERIAL PRIMARY KEY,
-    user_id INTEGER NOT NULL REFERENCES Users(id) ON DELETE CASCADE,
-    goal_name VARCHAR(255) NOT NULL,
-    goal_amount DECIMAL(10, 2) NOT NULL,
-    current_amount DECIMAL(10, 2) DEFAULT 0.00,
+CREATE TABLE IF NOT EXISTS savings (
+    id BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    goal_name TEXT NOT NULL,
+    goal_amount DECIMAL NOT NULL DEFAULT 0,
+    current_amount DECIMAL NOT NULL DEFAULT 0,
     start_date DATE,
-    target_date DATE, -- Changed from end_date for clarity
-    notes TEXT,       -- Added notes field
-    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
-    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+    target_date DATE,
+    notes TEXT
 );
-CREATE INDEX IF NOT EXISTS idx_savings_user_id ON Savings(user_id);
+CREATE INDEX IF NOT EXISTS idx_savings_user_id ON savings(user_id);
+CREATE INDEX IF NOT EXISTS idx_savings_deleted_at ON savings(deleted_at);
@@ -1 +1 @@
-DROP TABLE IF EXISTS Debts;
+DROP TABLE IF EXISTS debts;
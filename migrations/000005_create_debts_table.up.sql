@@ -1,13 +1,15 @@
-CREATE TABLE IF NOT EXISTS Debts (
-    id SERIAL PRIMARY KEY,
-    user_id INTEGER NOT NULL REFERENCES Users(id) ON DELETE CASCADE,
-    debtor_name VARCHAR(255) NOT NULL,
-    description TEXT, -- Added for more details
-    amount DECIMAL(10, 2) NOT NULL,
+CREATE TABLE IF NOT EXISTS debts (
+    id BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    debtor_name TEXT NOT NULL,
+    description TEXT,
+    amount DECIMAL NOT NULL DEFAULT 0,
     due_date DATE NOT NULL,
-    status VARCHAR(50) DEFAULT 'Pending', -- e.g., Pending, Paid, Overdue
-    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
-    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+    status TEXT NOT NULL DEFAULT 'Pending' -- Pending, Paid, Overdue
 );
-CREATE INDEX IF NOT EXISTS idx_debts_user_id ON Debts(user_id);
-CREATE INDEX IF NOT EXISTS idx_debts_due_date ON Debts(due_date);
+CREATE INDEX IF NOT EXISTS idx_debts_user_id ON debts(user_id);
+CREATE INDEX IF NOT EXISTS idx_debts_due_date ON debts(due_date);
+CREATE INDEX IF NOT EXISTS idx_debts_deleted_at ON debts(deleted_at);
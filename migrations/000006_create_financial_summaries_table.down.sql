@@ -1 +1 @@
-DROP TABLE IF EXISTS FinancialSummaries;
+DROP TABLE IF EXISTS financial_summaries;
@@ -1,13 +1,17 @@
-CREATE TABLE IF NOT EXISTS FinancialSummaries (
-    id SERIAL PRIMARY KEY,
-    user_id INTEGER NOT NULL REFERENCES Users(id) ON DELETE CASCADE,
-    summary_type VARCHAR(10) NOT NULL, -- 'weekly', 'monthly', 'yearly'
-    period_start_date DATE NOT NULL,
-    period_end_date DATE NOT NULL,
-    total_income DECIMAL(12, 2) NOT NULL,
-    total_expenses DECIMAL(12, 2) NOT NULL,
-    net_balance DECIMAL(12, 2) NOT NULL, -- Changed from 'balance' for clarity
-    generated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
-    CONSTRAINT uq_financial_summary UNIQUE (user_id, summary_type, period_start_date) -- Ensure unique summaries
+CREATE TABLE IF NOT EXISTS financial_summaries (
+    id BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    summary_type TEXT NOT NULL, -- 'weekly', 'monthly', 'yearly'
+    period_start_date TIMESTAMPTZ NOT NULL,
+    period_end_date TIMESTAMPTZ NOT NULL,
+    total_income DECIMAL NOT NULL DEFAULT 0,
+    total_expenses DECIMAL NOT NULL DEFAULT 0,
+    net_balance DECIMAL NOT NULL DEFAULT 0
 );
-CREATE INDEX IF NOT EXISTS idx_fs_user_id_type ON FinancialSummaries(user_id, summary_type);
+-- GetOrCreateFinancialSummary relies on this index to detect concurrent inserts.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_type_period ON financial_summaries(user_id, summary_type, period_start_date);
+CREATE INDEX IF NOT EXISTS idx_financial_summaries_user_id ON financial_summaries(user_id);
+CREATE INDEX IF NOT EXISTS idx_financial_summaries_deleted_at ON financial_summaries(deleted_at);
@@ -0,0 +1,39 @@
+ALTER TABLE incomes ADD COLUMN amount_major DECIMAL NOT NULL DEFAULT 0;
+UPDATE incomes SET amount_major = amount / 100.0;
+ALTER TABLE incomes DROP COLUMN amount;
+ALTER TABLE incomes RENAME COLUMN amount_major TO amount;
+
+ALTER TABLE expenses ADD COLUMN amount_major DECIMAL NOT NULL DEFAULT 0;
+UPDATE expenses SET amount_major = amount / 100.0;
+ALTER TABLE expenses DROP COLUMN amount;
+ALTER TABLE expenses RENAME COLUMN amount_major TO amount;
+
+ALTER TABLE debts ADD COLUMN amount_major DECIMAL NOT NULL DEFAULT 0;
+UPDATE debts SET amount_major = amount / 100.0;
+ALTER TABLE debts DROP COLUMN amount;
+ALTER TABLE debts RENAME COLUMN amount_major TO amount;
+
+ALTER TABLE savings ADD COLUMN goal_amount_major DECIMAL NOT NULL DEFAULT 0;
+UPDATE savings SET goal_amount_major = goal_amount / 100.0;
+ALTER TABLE savings DROP COLUMN goal_amount;
+ALTER TABLE savings RENAME COLUMN goal_amount_major TO goal_amount;
+
+ALTER TABLE savings ADD COLUMN current_amount_major DECIMAL NOT NULL DEFAULT 0;
+UPDATE savings SET current_amount_major = current_amount / 100.0;
+ALTER TABLE savings DROP COLUMN current_amount;
+ALTER TABLE savings RENAME COLUMN current_amount_major TO current_amount;
+
+ALTER TABLE financial_summaries ADD COLUMN total_income_major DECIMAL NOT NULL DEFAULT 0;
+UPDATE financial_summaries SET total_income_major = total_income / 100.0;
+ALTER TABLE financial_summaries DROP COLUMN total_income;
+ALTER TABLE financial_summaries RENAME COLUMN total_income_major TO total_income;
+
+ALTER TABLE financial_summaries ADD COLUMN total_expenses_major DECIMAL NOT NULL DEFAULT 0;
+UPDATE financial_summaries SET total_expenses_major = total_expenses / 100.0;
+ALTER TABLE financial_summaries DROP COLUMN total_expenses;
+ALTER TABLE financial_summaries RENAME COLUMN total_expenses_major TO total_expenses;
+
+ALTER TABLE financial_summaries ADD COLUMN net_balance_major DECIMAL NOT NULL DEFAULT 0;
+UPDATE financial_summaries SET net_balance_major = net_balance / 100.0;
+ALTER TABLE financial_summaries DROP COLUMN net_balance;
+ALTER TABLE financial_summaries RENAME COLUMN net_balance_major TO net_balance;
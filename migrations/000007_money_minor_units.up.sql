@@ -0,0 +1,42 @@
+-- Store money as BIGINT minor units (cents) instead of DECIMAL major units, so sums are exact
+-- on every engine. Each column is rebuilt because SQLite cannot ALTER COLUMN ... TYPE.
+
+ALTER TABLE incomes ADD COLUMN amount_minor BIGINT NOT NULL DEFAULT 0;
+UPDATE incomes SET amount_minor = ROUND(amount * 100);
+ALTER TABLE incomes DROP COLUMN amount;
+ALTER TABLE incomes RENAME COLUMN amount_minor TO amount;
+
+ALTER TABLE expenses ADD COLUMN amount_minor BIGINT NOT NULL DEFAULT 0;
+UPDATE expenses SET amount_minor = ROUND(amount * 100);
+ALTER TABLE expenses DROP COLUMN amount;
+ALTER TABLE expenses RENAME COLUMN amount_minor TO amount;
+
+ALTER TABLE debts ADD COLUMN amount_minor BIGINT NOT NULL DEFAULT 0;
+UPDATE debts SET amount_minor = ROUND(amount * 100);
+ALTER TABLE debts DROP COLUMN amount;
+ALTER TABLE debts RENAME COLUMN amount_minor TO amount;
+
+ALTER TABLE savings ADD COLUMN goal_amount_minor BIGINT NOT NULL DEFAULT 0;
+UPDATE savings SET goal_amount_minor = ROUND(goal_amount * 100);
+ALTER TABLE savings DROP COLUMN goal_amount;
+ALTER TABLE savings RENAME COLUMN goal_amount_minor TO goal_amount;
+
+ALTER TABLE savings ADD COLUMN current_amount_minor BIGINT NOT NULL DEFAULT 0;
+UPDATE savings SET current_amount_minor = ROUND(current_amount * 100);
+ALTER TABLE savings DROP COLUMN current_amount;
+ALTER TABLE savings RENAME COLUMN current_amount_minor TO current_amount;
+
+ALTER TABLE financial_summaries ADD COLUMN total_income_minor BIGINT NOT NULL DEFAULT 0;
+UPDATE financial_summaries SET total_income_minor = ROUND(total_income * 100);
+ALTER TABLE financial_summaries DROP COLUMN total_income;
+ALTER TABLE financial_summaries RENAME COLUMN total_income_minor TO total_income;
+
+ALTER TABLE financial_summaries ADD COLUMN total_expenses_minor BIGINT NOT NULL DEFAULT 0;
+UPDATE financial_summaries SET total_expenses_minor = ROUND(total_expenses * 100);
+ALTER TABLE financial_summaries DROP COLUMN total_expenses;
+ALTER TABLE financial_summaries RENAME COLUMN total_expenses_minor TO total_expenses;
+
+ALTER TABLE financial_summaries ADD COLUMN net_balance_minor BIGINT NOT NULL DEFAULT 0;
+UPDATE financial_summaries SET net_balance_minor = ROUND(net_balance * 100);
+ALTER TABLE financial_summaries DROP COLUMN net_balance;
+ALTER TABLE financial_summaries RENAME COLUMN net_balance_minor TO net_balance;
@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS exchange_rates;
+
+ALTER TABLE financial_summaries DROP COLUMN currency;
+ALTER TABLE savings DROP COLUMN currency;
+ALTER TABLE debts DROP COLUMN currency;
+ALTER TABLE expenses DROP COLUMN currency;
+ALTER TABLE incomes DROP COLUMN currency;
+ALTER TABLE users DROP COLUMN base_currency;
@@ -0,0 +1,26 @@
+-- Amounts carry an ISO 4217 currency code; existing rows predate currencies and are taken as USD.
+ALTER TABLE users ADD COLUMN base_currency VARCHAR(3) NOT NULL DEFAULT 'USD';
+ALTER TABLE incomes ADD COLUMN currency VARCHAR(3) NOT NULL DEFAULT 'USD';
+ALTER TABLE expenses ADD COLUMN currency VARCHAR(3) NOT NULL DEFAULT 'USD';
+ALTER TABLE debts ADD COLUMN currency VARCHAR(3) NOT NULL DEFAULT 'USD';
+ALTER TABLE savings ADD COLUMN currency VARCHAR(3) NOT NULL DEFAULT 'USD';
+ALTER TABLE financial_summaries ADD COLUMN currency VARCHAR(3) NOT NULL DEFAULT 'USD';
+
+-- Summaries used to be soft-deleted on invalidation, which left their unique period key taken.
+-- They are a cache, so the leftovers can simply go.
+DELETE FROM financial_summaries WHERE deleted_at IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS exchange_rates (
+    id BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    from_currency VARCHAR(3) NOT NULL,
+    to_currency VARCHAR(3) NOT NULL,
+    rate DOUBLE PRECISION NOT NULL, -- Units of to_currency per unit of from_currency
+    date DATE NOT NULL -- Effective from this date until a later rate for the same pair
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_rate_pair_date ON exchange_rates(user_id, from_currency, to_currency, date);
+CREATE INDEX IF NOT EXISTS idx_exchange_rates_user_id ON exchange_rates(user_id);
+CREATE INDEX IF NOT EXISTS idx_exchange_rates_deleted_at ON exchange_rates(deleted_at);
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_expenses_account_id;
+ALTER TABLE expenses DROP COLUMN account_id;
+DROP INDEX IF EXISTS idx_incomes_account_id;
+ALTER TABLE incomes DROP COLUMN account_id;
+
+DROP TABLE IF EXISTS transfers;
+DROP TABLE IF EXISTS accounts;
@@ -0,0 +1,38 @@
+CREATE TABLE IF NOT EXISTS accounts (
+    id BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    type VARCHAR(20) NOT NULL, -- 'bank', 'cash', 'card'
+    currency VARCHAR(3) NOT NULL DEFAULT 'USD',
+    opening_balance BIGINT NOT NULL DEFAULT 0 -- Minor units
+);
+CREATE INDEX IF NOT EXISTS idx_accounts_user_id ON accounts(user_id);
+CREATE INDEX IF NOT EXISTS idx_accounts_deleted_at ON accounts(deleted_at);
+
+-- Transfers move money between accounts and are neither income nor expense.
+CREATE TABLE IF NOT EXISTS transfers (
+    id BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    from_account_id BIGINT NOT NULL REFERENCES accounts(id),
+    to_account_id BIGINT NOT NULL REFERENCES accounts(id),
+    amount BIGINT NOT NULL DEFAULT 0, -- Minor units of the source account's currency
+    to_amount BIGINT NOT NULL DEFAULT 0, -- Minor units of the destination account's currency
+    date DATE NOT NULL,
+    note TEXT
+);
+CREATE INDEX IF NOT EXISTS idx_transfers_user_id ON transfers(user_id);
+CREATE INDEX IF NOT EXISTS idx_transfers_from_account_id ON transfers(from_account_id);
+CREATE INDEX IF NOT EXISTS idx_transfers_to_account_id ON transfers(to_account_id);
+CREATE INDEX IF NOT EXISTS idx_transfers_date ON transfers(date);
+CREATE INDEX IF NOT EXISTS idx_transfers_deleted_at ON transfers(deleted_at);
+
+ALTER TABLE incomes ADD COLUMN account_id BIGINT REFERENCES accounts(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_incomes_account_id ON incomes(account_id);
+ALTER TABLE expenses ADD COLUMN account_id BIGINT REFERENCES accounts(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_expenses_account_id ON expenses(account_id);
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_expenses_recurring_date;
+ALTER TABLE expenses DROP COLUMN recurring_id;
+DROP INDEX IF EXISTS idx_incomes_recurring_date;
+ALTER TABLE incomes DROP COLUMN recurring_id;
+
+DROP TABLE IF EXISTS recurring_transactions;
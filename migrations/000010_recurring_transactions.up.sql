@@ -0,0 +1,31 @@
+CREATE TABLE IF NOT EXISTS recurring_transactions (
+    id BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type VARCHAR(10) NOT NULL, -- 'income', 'expense'
+    amount BIGINT NOT NULL DEFAULT 0, -- Minor units
+    currency VARCHAR(3) NOT NULL DEFAULT 'USD',
+    account_id BIGINT REFERENCES accounts(id) ON DELETE SET NULL,
+    category TEXT NOT NULL,
+    note TEXT,
+    frequency VARCHAR(10) NOT NULL, -- 'daily', 'weekly', 'monthly', 'yearly'
+    repeat_interval BIGINT NOT NULL DEFAULT 1,
+    day_of_month BIGINT, -- 1-31, or -1 for the last day of the month
+    start_date DATE NOT NULL,
+    end_date DATE,
+    next_run_date DATE NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE
+);
+CREATE INDEX IF NOT EXISTS idx_recurring_transactions_user_id ON recurring_transactions(user_id);
+CREATE INDEX IF NOT EXISTS idx_recurring_transactions_account_id ON recurring_transactions(account_id);
+CREATE INDEX IF NOT EXISTS idx_recurring_transactions_next_run_date ON recurring_transactions(next_run_date);
+CREATE INDEX IF NOT EXISTS idx_recurring_transactions_deleted_at ON recurring_transactions(deleted_at);
+
+-- Generated rows point back at their template. The unique index makes generation idempotent:
+-- each template produces at most one row per date, even across overlapping scheduler runs.
+ALTER TABLE incomes ADD COLUMN recurring_id BIGINT REFERENCES recurring_transactions(id) ON DELETE SET NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_incomes_recurring_date ON incomes(recurring_id, date);
+ALTER TABLE expenses ADD COLUMN recurring_id BIGINT REFERENCES recurring_transactions(id) ON DELETE SET NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_expenses_recurring_date ON expenses(recurring_id, date);
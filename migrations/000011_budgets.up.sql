@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS budgets (
+    id BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    category TEXT NOT NULL,
+    period VARCHAR(10) NOT NULL, -- 'weekly', 'monthly', 'yearly'
+    amount BIGINT NOT NULL DEFAULT 0, -- Minor units
+    currency VARCHAR(3) NOT NULL DEFAULT 'USD'
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_budget_category_period ON budgets(user_id, category, period);
+CREATE INDEX IF NOT EXISTS idx_budgets_deleted_at ON budgets(deleted_at);
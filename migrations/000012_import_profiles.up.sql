@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS import_profiles (
+    id BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    delimiter VARCHAR(1) NOT NULL DEFAULT ',',
+    has_header BOOLEAN NOT NULL DEFAULT TRUE,
+    skip_rows BIGINT NOT NULL DEFAULT 0,
+    date_column TEXT NOT NULL,
+    date_format VARCHAR(30) NOT NULL DEFAULT 'YYYY-MM-DD',
+    amount_column TEXT,
+    amount_sign VARCHAR(20) NOT NULL DEFAULT 'income_positive', -- 'income_positive', 'expense_positive'
+    debit_column TEXT,
+    credit_column TEXT,
+    decimal_separator VARCHAR(1) NOT NULL DEFAULT '.',
+    description_column TEXT,
+    category_column TEXT,
+    income_category TEXT NOT NULL DEFAULT 'Uncategorized',
+    expense_category TEXT NOT NULL DEFAULT 'Uncategorized',
+    account_id BIGINT REFERENCES accounts(id) ON DELETE SET NULL,
+    currency VARCHAR(3)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_import_profile_name ON import_profiles(user_id, name);
+CREATE INDEX IF NOT EXISTS idx_import_profiles_deleted_at ON import_profiles(deleted_at);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_expenses_user_external_id;
+ALTER TABLE expenses DROP COLUMN external_id;
+DROP INDEX IF EXISTS idx_incomes_user_external_id;
+ALTER TABLE incomes DROP COLUMN external_id;
@@ -0,0 +1,6 @@
+-- Imported rows keep the bank's own transaction ID (e.g. an OFX FITID) so a statement that is
+-- imported twice does not create the same transaction twice.
+ALTER TABLE incomes ADD COLUMN external_id VARCHAR(255);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_incomes_user_external_id ON incomes(user_id, external_id);
+ALTER TABLE expenses ADD COLUMN external_id VARCHAR(255);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_expenses_user_external_id ON expenses(user_id, external_id);
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS duplicate_pairs (
+    id BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type VARCHAR(10) NOT NULL, -- 'income' or 'expense'
+    transaction_id BIGINT NOT NULL, -- The later entry
+    duplicate_of_id BIGINT NOT NULL, -- The earlier entry
+    days_apart INTEGER NOT NULL DEFAULT 0,
+    matched_on VARCHAR(20) NOT NULL, -- 'note', 'category' or 'note+category'
+    status VARCHAR(20) NOT NULL DEFAULT 'pending', -- 'pending', 'merged' or 'dismissed'
+    kept_id BIGINT,
+    resolved_at TIMESTAMPTZ
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_duplicate_pairs_user_pair ON duplicate_pairs(user_id, type, transaction_id, duplicate_of_id);
+CREATE INDEX IF NOT EXISTS idx_duplicate_pairs_user_status ON duplicate_pairs(user_id, status);
+CREATE INDEX IF NOT EXISTS idx_duplicate_pairs_deleted_at ON duplicate_pairs(deleted_at);
@@ -0,0 +1,3 @@
+ALTER TABLE expenses DROP COLUMN category_rule_id;
+ALTER TABLE incomes DROP COLUMN category_rule_id;
+DROP TABLE IF EXISTS category_rules;
@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS category_rules (
+    id BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    priority INTEGER NOT NULL DEFAULT 0, -- Lower numbers are tried first
+    type VARCHAR(10) NOT NULL DEFAULT 'expense', -- 'income', 'expense' or 'both'
+    note_contains TEXT,
+    note_regex TEXT,
+    min_amount BIGINT, -- Minor units
+    max_amount BIGINT, -- Minor units
+    currency VARCHAR(3),
+    category TEXT NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE
+);
+CREATE INDEX IF NOT EXISTS idx_category_rules_user_priority ON category_rules(user_id, priority);
+CREATE INDEX IF NOT EXISTS idx_category_rules_deleted_at ON category_rules(deleted_at);
+
+-- The rule that chose an entry's category; NULL when it was set by hand.
+ALTER TABLE incomes ADD COLUMN category_rule_id BIGINT;
+ALTER TABLE expenses ADD COLUMN category_rule_id BIGINT;
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_expenses_category_id;
+ALTER TABLE expenses DROP COLUMN category_id;
+DROP INDEX IF EXISTS idx_incomes_category_id;
+ALTER TABLE incomes DROP COLUMN category_id;
+
+DROP TABLE IF EXISTS categories;
@@ -0,0 +1,59 @@
+CREATE TABLE IF NOT EXISTS categories (
+    id BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    kind VARCHAR(10) NOT NULL, -- 'income', 'expense'
+    name VARCHAR(100) NOT NULL,
+    parent_id BIGINT REFERENCES categories(id),
+    color VARCHAR(7), -- '#RRGGBB'
+    icon VARCHAR(50)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_categories_user_kind_name ON categories(user_id, kind, name);
+CREATE INDEX IF NOT EXISTS idx_categories_parent_id ON categories(parent_id);
+CREATE INDEX IF NOT EXISTS idx_categories_deleted_at ON categories(deleted_at);
+
+ALTER TABLE incomes ADD COLUMN category_id BIGINT REFERENCES categories(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_incomes_category_id ON incomes(category_id);
+ALTER TABLE expenses ADD COLUMN category_id BIGINT REFERENCES categories(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_expenses_category_id ON expenses(category_id);
+
+-- Categories used to be free text. Spellings that differ only in case or surrounding spaces
+-- ("Food", "food", "Food ") become one category, named after one of them.
+UPDATE incomes SET category = 'Uncategorized' WHERE TRIM(category) = '';
+UPDATE expenses SET category = 'Uncategorized' WHERE TRIM(category) = '';
+
+INSERT INTO categories (created_at, updated_at, user_id, kind, name)
+SELECT CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, user_id, 'income', MIN(SUBSTR(TRIM(category), 1, 100))
+FROM incomes GROUP BY user_id, LOWER(SUBSTR(TRIM(category), 1, 100));
+INSERT INTO categories (created_at, updated_at, user_id, kind, name)
+SELECT CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, user_id, 'expense', MIN(SUBSTR(TRIM(category), 1, 100))
+FROM expenses GROUP BY user_id, LOWER(SUBSTR(TRIM(category), 1, 100));
+
+UPDATE incomes SET category_id = (
+    SELECT c.id FROM categories c
+    WHERE c.user_id = incomes.user_id AND c.kind = 'income' AND LOWER(c.name) = LOWER(SUBSTR(TRIM(incomes.category), 1, 100))
+);
+UPDATE incomes SET category = (SELECT c.name FROM categories c WHERE c.id = incomes.category_id) WHERE category_id IS NOT NULL;
+UPDATE expenses SET category_id = (
+    SELECT c.id FROM categories c
+    WHERE c.user_id = expenses.user_id AND c.kind = 'expense' AND LOWER(c.name) = LOWER(SUBSTR(TRIM(expenses.category), 1, 100))
+);
+UPDATE expenses SET category = (SELECT c.name FROM categories c WHERE c.id = expenses.category_id) WHERE category_id IS NOT NULL;
+
+-- Budgets match expenses by category name, so they take the canonical spelling too, unless that
+-- would clash with another budget of the same period.
+UPDATE budgets SET category = (
+    SELECT c.name FROM categories c
+    WHERE c.user_id = budgets.user_id AND c.kind = 'expense' AND LOWER(c.name) = LOWER(TRIM(budgets.category))
+)
+WHERE EXISTS (
+    SELECT 1 FROM categories c
+    WHERE c.user_id = budgets.user_id AND c.kind = 'expense' AND LOWER(c.name) = LOWER(TRIM(budgets.category))
+)
+AND NOT EXISTS (
+    SELECT 1 FROM budgets other
+    WHERE other.user_id = budgets.user_id AND other.period = budgets.period AND other.id <> budgets.id
+    AND LOWER(TRIM(other.category)) = LOWER(TRIM(budgets.category))
+);
@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS tags (
+    id BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(50) NOT NULL -- Lower case, words joined with '-'
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tags_user_name ON tags(user_id, name);
+CREATE INDEX IF NOT EXISTS idx_tags_deleted_at ON tags(deleted_at);
+
+-- A tag can be on records of several types, so record_id has no foreign key.
+CREATE TABLE IF NOT EXISTS taggings (
+    id BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ,
+    tag_id BIGINT NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    record_type VARCHAR(10) NOT NULL, -- 'income', 'expense', 'debt', 'savings'
+    record_id BIGINT NOT NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_taggings_record_tag ON taggings(record_type, record_id, tag_id);
+CREATE INDEX IF NOT EXISTS idx_taggings_tag_id ON taggings(tag_id);
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS expense_splits (
+    id BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ,
+    expense_id BIGINT NOT NULL REFERENCES expenses(id) ON DELETE CASCADE,
+    amount BIGINT NOT NULL, -- Minor units, in the expense's currency
+    category TEXT NOT NULL,
+    category_id BIGINT REFERENCES categories(id) ON DELETE SET NULL,
+    note TEXT
+);
+CREATE INDEX IF NOT EXISTS idx_expense_splits_expense_id ON expense_splits(expense_id);
+CREATE INDEX IF NOT EXISTS idx_expense_splits_category_id ON expense_splits(category_id);
@@ -0,0 +1,16 @@
+-- user_id deliberately has no foreign key: when a user or record is deleted the row has to outlive it,
+-- so the orphan cleanup can find it and delete the stored file.
+CREATE TABLE IF NOT EXISTS attachments (
+    id BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ,
+    user_id BIGINT NOT NULL,
+    record_type VARCHAR(10) NOT NULL, -- 'income', 'expense', 'debt'
+    record_id BIGINT NOT NULL,
+    file_name VARCHAR(255) NOT NULL,
+    content_type VARCHAR(100) NOT NULL,
+    size BIGINT,
+    storage_key VARCHAR(255) NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_attachments_user_id ON attachments(user_id);
+CREATE INDEX IF NOT EXISTS idx_attachments_record ON attachments(record_type, record_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_attachments_storage_key ON attachments(storage_key);
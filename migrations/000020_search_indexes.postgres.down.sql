@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_savings_search;
+DROP INDEX IF EXISTS idx_debts_search;
+DROP INDEX IF EXISTS idx_expenses_search;
+DROP INDEX IF EXISTS idx_incomes_search;
@@ -0,0 +1,6 @@
+-- Full-text search indexes. The expressions must match searchSources in
+-- internal/services/search_service.go for PostgreSQL to use them. SQLite searches with LIKE instead.
+CREATE INDEX IF NOT EXISTS idx_incomes_search ON incomes USING GIN (to_tsvector('english', coalesce(category, '') || ' ' || coalesce(note, '')));
+CREATE INDEX IF NOT EXISTS idx_expenses_search ON expenses USING GIN (to_tsvector('english', coalesce(category, '') || ' ' || coalesce(note, '')));
+CREATE INDEX IF NOT EXISTS idx_debts_search ON debts USING GIN (to_tsvector('english', coalesce(debtor_name, '') || ' ' || coalesce(description, '')));
+CREATE INDEX IF NOT EXISTS idx_savings_search ON savings USING GIN (to_tsvector('english', coalesce(goal_name, '') || ' ' || coalesce(notes, '')));
@@ -0,0 +1,15 @@
+-- record_id deliberately has no foreign key to the audited tables: the history of a record has to
+-- outlive the record when it is purged.
+CREATE TABLE IF NOT EXISTS audit_entries (
+    id BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    record_type VARCHAR(10) NOT NULL, -- 'income', 'expense', 'debt', 'savings'
+    record_id BIGINT NOT NULL,
+    action VARCHAR(10) NOT NULL, -- 'create', 'update', 'delete', 'restore', 'purge'
+    actor_id BIGINT,
+    source VARCHAR(20) NOT NULL, -- 'api', 'bulk', 'import', 'recurring', 'retention'
+    changes TEXT
+);
+CREATE INDEX IF NOT EXISTS idx_audit_entries_created_at ON audit_entries(created_at);
+CREATE INDEX IF NOT EXISTS idx_audit_entries_record ON audit_entries(user_id, record_type, record_id);
@@ -0,0 +1,15 @@
+// Package migrations embeds the versioned SQL schema migrations so they ship inside the binary.
+//
+// Files are named NNNNNN_description.up.sql / NNNNNN_description.down.sql and are applied in
+// version order by database.Migrator. They are written for PostgreSQL; the only PostgreSQL-only
+// type names allowed are BIGSERIAL PRIMARY KEY and TIMESTAMPTZ, which the migrator rewrites for SQLite.
+// A migration that only makes sense on PostgreSQL, such as full-text indexes, is named
+// NNNNNN_description.postgres.up.sql / .postgres.down.sql and is only recorded on SQLite.
+package migrations
+
+import "embed"
+
+// FS holds every *.sql file in this directory.
+//
+//go:embed *.sql
+var FS embed.FS
@@ -0,0 +1,193 @@
+package migrations_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zayyadi/finance-tracker/internal/database"
+	"github.com/zayyadi/finance-tracker/internal/models"
+	"github.com/zayyadi/finance-tracker/internal/types"
+	"github.com/zayyadi/finance-tracker/migrations"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// migratedModels lists every GORM model whose table is owned by the migrations.
+var migratedModels = []interface{}{
+	&models.User{},
+	&models.Income{},
+	&models.Expense{},
+	&models.Savings{},
+	&models.Debt{},
+	&models.FinancialSummary{},
+	&models.ExchangeRate{},
+	&models.Account{},
+	&models.Transfer{},
+	&models.RecurringTransaction{},
+	&models.Budget{},
+	&models.ImportProfile{},
+	&models.DuplicatePair{},
+	&models.CategoryRule{},
+	&models.Category{},
+	&models.Tag{},
+	&models.Tagging{},
+	&models.ExpenseSplit{},
+	&models.Attachment{},
+	&models.AuditEntry{},
+}
+
+func setupMigrationTestDB(t *testing.T) *gorm.DB {
+	dsn := fmt.Sprintf("file:%s_%d?mode=memory&cache=shared&_foreign_keys=on", t.Name(), time.Now().UnixNano())
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{})
+	require.NoError(t, err, "Failed to connect to in-memory SQLite")
+
+	sqlDB, _ := db.DB()
+	t.Cleanup(func() { sqlDB.Close() })
+	return db
+}
+
+// TestMigrationsMatchModels guards against the migrations drifting from the GORM models:
+// every column and index a model declares must exist after running all migrations.
+func TestMigrationsMatchModels(t *testing.T) {
+	db := setupMigrationTestDB(t)
+	migrator, err := database.NewMigrator(db, migrations.FS)
+	require.NoError(t, err)
+
+	_, err = migrator.Up(0)
+	require.NoError(t, err)
+
+	for _, model := range migratedModels {
+		stmt := &gorm.Statement{DB: db}
+		require.NoError(t, stmt.Parse(model))
+		table := stmt.Schema.Table
+
+		assert.True(t, db.Migrator().HasTable(table), "table %s should exist", table)
+		for _, field := range stmt.Schema.Fields {
+			if field.DBName == "" {
+				continue
+			}
+			assert.True(t, db.Migrator().HasColumn(model, field.DBName), "column %s.%s should exist", table, field.DBName)
+		}
+		for _, idx := range stmt.Schema.ParseIndexes() {
+			assert.True(t, db.Migrator().HasIndex(model, idx.Name), "index %s on %s should exist", idx.Name, table)
+		}
+	}
+}
+
+func TestMigrationsUpDownRoundTrip(t *testing.T) {
+	db := setupMigrationTestDB(t)
+	migrator, err := database.NewMigrator(db, migrations.FS)
+	require.NoError(t, err)
+
+	applied, err := migrator.Up(0)
+	require.NoError(t, err)
+	assert.NotEmpty(t, applied)
+
+	// Running up again is a no-op.
+	again, err := migrator.Up(0)
+	require.NoError(t, err)
+	assert.Empty(t, again)
+
+	// The migrated schema is usable by the models.
+	user := models.User{Username: "migrated", Email: "migrated@example.com", PasswordHash: "hash"}
+	require.NoError(t, db.Create(&user).Error)
+	income := models.Income{UserID: user.ID, Amount: 10, Category: "Salary", Date: database.CustomDate{Time: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}}
+	require.NoError(t, db.Create(&income).Error)
+	var loaded models.Income
+	require.NoError(t, db.First(&loaded, income.ID).Error)
+	assert.Equal(t, "2024-01-01", loaded.Date.Format("2006-01-02"))
+
+	reverted, err := migrator.Down(0)
+	require.NoError(t, err)
+	assert.Len(t, reverted, len(applied))
+	for _, model := range migratedModels {
+		assert.False(t, db.Migrator().HasTable(model), "table for %T should be dropped", model)
+	}
+
+	statuses, err := migrator.Status()
+	require.NoError(t, err)
+	for _, status := range statuses {
+		assert.False(t, status.Applied)
+	}
+
+	_, err = migrator.Up(0)
+	assert.NoError(t, err, "Migrations should re-apply cleanly after a full rollback")
+}
+
+func TestMoneyMigrationConvertsToMinorUnits(t *testing.T) {
+	db := setupMigrationTestDB(t)
+	migrator, err := database.NewMigrator(db, migrations.FS)
+	require.NoError(t, err)
+
+	// Apply the schema as it was before amounts became minor units, and store a legacy row.
+	_, err = migrator.Up(6)
+	require.NoError(t, err)
+	require.NoError(t, db.Exec("INSERT INTO users (username, email, password_hash) VALUES ('legacy', 'legacy@example.com', 'hash')").Error)
+	require.NoError(t, db.Exec("INSERT INTO incomes (user_id, amount, category, date) VALUES (1, 12.34, 'Salary', '2024-01-01')").Error)
+
+	_, err = migrator.Up(1)
+	require.NoError(t, err)
+
+	var amount types.Money
+	require.NoError(t, db.Raw("SELECT amount FROM incomes WHERE category = 'Salary'").Scan(&amount).Error)
+	assert.Equal(t, types.NewMoneyFromMinor(1234), amount)
+
+	// Amounts that drift as floats sum exactly once stored as minor units.
+	for i := 0; i < 10; i++ {
+		require.NoError(t, db.Exec("INSERT INTO incomes (user_id, amount, category, date) VALUES (1, 10, 'Cents', '2024-01-01')").Error)
+	}
+	var total types.Money
+	require.NoError(t, db.Raw("SELECT COALESCE(SUM(amount), 0) FROM incomes WHERE category = 'Cents'").Scan(&total).Error)
+	assert.Equal(t, "1.00", total.String())
+
+	_, err = migrator.Down(1)
+	require.NoError(t, err)
+	var legacyAmount float64
+	require.NoError(t, db.Raw("SELECT amount FROM incomes WHERE category = 'Salary'").Scan(&legacyAmount).Error)
+	assert.InDelta(t, 12.34, legacyAmount, 0.0001)
+}
+
+func TestCategoryMigrationMergesSpellings(t *testing.T) {
+	db := setupMigrationTestDB(t)
+	migrator, err := database.NewMigrator(db, migrations.FS)
+	require.NoError(t, err)
+
+	// Free-text categories as they were stored before the category table existed.
+	_, err = migrator.Up(15)
+	require.NoError(t, err)
+	require.NoError(t, db.Exec("INSERT INTO users (username, email, password_hash) VALUES ('legacy', 'legacy@example.com', 'hash')").Error)
+	for _, category := range []string{"Food", "food", "Food ", "Rent", ""} {
+		require.NoError(t, db.Exec("INSERT INTO expenses (user_id, amount, category, date) VALUES (1, 100, ?, '2024-01-01')", category).Error)
+	}
+	require.NoError(t, db.Exec("INSERT INTO incomes (user_id, amount, category, date) VALUES (1, 100, 'food', '2024-01-01')").Error)
+	require.NoError(t, db.Exec("INSERT INTO budgets (user_id, category, period, amount) VALUES (1, 'FOOD', 'monthly', 500)").Error)
+
+	_, err = migrator.Up(1)
+	require.NoError(t, err)
+
+	var categories []models.Category
+	require.NoError(t, db.Order("kind, name").Find(&categories).Error)
+	require.Len(t, categories, 4)
+	assert.Equal(t, []string{"Food", "Rent", "Uncategorized"}, []string{categories[0].Name, categories[1].Name, categories[2].Name})
+	assert.Equal(t, "income", categories[3].Kind)
+	assert.Equal(t, "food", categories[3].Name, "Income and expense categories are kept apart")
+
+	var expenses []models.Expense
+	require.NoError(t, db.Order("id").Find(&expenses).Error)
+	for i, want := range []string{"Food", "Food", "Food", "Rent", "Uncategorized"} {
+		assert.Equal(t, want, expenses[i].Category)
+		require.NotNil(t, expenses[i].CategoryID)
+	}
+	assert.Equal(t, *expenses[0].CategoryID, *expenses[2].CategoryID)
+
+	var budget models.Budget
+	require.NoError(t, db.First(&budget).Error)
+	assert.Equal(t, "Food", budget.Category)
+
+	_, err = migrator.Down(1)
+	require.NoError(t, err)
+	assert.False(t, db.Migrator().HasTable("categories"))
+}